@@ -0,0 +1,1010 @@
+package calculator
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/tokenizers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// CompiledExpression implements an immutable parsed expression.
+// Unlike ExpressionCalculator it keeps no mutable state between evaluations,
+// so the same compiled expression can be evaluated concurrently from multiple goroutines
+// with different sets of variables. Each evaluation uses its own calculation stack.
+//
+// Calls of lazy functions like If, Choose and Coalesce are compiled into conditional jumps
+// using default functions. When functions passed during evaluation replace them with functions
+// that are evaluated differently, expressions created from parsers are compiled again
+// for those functions, while expressions created from tokens keep the compiled jumps.
+type CompiledExpression struct {
+	tokens            []*parsers.ExpressionToken
+	variableNames     []string
+	variantOperations variants.IVariantOperations
+	defaultFunctions  functions.IFunctionCollection
+	limits            EvaluationLimits
+	regexCache        *functions.RegexCache
+	originalTokens    []*tokenizers.Token
+	lazyCalls         []*parsers.LazyCall
+}
+
+// evaluationBudgetKey is the key of the evaluation budget in the evaluation context.
+type evaluationBudgetKey struct{}
+
+// evaluationBudget keeps the limits and the steps counter of evaluated expression,
+// so bodies of user-defined functions called by the expression share them.
+type evaluationBudget struct {
+	limits EvaluationLimits
+	step   *int
+}
+
+// NewCompiledExpression constructs this class from parsed expression tokens.
+//	Parameters:
+//		- tokens: The list of parsed expression tokens in reverse polish notation.
+//		- variantOperations: The manager for operations on variant values or nil to use type unsafe operations.
+//		- defaultFunctions: The list of functions used when no functions are set during evaluation
+//			or nil to use the standard functions.
+func NewCompiledExpression(tokens []*parsers.ExpressionToken,
+	variantOperations variants.IVariantOperations,
+	defaultFunctions functions.IFunctionCollection) *CompiledExpression {
+
+	if variantOperations == nil {
+		variantOperations = variants.NewTypeUnsafeVariantOperations()
+	}
+	if defaultFunctions == nil {
+		defaultFunctions = functions.NewDefaultFunctionCollection()
+	}
+
+	c := &CompiledExpression{
+		tokens:            make([]*parsers.ExpressionToken, len(tokens)),
+		variableNames:     []string{},
+		variantOperations: variantOperations,
+		defaultFunctions:  defaultFunctions,
+		regexCache:        functions.NewRegexCache(0),
+	}
+	copy(c.tokens, tokens)
+
+	// Parameters of lambdas are not included into the variables.
+	scopes := []string{}
+	scopeEnds := []int{}
+	for index, token := range c.tokens {
+		for len(scopeEnds) > 0 && scopeEnds[len(scopeEnds)-1] < index {
+			scopes = scopes[:len(scopes)-1]
+			scopeEnds = scopeEnds[:len(scopeEnds)-1]
+		}
+		if token.Type() == parsers.Lambda {
+			for _, parameter := range token.Value().AsArray()[0].AsArray() {
+				scopes = append(scopes, parameter.AsString())
+				scopeEnds = append(scopeEnds, index+token.Value().AsArray()[1].AsInteger())
+			}
+			continue
+		}
+		if token.Type() != parsers.Variable {
+			continue
+		}
+		name := token.Value().AsString()
+		found := false
+		for _, scope := range scopes {
+			if strings.EqualFold(scope, name) {
+				found = true
+				break
+			}
+		}
+		for _, v := range c.variableNames {
+			if v == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.variableNames = append(c.variableNames, name)
+		}
+	}
+
+	return c
+}
+
+// CompileExpression parses the expression string and creates a compiled expression
+// with type unsafe variant operations and standard functions.
+//	Parameters:
+//		- expression: The expression string.
+//	Returns: A compiled expression or error if expression has syntax errors.
+func CompileExpression(expression string) (*CompiledExpression, error) {
+	parser := parsers.NewExpressionParser()
+	err := parser.ParseString(expression)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompiledExpressionFromParser(parser, nil, nil), nil
+}
+
+// NewCompiledExpressionFromParser constructs this class from the parsed expression.
+// The original tokens are kept to compile the expression again when calls
+// of lazy functions are resolved differently during evaluation.
+//	Parameters:
+//		- parser: The parser with the parsed expression.
+//		- variantOperations: The manager for operations on variant values or nil to use type unsafe operations.
+//		- defaultFunctions: The list of functions used when no functions are set during evaluation
+//			or nil to use the standard functions.
+//	Returns: A compiled expression.
+func NewCompiledExpressionFromParser(parser *parsers.ExpressionParser,
+	variantOperations variants.IVariantOperations,
+	defaultFunctions functions.IFunctionCollection) *CompiledExpression {
+
+	c := NewCompiledExpression(parser.ResultTokens(), variantOperations, defaultFunctions)
+	c.setSource(parser)
+	return c
+}
+
+// setSource keeps the original tokens and calls of lazy functions of the parsed expression.
+func (c *CompiledExpression) setSource(parser *parsers.ExpressionParser) {
+	c.originalTokens = parser.OriginalTokens()
+	c.lazyCalls = parser.LazyCalls()
+}
+
+// WithDefaults creates a copy of this compiled expression with the specified variant operations
+// and default functions.
+//	Parameters:
+//		- variantOperations: The manager for operations on variant values or nil to use type unsafe operations.
+//		- defaultFunctions: The list of functions used when no functions are set during evaluation
+//			or nil to use the standard functions.
+//	Returns: A new compiled expression.
+func (c *CompiledExpression) WithDefaults(variantOperations variants.IVariantOperations,
+	defaultFunctions functions.IFunctionCollection) *CompiledExpression {
+
+	if variantOperations == nil {
+		variantOperations = variants.NewTypeUnsafeVariantOperations()
+	}
+	if defaultFunctions == nil {
+		defaultFunctions = functions.NewDefaultFunctionCollection()
+	}
+	result := *c
+	result.variantOperations = variantOperations
+	result.defaultFunctions = defaultFunctions
+	return &result
+}
+
+// Tokens gets the list of compiled expression tokens.
+func (c *CompiledExpression) Tokens() []*parsers.ExpressionToken {
+	result := make([]*parsers.ExpressionToken, len(c.tokens))
+	copy(result, c.tokens)
+	return result
+}
+
+// VariableNames gets the list of variable names used in the expression.
+func (c *CompiledExpression) VariableNames() []string {
+	result := make([]string, len(c.variableNames))
+	copy(result, c.variableNames)
+	return result
+}
+
+// VariantOperations gets the manager for operations on variant values.
+func (c *CompiledExpression) VariantOperations() variants.IVariantOperations {
+	return c.variantOperations
+}
+
+// DefaultFunctions gets the list of functions used when no functions are set during evaluation.
+func (c *CompiledExpression) DefaultFunctions() functions.IFunctionCollection {
+	return c.defaultFunctions
+}
+
+// Limits gets the limits applied during evaluation.
+func (c *CompiledExpression) Limits() EvaluationLimits {
+	return c.limits
+}
+
+// WithLimits creates a copy of this compiled expression with the specified evaluation limits.
+//	Parameters:
+//		- limits: The limits to be applied during evaluation.
+//	Returns: A new compiled expression.
+func (c *CompiledExpression) WithLimits(limits EvaluationLimits) *CompiledExpression {
+	result := *c
+	result.limits = limits
+	return &result
+}
+
+// Optimize creates a copy of this compiled expression with constant sub-expressions folded
+// and dead branches removed.
+// Function calls are resolved using default functions of this expression
+// and constant sub-expressions are folded with its limits.
+//	Returns: A new optimized compiled expression.
+func (c *CompiledExpression) Optimize() *CompiledExpression {
+	optimizer := NewExpressionOptimizer(c.variantOperations, c.defaultFunctions)
+	optimizer.SetLimits(c.limits)
+	result := NewCompiledExpression(optimizer.Optimize(c.tokens), c.variantOperations, c.defaultFunctions).
+		WithLimits(c.limits)
+	result.originalTokens = c.originalTokens
+	result.lazyCalls = c.lazyCalls
+	return result
+}
+
+// Evaluate this expression using specified variables and functions.
+// Calls of lazy functions replaced by the functions are compiled again as described in CompiledExpression.
+// The method is safe for concurrent use as long as the variables and functions
+// are not modified during evaluation.
+//	Parameters:
+//		- vars: The list of variables or nil if expression has no variables.
+//		- funcs: The list of functions or nil to use default functions.
+//	Returns: An evaluated expression value.
+func (c *CompiledExpression) Evaluate(
+	vars variables.IVariableCollection, funcs functions.IFunctionCollection) (*variants.Variant, error) {
+	return c.EvaluateWithContext(context.Background(), vars, funcs)
+}
+
+// EvaluateWithContext evaluates this expression using specified variables and functions.
+// The evaluation stops when the context is cancelled or any of the evaluation limits is exceeded.
+// The context is passed to functions that implement IContextFunction interface
+// together with the cache of regular expressions compiled for this expression.
+// Calls of lazy functions replaced by the functions are compiled again as described in CompiledExpression.
+//	Parameters:
+//		- ctx: The context to control the evaluation.
+//		- vars: The list of variables or nil if expression has no variables.
+//		- funcs: The list of functions or nil to use default functions.
+//	Returns: An evaluated expression value.
+func (c *CompiledExpression) EvaluateWithContext(ctx context.Context,
+	vars variables.IVariableCollection, funcs functions.IFunctionCollection) (*variants.Variant, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if vars == nil {
+		vars = variables.NewVariableCollection()
+	}
+	if funcs == nil {
+		funcs = c.defaultFunctions
+	}
+
+	expression, err := c.forFunctions(funcs)
+	if err != nil {
+		return nil, err
+	}
+	step := 0
+	return expression.evaluateTokens(expression.evaluationContext(ctx, &step), expression.tokens, vars, funcs, &step)
+}
+
+// forFunctions gets this expression compiled for the list of functions.
+// When calls of lazy functions compiled into jumps are resolved to functions
+// that are evaluated differently, the expression is compiled again from the original tokens,
+// so for instance a function that replaces If is called with all parameters evaluated.
+func (c *CompiledExpression) forFunctions(funcs functions.IFunctionCollection) (*CompiledExpression, error) {
+	if c.originalTokens == nil {
+		return c, nil
+	}
+	matches := true
+	for _, call := range c.lazyCalls {
+		if !call.Matches(funcs) {
+			matches = false
+			break
+		}
+	}
+	if matches {
+		return c, nil
+	}
+
+	parser := parsers.NewExpressionParser()
+	parser.SetFunctions(funcs)
+	err := parser.ParseTokens(c.originalTokens)
+	if err != nil {
+		return nil, err
+	}
+	result := NewCompiledExpression(parser.ResultTokens(), c.variantOperations, c.defaultFunctions)
+	result.limits = c.limits
+	result.regexCache = c.regexCache
+	return result, nil
+}
+
+// evaluationContext creates a context that passes the cache of regular expressions,
+// the size limits and the steps counter to functions called during evaluation.
+func (c *CompiledExpression) evaluationContext(ctx context.Context, step *int) context.Context {
+	ctx = functions.ContextWithRegexCache(ctx, c.regexCache)
+	if c.limits.MaxArrayLength > 0 || c.limits.MaxStringLength > 0 {
+		ctx = functions.ContextWithSizeLimits(ctx, c.limits.SizeLimits())
+	}
+	return context.WithValue(ctx, evaluationBudgetKey{}, &evaluationBudget{limits: c.limits, step: step})
+}
+
+// evaluateNested evaluates this expression called from another expression, like a body
+// of user-defined function. The nested expression counts its steps together with the calling one
+// and is evaluated with its limits. Without the calling expression it is evaluated as usual.
+func (c *CompiledExpression) evaluateNested(ctx context.Context,
+	vars variables.IVariableCollection, funcs functions.IFunctionCollection) (*variants.Variant, error) {
+
+	budget, ok := ctx.Value(evaluationBudgetKey{}).(*evaluationBudget)
+	if !ok {
+		return c.EvaluateWithContext(ctx, vars, funcs)
+	}
+
+	if funcs == nil {
+		funcs = c.defaultFunctions
+	}
+	expression, err := c.forFunctions(funcs)
+	if err != nil {
+		return nil, err
+	}
+	if expression.limits != budget.limits {
+		expression = expression.WithLimits(budget.limits)
+	}
+	return expression.evaluateTokens(ctx, expression.tokens, vars, funcs, budget.step)
+}
+
+// evaluateTokens evaluates a list of tokens with its own calculation stack.
+// The steps counter is shared by the expression and all lambdas invoked during evaluation.
+func (c *CompiledExpression) evaluateTokens(ctx context.Context, tokens []*parsers.ExpressionToken,
+	vars variables.IVariableCollection, funcs functions.IFunctionCollection, step *int) (*variants.Variant, error) {
+
+	stack := NewCalculationStack()
+	for index := 0; index < len(tokens); index++ {
+		token := tokens[index]
+		err := c.checkStep(ctx, *step, token)
+		if err != nil {
+			return nil, err
+		}
+		*step++
+
+		if ok, skip, err := c.evaluateJump(token, stack); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+			index += skip
+		} else if ok, skip := c.evaluateLambda(ctx, tokens, index, stack, vars, funcs, step); ok {
+			index += skip
+		} else if ok, err := c.evaluateConstant(token, stack); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+		} else if ok, err := c.evaluateVariable(token, stack, vars); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+		} else if ok, err := c.evaluateFunction(ctx, token, stack, funcs); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+		} else if ok, err := c.evaluateLogical(token, stack); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+		} else if ok, err := c.evaluateArithmetical(token, stack); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+		} else if ok, err := c.evaluateBoolean(token, stack); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+		} else if ok, err := c.evaluateOther(token, stack); ok || err != nil {
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			err := errors.NewExpressionError("", "INTERNAL", "Internal error", token.Line(), token.Column())
+			return nil, err
+		}
+
+		err = c.checkResult(token, stack)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if stack.Length() != 1 {
+		err := errors.NewExpressionError("", "INTERNAL", "Internal error", 0, 0)
+		return nil, err
+	}
+
+	return stack.Pop(), nil
+}
+
+// checkStep checks the context and the steps limit before processing the next token.
+func (c *CompiledExpression) checkStep(ctx context.Context, step int, token *parsers.ExpressionToken) error {
+	if ctx.Err() != nil {
+		return errors.NewExpressionError("", "EVALUATION_CANCELLED",
+			"Evaluation was cancelled: "+ctx.Err().Error(), token.Line(), token.Column())
+	}
+	if c.limits.MaxSteps > 0 && step >= c.limits.MaxSteps {
+		return errors.NewExpressionError("", "STEPS_LIMIT_EXCEEDED",
+			"Evaluation exceeded the limit of "+strconv.Itoa(c.limits.MaxSteps)+" steps",
+			token.Line(), token.Column())
+	}
+	return nil
+}
+
+// checkResult checks the stack depth and the size of value produced by the processed token.
+func (c *CompiledExpression) checkResult(token *parsers.ExpressionToken, stack *CalculationStack) error {
+	if c.limits.MaxStackDepth > 0 && stack.Length() > c.limits.MaxStackDepth {
+		return errors.NewExpressionError("", "STACK_LIMIT_EXCEEDED",
+			"Evaluation exceeded the stack depth of "+strconv.Itoa(c.limits.MaxStackDepth),
+			token.Line(), token.Column())
+	}
+
+	// Constants and variables are not produced by the expression.
+	if token.Type() == parsers.Constant || token.Type() == parsers.Variable || stack.Length() == 0 {
+		return nil
+	}
+
+	value := stack.Peek()
+	if c.limits.MaxArrayLength > 0 && value.Type() == variants.Array &&
+		value.Length() > c.limits.MaxArrayLength {
+		return errors.NewExpressionError("", "ARRAY_LIMIT_EXCEEDED",
+			"Array length exceeded the limit of "+strconv.Itoa(c.limits.MaxArrayLength),
+			token.Line(), token.Column())
+	}
+	if c.limits.MaxStringLength > 0 && value.Type() == variants.String &&
+		utf8.RuneCountInString(value.AsString()) > c.limits.MaxStringLength {
+		return errors.NewExpressionError("", "STRING_LIMIT_EXCEEDED",
+			"String length exceeded the limit of "+strconv.Itoa(c.limits.MaxStringLength),
+			token.Line(), token.Column())
+	}
+	return nil
+}
+
+func (c *CompiledExpression) evaluateJump(
+	token *parsers.ExpressionToken, stack *CalculationStack) (bool, int, error) {
+
+	switch token.Type() {
+	case parsers.Jump:
+		{
+			return true, token.Value().AsInteger(), nil
+		}
+	case parsers.JumpIfFalse:
+		{
+			condition, err := c.variantOperations.Convert(stack.Pop(), variants.Boolean)
+			if err != nil {
+				return false, 0, err
+			}
+			if !condition.AsBoolean() {
+				return true, token.Value().AsInteger(), nil
+			}
+			return true, 0, nil
+		}
+	case parsers.ShortCircuitAnd:
+		{
+			// The result is defined by false first operand.
+			// Null first operand needs the second one, since Null AND false is false.
+			value := stack.Peek()
+			if value.Type() == variants.Boolean && !value.AsBoolean() {
+				return true, token.Value().AsInteger(), nil
+			}
+			return true, 0, nil
+		}
+	case parsers.ShortCircuitOr:
+		{
+			// The result is defined by true first operand.
+			// Null first operand needs the second one, since Null OR true is true.
+			value := stack.Peek()
+			if value.Type() == variants.Boolean && value.AsBoolean() {
+				return true, token.Value().AsInteger(), nil
+			}
+			return true, 0, nil
+		}
+	case parsers.ShortCircuitCoalesce:
+		{
+			// The result is defined by not null first operand.
+			if !stack.Peek().IsNull() {
+				return true, token.Value().AsInteger(), nil
+			}
+			return true, 0, nil
+		}
+	case parsers.JumpTable:
+		{
+			value := stack.Pop()
+			condition, err := c.variantOperations.Convert(value, variants.Integer)
+			if err != nil {
+				return false, 0, err
+			}
+
+			branches := token.Value().AsArray()
+			branchIndex := condition.AsInteger()
+			if branchIndex < 0 {
+				err := errors.NewExpressionError("", "INDEX_OUT_OF_RANGE",
+					"Choice index "+strconv.Itoa(branchIndex)+" is out of range",
+					token.Line(), token.Column())
+				return false, 0, err
+			}
+			if branchIndex > len(branches) {
+				err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
+					"Expected at least "+strconv.Itoa(branchIndex+1)+" parameters",
+					token.Line(), token.Column())
+				return false, 0, err
+			}
+
+			skip := 0
+			if branchIndex == 0 {
+				// Zero index selects the index value itself.
+				stack.Push(value)
+				branchIndex = len(branches) + 1
+			}
+			for i := 0; i < branchIndex-1; i++ {
+				skip += branches[i].AsInteger()
+			}
+			return true, skip, nil
+		}
+	}
+
+	return false, 0, nil
+}
+
+func (c *CompiledExpression) evaluateLambda(ctx context.Context,
+	tokens []*parsers.ExpressionToken, index int, stack *CalculationStack,
+	vars variables.IVariableCollection, funcs functions.IFunctionCollection, step *int) (bool, int) {
+
+	token := tokens[index]
+	if token.Type() != parsers.Lambda {
+		return false, 0
+	}
+
+	definition := token.Value().AsArray()
+	parameters := []string{}
+	for _, parameter := range definition[0].AsArray() {
+		parameters = append(parameters, parameter.AsString())
+	}
+	length := definition[1].AsInteger()
+
+	lambda := &compiledLambda{
+		expression: c,
+		ctx:        ctx,
+		parameters: parameters,
+		tokens:     tokens[index+1 : index+1+length],
+		vars:       vars,
+		funcs:      funcs,
+		step:       step,
+	}
+	stack.Push(variants.VariantFromObject(lambda))
+	return true, length
+}
+
+func (c *CompiledExpression) evaluateConstant(
+	token *parsers.ExpressionToken, stack *CalculationStack) (bool, error) {
+	if token.Type() == parsers.Constant {
+		stack.Push(token.Value())
+		return true, nil
+	}
+	return false, nil
+}
+
+func (c *CompiledExpression) evaluateVariable(
+	token *parsers.ExpressionToken, stack *CalculationStack,
+	vars variables.IVariableCollection) (bool, error) {
+
+	if token.Type() == parsers.Variable {
+		variable := vars.FindByName(token.Value().AsString())
+		if variable == nil {
+			err := errors.NewExpressionError("", "VAR_NOT_FOUND",
+				"Variable "+token.Value().AsString()+" was not found.",
+				token.Line(), token.Column())
+			return false, err
+		}
+
+		stack.Push(variable.Value())
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (c *CompiledExpression) evaluateFunction(ctx context.Context,
+	token *parsers.ExpressionToken, stack *CalculationStack,
+	funcs functions.IFunctionCollection) (bool, error) {
+
+	if token.Type() == parsers.Function {
+		function := funcs.FindByName(token.Value().AsString())
+		if function == nil {
+			err := errors.NewExpressionError("", "FUNC_NOT_FOUND",
+				"Function "+token.Value().AsString()+" was not found.",
+				token.Line(), token.Column())
+			return false, err
+		}
+
+		// Prepare parameters
+		parameters := []*variants.Variant{}
+		paramCount := stack.Pop().AsInteger()
+		for paramCount > 0 {
+			parameters = append([]*variants.Variant{stack.Pop()}, parameters...)
+			paramCount = paramCount - 1
+		}
+
+		var functionResult *variants.Variant
+		var err error
+		if contextFunction, ok := function.(functions.IContextFunction); ok {
+			functionResult, err = contextFunction.CalculateWithContext(ctx, parameters, c.variantOperations)
+		} else {
+			functionResult, err = function.Calculate(parameters, c.variantOperations)
+		}
+		if err != nil {
+			return false, errors.ErrorWithPosition(err, token.Line(), token.Column())
+		}
+
+		stack.Push(functionResult)
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (c *CompiledExpression) evaluateLogical(
+	token *parsers.ExpressionToken, stack *CalculationStack) (bool, error) {
+
+	switch token.Type() {
+	case parsers.And:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.And(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Or:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Or(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Xor:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Xor(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Not:
+		{
+			value := stack.Pop()
+			result, err := c.variantOperations.Not(value)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (c *CompiledExpression) evaluateArithmetical(
+	token *parsers.ExpressionToken, stack *CalculationStack) (bool, error) {
+
+	switch token.Type() {
+	case parsers.Plus:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Add(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Minus:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Sub(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Star:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Mul(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Slash:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Div(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Procent:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Mod(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Power:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Pow(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Unary:
+		{
+			value := stack.Pop()
+			result, err := c.variantOperations.Negative(value)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.ShiftLeft:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Lsh(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.ShiftRight:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Rsh(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (c *CompiledExpression) evaluateBoolean(
+	token *parsers.ExpressionToken, stack *CalculationStack) (bool, error) {
+
+	switch token.Type() {
+	case parsers.Equal:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Equal(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.NotEqual:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.NotEqual(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.More:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.More(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Less:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Less(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.EqualMore:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.MoreEqual(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.EqualLess:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.LessEqual(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (c *CompiledExpression) evaluateOther(
+	token *parsers.ExpressionToken, stack *CalculationStack) (bool, error) {
+
+	switch token.Type() {
+	case parsers.Duplicate:
+		{
+			stack.Push(stack.Peek())
+			return true, nil
+		}
+	case parsers.Pop:
+		{
+			stack.Pop()
+			return true, nil
+		}
+	case parsers.In:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.In(value2, value1)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.NotIn:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.In(value2, value1)
+			if err != nil {
+				return false, err
+			}
+			result = variants.VariantFromBoolean(!result.AsBoolean())
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Like:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Like(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.NotLike:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.Like(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			if !result.IsNull() {
+				result = variants.VariantFromBoolean(!result.AsBoolean())
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Coalesce:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			if value1.IsNull() {
+				value1 = value2
+			}
+			stack.Push(value1)
+			return true, nil
+		}
+	case parsers.Matches, parsers.NotMatches:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.matchPattern(token, value1, value2)
+			if err != nil {
+				return false, err
+			}
+			if token.Type() == parsers.NotMatches && !result.IsNull() {
+				result = variants.VariantFromBoolean(!result.AsBoolean())
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Element:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.GetElement(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.Member:
+		{
+			value2 := stack.Pop()
+			value1 := stack.Pop()
+			result, err := c.variantOperations.GetMember(value1, value2)
+			if err != nil {
+				return false, err
+			}
+			stack.Push(result)
+			return true, nil
+		}
+	case parsers.IsNull:
+		{
+			stack.Push(variants.VariantFromBoolean(stack.Pop().IsNull()))
+			return true, nil
+		}
+	case parsers.IsNotNull:
+		{
+			stack.Push(variants.VariantFromBoolean(!stack.Pop().IsNull()))
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// matchPattern checks if the value contains a match of the regular expression pattern.
+// The result is null when any of the values is null.
+func (c *CompiledExpression) matchPattern(token *parsers.ExpressionToken,
+	value *variants.Variant, pattern *variants.Variant) (*variants.Variant, error) {
+
+	if value.IsNull() || pattern.IsNull() {
+		return variants.EmptyVariant(), nil
+	}
+	value, err := c.variantOperations.Convert(value, variants.String)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err = c.variantOperations.Convert(pattern, variants.String)
+	if err != nil {
+		return nil, err
+	}
+
+	regex, err := c.regexCache.Compile(pattern.AsString())
+	if err != nil {
+		return nil, errors.ErrorWithPosition(err, token.Line(), token.Column())
+	}
+	return variants.VariantFromBoolean(regex.MatchString(value.AsString())), nil
+}
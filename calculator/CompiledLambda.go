@@ -0,0 +1,48 @@
+package calculator
+
+import (
+	"context"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// compiledLambda implements a lambda expression created during evaluation.
+// It captures variables and functions of the evaluation where it was created
+// and shares its context and steps counter, so evaluation limits apply to lambda calls.
+type compiledLambda struct {
+	expression *CompiledExpression
+	ctx        context.Context
+	parameters []string
+	tokens     []*parsers.ExpressionToken
+	vars       variables.IVariableCollection
+	funcs      functions.IFunctionCollection
+	step       *int
+}
+
+// Parameters the names of lambda parameters.
+func (c *compiledLambda) Parameters() []string {
+	result := make([]string, len(c.parameters))
+	copy(result, c.parameters)
+	return result
+}
+
+// Invoke evaluates the lambda body with the specified parameter values.
+// Parameters are scoped variables that shadow captured variables with the same names.
+// Missing parameters are set to null and extra parameters are ignored.
+//	Parameters:
+//		- parameters: A list with parameter values.
+//	Returns: The evaluated lambda result.
+func (c *compiledLambda) Invoke(parameters []*variants.Variant) (*variants.Variant, error) {
+	vars := variables.NewScopedVariableCollection(c.vars)
+	for i, name := range c.parameters {
+		value := variants.EmptyVariant()
+		if i < len(parameters) {
+			value = parameters[i]
+		}
+		vars.Add(variables.NewVariable(name, value))
+	}
+	return c.expression.evaluateTokens(c.ctx, c.tokens, vars, c.funcs, c.step)
+}
@@ -0,0 +1,38 @@
+package calculator
+
+import "github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+
+// EvaluationLimits defines limits that protect evaluation of untrusted expressions.
+// Zero value of any limit means that the limit is not applied.
+//
+// Sizes of values produced by operators are checked after the values are created.
+// Array and string limits are also passed to functions in the evaluation context as functions.SizeLimits,
+// so standard functions that build large values (Array, Split, Join, Replace, Repeat, PadLeft, PadRight)
+// check the sizes before allocating them. Custom functions shall do the same using functions.SizeLimitsFromContext,
+// otherwise the limits do not bound the memory they allocate.
+type EvaluationLimits struct {
+	// MaxSteps is the maximum number of processed expression tokens.
+	MaxSteps int
+
+	// MaxStackDepth is the maximum number of values on the calculation stack.
+	MaxStackDepth int
+
+	// MaxArrayLength is the maximum length of arrays produced by operations and functions.
+	MaxArrayLength int
+
+	// MaxStringLength is the maximum length (in characters) of strings produced by operations and functions.
+	MaxStringLength int
+}
+
+// NewEvaluationLimits creates evaluation limits with all limits turned off.
+func NewEvaluationLimits() EvaluationLimits {
+	return EvaluationLimits{}
+}
+
+// SizeLimits gets the array and string limits passed to functions.
+func (c EvaluationLimits) SizeLimits() functions.SizeLimits {
+	return functions.SizeLimits{
+		MaxArrayLength:  c.MaxArrayLength,
+		MaxStringLength: c.MaxStringLength,
+	}
+}
@@ -1,7 +1,9 @@
 package calculator
 
 import (
-	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"context"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
@@ -16,6 +18,11 @@ type ExpressionCalculator struct {
 	variantOperations variants.IVariantOperations
 	parser            *parsers.ExpressionParser
 	autoVariables     bool
+	autoOptimize      bool
+	optimizedTokens   []*parsers.ExpressionToken
+	compiled          *CompiledExpression
+	limits            EvaluationLimits
+	regexCache        *functions.RegexCache
 }
 
 // NewExpressionCalculator constructs this class with default parameters.
@@ -26,7 +33,9 @@ func NewExpressionCalculator() *ExpressionCalculator {
 		variantOperations: variants.NewTypeUnsafeVariantOperations(),
 		parser:            parsers.NewExpressionParser(),
 		autoVariables:     true,
+		regexCache:        functions.NewRegexCache(0),
 	}
+	c.parser.SetFunctions(c.defaultFunctions)
 	return c
 }
 
@@ -52,6 +61,9 @@ func (c *ExpressionCalculator) Expression() string {
 
 // SetExpression sets the expression string.
 func (c *ExpressionCalculator) SetExpression(value string) error {
+	c.optimizedTokens = nil
+	c.compiled = nil
+	c.regexCache = functions.NewRegexCache(0)
 	err := c.parser.SetExpression(value)
 	if err != nil {
 		return err
@@ -69,6 +81,9 @@ func (c *ExpressionCalculator) OriginalTokens() []*tokenizers.Token {
 }
 
 func (c *ExpressionCalculator) SetOriginalTokens(value []*tokenizers.Token) {
+	c.optimizedTokens = nil
+	c.compiled = nil
+	c.regexCache = functions.NewRegexCache(0)
 	c.parser.SetOriginalTokens(value)
 	if c.autoVariables {
 		c.CreateVariables(c.defaultVariables)
@@ -85,6 +100,19 @@ func (c *ExpressionCalculator) SetAutoVariables(value bool) {
 	c.autoVariables = value
 }
 
+// AutoOptimize gets the flag to turn on optimization of the expression before evaluation.
+func (c *ExpressionCalculator) AutoOptimize() bool {
+	return c.autoOptimize
+}
+
+// SetAutoOptimize sets the flag to turn on optimization of the expression before evaluation.
+// The expression is optimized once using default functions, the current variant operations and limits.
+func (c *ExpressionCalculator) SetAutoOptimize(value bool) {
+	c.autoOptimize = value
+	c.optimizedTokens = nil
+	c.compiled = nil
+}
+
 // VariantOperations gets the manager for operations on variant values.
 func (c *ExpressionCalculator) VariantOperations() variants.IVariantOperations {
 	return c.variantOperations
@@ -93,6 +121,20 @@ func (c *ExpressionCalculator) VariantOperations() variants.IVariantOperations {
 // SetVariantOperations sets the manager for operations on variant values.
 func (c *ExpressionCalculator) SetVariantOperations(value variants.IVariantOperations) {
 	c.variantOperations = value
+	c.optimizedTokens = nil
+	c.compiled = nil
+}
+
+// Limits gets the limits applied during evaluation.
+func (c *ExpressionCalculator) Limits() EvaluationLimits {
+	return c.limits
+}
+
+// SetLimits sets the limits applied during evaluation.
+func (c *ExpressionCalculator) SetLimits(value EvaluationLimits) {
+	c.limits = value
+	c.optimizedTokens = nil
+	c.compiled = nil
 }
 
 // DefaultVariables the list with default variables.
@@ -101,6 +143,7 @@ func (c *ExpressionCalculator) DefaultVariables() variables.IVariableCollection
 }
 
 // DefaultFunctions the list with default functions.
+// Lazy functions like If and Choose are found in this list when the expression is set.
 func (c *ExpressionCalculator) DefaultFunctions() functions.IFunctionCollection {
 	return c.defaultFunctions
 }
@@ -115,6 +158,21 @@ func (c *ExpressionCalculator) ResultTokens() []*parsers.ExpressionToken {
 	return c.parser.ResultTokens()
 }
 
+// SyntaxTree the abstract syntax tree of the expression or nil if the expression is empty.
+func (c *ExpressionCalculator) SyntaxTree() ast.Node {
+	return c.parser.SyntaxTree()
+}
+
+// OptimizedTokens the list of processed expression tokens after optimization.
+func (c *ExpressionCalculator) OptimizedTokens() []*parsers.ExpressionToken {
+	if c.optimizedTokens == nil {
+		optimizer := NewExpressionOptimizer(c.variantOperations, c.defaultFunctions)
+		optimizer.SetLimits(c.limits)
+		c.optimizedTokens = optimizer.Optimize(c.ResultTokens())
+	}
+	return c.optimizedTokens
+}
+
 // CreateVariables populates the specified variables list with variables from parsed expression.
 //	Parameters:
 //		- variables: The list of variables to be populated.
@@ -126,8 +184,35 @@ func (c *ExpressionCalculator) CreateVariables(vars variables.IVariableCollectio
 	}
 }
 
+// Compile creates an immutable compiled expression from the currently parsed expression.
+// The compiled expression can be safely evaluated from multiple goroutines.
+// When AutoOptimize is set the compiled expression uses optimized tokens.
+// Compiled expressions share the cache of regular expressions until the expression is changed.
+// The compiled expression is cached until the expression, variant operations, limits
+// or optimization flag are changed.
+//	Returns: A compiled expression.
+func (c *ExpressionCalculator) Compile() *CompiledExpression {
+	if c.compiled != nil {
+		return c.compiled
+	}
+
+	tokens := c.ResultTokens()
+	if c.autoOptimize {
+		tokens = c.OptimizedTokens()
+	}
+	result := NewCompiledExpression(tokens, c.variantOperations, c.defaultFunctions).
+		WithLimits(c.limits)
+	result.setSource(c.parser)
+	result.regexCache = c.regexCache
+	c.compiled = result
+	return result
+}
+
 // Clear cleans up this calculator from all data.
 func (c *ExpressionCalculator) Clear() {
+	c.optimizedTokens = nil
+	c.compiled = nil
+	c.regexCache = functions.NewRegexCache(0)
 	c.parser.Clear()
 	c.defaultVariables.Clear()
 }
@@ -148,6 +233,10 @@ func (c *ExpressionCalculator) EvaluateUsingVariables(
 }
 
 // EvaluateUsingVariablesAndFunctions evaluates this expression using specified variables and functions.
+// Calls of lazy functions like If, Choose, Coalesce and IfNull are compiled using default functions.
+// When the functions replace them with functions that are evaluated differently, the expression
+// is compiled again for this evaluation, so the replacements are called with all parameters evaluated.
+// The '??' operator is not a function call, so it is not affected by the functions.
 //	Parameters:
 //		- variables: The list of variables
 //		- functions: The list of functions.
@@ -155,7 +244,6 @@ func (c *ExpressionCalculator) EvaluateUsingVariables(
 func (c *ExpressionCalculator) EvaluateUsingVariablesAndFunctions(
 	vars variables.IVariableCollection, funcs functions.IFunctionCollection) (*variants.Variant, error) {
 
-	stack := NewCalculationStack()
 	if vars == nil {
 		vars = c.defaultVariables
 	}
@@ -163,395 +251,26 @@ func (c *ExpressionCalculator) EvaluateUsingVariablesAndFunctions(
 		funcs = c.defaultFunctions
 	}
 
-	for _, token := range c.ResultTokens() {
-		if ok, err := c.evaluateConstant(token, stack); ok || err != nil {
-			if err != nil {
-				return nil, err
-			}
-		} else if ok, err := c.evaluateVariable(token, stack, vars); ok || err != nil {
-			if err != nil {
-				return nil, err
-			}
-		} else if ok, err := c.evaluateFunction(token, stack, funcs); ok || err != nil {
-			if err != nil {
-				return nil, err
-			}
-		} else if ok, err := c.evaluateLogical(token, stack); ok || err != nil {
-			if err != nil {
-				return nil, err
-			}
-		} else if ok, err := c.evaluateArithmetical(token, stack); ok || err != nil {
-			if err != nil {
-				return nil, err
-			}
-		} else if ok, err := c.evaluateBoolean(token, stack); ok || err != nil {
-			if err != nil {
-				return nil, err
-			}
-		} else if ok, err := c.evaluateOther(token, stack); ok || err != nil {
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			err := errors.NewExpressionError("", "INTERNAL", "Internal error", token.Line(), token.Column())
-			return nil, err
-		}
-	}
-
-	if stack.Length() != 1 {
-		err := errors.NewExpressionError("", "INTERNAL", "Internal error", 0, 0)
-		return nil, err
-	}
-
-	return stack.Pop(), nil
-}
-
-func (c *ExpressionCalculator) evaluateConstant(
-	token *parsers.ExpressionToken, stack *CalculationStack) (bool, error) {
-	if token.Type() == parsers.Constant {
-		stack.Push(token.Value())
-		return true, nil
-	}
-	return false, nil
-}
-
-func (c *ExpressionCalculator) evaluateVariable(
-	token *parsers.ExpressionToken, stack *CalculationStack,
-	vars variables.IVariableCollection) (bool, error) {
-
-	if token.Type() == parsers.Variable {
-		variable := vars.FindByName(token.Value().AsString())
-		if variable == nil {
-			err := errors.NewExpressionError("", "VAR_NOT_FOUND",
-				"Variable "+token.Value().AsString()+" was not found.",
-				token.Line(), token.Column())
-			return false, err
-		}
-
-		stack.Push(variable.Value())
-		return true, nil
-	}
-
-	return false, nil
-}
-
-func (c *ExpressionCalculator) evaluateFunction(
-	token *parsers.ExpressionToken, stack *CalculationStack,
-	funcs functions.IFunctionCollection) (bool, error) {
-
-	if token.Type() == parsers.Function {
-		function := funcs.FindByName(token.Value().AsString())
-		if function == nil {
-			err := errors.NewExpressionError("", "FUNC_NOT_FOUND",
-				"Function "+token.Value().AsString()+" was not found.",
-				token.Line(), token.Column())
-			return false, err
-		}
-
-		// Prepare parameters
-		parameters := []*variants.Variant{}
-		paramCount := stack.Pop().AsInteger()
-		for paramCount > 0 {
-			parameters = append([]*variants.Variant{stack.Pop()}, parameters...)
-			paramCount = paramCount - 1
-		}
-
-		functionResult, err := function.Calculate(parameters, c.variantOperations)
-		if err != nil {
-			return false, err
-		}
-
-		stack.Push(functionResult)
-
-		return true, nil
-	}
-
-	return false, nil
+	return c.Compile().Evaluate(vars, funcs)
 }
 
-func (c *ExpressionCalculator) evaluateLogical(
-	token *parsers.ExpressionToken, stack *CalculationStack) (bool, error) {
-
-	switch token.Type() {
-	case parsers.And:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.And(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Or:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Or(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Xor:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Xor(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Not:
-		{
-			value := stack.Pop()
-			result, err := c.variantOperations.Not(value)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	}
-
-	return false, nil
-}
-
-func (c *ExpressionCalculator) evaluateArithmetical(
-	token *parsers.ExpressionToken, stack *CalculationStack) (bool, error) {
-
-	switch token.Type() {
-	case parsers.Plus:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Add(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Minus:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Sub(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Star:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Mul(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Slash:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Div(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Procent:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Mod(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Power:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Pow(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Unary:
-		{
-			value := stack.Pop()
-			result, err := c.variantOperations.Negative(value)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.ShiftLeft:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Lsh(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.ShiftRight:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Rsh(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	}
-
-	return false, nil
-}
+// EvaluateWithContext evaluates this expression using specified variables and functions.
+// The evaluation stops when the context is cancelled or any of the evaluation limits is exceeded.
+// Calls of lazy functions replaced by the functions are processed like in EvaluateUsingVariablesAndFunctions.
+//	Parameters:
+//		- ctx: The context to control the evaluation.
+//		- variables: The list of variables
+//		- functions: The list of functions.
+//	Returns: An evaluated expression value.
+func (c *ExpressionCalculator) EvaluateWithContext(ctx context.Context,
+	vars variables.IVariableCollection, funcs functions.IFunctionCollection) (*variants.Variant, error) {
 
-func (c *ExpressionCalculator) evaluateBoolean(
-	token *parsers.ExpressionToken, stack *CalculationStack) (bool, error) {
-
-	switch token.Type() {
-	case parsers.Equal:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Equal(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.NotEqual:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.NotEqual(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.More:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.More(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Less:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.Less(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.EqualMore:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.MoreEqual(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.EqualLess:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.LessEqual(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
+	if vars == nil {
+		vars = c.defaultVariables
 	}
-
-	return false, nil
-}
-
-func (c *ExpressionCalculator) evaluateOther(
-	token *parsers.ExpressionToken, stack *CalculationStack) (bool, error) {
-
-	switch token.Type() {
-	case parsers.In:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.In(value2, value1)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.NotIn:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.In(value2, value1)
-			if err != nil {
-				return false, err
-			}
-			result = variants.VariantFromBoolean(!result.AsBoolean())
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.Element:
-		{
-			value2 := stack.Pop()
-			value1 := stack.Pop()
-			result, err := c.variantOperations.GetElement(value1, value2)
-			if err != nil {
-				return false, err
-			}
-			stack.Push(result)
-			return true, nil
-		}
-	case parsers.IsNull:
-		{
-			stack.Push(variants.VariantFromBoolean(stack.Pop().IsNull()))
-			return true, nil
-		}
-	case parsers.IsNotNull:
-		{
-			stack.Push(variants.VariantFromBoolean(!stack.Pop().IsNull()))
-			return true, nil
-		}
+	if funcs == nil {
+		funcs = c.defaultFunctions
 	}
 
-	return false, nil
+	return c.Compile().EvaluateWithContext(ctx, vars, funcs)
 }
@@ -0,0 +1,536 @@
+package calculator
+
+import (
+	"context"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// ExpressionOptimizer implements an optimization pass over parsed expression tokens.
+// It folds constant sub-expressions, removes dead branches of If, Choose, conditional and CASE expressions
+// and short-circuit operators and optionally simplifies identities like <code>x * 1</code> and <code>x + 0</code>.
+// Only calls of deterministic functions (see functions.IDeterministicFunction) are folded.
+// Sub-expressions that fail during folding are left unchanged, so errors are raised during evaluation.
+// Folding is evaluated with the same limits as the optimized expression, so sub-expressions
+// which results exceed the limits are not folded and fail during evaluation as well.
+type ExpressionOptimizer struct {
+	variantOperations  variants.IVariantOperations
+	functions          functions.IFunctionCollection
+	limits             EvaluationLimits
+	foldConstants      bool
+	simplifyIdentities bool
+	removeDeadBranches bool
+}
+
+// optimizedSegment is a part of optimized tokens that calculates a single value.
+type optimizedSegment struct {
+	tokens   []*parsers.ExpressionToken
+	constant bool
+}
+
+// NewExpressionOptimizer constructs this class with all optimizations except simplification of identities turned on.
+//	Parameters:
+//		- variantOperations: The manager for operations on variant values or nil to use type unsafe operations.
+//		- functions: The list of functions to resolve function calls or nil to use the standard functions.
+func NewExpressionOptimizer(variantOperations variants.IVariantOperations,
+	funcs functions.IFunctionCollection) *ExpressionOptimizer {
+
+	if variantOperations == nil {
+		variantOperations = variants.NewTypeUnsafeVariantOperations()
+	}
+	if funcs == nil {
+		funcs = functions.NewDefaultFunctionCollection()
+	}
+
+	c := &ExpressionOptimizer{
+		variantOperations:  variantOperations,
+		functions:          funcs,
+		foldConstants:      true,
+		simplifyIdentities: false,
+		removeDeadBranches: true,
+	}
+	return c
+}
+
+// Limits gets the limits applied when constant sub-expressions are folded.
+func (c *ExpressionOptimizer) Limits() EvaluationLimits {
+	return c.limits
+}
+
+// SetLimits sets the limits applied when constant sub-expressions are folded.
+// They shall be the same as the limits of the optimized expression.
+func (c *ExpressionOptimizer) SetLimits(value EvaluationLimits) {
+	c.limits = value
+}
+
+// FoldConstants gets the flag to evaluate constant sub-expressions.
+func (c *ExpressionOptimizer) FoldConstants() bool {
+	return c.foldConstants
+}
+
+// SetFoldConstants sets the flag to evaluate constant sub-expressions.
+func (c *ExpressionOptimizer) SetFoldConstants(value bool) {
+	c.foldConstants = value
+}
+
+// SimplifyIdentities gets the flag to simplify identities
+// <code>x * 1</code>, <code>1 * x</code>, <code>x / 1</code>, <code>x + 0</code>, <code>0 + x</code> and <code>x - 0</code>.
+// Operators convert operands, so an identity is simplified only when the type of x is known
+// before evaluation and it is the same as the type of the constant. Types are known for constants
+// and for calls of functions with result types in their signatures. By default identities are not simplified.
+func (c *ExpressionOptimizer) SimplifyIdentities() bool {
+	return c.simplifyIdentities
+}
+
+// SetSimplifyIdentities sets the flag to simplify identities.
+func (c *ExpressionOptimizer) SetSimplifyIdentities(value bool) {
+	c.simplifyIdentities = value
+}
+
+// RemoveDeadBranches gets the flag to remove branches that are never evaluated.
+func (c *ExpressionOptimizer) RemoveDeadBranches() bool {
+	return c.removeDeadBranches
+}
+
+// SetRemoveDeadBranches sets the flag to remove branches that are never evaluated.
+func (c *ExpressionOptimizer) SetRemoveDeadBranches(value bool) {
+	c.removeDeadBranches = value
+}
+
+// Optimize creates an optimized list of tokens that gives the same results as the original one.
+// If the tokens have unexpected structure they are returned unchanged.
+//	Parameters:
+//		- tokens: The list of parsed expression tokens in reverse polish notation.
+//	Returns: The optimized list of tokens.
+func (c *ExpressionOptimizer) Optimize(tokens []*parsers.ExpressionToken) []*parsers.ExpressionToken {
+	return c.OptimizeWithContext(context.Background(), tokens)
+}
+
+// OptimizeWithContext creates an optimized list of tokens that gives the same results as the original one.
+// When the context is cancelled the remaining sub-expressions are not folded.
+//	Parameters:
+//		- ctx: The context to control folding of constant sub-expressions.
+//		- tokens: The list of parsed expression tokens in reverse polish notation.
+//	Returns: The optimized list of tokens.
+func (c *ExpressionOptimizer) OptimizeWithContext(ctx context.Context,
+	tokens []*parsers.ExpressionToken) []*parsers.ExpressionToken {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result, ok := c.optimizeTokens(ctx, tokens)
+	if !ok {
+		result = make([]*parsers.ExpressionToken, len(tokens))
+		copy(result, tokens)
+	}
+	return result
+}
+
+// optimizeTokens optimizes a list of tokens that calculates a single value.
+func (c *ExpressionOptimizer) optimizeTokens(ctx context.Context, tokens []*parsers.ExpressionToken) ([]*parsers.ExpressionToken, bool) {
+	segment, ok := c.optimizeSegment(ctx, tokens)
+	if !ok {
+		return nil, false
+	}
+	return segment.tokens, true
+}
+
+// optimizeSegment optimizes a list of tokens and combines the results into a single segment.
+// Stack manipulation tokens of simple CASE expressions are kept as separate non constant segments,
+// so they are never folded.
+func (c *ExpressionOptimizer) optimizeSegment(ctx context.Context, tokens []*parsers.ExpressionToken) (*optimizedSegment, bool) {
+	stack := []*optimizedSegment{}
+	pop := func(count int) ([]*optimizedSegment, bool) {
+		if len(stack) < count {
+			return nil, false
+		}
+		result := stack[len(stack)-count:]
+		stack = stack[:len(stack)-count]
+		return result, true
+	}
+
+	for index := 0; index < len(tokens); index++ {
+		token := tokens[index]
+
+		switch token.Type() {
+		case parsers.Constant:
+			stack = append(stack, &optimizedSegment{tokens: []*parsers.ExpressionToken{token}, constant: true})
+		case parsers.Variable, parsers.Duplicate, parsers.Pop:
+			stack = append(stack, &optimizedSegment{tokens: []*parsers.ExpressionToken{token}})
+		case parsers.Lambda:
+			definition := token.Value().AsArray()
+			length := definition[1].AsInteger()
+			if index+length >= len(tokens) {
+				return nil, false
+			}
+			body, ok := c.optimizeTokens(ctx, tokens[index+1 : index+1+length])
+			if !ok {
+				return nil, false
+			}
+			value := variants.VariantFromArray([]*variants.Variant{
+				definition[0], variants.VariantFromInteger(len(body)),
+			})
+			result := []*parsers.ExpressionToken{c.newToken(token, value)}
+			result = append(result, body...)
+			stack = append(stack, &optimizedSegment{tokens: result})
+			index += length
+		case parsers.ShortCircuitAnd, parsers.ShortCircuitOr, parsers.ShortCircuitCoalesce:
+			length := token.Value().AsInteger()
+			operands, ok := pop(1)
+			if !ok || length < 1 || index+length >= len(tokens) {
+				return nil, false
+			}
+			segment, ok := c.optimizeShortCircuit(ctx, token, operands[0],
+				tokens[index+1:index+length], tokens[index+length])
+			if !ok {
+				return nil, false
+			}
+			stack = append(stack, segment)
+			index += length
+		case parsers.JumpIfFalse:
+			trueLength := token.Value().AsInteger()
+			operands, ok := pop(1)
+			if !ok || trueLength < 1 || index+trueLength >= len(tokens) ||
+				tokens[index+trueLength].Type() != parsers.Jump {
+				return nil, false
+			}
+			falseLength := tokens[index+trueLength].Value().AsInteger()
+			if index+trueLength+falseLength >= len(tokens) {
+				return nil, false
+			}
+			segment, ok := c.optimizeCondition(ctx, token, operands[0],
+				tokens[index+1:index+trueLength],
+				tokens[index+trueLength+1:index+trueLength+1+falseLength])
+			if !ok {
+				return nil, false
+			}
+			stack = append(stack, segment)
+			index += trueLength + falseLength
+		case parsers.JumpTable:
+			operands, ok := pop(1)
+			if !ok {
+				return nil, false
+			}
+			branches := [][]*parsers.ExpressionToken{}
+			position := index + 1
+			for _, length := range token.Value().AsArray() {
+				end := position + length.AsInteger() - 1
+				if end < position || end >= len(tokens) || tokens[end].Type() != parsers.Jump {
+					return nil, false
+				}
+				branches = append(branches, tokens[position:end])
+				position = end + 1
+			}
+			segment, ok := c.optimizeChoice(ctx, token, operands[0], branches)
+			if !ok {
+				return nil, false
+			}
+			stack = append(stack, segment)
+			index = position - 1
+		case parsers.Function:
+			operands, ok := pop(1)
+			if !ok {
+				return nil, false
+			}
+			count := operands[0].tokens[len(operands[0].tokens)-1].Value().AsInteger()
+			parameters, ok := pop(count)
+			if !ok {
+				return nil, false
+			}
+			parameters = append(parameters, operands[0])
+			function := c.functions.FindByName(token.Value().AsString())
+			deterministic := false
+			if f, ok := function.(functions.IDeterministicFunction); ok {
+				deterministic = f.Deterministic()
+			}
+			stack = append(stack, c.combineSegments(ctx, token, parameters, deterministic))
+		case parsers.Unary, parsers.Not, parsers.IsNull, parsers.IsNotNull:
+			operands, ok := pop(1)
+			if !ok {
+				return nil, false
+			}
+			stack = append(stack, c.combineSegments(ctx, token, operands, true))
+		case parsers.Plus, parsers.Minus, parsers.Star, parsers.Slash, parsers.Procent, parsers.Power,
+			parsers.Equal, parsers.NotEqual, parsers.More, parsers.Less, parsers.EqualMore, parsers.EqualLess,
+			parsers.ShiftLeft, parsers.ShiftRight, parsers.And, parsers.Or, parsers.Xor,
+			parsers.In, parsers.NotIn, parsers.Element, parsers.Like, parsers.NotLike, parsers.Member,
+			parsers.Matches, parsers.NotMatches:
+			operands, ok := pop(2)
+			if !ok {
+				return nil, false
+			}
+			if segment := c.simplifyIdentity(token, operands[0], operands[1]); segment != nil {
+				stack = append(stack, segment)
+				continue
+			}
+			stack = append(stack, c.combineSegments(ctx, token, operands, true))
+		default:
+			return nil, false
+		}
+	}
+
+	if len(stack) == 1 {
+		return stack[0], true
+	}
+	return c.concatSegments(stack...), true
+}
+
+// optimizeShortCircuit optimizes AND, OR and null-coalescing operators with short-circuit jump.
+func (c *ExpressionOptimizer) optimizeShortCircuit(ctx context.Context, jumpToken *parsers.ExpressionToken,
+	left *optimizedSegment, rightTokens []*parsers.ExpressionToken,
+	operatorToken *parsers.ExpressionToken) (*optimizedSegment, bool) {
+
+	right, ok := c.optimizeSegment(ctx, rightTokens)
+	if !ok {
+		return nil, false
+	}
+
+	if left.constant && c.removeDeadBranches {
+		value := left.tokens[0].Value()
+		if jumpToken.Type() == parsers.ShortCircuitCoalesce {
+			if value.IsNull() {
+				return right, true
+			}
+			return left, true
+		}
+		skip := value.Type() == variants.Boolean &&
+			value.AsBoolean() == (jumpToken.Type() == parsers.ShortCircuitOr)
+		if skip {
+			return left, true
+		}
+		if right.constant {
+			return c.combineSegments(ctx, operatorToken, []*optimizedSegment{left, right}, true), true
+		}
+	}
+
+	result := append([]*parsers.ExpressionToken{}, left.tokens...)
+	result = append(result, c.newToken(jumpToken, variants.VariantFromInteger(len(right.tokens)+1)))
+	result = append(result, right.tokens...)
+	result = append(result, operatorToken)
+	return &optimizedSegment{tokens: result}, true
+}
+
+// optimizeCondition optimizes conditional jumps of If, conditional and CASE expressions.
+func (c *ExpressionOptimizer) optimizeCondition(ctx context.Context, jumpToken *parsers.ExpressionToken,
+	condition *optimizedSegment, trueTokens []*parsers.ExpressionToken,
+	falseTokens []*parsers.ExpressionToken) (*optimizedSegment, bool) {
+
+	trueBranch, ok := c.optimizeSegment(ctx, trueTokens)
+	if !ok {
+		return nil, false
+	}
+	falseBranch, ok := c.optimizeSegment(ctx, falseTokens)
+	if !ok {
+		return nil, false
+	}
+
+	if condition.constant && c.removeDeadBranches {
+		value, err := c.variantOperations.Convert(condition.tokens[0].Value(), variants.Boolean)
+		if err == nil {
+			if value.AsBoolean() {
+				return trueBranch, true
+			}
+			return falseBranch, true
+		}
+	}
+
+	result := append([]*parsers.ExpressionToken{}, condition.tokens...)
+	result = append(result, c.newToken(jumpToken, variants.VariantFromInteger(len(trueBranch.tokens)+1)))
+	result = append(result, trueBranch.tokens...)
+	result = append(result, parsers.NewExpressionToken(parsers.Jump,
+		variants.VariantFromInteger(len(falseBranch.tokens)), jumpToken.Line(), jumpToken.Column()))
+	result = append(result, falseBranch.tokens...)
+	return &optimizedSegment{tokens: result}, true
+}
+
+// optimizeChoice optimizes jump tables of Choose function.
+func (c *ExpressionOptimizer) optimizeChoice(ctx context.Context, jumpToken *parsers.ExpressionToken,
+	index *optimizedSegment, branchTokens [][]*parsers.ExpressionToken) (*optimizedSegment, bool) {
+
+	branches := make([]*optimizedSegment, len(branchTokens))
+	for i, tokens := range branchTokens {
+		branch, ok := c.optimizeSegment(ctx, tokens)
+		if !ok {
+			return nil, false
+		}
+		branches[i] = branch
+	}
+
+	if index.constant && c.removeDeadBranches {
+		value, err := c.variantOperations.Convert(index.tokens[0].Value(), variants.Integer)
+		if err == nil {
+			branchIndex := value.AsInteger()
+			if branchIndex == 0 {
+				return index, true
+			}
+			if branchIndex > 0 && branchIndex <= len(branches) {
+				return branches[branchIndex-1], true
+			}
+		}
+	}
+
+	lengths := make([]*variants.Variant, len(branches))
+	remaining := 0
+	for i, branch := range branches {
+		lengths[i] = variants.VariantFromInteger(len(branch.tokens) + 1)
+		remaining += len(branch.tokens) + 1
+	}
+
+	result := append([]*parsers.ExpressionToken{}, index.tokens...)
+	result = append(result, c.newToken(jumpToken, variants.VariantFromArray(lengths)))
+	for _, branch := range branches {
+		remaining -= len(branch.tokens) + 1
+		result = append(result, branch.tokens...)
+		result = append(result, parsers.NewExpressionToken(parsers.Jump,
+			variants.VariantFromInteger(remaining), jumpToken.Line(), jumpToken.Column()))
+	}
+	return &optimizedSegment{tokens: result}, true
+}
+
+// simplifyIdentity replaces operations with neutral numeric constants by the other operand.
+// The other operand shall have the same type as the constant, so the operation result has the same type
+// and no conversion is lost.
+//	Returns: The simplified segment or nil if the operation cannot be simplified.
+func (c *ExpressionOptimizer) simplifyIdentity(token *parsers.ExpressionToken,
+	left *optimizedSegment, right *optimizedSegment) *optimizedSegment {
+
+	if !c.simplifyIdentities || left.constant == right.constant {
+		return nil
+	}
+	typ := c.staticType(left)
+	if typ == variants.Null || typ != c.staticType(right) {
+		return nil
+	}
+
+	switch token.Type() {
+	case parsers.Plus:
+		if c.isNumericConstant(right, 0) {
+			return left
+		}
+		if c.isNumericConstant(left, 0) {
+			return right
+		}
+	case parsers.Minus:
+		if c.isNumericConstant(right, 0) {
+			return left
+		}
+	case parsers.Star:
+		if c.isNumericConstant(right, 1) {
+			return left
+		}
+		if c.isNumericConstant(left, 1) {
+			return right
+		}
+	case parsers.Slash:
+		if c.isNumericConstant(right, 1) {
+			return left
+		}
+	}
+	return nil
+}
+
+// staticType gets the type of the segment result known before evaluation.
+//	Returns: The type of constant or the result type from the signature of called function
+//		or Null if the type is unknown.
+func (c *ExpressionOptimizer) staticType(segment *optimizedSegment) variants.VariantType {
+	if segment.constant {
+		return segment.tokens[0].Value().Type()
+	}
+	token := segment.tokens[len(segment.tokens)-1]
+	if token.Type() != parsers.Function {
+		return variants.Null
+	}
+	signature := functions.GetFunctionSignature(c.functions.FindByName(token.Value().AsString()))
+	if signature == nil {
+		return variants.Null
+	}
+	return signature.ResultType
+}
+
+// isNumericConstant checks if the segment is a numeric constant with the specified value.
+func (c *ExpressionOptimizer) isNumericConstant(segment *optimizedSegment, number float64) bool {
+	if !segment.constant {
+		return false
+	}
+	value := segment.tokens[0].Value()
+	switch value.Type() {
+	case variants.Integer:
+		return float64(value.AsInteger()) == number
+	case variants.Long:
+		return float64(value.AsLong()) == number
+	case variants.Float:
+		return float64(value.AsFloat()) == number
+	case variants.Double:
+		return value.AsDouble() == number
+	}
+	return false
+}
+
+// combineSegments combines operand segments with the operation token.
+// When all operands are constant and the operation can be folded, the operation is evaluated.
+func (c *ExpressionOptimizer) combineSegments(ctx context.Context, token *parsers.ExpressionToken,
+	operands []*optimizedSegment, foldable bool) *optimizedSegment {
+
+	result := c.concatSegments(operands...)
+	result.tokens = append(result.tokens, token)
+
+	if !c.foldConstants || !foldable {
+		return result
+	}
+	for _, operand := range operands {
+		if !operand.constant {
+			return result
+		}
+	}
+
+	value, ok := c.evaluate(ctx, result.tokens)
+	if !ok {
+		return result
+	}
+	return &optimizedSegment{
+		tokens:   []*parsers.ExpressionToken{c.newConstant(token, value)},
+		constant: true,
+	}
+}
+
+// concatSegments concatenates tokens of segments into a non constant segment.
+func (c *ExpressionOptimizer) concatSegments(segments ...*optimizedSegment) *optimizedSegment {
+	result := &optimizedSegment{tokens: []*parsers.ExpressionToken{}}
+	for _, segment := range segments {
+		result.tokens = append(result.tokens, segment.tokens...)
+	}
+	return result
+}
+
+// evaluate calculates constant tokens with the optimizer limits.
+// Errors, panics and exceeded limits mean the tokens cannot be folded.
+func (c *ExpressionOptimizer) evaluate(ctx context.Context, tokens []*parsers.ExpressionToken) (result *variants.Variant, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			ok = false
+		}
+	}()
+
+	expression := NewCompiledExpression(nil, c.variantOperations, c.functions).WithLimits(c.limits)
+	step := 0
+	value, err := expression.evaluateTokens(expression.evaluationContext(ctx, &step), tokens,
+		variables.NewVariableCollection(), c.functions, &step)
+	if err != nil || value == nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// newToken creates a copy of the token with a new value.
+func (c *ExpressionOptimizer) newToken(token *parsers.ExpressionToken, value *variants.Variant) *parsers.ExpressionToken {
+	return parsers.NewExpressionToken(token.Type(), value, token.Line(), token.Column())
+}
+
+// newConstant creates a constant token at position of the token.
+func (c *ExpressionOptimizer) newConstant(token *parsers.ExpressionToken, value *variants.Variant) *parsers.ExpressionToken {
+	return parsers.NewExpressionToken(parsers.Constant, value, token.Line(), token.Column())
+}
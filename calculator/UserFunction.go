@@ -0,0 +1,270 @@
+package calculator
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	ctokenizers "github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/tokenizers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/tokenizers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// DefaultMaxRecursionDepth is the maximum depth of nested calls of user-defined functions
+// used when the function does not belong to UserFunctionCollection.
+const DefaultMaxRecursionDepth = 100
+
+// userFunctionDepthKey is the key of nested calls depth of user-defined functions in the evaluation context.
+type userFunctionDepthKey struct{}
+
+// UserFunction implements a function defined in the expression language,
+// for example <code>DEF Margin(price, cost) = (price - cost) / price</code>.
+// Parameters are bound to the function body by position and the body cannot see
+// variables of the calling expression. Functions called in the body are resolved
+// in the collection the function belongs to.
+type UserFunction struct {
+	name       string
+	parameters []string
+	body       *CompiledExpression
+	calls      []string
+	collection *UserFunctionCollection
+}
+
+// NewUserFunction constructs this function class with specified parameters.
+//	Parameters:
+//		- name: The name of this function.
+//		- parameters: The names of function parameters.
+//		- body: The compiled function body.
+func NewUserFunction(name string, parameters []string, body *CompiledExpression) *UserFunction {
+	if name == "" {
+		panic("Name parameter cannot be empty.")
+	}
+	if body == nil {
+		panic("Body parameter cannot be nil.")
+	}
+
+	c := &UserFunction{
+		name:       name,
+		parameters: make([]string, len(parameters)),
+		body:       body,
+		calls:      []string{},
+	}
+	copy(c.parameters, parameters)
+
+	for _, token := range body.tokens {
+		if token.Type() != parsers.Function {
+			continue
+		}
+		name := token.Value().AsString()
+		found := false
+		for _, call := range c.calls {
+			if strings.EqualFold(call, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.calls = append(c.calls, name)
+		}
+	}
+
+	return c
+}
+
+// ParseUserFunction parses the function definition in the form
+// <code>[DEF] Name(parameter1, parameter2, ...) = expression</code>.
+//	Parameters:
+//		- definition: The function definition string.
+//		- variantOperations: The manager for operations on variant values or nil to use type unsafe operations.
+//	Returns: A parsed function or error if the definition has syntax errors.
+func ParseUserFunction(definition string,
+	variantOperations variants.IVariantOperations) (*UserFunction, error) {
+
+	tokenizer := ctokenizers.NewExpressionTokenizer()
+	tokenizer.SetSkipWhitespaces(true)
+	tokenizer.SetSkipComments(true)
+	tokenizer.SetSkipEof(true)
+	tokenizer.SetDecodeStrings(true)
+	return ParseUserFunctionTokens(tokenizer.TokenizeBuffer(definition), variantOperations)
+}
+
+// ParseUserFunctionTokens parses the tokens of function definition.
+//	Parameters:
+//		- tokens: The tokens of function definition.
+//		- variantOperations: The manager for operations on variant values or nil to use type unsafe operations.
+//	Returns: A parsed function or error if the definition has syntax errors.
+func ParseUserFunctionTokens(tokens []*tokenizers.Token,
+	variantOperations variants.IVariantOperations) (*UserFunction, error) {
+
+	filtered := []*tokenizers.Token{}
+	for _, token := range tokens {
+		if token.Type() != tokenizers.Whitespace && token.Type() != tokenizers.Comment &&
+			token.Type() != tokenizers.Eof {
+			filtered = append(filtered, token)
+		}
+	}
+	tokens = filtered
+
+	index := 0
+	newError := func(code string, message string) error {
+		if index < len(tokens) {
+			return errors.NewSyntaxError("", code, message, tokens[index].Line(), tokens[index].Column())
+		}
+		return errors.NewSyntaxError("", errors.ErrUnexpectedEnd, "Unexpected end of function definition.", 0, 0)
+	}
+	isSymbol := func(symbol string) bool {
+		return index < len(tokens) && tokens[index].Type() == tokenizers.Symbol && tokens[index].Value() == symbol
+	}
+
+	if index < len(tokens) && strings.ToUpper(tokens[index].Value()) == "DEF" &&
+		tokens[index].Type() == tokenizers.Word {
+		index++
+	}
+	if index >= len(tokens) || tokens[index].Type() != tokenizers.Word {
+		return nil, newError(errors.ErrMissedFunctionName, "Expected function name was not found")
+	}
+	name := tokens[index].Value()
+	index++
+
+	parameters := []string{}
+	if !isSymbol("(") {
+		return nil, newError(errors.ErrErrorNear, "Expected '(' was not found")
+	}
+	index++
+	for !isSymbol(")") {
+		if len(parameters) > 0 {
+			if !isSymbol(",") {
+				return nil, newError(errors.ErrMissedCloseParenthesis, "Expected ')' was not found")
+			}
+			index++
+		}
+		if index >= len(tokens) || tokens[index].Type() != tokenizers.Word {
+			return nil, newError(errors.ErrErrorNear, "Expected parameter name was not found")
+		}
+		parameters = append(parameters, tokens[index].Value())
+		index++
+	}
+	index++
+	if !isSymbol("=") {
+		return nil, newError(errors.ErrMissedEqual, "Expected '=' was not found")
+	}
+	index++
+	if index >= len(tokens) {
+		return nil, newError(errors.ErrMissedExpression, "Expected function body was not found")
+	}
+
+	parser := parsers.NewExpressionParser()
+	err := parser.ParseTokens(tokens[index:])
+	if err != nil {
+		return nil, err
+	}
+	for _, variable := range parser.VariableNames() {
+		found := false
+		for _, parameter := range parameters {
+			if strings.EqualFold(parameter, variable) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.NewSyntaxError("", errors.ErrUnknownParameter,
+				"Function "+name+" has no parameter "+variable, tokens[index].Line(), tokens[index].Column())
+		}
+	}
+
+	body := NewCompiledExpressionFromParser(parser, variantOperations, nil)
+	return NewUserFunction(name, parameters, body), nil
+}
+
+// Name the function name.
+func (c *UserFunction) Name() string {
+	return c.name
+}
+
+// Parameters the names of function parameters.
+func (c *UserFunction) Parameters() []string {
+	result := make([]string, len(c.parameters))
+	copy(result, c.parameters)
+	return result
+}
+
+// Body the compiled function body.
+func (c *UserFunction) Body() *CompiledExpression {
+	return c.body
+}
+
+// Calls the names of functions called in the function body.
+func (c *UserFunction) Calls() []string {
+	result := make([]string, len(c.calls))
+	copy(result, c.calls)
+	return result
+}
+
+// Signature the function signature with parameters of any type.
+// The result type is unknown and the function is not considered pure
+// because its body may call non-deterministic functions.
+func (c *UserFunction) Signature() *functions.FunctionSignature {
+	parameters := make([]functions.FunctionParameter, len(c.parameters))
+	for i, name := range c.parameters {
+		parameters[i] = functions.NewFunctionParameter(name, variants.Null)
+	}
+	return functions.NewFunctionSignature(variants.Null, "", parameters...)
+}
+
+// Calculate the function calculation method.
+//	Parameters:
+//		- parameters: A list with function parameters.
+//		- variantOperations: Variants operations manager.
+func (c *UserFunction) Calculate(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return c.CalculateWithContext(context.Background(), parameters, variantOperations)
+}
+
+// CalculateWithContext the function calculation method.
+// The number of nested calls of user-defined functions is limited by the recursion depth
+// of the collection the function belongs to. When the function is called from CompiledExpression
+// its body is evaluated with the limits of the calling expression and its steps are counted
+// together with the steps of the caller.
+//	Parameters:
+//		- ctx: The evaluation context.
+//		- parameters: A list with function parameters.
+//		- variantOperations: Variants operations manager.
+func (c *UserFunction) CalculateWithContext(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+
+	if len(parameters) != len(c.parameters) {
+		err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
+			"Expected "+strconv.Itoa(len(c.parameters))+
+				" parameters but was found "+strconv.Itoa(len(parameters)), 0, 0)
+		return nil, err
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	maxDepth := DefaultMaxRecursionDepth
+	if c.collection != nil {
+		maxDepth = c.collection.MaxRecursionDepth()
+	}
+	depth, _ := ctx.Value(userFunctionDepthKey{}).(int)
+	if maxDepth > 0 && depth >= maxDepth {
+		err := errors.NewExpressionError("", "RECURSION_LIMIT_EXCEEDED",
+			"Call of function "+c.name+" exceeded the recursion depth of "+strconv.Itoa(maxDepth), 0, 0)
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, userFunctionDepthKey{}, depth+1)
+
+	vars := variables.NewVariableCollection()
+	for i, name := range c.parameters {
+		vars.Add(variables.NewVariable(name, parameters[i]))
+	}
+
+	if c.collection != nil {
+		return c.body.evaluateNested(ctx, vars, c.collection)
+	}
+	return c.body.evaluateNested(ctx, vars, nil)
+}
@@ -0,0 +1,226 @@
+package calculator
+
+import (
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// UserFunctionCollection implements a functions list with user-defined functions over a parent list.
+// Functions added to the collection shadow parent functions with the same name.
+// The parent functions are visible through the collection, but Remove and Clear
+// change only the functions of this collection.
+//
+// By default functions that call themselves directly or through other functions are rejected
+// when they are defined. When recursion is allowed the depth of nested calls is limited instead.
+type UserFunctionCollection struct {
+	parent            functions.IFunctionCollection
+	functions         *functions.FunctionCollection
+	variantOperations variants.IVariantOperations
+	maxRecursionDepth int
+	allowRecursion    bool
+}
+
+// NewUserFunctionCollection constructs this collection over the parent collection.
+//	Parameters:
+//		- parent: The parent list of functions or nil to use the standard functions.
+func NewUserFunctionCollection(parent functions.IFunctionCollection) *UserFunctionCollection {
+	if parent == nil {
+		parent = functions.NewDefaultFunctionCollection()
+	}
+
+	c := &UserFunctionCollection{
+		parent:            parent,
+		functions:         functions.NewFunctionCollection(),
+		variantOperations: variants.NewTypeUnsafeVariantOperations(),
+		maxRecursionDepth: DefaultMaxRecursionDepth,
+	}
+	return c
+}
+
+// Parent gets the parent list of functions.
+func (c *UserFunctionCollection) Parent() functions.IFunctionCollection {
+	return c.parent
+}
+
+// VariantOperations gets the manager for operations on variant values used by defined functions.
+func (c *UserFunctionCollection) VariantOperations() variants.IVariantOperations {
+	return c.variantOperations
+}
+
+// SetVariantOperations sets the manager for operations on variant values used by defined functions.
+// The change affects only functions defined after the call.
+func (c *UserFunctionCollection) SetVariantOperations(value variants.IVariantOperations) {
+	c.variantOperations = value
+}
+
+// MaxRecursionDepth gets the maximum depth of nested calls of user-defined functions.
+func (c *UserFunctionCollection) MaxRecursionDepth() int {
+	return c.maxRecursionDepth
+}
+
+// SetMaxRecursionDepth sets the maximum depth of nested calls of user-defined functions.
+// Zero value turns the limit off.
+func (c *UserFunctionCollection) SetMaxRecursionDepth(value int) {
+	c.maxRecursionDepth = value
+}
+
+// AllowRecursion gets the flag that allows functions to call themselves.
+func (c *UserFunctionCollection) AllowRecursion() bool {
+	return c.allowRecursion
+}
+
+// SetAllowRecursion sets the flag that allows functions to call themselves.
+func (c *UserFunctionCollection) SetAllowRecursion(value bool) {
+	c.allowRecursion = value
+}
+
+// Define parses the function definition and adds the function to the collection.
+//	Parameters:
+//		- definition: The function definition like <code>DEF Margin(price, cost) = (price - cost) / price</code>.
+//	Returns: The defined function or error if the definition is not valid.
+func (c *UserFunctionCollection) Define(definition string) (*UserFunction, error) {
+	function, err := ParseUserFunction(definition, c.variantOperations)
+	if err != nil {
+		return nil, err
+	}
+	return c.AddUserFunction(function)
+}
+
+// AddUserFunction adds the user-defined function to the collection and replaces
+// the function with the same name. Functions that call themselves are rejected
+// unless recursion is allowed.
+//	Parameters:
+//		- function: The function to be added.
+//	Returns: The function bound to this collection or error if the function creates a cycle of calls.
+func (c *UserFunctionCollection) AddUserFunction(function *UserFunction) (*UserFunction, error) {
+	if function == nil {
+		panic("Function cannot be nil.")
+	}
+
+	if !c.allowRecursion {
+		if cycle := c.findCycle(function, function.calls, []string{function.Name()}); cycle != nil {
+			err := errors.NewExpressionError("", "FUNCTION_CYCLE",
+				"Function "+function.Name()+" calls itself: "+strings.Join(cycle, " -> "), 0, 0)
+			return nil, err
+		}
+	}
+
+	bound := *function
+	bound.collection = c
+	c.functions.RemoveByName(function.Name())
+	c.functions.Add(&bound)
+	return &bound, nil
+}
+
+// findCycle finds a chain of calls that leads from the called functions back to the defined function.
+func (c *UserFunctionCollection) findCycle(function *UserFunction, calls []string, path []string) []string {
+	for _, call := range calls {
+		chain := append(path[:len(path):len(path)], call)
+		if strings.EqualFold(call, function.Name()) {
+			return chain
+		}
+
+		visited := false
+		for _, name := range path {
+			if strings.EqualFold(name, call) {
+				visited = true
+				break
+			}
+		}
+		called, _ := c.FindByName(call).(*UserFunction)
+		if visited || called == nil {
+			continue
+		}
+		if cycle := c.findCycle(function, called.calls, chain); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// Add a new function to the collection. User-defined functions are added by AddUserFunction,
+// so they are bound to this collection and checked for cycles of calls.
+// Since the method cannot return errors, it panics when the function creates a cycle of calls.
+//	Parameters:
+//		- function: a function to be added.
+func (c *UserFunctionCollection) Add(function functions.IFunction) {
+	if userFunction, ok := function.(*UserFunction); ok {
+		if _, err := c.AddUserFunction(userFunction); err != nil {
+			panic(err)
+		}
+		return
+	}
+	c.functions.Add(function)
+}
+
+// Length number of functions visible in the collection.
+func (c *UserFunctionCollection) Length() int {
+	return len(c.GetAll())
+}
+
+// Get a function by its index.
+//	Parameters:
+//		- index: a function index.
+//	Returns: a retrieved function.
+func (c *UserFunctionCollection) Get(index int) functions.IFunction {
+	return c.GetAll()[index]
+}
+
+// GetAll functions visible in the collection. Functions of this collection go first.
+//	Returns: a list with functions.
+func (c *UserFunctionCollection) GetAll() []functions.IFunction {
+	result := c.functions.GetAll()
+	for _, f := range c.parent.GetAll() {
+		if c.functions.FindByName(f.Name()) == nil {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// FindIndexByName function index in the list by it's name.
+//	Parameters:
+//		- name: The function name to be found.
+//	Returns: Function index in the list or <code>-1</code> if function was not found.
+func (c *UserFunctionCollection) FindIndexByName(name string) int {
+	name = strings.ToUpper(name)
+	for i, f := range c.GetAll() {
+		if strings.ToUpper(f.Name()) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindByName finds function in this collection first and then in the parent list.
+//	Parameters:
+//		- name: The function name to be found.
+//	Returns: Function or <code>null</code> if function was not found.
+func (c *UserFunctionCollection) FindByName(name string) functions.IFunction {
+	if f := c.functions.FindByName(name); f != nil {
+		return f
+	}
+	return c.parent.FindByName(name)
+}
+
+// Remove a function of this collection by its index.
+//	Parameters:
+//		- index: a index of the function to be removed.
+func (c *UserFunctionCollection) Remove(index int) {
+	c.RemoveByName(c.Get(index).Name())
+}
+
+// RemoveByName removes function of this collection by it's name.
+//	Parameters:
+//		- name: The function name to be removed.
+func (c *UserFunctionCollection) RemoveByName(name string) {
+	c.functions.RemoveByName(name)
+}
+
+// Clear removes all functions of this collection.
+func (c *UserFunctionCollection) Clear() {
+	c.functions.Clear()
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
+)
+
+// Diagnostic describes a syntax or semantic problem found in the expression.
+type Diagnostic struct {
+	// Code the problem code from SyntaxErrorCode.
+	Code string
+	// Message the problem description without position.
+	Message string
+	// Start the position of the first character of the problem.
+	Start ast.Position
+	// End the position right after the last character of the problem.
+	End ast.Position
+	// Suggestions the names that were probably meant instead of unknown variable or function.
+	Suggestions []string
+}
+
+// Error gets the problem description with position and suggestions.
+func (c *Diagnostic) Error() string {
+	builder := strings.Builder{}
+	builder.WriteString(c.Message)
+	if c.Start.Line != 0 || c.Start.Column != 0 {
+		builder.WriteString(" at line " + strconv.Itoa(c.Start.Line) +
+			" and column " + strconv.Itoa(c.Start.Column))
+	}
+	if len(c.Suggestions) > 0 {
+		builder.WriteString(". Did you mean " + strings.Join(c.Suggestions, ", ") + "?")
+	}
+	return builder.String()
+}
+
+// Render formats the problem description followed by the source line
+// with carets under the problem.
+//	Parameters:
+//		- source: The source expression.
+//	Returns: The formatted problem.
+func (c *Diagnostic) Render(source string) string {
+	builder := strings.Builder{}
+	builder.WriteString(c.Error())
+
+	lines := strings.Split(source, "\n")
+	if c.Start.Line < 1 || c.Start.Line > len(lines) {
+		return builder.String()
+	}
+	line := []rune(strings.TrimRight(lines[c.Start.Line-1], "\r"))
+
+	start := c.Start.Column - 1
+	if start < 0 {
+		start = 0
+	}
+	if start > len(line) {
+		start = len(line)
+	}
+	end := len(line)
+	if c.End.Line == c.Start.Line && c.End.Column-1 < end {
+		end = c.End.Column - 1
+	}
+	if end <= start {
+		end = start + 1
+	}
+
+	builder.WriteString("\n")
+	builder.WriteString(string(line))
+	builder.WriteString("\n")
+	// Tabs are kept, so carets are aligned with the source line
+	for _, r := range line[:start] {
+		if r == '\t' {
+			builder.WriteRune('\t')
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+	builder.WriteString(strings.Repeat("^", end-start))
+	return builder.String()
+}
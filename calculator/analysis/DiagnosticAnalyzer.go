@@ -0,0 +1,336 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	ctokenizers "github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/tokenizers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/tokenizers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// maxSuggestions is the maximum number of suggested names for unknown variables and functions.
+const maxSuggestions = 3
+
+// DiagnosticAnalyzer collects all syntax and semantic problems of expressions instead of
+// stopping at the first error. After a syntax error the token that caused it is skipped
+// and parsing is repeated, so following problems are found as well.
+// Semantic problems are found by TypeAnalyzer in the recovered syntax tree.
+// Unknown variables and functions come with suggestions of similar registered names.
+type DiagnosticAnalyzer struct {
+	variantOperations variants.IVariantOperations
+	functions         functions.IFunctionCollection
+}
+
+// NewDiagnosticAnalyzer constructs this class.
+//	Parameters:
+//		- variantOperations: The manager for operations on variant values or nil to use type unsafe operations.
+//		- functions: The list of registered functions or nil to use the standard functions.
+func NewDiagnosticAnalyzer(variantOperations variants.IVariantOperations,
+	funcs functions.IFunctionCollection) *DiagnosticAnalyzer {
+
+	if variantOperations == nil {
+		variantOperations = variants.NewTypeUnsafeVariantOperations()
+	}
+	if funcs == nil {
+		funcs = functions.NewDefaultFunctionCollection()
+	}
+
+	c := &DiagnosticAnalyzer{
+		variantOperations: variantOperations,
+		functions:         funcs,
+	}
+	return c
+}
+
+// VariantOperations gets the manager for operations on variant values.
+func (c *DiagnosticAnalyzer) VariantOperations() variants.IVariantOperations {
+	return c.variantOperations
+}
+
+// Functions gets the list of registered functions.
+func (c *DiagnosticAnalyzer) Functions() functions.IFunctionCollection {
+	return c.functions
+}
+
+// Analyze collects problems of the expression.
+// Types of variables are taken from their current values, variables with null values may have any type.
+//	Parameters:
+//		- expression: The expression string.
+//		- vars: The list of registered variables or nil to skip checks of unknown variables.
+//	Returns: The list of found problems ordered by their positions.
+func (c *DiagnosticAnalyzer) Analyze(expression string, vars variables.IVariableCollection) []*Diagnostic {
+	tokenizer := ctokenizers.NewExpressionTokenizer()
+	tokenizer.SetSkipWhitespaces(true)
+	tokenizer.SetSkipComments(true)
+	tokenizer.SetSkipEof(true)
+	tokenizer.SetDecodeStrings(false)
+	spans := newTokenSpans(tokenizer.TokenizeBuffer(expression))
+
+	tokenizer.SetDecodeStrings(true)
+	tokens := []*tokenizers.Token{}
+	for _, token := range tokenizer.TokenizeBuffer(expression) {
+		if token.Type() != tokenizers.Whitespace && token.Type() != tokenizers.Comment &&
+			token.Type() != tokenizers.Eof {
+			tokens = append(tokens, token)
+		}
+	}
+
+	result := []*Diagnostic{}
+	var tree ast.Node
+	for attempt := 0; attempt <= len(spans.tokens); attempt++ {
+		parser := parsers.NewExpressionParser()
+		err := parser.ParseTokens(tokens)
+		if err == nil {
+			tree = parser.SyntaxTree()
+			break
+		}
+
+		diagnostic := c.newDiagnostic(err, spans)
+		result = appendDiagnostic(result, diagnostic)
+
+		// Skips the token that caused the error and tries again
+		index := -1
+		for i, token := range tokens {
+			if token.Line() == diagnostic.Start.Line && token.Column() == diagnostic.Start.Column {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			break
+		}
+		tokens = append(tokens[:index:index], tokens[index+1:]...)
+	}
+
+	if tree != nil {
+		analyzer := NewTypeAnalyzer(c.variantOperations, c.functions)
+		if vars != nil {
+			for _, v := range vars.GetAll() {
+				analyzer.SetVariableType(v.Name(), v.Value().Type())
+			}
+		}
+		_, errs := analyzer.Analyze(tree)
+		for _, err := range errs {
+			diagnostic := c.newDiagnostic(err, spans)
+			if diagnostic.Code == errors.ErrUnknownVariable {
+				if vars == nil {
+					continue
+				}
+				diagnostic.Suggestions = suggestNames(c.nameAt(diagnostic, spans), variableNames(vars))
+			} else if diagnostic.Code == errors.ErrUnknownFunction {
+				diagnostic.Suggestions = suggestNames(c.nameAt(diagnostic, spans), functionNames(c.functions))
+			}
+			result = appendDiagnostic(result, diagnostic)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Start.Line != result[j].Start.Line {
+			return result[i].Start.Line < result[j].Start.Line
+		}
+		return result[i].Start.Column < result[j].Start.Column
+	})
+	return result
+}
+
+// newDiagnostic converts the error into the diagnostic with codes from SyntaxErrorCode.
+// Errors without position are placed at the end of the expression.
+func (c *DiagnosticAnalyzer) newDiagnostic(err error, spans *tokenSpans) *Diagnostic {
+	diagnostic := &Diagnostic{Code: errors.ErrUnknown, Message: err.Error()}
+	if appErr, ok := err.(*cerrors.ApplicationError); ok {
+		diagnostic.Code = appErr.Code
+		diagnostic.Message = appErr.Message
+	}
+
+	line, column, ok := errors.ErrorPosition(err)
+	if ok {
+		diagnostic.Message = strings.TrimSuffix(diagnostic.Message,
+			" at line "+strconv.Itoa(line)+" and column "+strconv.Itoa(column))
+		diagnostic.Start = ast.Position{Line: line, Column: column}
+		diagnostic.End = spans.end(diagnostic.Start)
+	} else {
+		diagnostic.Start = spans.last()
+		diagnostic.End = diagnostic.Start
+	}
+	diagnostic.Message = strings.TrimSuffix(diagnostic.Message, ".")
+
+	switch diagnostic.Code {
+	case "VAR_NOT_FOUND":
+		diagnostic.Code = errors.ErrUnknownVariable
+	case "FUNC_NOT_FOUND":
+		diagnostic.Code = errors.ErrUnknownFunction
+	case "WRONG_PARAM_COUNT":
+		diagnostic.Code = errors.ErrWrongParamCount
+	case "CONV_NOT_SUPPORTED", "OP_NOT_SUPPORTED", "WRONG_PARAM_TYPE", "INTEGER_OVERFLOW":
+		diagnostic.Code = errors.ErrTypeMismatch
+	}
+	return diagnostic
+}
+
+// nameAt gets the name of variable or function at the start of the diagnostic.
+func (c *DiagnosticAnalyzer) nameAt(diagnostic *Diagnostic, spans *tokenSpans) string {
+	if token := spans.find(diagnostic.Start); token != nil {
+		return token.Value()
+	}
+	return ""
+}
+
+// appendDiagnostic adds the diagnostic unless a problem with the same code and position is already found.
+func appendDiagnostic(diagnostics []*Diagnostic, diagnostic *Diagnostic) []*Diagnostic {
+	for _, other := range diagnostics {
+		if other.Code == diagnostic.Code && other.Start == diagnostic.Start {
+			return diagnostics
+		}
+	}
+	return append(diagnostics, diagnostic)
+}
+
+func variableNames(vars variables.IVariableCollection) []string {
+	result := []string{}
+	for _, v := range vars.GetAll() {
+		result = append(result, v.Name())
+	}
+	return result
+}
+
+func functionNames(funcs functions.IFunctionCollection) []string {
+	result := []string{}
+	for _, f := range funcs.GetAll() {
+		result = append(result, f.Name())
+	}
+	return result
+}
+
+// suggestNames finds names similar to the unknown name. Names are compared ignoring case
+// and a name is similar when its edit distance does not exceed a third of the name length.
+//	Parameters:
+//		- name: The unknown name.
+//		- candidates: The registered names.
+//	Returns: Up to three similar names starting from the most similar.
+func suggestNames(name string, candidates []string) []string {
+	if name == "" {
+		return nil
+	}
+	maxDistance := len([]rune(name)) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	suggestions := []suggestion{}
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToUpper(name), strings.ToUpper(candidate))
+		if distance <= maxDistance {
+			suggestions = append(suggestions, suggestion{name: candidate, distance: distance})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+
+	result := []string{}
+	for _, s := range suggestions {
+		if len(result) == maxSuggestions {
+			break
+		}
+		result = append(result, s.name)
+	}
+	return result
+}
+
+// editDistance calculates the Levenshtein distance between two strings.
+func editDistance(value1 string, value2 string) int {
+	runes1 := []rune(value1)
+	runes2 := []rune(value2)
+	previous := make([]int, len(runes2)+1)
+	current := make([]int, len(runes2)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(runes1); i++ {
+		current[0] = i
+		for j := 1; j <= len(runes2); j++ {
+			cost := 1
+			if runes1[i-1] == runes2[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(runes2)]
+}
+
+// tokenSpans keeps undecoded tokens of the expression to find spans of problems.
+type tokenSpans struct {
+	tokens []*tokenizers.Token
+}
+
+func newTokenSpans(tokens []*tokenizers.Token) *tokenSpans {
+	c := &tokenSpans{tokens: []*tokenizers.Token{}}
+	for _, token := range tokens {
+		if token.Type() != tokenizers.Whitespace && token.Type() != tokenizers.Comment &&
+			token.Type() != tokenizers.Eof {
+			c.tokens = append(c.tokens, token)
+		}
+	}
+	return c
+}
+
+// find gets the token that starts at the position or nil.
+func (c *tokenSpans) find(position ast.Position) *tokenizers.Token {
+	for _, token := range c.tokens {
+		if token.Line() == position.Line && token.Column() == position.Column {
+			return token
+		}
+	}
+	return nil
+}
+
+// end gets the position right after the token that starts at the position.
+func (c *tokenSpans) end(position ast.Position) ast.Position {
+	token := c.find(position)
+	if token == nil {
+		return ast.Position{Line: position.Line, Column: position.Column + 1}
+	}
+	return tokenEnd(token)
+}
+
+// last gets the position right after the last token.
+func (c *tokenSpans) last() ast.Position {
+	if len(c.tokens) == 0 {
+		return ast.Position{Line: 1, Column: 1}
+	}
+	return tokenEnd(c.tokens[len(c.tokens)-1])
+}
+
+func tokenEnd(token *tokenizers.Token) ast.Position {
+	line := token.Line()
+	column := token.Column()
+	for _, r := range token.Value() {
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return ast.Position{Line: line, Column: column}
+}
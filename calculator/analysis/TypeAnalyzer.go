@@ -0,0 +1,614 @@
+package analysis
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// TypeAnalyzer infers result types of expressions and reports type errors without evaluating them.
+// Types of variables are set in a schema, types of function results are taken from
+// function signatures or inferred by calling deterministic functions with sample values.
+// Calls of functions that implement ISignatureFunction are checked by their signatures.
+// Operations are checked by applying the configured variant operations to sample values,
+// so the analyzer follows the same conversion rules as the evaluation.
+// The Null type stands for an unknown type that is compatible with any other type.
+type TypeAnalyzer struct {
+	variantOperations variants.IVariantOperations
+	functions         functions.IFunctionCollection
+	variableTypes     map[string]variants.VariantType
+	signatures        map[string]*functions.FunctionSignature
+	lambdaScopes      [][]string
+	errors            []error
+}
+
+// NewTypeAnalyzer constructs this class with signatures of standard non-deterministic
+// and higher-order functions.
+//	Parameters:
+//		- variantOperations: The manager for operations on variant values or nil to use type unsafe operations.
+//		- functions: The list of functions to resolve function calls or nil to use the standard functions.
+func NewTypeAnalyzer(variantOperations variants.IVariantOperations,
+	funcs functions.IFunctionCollection) *TypeAnalyzer {
+
+	if variantOperations == nil {
+		variantOperations = variants.NewTypeUnsafeVariantOperations()
+	}
+	if funcs == nil {
+		funcs = functions.NewDefaultFunctionCollection()
+	}
+
+	c := &TypeAnalyzer{
+		variantOperations: variantOperations,
+		functions:         funcs,
+		variableTypes:     map[string]variants.VariantType{},
+		signatures:        map[string]*functions.FunctionSignature{},
+	}
+
+	param := functions.NewFunctionParameter
+	optional := functions.NewOptionalFunctionParameter
+	variadic := functions.NewVariadicFunctionParameter
+	signature := functions.NewFunctionSignature
+
+	c.SetFunctionSignature("Ticks", signature(variants.Long, ""))
+	c.SetFunctionSignature("Now", signature(variants.DateTime, ""))
+	c.SetFunctionSignature("Rnd", signature(variants.Float, ""))
+	c.SetFunctionSignature("Random", signature(variants.Float, ""))
+	c.SetFunctionSignature("Map", signature(variants.Array, "",
+		param("values", variants.Array), param("lambda", variants.Object)))
+	c.SetFunctionSignature("Filter", signature(variants.Array, "",
+		param("values", variants.Array), param("predicate", variants.Object)))
+	c.SetFunctionSignature("SortBy", signature(variants.Array, "",
+		param("values", variants.Array), param("lambda", variants.Object)))
+	c.SetFunctionSignature("GroupBy", signature(variants.Array, "",
+		param("values", variants.Array), param("lambda", variants.Object)))
+	c.SetFunctionSignature("Any", signature(variants.Boolean, "",
+		param("values", variants.Array), param("predicate", variants.Object)))
+	c.SetFunctionSignature("All", signature(variants.Boolean, "",
+		param("values", variants.Array), param("predicate", variants.Object)))
+	c.SetFunctionSignature("Find", signature(variants.Null, "",
+		param("values", variants.Array), param("predicate", variants.Object)))
+	c.SetFunctionSignature("CountIf", signature(variants.Integer, "",
+		param("values", variants.Null), variadic("valuesAndPredicate", variants.Null, false)))
+	c.SetFunctionSignature("Reduce", signature(variants.Null, "",
+		param("values", variants.Array), param("lambda", variants.Object), optional("initial", variants.Null)))
+	// Sample values cannot be used as date units, layouts, time zones or regular expression groups.
+	c.SetFunctionSignature("DateDiff", signature(variants.Integer, "",
+		param("start", variants.DateTime), param("end", variants.DateTime), param("unit", variants.String)))
+	c.SetFunctionSignature("ParseDate", signature(variants.DateTime, "",
+		param("value", variants.String), optional("layout", variants.String), optional("timeZone", variants.String)))
+	c.SetFunctionSignature("ToTimeZone", signature(variants.DateTime, "",
+		param("date", variants.DateTime), param("timeZone", variants.String)))
+	c.SetFunctionSignature("RegexExtract", signature(variants.String, "",
+		param("value", variants.String), param("pattern", variants.String), optional("group", variants.Integer)))
+	return c
+}
+
+// VariantOperations gets the manager for operations on variant values.
+func (c *TypeAnalyzer) VariantOperations() variants.IVariantOperations {
+	return c.variantOperations
+}
+
+// Functions gets the list of functions to resolve function calls.
+func (c *TypeAnalyzer) Functions() functions.IFunctionCollection {
+	return c.functions
+}
+
+// VariableType gets the type of variable from the schema.
+//	Parameters:
+//		- name: The variable name.
+//	Returns: The variable type and <code>true</code> if the variable is defined in the schema.
+func (c *TypeAnalyzer) VariableType(name string) (variants.VariantType, bool) {
+	typ, ok := c.variableTypes[strings.ToUpper(name)]
+	return typ, ok
+}
+
+// SetVariableType sets the type of variable in the schema.
+// Variables with the Null type may hold values of any type.
+//	Parameters:
+//		- name: The variable name.
+//		- typ: The variable type.
+func (c *TypeAnalyzer) SetVariableType(name string, typ variants.VariantType) {
+	c.variableTypes[strings.ToUpper(name)] = typ
+}
+
+// RemoveVariableType removes the variable from the schema.
+//	Parameters:
+//		- name: The variable name.
+func (c *TypeAnalyzer) RemoveVariableType(name string) {
+	delete(c.variableTypes, strings.ToUpper(name))
+}
+
+// FunctionSignature gets the signature of function set in the analyzer.
+// It overrides the signature of the function itself, and its result type is used without calling the function.
+//	Parameters:
+//		- name: The function name.
+//	Returns: The function signature or nil if it is not set.
+func (c *TypeAnalyzer) FunctionSignature(name string) *functions.FunctionSignature {
+	return c.signatures[strings.ToUpper(name)]
+}
+
+// SetFunctionSignature sets the signature of function.
+//	Parameters:
+//		- name: The function name.
+//		- signature: The function signature or nil to remove it.
+func (c *TypeAnalyzer) SetFunctionSignature(name string, signature *functions.FunctionSignature) {
+	if signature == nil {
+		delete(c.signatures, strings.ToUpper(name))
+	} else {
+		c.signatures[strings.ToUpper(name)] = signature
+	}
+}
+
+// AnalyzeExpression parses the expression and infers its result type.
+//	Parameters:
+//		- expression: The expression string.
+//	Returns: The inferred result type or Null if it is unknown
+//		and the list of found syntax and type errors.
+func (c *TypeAnalyzer) AnalyzeExpression(expression string) (variants.VariantType, []error) {
+	parser := parsers.NewExpressionParser()
+	err := parser.SetExpression(expression)
+	if err != nil {
+		return variants.Null, []error{err}
+	}
+	return c.Analyze(parser.SyntaxTree())
+}
+
+// Analyze infers the result type of the syntax tree.
+//	Parameters:
+//		- node: The root node of the syntax tree.
+//	Returns: The inferred result type or Null if it is unknown
+//		and the list of found type errors.
+func (c *TypeAnalyzer) Analyze(node ast.Node) (variants.VariantType, []error) {
+	c.errors = []error{}
+	c.lambdaScopes = [][]string{}
+	result := c.analyzeNode(node)
+	errs := c.errors
+	c.errors = nil
+	return result, errs
+}
+
+func (c *TypeAnalyzer) addError(err error, position ast.Position) {
+	code := errors.ErrUnknown
+	message := err.Error()
+	if appErr, ok := err.(*cerrors.ApplicationError); ok {
+		code = appErr.Code
+		message = appErr.Message
+	}
+	c.errors = append(c.errors, errors.NewExpressionError("", code, message, position.Line, position.Column))
+}
+
+func (c *TypeAnalyzer) analyzeNode(node ast.Node) variants.VariantType {
+	switch n := node.(type) {
+	case nil:
+		return variants.Null
+	case *ast.ConstantNode:
+		return n.Value.Type()
+	case *ast.VariableNode:
+		return c.analyzeVariable(n)
+	case *ast.UnaryNode:
+		return c.analyzeUnary(n)
+	case *ast.BinaryNode:
+		return c.analyzeBinary(n)
+	case *ast.FunctionNode:
+		return c.analyzeFunction(n)
+	case *ast.MemberNode:
+		return c.analyzeMember(n)
+	case *ast.ElementNode:
+		return c.analyzeElement(n)
+	case *ast.ConditionalNode:
+		c.checkCondition(n.Condition)
+		return commonType(c.analyzeNode(n.TrueValue), c.analyzeNode(n.FalseValue))
+	case *ast.CaseNode:
+		return c.analyzeCase(n)
+	case *ast.LambdaNode:
+		c.lambdaScopes = append(c.lambdaScopes, n.Parameters)
+		c.analyzeNode(n.Body)
+		c.lambdaScopes = c.lambdaScopes[:len(c.lambdaScopes)-1]
+		return variants.Object
+	}
+	for _, child := range node.Children() {
+		c.analyzeNode(child)
+	}
+	return variants.Null
+}
+
+func (c *TypeAnalyzer) isLambdaParameter(name string) bool {
+	for _, scope := range c.lambdaScopes {
+		for _, parameter := range scope {
+			if strings.EqualFold(parameter, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *TypeAnalyzer) analyzeVariable(node *ast.VariableNode) variants.VariantType {
+	if c.isLambdaParameter(node.Name) {
+		return variants.Null
+	}
+	typ, ok := c.VariableType(node.Name)
+	if !ok {
+		err := errors.NewExpressionError("", "VAR_NOT_FOUND",
+			"Variable "+node.Name+" was not found", node.Line, node.Column)
+		c.errors = append(c.errors, err)
+		return variants.Null
+	}
+	return typ
+}
+
+func (c *TypeAnalyzer) analyzeUnary(node *ast.UnaryNode) variants.VariantType {
+	typ := c.analyzeNode(node.Operand)
+	switch node.Operator {
+	case ast.IsNull, ast.IsNotNull:
+		return variants.Boolean
+	case ast.Not:
+		return c.applyOperation(node, variants.Boolean, []variants.VariantType{typ}, func(values []*variants.Variant) (*variants.Variant, error) {
+			return c.variantOperations.Not(values[0])
+		})
+	}
+	return c.applyOperation(node, variants.Null, []variants.VariantType{typ}, func(values []*variants.Variant) (*variants.Variant, error) {
+		return c.variantOperations.Negative(values[0])
+	})
+}
+
+func (c *TypeAnalyzer) analyzeBinary(node *ast.BinaryNode) variants.VariantType {
+	left := c.analyzeNode(node.Left)
+	right := c.analyzeNode(node.Right)
+
+	if node.Operator == ast.Coalesce {
+		return commonType(left, right)
+	}
+
+	var operation func(value1 *variants.Variant, value2 *variants.Variant) (*variants.Variant, error)
+	unknownType := variants.Null
+	ops := c.variantOperations
+	switch node.Operator {
+	case ast.Add:
+		operation = ops.Add
+	case ast.Subtract:
+		operation = ops.Sub
+	case ast.Multiply:
+		operation = ops.Mul
+	case ast.Divide:
+		operation = ops.Div
+	case ast.Modulo:
+		operation = ops.Mod
+	case ast.Power:
+		operation = ops.Pow
+	case ast.ShiftLeft:
+		operation = ops.Lsh
+	case ast.ShiftRight:
+		operation = ops.Rsh
+	case ast.And:
+		operation = ops.And
+	case ast.Or:
+		operation = ops.Or
+	case ast.Xor:
+		operation = ops.Xor
+	case ast.Equal:
+		operation, unknownType = ops.Equal, variants.Boolean
+	case ast.NotEqual:
+		operation, unknownType = ops.NotEqual, variants.Boolean
+	case ast.More:
+		operation, unknownType = ops.More, variants.Boolean
+	case ast.Less:
+		operation, unknownType = ops.Less, variants.Boolean
+	case ast.MoreEqual:
+		operation, unknownType = ops.MoreEqual, variants.Boolean
+	case ast.LessEqual:
+		operation, unknownType = ops.LessEqual, variants.Boolean
+	case ast.Like, ast.NotLike:
+		operation, unknownType = ops.Like, variants.Boolean
+	case ast.Matches, ast.NotMatches:
+		// Sample values are not valid patterns, so only conversions to strings are checked.
+		operation = func(value1 *variants.Variant, value2 *variants.Variant) (*variants.Variant, error) {
+			for _, value := range []*variants.Variant{value1, value2} {
+				if _, err := ops.Convert(value, variants.String); err != nil {
+					return nil, err
+				}
+			}
+			return variants.VariantFromBoolean(true), nil
+		}
+		unknownType = variants.Boolean
+	case ast.In, ast.NotIn:
+		operation = func(value1 *variants.Variant, value2 *variants.Variant) (*variants.Variant, error) {
+			return ops.In(value2, value1)
+		}
+		unknownType = variants.Boolean
+	default:
+		return variants.Null
+	}
+
+	return c.applyOperation(node, unknownType, []variants.VariantType{left, right},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			return operation(values[0], values[1])
+		})
+}
+
+func (c *TypeAnalyzer) analyzeMember(node *ast.MemberNode) variants.VariantType {
+	typ := c.analyzeNode(node.Object)
+	if typ == variants.Null || typ == variants.Object {
+		return variants.Null
+	}
+	return c.applyOperation(node, variants.Null, []variants.VariantType{typ}, func(values []*variants.Variant) (*variants.Variant, error) {
+		return c.variantOperations.GetMember(values[0], variants.VariantFromString(node.Name))
+	})
+}
+
+func (c *TypeAnalyzer) analyzeElement(node *ast.ElementNode) variants.VariantType {
+	typ := c.analyzeNode(node.Object)
+	indexType := c.analyzeNode(node.Index)
+	result := c.applyOperation(node, variants.Null, []variants.VariantType{typ, indexType},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			index, err := c.variantOperations.Convert(values[1], variants.Integer)
+			if err != nil {
+				return nil, err
+			}
+			// Sample index is out of range for sample strings and arrays
+			index.SetAsInteger(0)
+			return c.variantOperations.GetElement(values[0], index)
+		})
+	// Types of array elements are unknown
+	if typ == variants.Array {
+		return variants.Null
+	}
+	return result
+}
+
+func (c *TypeAnalyzer) analyzeCase(node *ast.CaseNode) variants.VariantType {
+	selectorType := c.analyzeNode(node.Selector)
+	result := variants.Null
+	for i, when := range node.Whens {
+		if node.Selector == nil {
+			c.checkCondition(when.When)
+		} else {
+			whenType := c.analyzeNode(when.When)
+			c.applyOperation(when.When, variants.Boolean, []variants.VariantType{selectorType, whenType},
+				func(values []*variants.Variant) (*variants.Variant, error) {
+					return c.variantOperations.Equal(values[0], values[1])
+				})
+		}
+		thenType := c.analyzeNode(when.Then)
+		if i == 0 {
+			result = thenType
+		} else {
+			result = commonType(result, thenType)
+		}
+	}
+	if node.Else != nil {
+		result = commonType(result, c.analyzeNode(node.Else))
+	}
+	return result
+}
+
+func (c *TypeAnalyzer) analyzeFunction(node *ast.FunctionNode) variants.VariantType {
+	function := c.functions.FindByName(node.Name)
+	evaluation := functions.GetLazyEvaluation(function, len(node.Parameters))
+	if evaluation == functions.LazyCondition {
+		c.checkCondition(node.Parameters[0])
+		return commonType(c.analyzeNode(node.Parameters[1]), c.analyzeNode(node.Parameters[2]))
+	}
+	if evaluation == functions.LazyCoalesce {
+		result := c.analyzeNode(node.Parameters[0])
+		for _, parameter := range node.Parameters[1:] {
+			result = commonType(result, c.analyzeNode(parameter))
+		}
+		return result
+	}
+	if evaluation == functions.LazyChoice {
+		c.checkConversion(node.Parameters[0], c.analyzeNode(node.Parameters[0]), variants.Integer)
+		result := c.analyzeNode(node.Parameters[1])
+		for _, parameter := range node.Parameters[2:] {
+			result = commonType(result, c.analyzeNode(parameter))
+		}
+		return result
+	}
+
+	types := make([]variants.VariantType, len(node.Parameters))
+	for i, parameter := range node.Parameters {
+		types[i] = c.analyzeNode(parameter)
+	}
+
+	if function == nil {
+		err := errors.NewExpressionError("", "FUNC_NOT_FOUND",
+			"Function "+node.Name+" was not found", node.Line, node.Column)
+		c.errors = append(c.errors, err)
+		return variants.Null
+	}
+
+	// Signatures set in the analyzer override signatures of functions and their result types
+	// are final. Otherwise signatures of functions are checked, but result types are still inferred
+	// from sample values because they are more precise for functions that return values of the parameter types.
+	signature := c.FunctionSignature(node.Name)
+	overridden := signature != nil
+	if !overridden {
+		signature = functions.GetFunctionSignature(function)
+	}
+	resultType := variants.Null
+	if signature != nil {
+		if signature.CheckParamCount(len(node.Parameters)) != nil {
+			err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
+				"Wrong number of parameters in function "+node.Name, node.Line, node.Column)
+			c.errors = append(c.errors, err)
+			return signature.ResultType
+		}
+		errorCount := len(c.errors)
+		for i, parameter := range node.Parameters {
+			c.checkConversion(parameter, types[i], signature.ParameterType(i))
+		}
+		if overridden || len(c.errors) > errorCount {
+			return signature.ResultType
+		}
+		resultType = signature.ResultType
+	}
+
+	if deterministic, ok := function.(functions.IDeterministicFunction); !ok || !deterministic.Deterministic() {
+		return resultType
+	}
+	for _, typ := range types {
+		if typ == variants.Null {
+			return resultType
+		}
+	}
+
+	values := make([]*variants.Variant, len(types))
+	for i, typ := range types {
+		values[i] = sampleValue(typ)
+	}
+	result, err := c.safeOperation(func() (*variants.Variant, error) {
+		return function.Calculate(values, c.variantOperations)
+	})
+	if err != nil {
+		// Only type related errors are reported, others depend on actual values
+		if isTypeError(err) {
+			c.addError(err, node.Pos())
+		}
+		return resultType
+	}
+	return result.Type()
+}
+
+func (c *TypeAnalyzer) checkCondition(node ast.Node) {
+	c.checkConversion(node, c.analyzeNode(node), variants.Boolean)
+}
+
+func (c *TypeAnalyzer) checkConversion(node ast.Node, typ variants.VariantType, newType variants.VariantType) {
+	if typ == variants.Null || newType == variants.Null || typ == newType {
+		return
+	}
+	_, err := c.safeOperation(func() (*variants.Variant, error) {
+		return c.variantOperations.Convert(sampleValue(typ), newType)
+	})
+	if err != nil {
+		code := "WRONG_PARAM_TYPE"
+		if appErr, ok := err.(*cerrors.ApplicationError); ok {
+			code = appErr.Code
+		}
+		err = errors.NewExpressionError("", code,
+			"Value of type "+typeToString(typ)+" cannot be converted to "+typeToString(newType),
+			node.Pos().Line, node.Pos().Column)
+		c.errors = append(c.errors, err)
+	}
+}
+
+func (c *TypeAnalyzer) applyOperation(node ast.Node, unknownType variants.VariantType,
+	types []variants.VariantType, operation func(values []*variants.Variant) (*variants.Variant, error)) variants.VariantType {
+
+	values := make([]*variants.Variant, len(types))
+	for i, typ := range types {
+		if typ == variants.Null {
+			return unknownType
+		}
+		values[i] = sampleValue(typ)
+	}
+
+	result, err := c.safeOperation(func() (*variants.Variant, error) {
+		return operation(values)
+	})
+	if err != nil {
+		c.addError(err, node.Pos())
+		return unknownType
+	}
+	return result.Type()
+}
+
+func (c *TypeAnalyzer) safeOperation(operation func() (*variants.Variant, error)) (result *variants.Variant, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			err = errors.NewExpressionError("", "OP_NOT_SUPPORTED", "Operation is not supported", 0, 0)
+		}
+	}()
+	result, err = operation()
+	if err == nil && result == nil {
+		result = variants.EmptyVariant()
+	}
+	return result, err
+}
+
+func isTypeError(err error) bool {
+	if appErr, ok := err.(*cerrors.ApplicationError); ok {
+		switch appErr.Code {
+		case "CONV_NOT_SUPPORTED", "OP_NOT_SUPPORTED", "WRONG_PARAM_TYPE", "WRONG_PARAM_COUNT":
+			return true
+		}
+	}
+	return false
+}
+
+// commonType gets the type of value that can be returned by alternative branches.
+func commonType(type1 variants.VariantType, type2 variants.VariantType) variants.VariantType {
+	if type1 == type2 {
+		return type1
+	}
+	return variants.Null
+}
+
+// sampleValue creates a sample value of the specified type used to check operations.
+func sampleValue(typ variants.VariantType) *variants.Variant {
+	switch typ {
+	case variants.Integer:
+		return variants.VariantFromInteger(1)
+	case variants.Long:
+		return variants.VariantFromLong(1)
+	case variants.Float:
+		return variants.VariantFromFloat(1)
+	case variants.Double:
+		return variants.VariantFromDouble(1)
+	case variants.Decimal:
+		return variants.VariantFromDecimal(variants.DecimalFromInt64(1))
+	case variants.BigInteger:
+		return variants.VariantFromBigInteger(big.NewInt(1))
+	case variants.String:
+		return variants.VariantFromString("1")
+	case variants.Boolean:
+		return variants.VariantFromBoolean(true)
+	case variants.DateTime:
+		return variants.VariantFromDateTime(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	case variants.TimeSpan:
+		return variants.VariantFromTimeSpan(time.Second)
+	case variants.Object:
+		return variants.VariantFromObject(map[string]any{})
+	case variants.Array:
+		return variants.VariantFromArray([]*variants.Variant{variants.VariantFromInteger(1)})
+	}
+	return variants.EmptyVariant()
+}
+
+func typeToString(typ variants.VariantType) string {
+	switch typ {
+	case variants.Integer:
+		return "Integer"
+	case variants.Long:
+		return "Long"
+	case variants.Float:
+		return "Float"
+	case variants.Double:
+		return "Double"
+	case variants.Decimal:
+		return "Decimal"
+	case variants.BigInteger:
+		return "BigInteger"
+	case variants.String:
+		return "String"
+	case variants.Boolean:
+		return "Boolean"
+	case variants.DateTime:
+		return "DateTime"
+	case variants.TimeSpan:
+		return "TimeSpan"
+	case variants.Object:
+		return "Object"
+	case variants.Array:
+		return "Array"
+	}
+	return "Null"
+}
@@ -0,0 +1,25 @@
+package ast
+
+// BinaryNode defines a binary operation.
+type BinaryNode struct {
+	Position
+	Operator Operator
+	Left     Node
+	Right    Node
+}
+
+// NewBinaryNode creates a binary operation node.
+//	Parameters:
+//		- operator: The binary operator.
+//		- left: The left operand node.
+//		- right: The right operand node.
+//		- line: The line number where the operator is.
+//		- column: The column number where the operator is.
+func NewBinaryNode(operator Operator, left Node, right Node, line int, column int) *BinaryNode {
+	return &BinaryNode{Position: Position{Line: line, Column: column}, Operator: operator, Left: left, Right: right}
+}
+
+// Children gets the child nodes in the evaluation order.
+func (c *BinaryNode) Children() []Node {
+	return []Node{c.Left, c.Right}
+}
@@ -0,0 +1,55 @@
+package ast
+
+// WhenClause defines a WHEN ... THEN ... clause of CASE expression.
+type WhenClause struct {
+	// When the condition in searched form or the compared value in simple form.
+	When Node
+	// Then the result of the clause.
+	Then Node
+}
+
+// CaseNode defines a CASE expression in searched form
+// <code>CASE WHEN condition THEN value ... ELSE value END</code>
+// or in simple form <code>CASE selector WHEN value THEN value ... ELSE value END</code>.
+type CaseNode struct {
+	Position
+	// Selector the selector in simple form or nil in searched form.
+	Selector Node
+	Whens    []*WhenClause
+	// Else the ELSE value or nil if it is not set.
+	Else Node
+}
+
+// NewCaseNode creates a CASE expression node.
+//	Parameters:
+//		- selector: The selector in simple form or nil in searched form.
+//		- whens: The WHEN clauses.
+//		- elseValue: The ELSE value or nil.
+//		- line: The line number where CASE keyword is.
+//		- column: The column number where CASE keyword is.
+func NewCaseNode(selector Node, whens []*WhenClause, elseValue Node, line int, column int) *CaseNode {
+	if whens == nil {
+		whens = []*WhenClause{}
+	}
+	return &CaseNode{
+		Position: Position{Line: line, Column: column},
+		Selector: selector,
+		Whens:    whens,
+		Else:     elseValue,
+	}
+}
+
+// Children gets the child nodes in the evaluation order.
+func (c *CaseNode) Children() []Node {
+	result := []Node{}
+	if c.Selector != nil {
+		result = append(result, c.Selector)
+	}
+	for _, when := range c.Whens {
+		result = append(result, when.When, when.Then)
+	}
+	if c.Else != nil {
+		result = append(result, c.Else)
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+package ast
+
+// ConditionalNode defines a conditional operator: <code>condition ? trueValue : falseValue</code>.
+type ConditionalNode struct {
+	Position
+	Condition  Node
+	TrueValue  Node
+	FalseValue Node
+}
+
+// NewConditionalNode creates a conditional operator node.
+//	Parameters:
+//		- condition: The condition node.
+//		- trueValue: The node evaluated when the condition is true.
+//		- falseValue: The node evaluated when the condition is false.
+//		- line: The line number where the '?' operator is.
+//		- column: The column number where the '?' operator is.
+func NewConditionalNode(condition Node, trueValue Node, falseValue Node, line int, column int) *ConditionalNode {
+	return &ConditionalNode{
+		Position:   Position{Line: line, Column: column},
+		Condition:  condition,
+		TrueValue:  trueValue,
+		FalseValue: falseValue,
+	}
+}
+
+// Children gets the child nodes in the evaluation order.
+func (c *ConditionalNode) Children() []Node {
+	return []Node{c.Condition, c.TrueValue, c.FalseValue}
+}
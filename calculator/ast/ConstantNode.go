@@ -0,0 +1,26 @@
+package ast
+
+import "github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+
+// ConstantNode defines a constant value.
+type ConstantNode struct {
+	Position
+	Value *variants.Variant
+}
+
+// NewConstantNode creates a constant node.
+//	Parameters:
+//		- value: The constant value.
+//		- line: The line number where the constant is.
+//		- column: The column number where the constant is.
+func NewConstantNode(value *variants.Variant, line int, column int) *ConstantNode {
+	if value == nil {
+		value = variants.EmptyVariant()
+	}
+	return &ConstantNode{Position: Position{Line: line, Column: column}, Value: value}
+}
+
+// Children gets the child nodes in the evaluation order.
+func (c *ConstantNode) Children() []Node {
+	return []Node{}
+}
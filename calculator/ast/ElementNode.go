@@ -0,0 +1,23 @@
+package ast
+
+// ElementNode defines an access to the array element or string character: <code>object[index]</code>.
+type ElementNode struct {
+	Position
+	Object Node
+	Index  Node
+}
+
+// NewElementNode creates an element access node.
+//	Parameters:
+//		- object: The array or string node.
+//		- index: The index node.
+//		- line: The line number where the '[' operator is.
+//		- column: The column number where the '[' operator is.
+func NewElementNode(object Node, index Node, line int, column int) *ElementNode {
+	return &ElementNode{Position: Position{Line: line, Column: column}, Object: object, Index: index}
+}
+
+// Children gets the child nodes in the evaluation order.
+func (c *ElementNode) Children() []Node {
+	return []Node{c.Object, c.Index}
+}
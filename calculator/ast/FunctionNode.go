@@ -0,0 +1,28 @@
+package ast
+
+// FunctionNode defines a function call.
+type FunctionNode struct {
+	Position
+	Name       string
+	Parameters []Node
+}
+
+// NewFunctionNode creates a function call node.
+//	Parameters:
+//		- name: The function name.
+//		- parameters: The function parameter nodes.
+//		- line: The line number where the function name is.
+//		- column: The column number where the function name is.
+func NewFunctionNode(name string, parameters []Node, line int, column int) *FunctionNode {
+	if parameters == nil {
+		parameters = []Node{}
+	}
+	return &FunctionNode{Position: Position{Line: line, Column: column}, Name: name, Parameters: parameters}
+}
+
+// Children gets the child nodes in the evaluation order.
+func (c *FunctionNode) Children() []Node {
+	result := make([]Node, len(c.Parameters))
+	copy(result, c.Parameters)
+	return result
+}
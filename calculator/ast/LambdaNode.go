@@ -0,0 +1,26 @@
+package ast
+
+// LambdaNode defines a lambda expression: <code>x => body</code> or <code>(x, y) => body</code>.
+type LambdaNode struct {
+	Position
+	Parameters []string
+	Body       Node
+}
+
+// NewLambdaNode creates a lambda expression node.
+//	Parameters:
+//		- parameters: The names of lambda parameters.
+//		- body: The lambda body node.
+//		- line: The line number where the '=>' operator is.
+//		- column: The column number where the '=>' operator is.
+func NewLambdaNode(parameters []string, body Node, line int, column int) *LambdaNode {
+	if parameters == nil {
+		parameters = []string{}
+	}
+	return &LambdaNode{Position: Position{Line: line, Column: column}, Parameters: parameters, Body: body}
+}
+
+// Children gets the child nodes in the evaluation order.
+func (c *LambdaNode) Children() []Node {
+	return []Node{c.Body}
+}
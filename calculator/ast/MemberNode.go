@@ -0,0 +1,23 @@
+package ast
+
+// MemberNode defines an access to the object member: <code>object.name</code>.
+type MemberNode struct {
+	Position
+	Object Node
+	Name   string
+}
+
+// NewMemberNode creates a member access node.
+//	Parameters:
+//		- object: The object node.
+//		- name: The member name.
+//		- line: The line number where the '.' operator is.
+//		- column: The column number where the '.' operator is.
+func NewMemberNode(object Node, name string, line int, column int) *MemberNode {
+	return &MemberNode{Position: Position{Line: line, Column: column}, Object: object, Name: name}
+}
+
+// Children gets the child nodes in the evaluation order.
+func (c *MemberNode) Children() []Node {
+	return []Node{c.Object}
+}
@@ -0,0 +1,25 @@
+package ast
+
+// Position defines a position of the node in the source expression.
+type Position struct {
+	// Line the line number starting from 1.
+	Line int
+	// Column the column number starting from 1.
+	Column int
+}
+
+// Pos gets the position of the node.
+func (c Position) Pos() Position {
+	return c
+}
+
+// Node defines an interface for nodes of the expression abstract syntax tree.
+// Operator nodes are positioned at their operator, other nodes at their first token.
+type Node interface {
+	// Pos gets the position of the node in the source expression.
+	Pos() Position
+
+	// Children gets the child nodes in the evaluation order.
+	// Missing optional children are skipped.
+	Children() []Node
+}
@@ -0,0 +1,41 @@
+package ast
+
+// Operator defines operators of unary and binary nodes.
+// The operator value is its text in the expression.
+type Operator string
+
+// Binary operators.
+const (
+	Add        Operator = "+"
+	Subtract   Operator = "-"
+	Multiply   Operator = "*"
+	Divide     Operator = "/"
+	Modulo     Operator = "%"
+	Power      Operator = "^"
+	Equal      Operator = "="
+	NotEqual   Operator = "<>"
+	More       Operator = ">"
+	Less       Operator = "<"
+	MoreEqual  Operator = ">="
+	LessEqual  Operator = "<="
+	ShiftLeft  Operator = "<<"
+	ShiftRight Operator = ">>"
+	And        Operator = "AND"
+	Or         Operator = "OR"
+	Xor        Operator = "XOR"
+	In         Operator = "IN"
+	NotIn      Operator = "NOT IN"
+	Like       Operator = "LIKE"
+	NotLike    Operator = "NOT LIKE"
+	Matches    Operator = "MATCHES"
+	NotMatches Operator = "NOT MATCHES"
+	Coalesce   Operator = "??"
+)
+
+// Unary operators.
+const (
+	Negate    Operator = "-"
+	Not       Operator = "NOT"
+	IsNull    Operator = "IS NULL"
+	IsNotNull Operator = "IS NOT NULL"
+)
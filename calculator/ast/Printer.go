@@ -0,0 +1,246 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	ctokenizers "github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/tokenizers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// Defines precedence levels of nodes as they are parsed by ExpressionParser.
+const (
+	lowestPrecedence = iota
+	logicalPrecedence
+	notPrecedence
+	comparePrecedence
+	coalescePrecedence
+	additivePrecedence
+	multiplicativePrecedence
+	powerPrecedence
+	unaryPrecedence
+	primaryPrecedence
+)
+
+// Format converts the syntax tree back into infix expression text.
+// Parentheses are added only where they are required by the operator precedence.
+//	Parameters:
+//		- node: The root node of the tree.
+//	Returns: The expression text.
+func Format(node Node) string {
+	builder := &strings.Builder{}
+	formatNode(builder, node)
+	return builder.String()
+}
+
+// precedence gets the precedence level of the node.
+func precedence(node Node) int {
+	switch n := node.(type) {
+	case *LambdaNode, *ConditionalNode:
+		return lowestPrecedence
+	case *UnaryNode:
+		switch n.Operator {
+		case Not:
+			return notPrecedence
+		case IsNull, IsNotNull:
+			return additivePrecedence
+		}
+		return unaryPrecedence
+	case *BinaryNode:
+		switch n.Operator {
+		case And, Or, Xor:
+			return logicalPrecedence
+		case Equal, NotEqual, More, Less, MoreEqual, LessEqual:
+			return comparePrecedence
+		case Coalesce:
+			return coalescePrecedence
+		case Add, Subtract, Like, NotLike, Matches, NotMatches, NotIn:
+			return additivePrecedence
+		case Multiply, Divide, Modulo:
+			return multiplicativePrecedence
+		}
+		return powerPrecedence
+	case *ConstantNode:
+		if strings.HasPrefix(formatConstant(n.Value), "-") {
+			return unaryPrecedence
+		}
+	}
+	return primaryPrecedence
+}
+
+// formatOperand writes the node enclosed into parentheses when its precedence is lower than required.
+func formatOperand(builder *strings.Builder, node Node, minPrecedence int) {
+	if precedence(node) < minPrecedence {
+		builder.WriteString("(")
+		formatNode(builder, node)
+		builder.WriteString(")")
+		return
+	}
+	formatNode(builder, node)
+}
+
+func formatNode(builder *strings.Builder, node Node) {
+	switch n := node.(type) {
+	case *ConstantNode:
+		builder.WriteString(formatConstant(n.Value))
+	case *VariableNode:
+		builder.WriteString(formatName(n.Name))
+	case *UnaryNode:
+		switch n.Operator {
+		case Not:
+			builder.WriteString("NOT ")
+			formatOperand(builder, n.Operand, comparePrecedence)
+		case IsNull, IsNotNull:
+			formatOperand(builder, n.Operand, additivePrecedence)
+			builder.WriteString(" " + string(n.Operator))
+		default:
+			builder.WriteString(string(n.Operator))
+			formatOperand(builder, n.Operand, primaryPrecedence)
+		}
+	case *BinaryNode:
+		// Operators are left associative.
+		level := precedence(n)
+		formatOperand(builder, n.Left, level)
+		builder.WriteString(" " + string(n.Operator) + " ")
+		if list, ok := n.Right.(*FunctionNode); ok && (n.Operator == In || n.Operator == NotIn) &&
+			strings.ToUpper(list.Name) == "ARRAY" && len(list.Parameters) != 1 {
+			// Lists of values are printed as <code>IN (1, 2, 3)</code>.
+			formatParameters(builder, list.Parameters)
+		} else {
+			formatOperand(builder, n.Right, level+1)
+		}
+	case *FunctionNode:
+		builder.WriteString(n.Name)
+		formatParameters(builder, n.Parameters)
+	case *MemberNode:
+		formatOperand(builder, n.Object, primaryPrecedence)
+		builder.WriteString(".")
+		builder.WriteString(n.Name)
+	case *ElementNode:
+		formatOperand(builder, n.Object, primaryPrecedence)
+		builder.WriteString("[")
+		formatNode(builder, n.Index)
+		builder.WriteString("]")
+	case *ConditionalNode:
+		formatOperand(builder, n.Condition, logicalPrecedence)
+		builder.WriteString(" ? ")
+		formatNode(builder, n.TrueValue)
+		builder.WriteString(" : ")
+		formatNode(builder, n.FalseValue)
+	case *CaseNode:
+		builder.WriteString("CASE")
+		if n.Selector != nil {
+			builder.WriteString(" ")
+			formatNode(builder, n.Selector)
+		}
+		for _, when := range n.Whens {
+			builder.WriteString(" WHEN ")
+			formatNode(builder, when.When)
+			builder.WriteString(" THEN ")
+			formatNode(builder, when.Then)
+		}
+		if n.Else != nil {
+			builder.WriteString(" ELSE ")
+			formatNode(builder, n.Else)
+		}
+		builder.WriteString(" END")
+	case *LambdaNode:
+		if len(n.Parameters) == 1 {
+			builder.WriteString(formatName(n.Parameters[0]))
+		} else {
+			builder.WriteString("(")
+			for i, parameter := range n.Parameters {
+				if i > 0 {
+					builder.WriteString(", ")
+				}
+				builder.WriteString(formatName(parameter))
+			}
+			builder.WriteString(")")
+		}
+		builder.WriteString(" => ")
+		formatNode(builder, n.Body)
+	}
+}
+
+// formatParameters writes a list of nodes in parentheses separated by commas.
+func formatParameters(builder *strings.Builder, parameters []Node) {
+	builder.WriteString("(")
+	for i, parameter := range parameters {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		formatNode(builder, parameter)
+	}
+	builder.WriteString(")")
+}
+
+// formatConstant converts a constant value into expression text.
+func formatConstant(value *variants.Variant) string {
+	switch value.Type() {
+	case variants.Null:
+		return "NULL"
+	case variants.Boolean:
+		if value.AsBoolean() {
+			return "TRUE"
+		}
+		return "FALSE"
+	case variants.Integer:
+		return strconv.Itoa(value.AsInteger())
+	case variants.Long:
+		return strconv.FormatInt(value.AsLong(), 10)
+	case variants.Float:
+		return formatFloat(float64(value.AsFloat()), 32)
+	case variants.Double:
+		return formatFloat(value.AsDouble(), 64)
+	case variants.Decimal:
+		return value.AsDecimal().String() + "m"
+	case variants.BigInteger:
+		return value.AsBigInteger().String()
+	case variants.String:
+		return ctokenizers.NewExpressionQuoteState().EncodeString(value.AsString(), '\'')
+	case variants.Array:
+		parameters := []string{}
+		for _, element := range value.AsArray() {
+			parameters = append(parameters, formatConstant(element))
+		}
+		return "Array(" + strings.Join(parameters, ", ") + ")"
+	}
+
+	text, err := variants.NewTypeUnsafeVariantOperations().Convert(value, variants.String)
+	if err != nil {
+		return "NULL"
+	}
+	return ctokenizers.NewExpressionQuoteState().EncodeString(text.AsString(), '\'')
+}
+
+// formatFloat formats a floating point number so it is parsed back as a float.
+func formatFloat(value float64, bitSize int) string {
+	result := strconv.FormatFloat(value, 'f', -1, bitSize)
+	if !strings.Contains(result, ".") {
+		result += ".0"
+	}
+	return result
+}
+
+// formatName writes the name as is or encloses it into double quotes
+// when it is a keyword or contains symbols other than letters, digits and underscores.
+func formatName(name string) string {
+	valid := name != ""
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			valid = false
+			break
+		}
+	}
+	for _, keyword := range ctokenizers.Keywords {
+		if strings.EqualFold(keyword, name) {
+			valid = false
+			break
+		}
+	}
+	if valid {
+		return name
+	}
+	return ctokenizers.NewExpressionQuoteState().EncodeString(name, '"')
+}
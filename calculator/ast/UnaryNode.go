@@ -0,0 +1,23 @@
+package ast
+
+// UnaryNode defines a unary operation: '-', NOT, IS NULL or IS NOT NULL.
+type UnaryNode struct {
+	Position
+	Operator Operator
+	Operand  Node
+}
+
+// NewUnaryNode creates a unary operation node.
+//	Parameters:
+//		- operator: The unary operator.
+//		- operand: The operand node.
+//		- line: The line number where the operator is.
+//		- column: The column number where the operator is.
+func NewUnaryNode(operator Operator, operand Node, line int, column int) *UnaryNode {
+	return &UnaryNode{Position: Position{Line: line, Column: column}, Operator: operator, Operand: operand}
+}
+
+// Children gets the child nodes in the evaluation order.
+func (c *UnaryNode) Children() []Node {
+	return []Node{c.Operand}
+}
@@ -0,0 +1,21 @@
+package ast
+
+// VariableNode defines a reference to a variable or a lambda parameter.
+type VariableNode struct {
+	Position
+	Name string
+}
+
+// NewVariableNode creates a variable node.
+//	Parameters:
+//		- name: The variable name.
+//		- line: The line number where the variable is.
+//		- column: The column number where the variable is.
+func NewVariableNode(name string, line int, column int) *VariableNode {
+	return &VariableNode{Position: Position{Line: line, Column: column}, Name: name}
+}
+
+// Children gets the child nodes in the evaluation order.
+func (c *VariableNode) Children() []Node {
+	return []Node{}
+}
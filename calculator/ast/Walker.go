@@ -0,0 +1,97 @@
+package ast
+
+// IVisitor defines an interface for visitors of syntax tree nodes used by Walk.
+type IVisitor interface {
+	// Visit is called for each node of the tree.
+	// If the returned visitor is not nil, Walk visits each child of the node with it,
+	// followed by a call of Visit(nil).
+	//	Parameters:
+	//		- node: The visited node.
+	//	Returns: The visitor for the child nodes or nil to skip them.
+	Visit(node Node) IVisitor
+}
+
+// Walk traverses the syntax tree in depth-first order.
+//	Parameters:
+//		- visitor: The visitor called for each node.
+//		- node: The root node of the tree.
+func Walk(visitor IVisitor, node Node) {
+	if node == nil {
+		return
+	}
+	if visitor = visitor.Visit(node); visitor == nil {
+		return
+	}
+	for _, child := range node.Children() {
+		Walk(visitor, child)
+	}
+	visitor.Visit(nil)
+}
+
+// inspector implements a visitor that calls a function for each node.
+type inspector func(node Node) bool
+
+func (c inspector) Visit(node Node) IVisitor {
+	if c(node) {
+		return c
+	}
+	return nil
+}
+
+// Inspect traverses the syntax tree in depth-first order and calls the function for each node.
+// If the function returns true, Inspect visits the children of the node,
+// followed by a call of the function with nil.
+//	Parameters:
+//		- node: The root node of the tree.
+//		- f: The function called for each node.
+func Inspect(node Node, f func(node Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// Rewrite replaces nodes of the syntax tree in depth-first order.
+// Children are rewritten before their parent, so the function receives the parent with rewritten children.
+// The function may return the same node or a new one; nil results are ignored.
+//	Parameters:
+//		- node: The root node of the tree.
+//		- f: The function that returns a replacement for the node.
+//	Returns: The rewritten root node.
+func Rewrite(node Node, f func(node Node) Node) Node {
+	if node == nil {
+		return nil
+	}
+
+	switch n := node.(type) {
+	case *UnaryNode:
+		n.Operand = Rewrite(n.Operand, f)
+	case *BinaryNode:
+		n.Left = Rewrite(n.Left, f)
+		n.Right = Rewrite(n.Right, f)
+	case *FunctionNode:
+		for i, parameter := range n.Parameters {
+			n.Parameters[i] = Rewrite(parameter, f)
+		}
+	case *MemberNode:
+		n.Object = Rewrite(n.Object, f)
+	case *ElementNode:
+		n.Object = Rewrite(n.Object, f)
+		n.Index = Rewrite(n.Index, f)
+	case *ConditionalNode:
+		n.Condition = Rewrite(n.Condition, f)
+		n.TrueValue = Rewrite(n.TrueValue, f)
+		n.FalseValue = Rewrite(n.FalseValue, f)
+	case *CaseNode:
+		n.Selector = Rewrite(n.Selector, f)
+		for _, when := range n.Whens {
+			when.When = Rewrite(when.When, f)
+			when.Then = Rewrite(when.Then, f)
+		}
+		n.Else = Rewrite(n.Else, f)
+	case *LambdaNode:
+		n.Body = Rewrite(n.Body, f)
+	}
+
+	if result := f(node); result != nil {
+		return result
+	}
+	return node
+}
@@ -0,0 +1,53 @@
+package bytecode
+
+import "github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+
+// lambda implements a lambda expression created during evaluation of a program.
+// It shares the frame of the evaluation where it was created,
+// so evaluation limits apply to lambda calls.
+type lambda struct {
+	frame      *frame
+	definition *LambdaDefinition
+	start      int
+}
+
+// Parameters the names of lambda parameters.
+func (c *lambda) Parameters() []string {
+	result := []string{}
+	for _, slot := range c.definition.ParameterSlots {
+		result = append(result, c.frame.machine.program.variables[slot])
+	}
+	return result
+}
+
+// Invoke evaluates the lambda body with the specified parameter values.
+// Parameters are set into variable slots and restored after the call,
+// so they shadow variables with the same names.
+// Missing parameters are set to null and extra parameters are ignored.
+//	Parameters:
+//		- parameters: A list with parameter values.
+//	Returns: The evaluated lambda result.
+func (c *lambda) Invoke(parameters []*variants.Variant) (*variants.Variant, error) {
+	slots := c.definition.ParameterSlots
+	values := make([]*variants.Variant, len(slots))
+	resolved := make([]bool, len(slots))
+	for i, slot := range slots {
+		values[i] = c.frame.slots[slot]
+		resolved[i] = c.frame.resolved[slot]
+
+		value := variants.EmptyVariant()
+		if i < len(parameters) {
+			value = parameters[i]
+		}
+		c.frame.slots[slot] = value
+		c.frame.resolved[slot] = true
+	}
+
+	result, err := c.frame.run(c.start, c.start+c.definition.Length)
+
+	for i, slot := range slots {
+		c.frame.slots[slot] = values[i]
+		c.frame.resolved[slot] = resolved[i]
+	}
+	return result, err
+}
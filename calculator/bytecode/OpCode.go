@@ -0,0 +1,67 @@
+package bytecode
+
+// OpCode defines a type of bytecode instruction.
+type OpCode byte
+
+// Defines supported bytecode instructions.
+// Operands of instructions are described in the comments.
+const (
+	// OpConstant pushes the constant with the operand index.
+	OpConstant OpCode = iota
+	// OpVariable pushes the value of variable in the operand slot.
+	OpVariable
+	// OpFunction calls the function in the operand slot.
+	OpFunction
+	// OpLambda pushes a lambda defined by the operand index. The lambda body follows the instruction.
+	OpLambda
+	// OpJump skips the operand number of instructions.
+	OpJump
+	// OpJumpIfFalse pops a condition and skips the operand number of instructions when it is false.
+	OpJumpIfFalse
+	// OpJumpTable pops a branch index and jumps to the branch from the jump table with the operand index.
+	OpJumpTable
+	// OpShortCircuitAnd skips the operand number of instructions when the top value is false.
+	OpShortCircuitAnd
+	// OpShortCircuitOr skips the operand number of instructions when the top value is true.
+	OpShortCircuitOr
+	OpDuplicate
+	OpPop
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpPow
+	OpNegative
+	OpShiftLeft
+	OpShiftRight
+	OpAnd
+	OpOr
+	OpXor
+	OpNot
+	OpEqual
+	OpNotEqual
+	OpMore
+	OpLess
+	OpMoreEqual
+	OpLessEqual
+	OpIn
+	OpNotIn
+	OpLike
+	OpNotLike
+	OpElement
+	OpMember
+	OpIsNull
+	OpIsNotNull
+	OpMatches
+	OpNotMatches
+	// OpShortCircuitCoalesce skips the operand number of instructions when the top value is not null.
+	OpShortCircuitCoalesce
+	OpCoalesce
+)
+
+// Instruction defines a single bytecode instruction.
+type Instruction struct {
+	OpCode  OpCode
+	Operand int32
+}
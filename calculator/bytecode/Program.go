@@ -0,0 +1,286 @@
+package bytecode
+
+import (
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// FunctionSlot defines a function called by the program with a fixed number of parameters.
+type FunctionSlot struct {
+	Name           string
+	ParameterCount int
+}
+
+// LambdaDefinition defines a lambda expression of the program.
+type LambdaDefinition struct {
+	// ParameterSlots the variable slots of lambda parameters.
+	ParameterSlots []int
+	// Length the number of instructions in the lambda body.
+	Length int
+}
+
+// Program implements an immutable expression compiled into bytecode.
+// Constants are kept in a pool, variables and functions are referenced by slots,
+// so their names are resolved once and not on every use.
+// Lambda parameters share slots with variables of the same names.
+// The program can be serialized into bytes with MarshalBinary and restored with UnmarshalBinary.
+type Program struct {
+	instructions  []Instruction
+	positions     []ast.Position
+	constants     []*variants.Variant
+	variables     []string
+	variableNames []string
+	functions     []FunctionSlot
+	jumpTables    [][]int
+	lambdas       []LambdaDefinition
+}
+
+// opCodes defines instructions for simple expression tokens.
+var opCodes = map[int]OpCode{
+	parsers.Duplicate: OpDuplicate, parsers.Pop: OpPop,
+	parsers.Plus: OpAdd, parsers.Minus: OpSub, parsers.Star: OpMul, parsers.Slash: OpDiv,
+	parsers.Procent: OpMod, parsers.Power: OpPow, parsers.Unary: OpNegative,
+	parsers.ShiftLeft: OpShiftLeft, parsers.ShiftRight: OpShiftRight,
+	parsers.And: OpAnd, parsers.Or: OpOr, parsers.Xor: OpXor, parsers.Not: OpNot,
+	parsers.Equal: OpEqual, parsers.NotEqual: OpNotEqual, parsers.More: OpMore, parsers.Less: OpLess,
+	parsers.EqualMore: OpMoreEqual, parsers.EqualLess: OpLessEqual,
+	parsers.In: OpIn, parsers.NotIn: OpNotIn, parsers.Like: OpLike, parsers.NotLike: OpNotLike,
+	parsers.Element: OpElement, parsers.Member: OpMember,
+	parsers.IsNull: OpIsNull, parsers.IsNotNull: OpIsNotNull,
+	parsers.Matches: OpMatches, parsers.NotMatches: OpNotMatches,
+	parsers.Jump: OpJump, parsers.JumpIfFalse: OpJumpIfFalse,
+	parsers.ShortCircuitAnd: OpShortCircuitAnd, parsers.ShortCircuitOr: OpShortCircuitOr,
+	parsers.ShortCircuitCoalesce: OpShortCircuitCoalesce, parsers.Coalesce: OpCoalesce,
+}
+
+// CompileExpression parses the expression string and compiles it into bytecode.
+//	Parameters:
+//		- expression: The expression string.
+//	Returns: A compiled program or error if expression has syntax errors.
+func CompileExpression(expression string) (*Program, error) {
+	parser := parsers.NewExpressionParser()
+	err := parser.ParseString(expression)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(parser.ResultTokens())
+}
+
+// Compile compiles parsed expression tokens into bytecode.
+//	Parameters:
+//		- tokens: The list of parsed expression tokens in reverse polish notation.
+//	Returns: A compiled program or error if tokens are not valid.
+func Compile(tokens []*parsers.ExpressionToken) (*Program, error) {
+	c := &Program{
+		instructions:  []Instruction{},
+		positions:     []ast.Position{},
+		constants:     []*variants.Variant{},
+		variables:     []string{},
+		variableNames: []string{},
+		functions:     []FunctionSlot{},
+		jumpTables:    [][]int{},
+		lambdas:       []LambdaDefinition{},
+	}
+
+	// Indexes of the first instruction compiled for each token.
+	// Jumps in tokens are relative to token positions, so they are recalculated.
+	indexes := make([]int, len(tokens)+1)
+	jumps := map[int]int{}
+	constants := map[any]int{}
+	scopes := []string{}
+	scopeEnds := []int{}
+
+	for index, token := range tokens {
+		indexes[index] = len(c.instructions)
+		for len(scopeEnds) > 0 && scopeEnds[len(scopeEnds)-1] < index {
+			scopes = scopes[:len(scopes)-1]
+			scopeEnds = scopeEnds[:len(scopeEnds)-1]
+		}
+
+		switch token.Type() {
+		case parsers.Constant:
+			// Parameter counts of functions are kept in function slots.
+			if index+1 < len(tokens) && tokens[index+1].Type() == parsers.Function {
+				continue
+			}
+			key := constantKey(token.Value())
+			slot, ok := constants[key]
+			if !ok || key == nil {
+				slot = len(c.constants)
+				c.constants = append(c.constants, token.Value())
+				if key != nil {
+					constants[key] = slot
+				}
+			}
+			c.addInstruction(OpConstant, slot, token)
+		case parsers.Variable:
+			name := token.Value().AsString()
+			local := false
+			for _, scope := range scopes {
+				if strings.EqualFold(scope, name) {
+					local = true
+					break
+				}
+			}
+			if !local {
+				c.addVariableName(name)
+			}
+			c.addInstruction(OpVariable, c.variableSlot(name), token)
+		case parsers.Function:
+			if index == 0 || tokens[index-1].Type() != parsers.Constant {
+				return nil, errors.NewExpressionError("", "INTERNAL", "Internal error", token.Line(), token.Column())
+			}
+			c.addInstruction(OpFunction, c.functionSlot(token.Value().AsString(), tokens[index-1].Value().AsInteger()), token)
+		case parsers.Lambda:
+			definition := token.Value().AsArray()
+			lambda := LambdaDefinition{ParameterSlots: []int{}, Length: definition[1].AsInteger()}
+			for _, parameter := range definition[0].AsArray() {
+				lambda.ParameterSlots = append(lambda.ParameterSlots, c.variableSlot(parameter.AsString()))
+				scopes = append(scopes, parameter.AsString())
+				scopeEnds = append(scopeEnds, index+lambda.Length)
+			}
+			jumps[len(c.instructions)] = index + lambda.Length + 1
+			c.lambdas = append(c.lambdas, lambda)
+			c.addInstruction(OpLambda, len(c.lambdas)-1, token)
+		case parsers.JumpTable:
+			// Keeps absolute token indexes of branches until all instructions are compiled.
+			branches := []int{}
+			start := index + 1
+			for _, length := range token.Value().AsArray() {
+				branches = append(branches, start)
+				start += length.AsInteger()
+			}
+			branches = append(branches, start)
+			c.jumpTables = append(c.jumpTables, branches)
+			c.addInstruction(OpJumpTable, len(c.jumpTables)-1, token)
+		default:
+			opCode, ok := opCodes[token.Type()]
+			if !ok {
+				return nil, errors.NewExpressionError("", "INTERNAL", "Internal error", token.Line(), token.Column())
+			}
+			if opCode == OpJump || opCode == OpJumpIfFalse || opCode == OpShortCircuitAnd ||
+				opCode == OpShortCircuitOr || opCode == OpShortCircuitCoalesce {
+				jumps[len(c.instructions)] = index + token.Value().AsInteger() + 1
+			}
+			c.addInstruction(opCode, 0, token)
+		}
+	}
+	indexes[len(tokens)] = len(c.instructions)
+
+	// Converts jump targets from token indexes into relative instruction offsets.
+	for instruction, target := range jumps {
+		if target > len(tokens) {
+			return nil, errors.NewExpressionError("", "INTERNAL", "Internal error", 0, 0)
+		}
+		offset := indexes[target] - instruction - 1
+		if c.instructions[instruction].OpCode == OpLambda {
+			c.lambdas[c.instructions[instruction].Operand].Length = offset
+		} else {
+			c.instructions[instruction].Operand = int32(offset)
+		}
+	}
+	for tableIndex, branches := range c.jumpTables {
+		instruction := 0
+		for index, current := range c.instructions {
+			if current.OpCode == OpJumpTable && int(current.Operand) == tableIndex {
+				instruction = index
+				break
+			}
+		}
+
+		// Branch lengths are stored in the same way as in JumpTable tokens.
+		lengths := make([]int, len(branches)-1)
+		previous := instruction + 1
+		for i, start := range branches[1:] {
+			if start > len(tokens) {
+				return nil, errors.NewExpressionError("", "INTERNAL", "Internal error", 0, 0)
+			}
+			lengths[i] = indexes[start] - previous
+			previous = indexes[start]
+		}
+		c.jumpTables[tableIndex] = lengths
+	}
+
+	return c, nil
+}
+
+// constantKey gets a key to share equal constants in the pool or nil if the constant is not shared.
+func constantKey(value *variants.Variant) any {
+	switch value.Type() {
+	case variants.Integer, variants.Long, variants.Float, variants.Double, variants.String, variants.Boolean:
+		return [2]any{value.Type(), value.AsObject()}
+	case variants.Decimal:
+		// Decimals with different scales are kept apart, so 1.0m and 1.00m are not shared.
+		return [2]any{value.Type(), value.AsDecimal().String()}
+	case variants.BigInteger:
+		return [2]any{value.Type(), value.AsBigInteger().String()}
+	}
+	return nil
+}
+
+func (c *Program) addInstruction(opCode OpCode, operand int, token *parsers.ExpressionToken) {
+	c.instructions = append(c.instructions, Instruction{OpCode: opCode, Operand: int32(operand)})
+	c.positions = append(c.positions, ast.Position{Line: token.Line(), Column: token.Column()})
+}
+
+func (c *Program) addVariableName(name string) {
+	for _, variableName := range c.variableNames {
+		if variableName == name {
+			return
+		}
+	}
+	c.variableNames = append(c.variableNames, name)
+}
+
+func (c *Program) variableSlot(name string) int {
+	for index, variable := range c.variables {
+		if strings.EqualFold(variable, name) {
+			return index
+		}
+	}
+	c.variables = append(c.variables, name)
+	return len(c.variables) - 1
+}
+
+func (c *Program) functionSlot(name string, parameterCount int) int {
+	for index, function := range c.functions {
+		if strings.EqualFold(function.Name, name) && function.ParameterCount == parameterCount {
+			return index
+		}
+	}
+	c.functions = append(c.functions, FunctionSlot{Name: name, ParameterCount: parameterCount})
+	return len(c.functions) - 1
+}
+
+// Instructions gets the list of program instructions.
+func (c *Program) Instructions() []Instruction {
+	result := make([]Instruction, len(c.instructions))
+	copy(result, c.instructions)
+	return result
+}
+
+// Constants gets the pool of program constants.
+func (c *Program) Constants() []*variants.Variant {
+	result := make([]*variants.Variant, len(c.constants))
+	copy(result, c.constants)
+	return result
+}
+
+// VariableNames gets the list of variable names used in the expression.
+// Lambda parameters are not included.
+func (c *Program) VariableNames() []string {
+	result := make([]string, len(c.variableNames))
+	copy(result, c.variableNames)
+	return result
+}
+
+// Functions gets the list of function slots.
+func (c *Program) Functions() []FunctionSlot {
+	result := make([]FunctionSlot, len(c.functions))
+	copy(result, c.functions)
+	return result
+}
@@ -0,0 +1,373 @@
+package bytecode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// programSignature starts serialized programs.
+var programSignature = []byte("PXBC")
+
+// programVersion defines the version of serialized programs.
+const programVersion = 1
+
+// programWriter writes values of serialized programs.
+type programWriter struct {
+	buffer bytes.Buffer
+	bytes  [binary.MaxVarintLen64]byte
+}
+
+func (c *programWriter) writeInt(value int64) {
+	n := binary.PutVarint(c.bytes[:], value)
+	c.buffer.Write(c.bytes[:n])
+}
+
+func (c *programWriter) writeUint(value uint64) {
+	n := binary.PutUvarint(c.bytes[:], value)
+	c.buffer.Write(c.bytes[:n])
+}
+
+func (c *programWriter) writeString(value string) {
+	c.writeUint(uint64(len(value)))
+	c.buffer.WriteString(value)
+}
+
+func (c *programWriter) writeValue(value *variants.Variant) error {
+	c.buffer.WriteByte(byte(value.Type()))
+	switch value.Type() {
+	case variants.Null:
+	case variants.Integer:
+		c.writeInt(int64(value.AsInteger()))
+	case variants.Long:
+		c.writeInt(value.AsLong())
+	case variants.Float:
+		c.writeUint(uint64(math.Float32bits(value.AsFloat())))
+	case variants.Double:
+		c.writeUint(math.Float64bits(value.AsDouble()))
+	case variants.Decimal:
+		c.writeString(value.AsDecimal().String())
+	case variants.BigInteger:
+		c.writeString(value.AsBigInteger().String())
+	case variants.String:
+		c.writeString(value.AsString())
+	case variants.Boolean:
+		if value.AsBoolean() {
+			c.buffer.WriteByte(1)
+		} else {
+			c.buffer.WriteByte(0)
+		}
+	case variants.DateTime:
+		data, err := value.AsDateTime().MarshalBinary()
+		if err != nil {
+			return err
+		}
+		c.writeString(string(data))
+	case variants.TimeSpan:
+		c.writeInt(int64(value.AsTimeSpan()))
+	case variants.Array:
+		c.writeUint(uint64(value.Length()))
+		for _, element := range value.AsArray() {
+			if err := c.writeValue(element); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.NewExpressionError("", "CONV_NOT_SUPPORTED",
+			"Constants of object type cannot be serialized", 0, 0)
+	}
+	return nil
+}
+
+// programReader reads values of serialized programs.
+// The first error stops reading and all following values are zero.
+type programReader struct {
+	reader *bytes.Reader
+	err    error
+}
+
+func (c *programReader) fail() {
+	if c.err == nil {
+		c.err = errors.NewExpressionError("", "INVALID_PROGRAM", "Serialized program is corrupted", 0, 0)
+	}
+}
+
+func (c *programReader) readInt() int64 {
+	if c.err != nil {
+		return 0
+	}
+	value, err := binary.ReadVarint(c.reader)
+	if err != nil {
+		c.fail()
+	}
+	return value
+}
+
+func (c *programReader) readUint() uint64 {
+	if c.err != nil {
+		return 0
+	}
+	value, err := binary.ReadUvarint(c.reader)
+	if err != nil {
+		c.fail()
+	}
+	return value
+}
+
+// readCount reads a number of items that cannot exceed the remaining data.
+func (c *programReader) readCount() int {
+	count := c.readUint()
+	if count > uint64(c.reader.Len()) {
+		c.fail()
+		return 0
+	}
+	return int(count)
+}
+
+func (c *programReader) readByte() byte {
+	if c.err != nil {
+		return 0
+	}
+	value, err := c.reader.ReadByte()
+	if err != nil {
+		c.fail()
+	}
+	return value
+}
+
+func (c *programReader) readString() string {
+	length := c.readCount()
+	if c.err != nil {
+		return ""
+	}
+	data := make([]byte, length)
+	if _, err := c.reader.Read(data); err != nil && length > 0 {
+		c.fail()
+	}
+	return string(data)
+}
+
+func (c *programReader) readValue() *variants.Variant {
+	typ := variants.VariantType(c.readByte())
+	switch typ {
+	case variants.Null:
+		return variants.EmptyVariant()
+	case variants.Integer:
+		return variants.VariantFromInteger(int(c.readInt()))
+	case variants.Long:
+		return variants.VariantFromLong(c.readInt())
+	case variants.Float:
+		return variants.VariantFromFloat(math.Float32frombits(uint32(c.readUint())))
+	case variants.Double:
+		return variants.VariantFromDouble(math.Float64frombits(c.readUint()))
+	case variants.Decimal:
+		value, err := variants.ParseDecimal(c.readString())
+		if err != nil {
+			c.fail()
+		}
+		return variants.VariantFromDecimal(value)
+	case variants.BigInteger:
+		value, ok := new(big.Int).SetString(c.readString(), 10)
+		if !ok {
+			c.fail()
+			value = new(big.Int)
+		}
+		return variants.VariantFromBigInteger(value)
+	case variants.String:
+		return variants.VariantFromString(c.readString())
+	case variants.Boolean:
+		return variants.VariantFromBoolean(c.readByte() != 0)
+	case variants.DateTime:
+		value := time.Time{}
+		if err := value.UnmarshalBinary([]byte(c.readString())); err != nil {
+			c.fail()
+		}
+		return variants.VariantFromDateTime(value)
+	case variants.TimeSpan:
+		return variants.VariantFromTimeSpan(time.Duration(c.readInt()))
+	case variants.Array:
+		count := c.readCount()
+		elements := make([]*variants.Variant, 0, count)
+		for i := 0; i < count && c.err == nil; i++ {
+			elements = append(elements, c.readValue())
+		}
+		return variants.VariantFromArray(elements)
+	}
+	c.fail()
+	return variants.EmptyVariant()
+}
+
+// MarshalBinary serializes the program into bytes.
+// Constants of object type cannot be serialized.
+//	Returns: The serialized program or error if it cannot be serialized.
+func (c *Program) MarshalBinary() ([]byte, error) {
+	writer := &programWriter{}
+	writer.buffer.Write(programSignature)
+	writer.writeUint(programVersion)
+
+	writer.writeUint(uint64(len(c.instructions)))
+	for index, instruction := range c.instructions {
+		writer.buffer.WriteByte(byte(instruction.OpCode))
+		writer.writeInt(int64(instruction.Operand))
+		writer.writeInt(int64(c.positions[index].Line))
+		writer.writeInt(int64(c.positions[index].Column))
+	}
+
+	writer.writeUint(uint64(len(c.constants)))
+	for _, constant := range c.constants {
+		if err := writer.writeValue(constant); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, names := range [][]string{c.variables, c.variableNames} {
+		writer.writeUint(uint64(len(names)))
+		for _, name := range names {
+			writer.writeString(name)
+		}
+	}
+
+	writer.writeUint(uint64(len(c.functions)))
+	for _, function := range c.functions {
+		writer.writeString(function.Name)
+		writer.writeUint(uint64(function.ParameterCount))
+	}
+
+	writer.writeUint(uint64(len(c.jumpTables)))
+	for _, branches := range c.jumpTables {
+		writer.writeUint(uint64(len(branches)))
+		for _, length := range branches {
+			writer.writeInt(int64(length))
+		}
+	}
+
+	writer.writeUint(uint64(len(c.lambdas)))
+	for _, lambda := range c.lambdas {
+		writer.writeUint(uint64(len(lambda.ParameterSlots)))
+		for _, slot := range lambda.ParameterSlots {
+			writer.writeUint(uint64(slot))
+		}
+		writer.writeInt(int64(lambda.Length))
+	}
+
+	return writer.buffer.Bytes(), nil
+}
+
+// UnmarshalBinary restores the program serialized with MarshalBinary.
+//	Parameters:
+//		- data: The serialized program.
+//	Returns: Error if the data is not a valid serialized program.
+func (c *Program) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, programSignature) {
+		return errors.NewExpressionError("", "INVALID_PROGRAM", "Data is not a serialized program", 0, 0)
+	}
+	reader := &programReader{reader: bytes.NewReader(data[len(programSignature):])}
+	if version := reader.readUint(); version != programVersion && reader.err == nil {
+		return errors.NewExpressionError("", "INVALID_PROGRAM", "Serialized program version is not supported", 0, 0)
+	}
+
+	program := Program{}
+	count := reader.readCount()
+	program.instructions = make([]Instruction, 0, count)
+	program.positions = make([]ast.Position, 0, count)
+	for i := 0; i < count && reader.err == nil; i++ {
+		opCode := OpCode(reader.readByte())
+		operand := int32(reader.readInt())
+		line := int(reader.readInt())
+		column := int(reader.readInt())
+		program.instructions = append(program.instructions, Instruction{OpCode: opCode, Operand: operand})
+		program.positions = append(program.positions, ast.Position{Line: line, Column: column})
+	}
+
+	count = reader.readCount()
+	program.constants = make([]*variants.Variant, 0, count)
+	for i := 0; i < count && reader.err == nil; i++ {
+		program.constants = append(program.constants, reader.readValue())
+	}
+
+	for _, names := range []*[]string{&program.variables, &program.variableNames} {
+		count = reader.readCount()
+		*names = make([]string, 0, count)
+		for i := 0; i < count && reader.err == nil; i++ {
+			*names = append(*names, reader.readString())
+		}
+	}
+
+	count = reader.readCount()
+	program.functions = make([]FunctionSlot, 0, count)
+	for i := 0; i < count && reader.err == nil; i++ {
+		name := reader.readString()
+		program.functions = append(program.functions, FunctionSlot{Name: name, ParameterCount: int(reader.readUint())})
+	}
+
+	count = reader.readCount()
+	program.jumpTables = make([][]int, 0, count)
+	for i := 0; i < count && reader.err == nil; i++ {
+		branches := make([]int, reader.readCount())
+		for j := range branches {
+			branches[j] = int(reader.readInt())
+		}
+		program.jumpTables = append(program.jumpTables, branches)
+	}
+
+	count = reader.readCount()
+	program.lambdas = make([]LambdaDefinition, 0, count)
+	for i := 0; i < count && reader.err == nil; i++ {
+		slots := make([]int, reader.readCount())
+		for j := range slots {
+			slots[j] = int(reader.readUint())
+		}
+		program.lambdas = append(program.lambdas, LambdaDefinition{ParameterSlots: slots, Length: int(reader.readInt())})
+	}
+
+	if reader.err != nil {
+		return reader.err
+	}
+	if err := program.validate(); err != nil {
+		return err
+	}
+	*c = program
+	return nil
+}
+
+// validate checks that operands of instructions reference existing items,
+// so corrupted programs cannot crash the virtual machine.
+func (c *Program) validate() error {
+	length := len(c.instructions)
+	for index, instruction := range c.instructions {
+		operand := int(instruction.Operand)
+		valid := true
+		switch instruction.OpCode {
+		case OpConstant:
+			valid = operand >= 0 && operand < len(c.constants)
+		case OpVariable:
+			valid = operand >= 0 && operand < len(c.variables)
+		case OpFunction:
+			valid = operand >= 0 && operand < len(c.functions)
+		case OpJumpTable:
+			valid = operand >= 0 && operand < len(c.jumpTables)
+		case OpLambda:
+			valid = operand >= 0 && operand < len(c.lambdas) &&
+				c.lambdas[operand].Length >= 0 && index+c.lambdas[operand].Length < length
+			if valid {
+				for _, slot := range c.lambdas[operand].ParameterSlots {
+					valid = valid && slot >= 0 && slot < len(c.variables)
+				}
+			}
+		case OpJump, OpJumpIfFalse, OpShortCircuitAnd, OpShortCircuitOr, OpShortCircuitCoalesce:
+			valid = operand >= 0 && index+operand < length
+		default:
+			valid = instruction.OpCode <= OpCoalesce
+		}
+		if !valid {
+			return errors.NewExpressionError("", "INVALID_PROGRAM", "Serialized program is corrupted", 0, 0)
+		}
+	}
+	return nil
+}
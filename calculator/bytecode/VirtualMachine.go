@@ -0,0 +1,618 @@
+package bytecode
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// contextCheckInterval defines how often (in instructions) the context is checked for cancellation.
+const contextCheckInterval = 64
+
+// VirtualMachine implements a stack machine that evaluates compiled programs.
+// Functions are resolved once when the machine is created and variables once per evaluation.
+// Comparisons and arithmetic of numbers of the same type are performed without conversions
+// when standard variant operations are used, and calculation stacks are reused between evaluations.
+// The machine is safe for concurrent use as long as the variables and functions
+// are not modified during evaluation.
+type VirtualMachine struct {
+	program           *Program
+	variantOperations variants.IVariantOperations
+	functions         []functions.IFunction
+	limits            calculator.EvaluationLimits
+	fastOperations    bool
+	frames            *sync.Pool
+	regexCache        *functions.RegexCache
+}
+
+// frame keeps the state of a single evaluation.
+type frame struct {
+	machine  *VirtualMachine
+	ctx      context.Context
+	done     <-chan struct{}
+	vars     variables.IVariableCollection
+	slots    []*variants.Variant
+	resolved []bool
+	stack    []*variants.Variant
+	step     int
+	retained bool
+}
+
+// NewVirtualMachine creates a machine to evaluate the program.
+// Calls of lazy functions like If, Choose, Coalesce and IfNull are compiled into jumps of the program
+// when it is parsed, so the functions do not replace them. Programs that shall call such replacements
+// are compiled from tokens parsed with the same functions set in the parser.
+//	Parameters:
+//		- program: The compiled program.
+//		- variantOperations: The manager for operations on variant values or nil to use type unsafe operations.
+//		- funcs: The list of functions or nil to use the standard functions.
+//	Returns: A created virtual machine.
+func NewVirtualMachine(program *Program, variantOperations variants.IVariantOperations,
+	funcs functions.IFunctionCollection) *VirtualMachine {
+
+	if variantOperations == nil {
+		variantOperations = variants.NewTypeUnsafeVariantOperations()
+	}
+	if funcs == nil {
+		funcs = functions.NewDefaultFunctionCollection()
+	}
+
+	c := &VirtualMachine{
+		program:           program,
+		variantOperations: variantOperations,
+		functions:         make([]functions.IFunction, len(program.functions)),
+		regexCache:        functions.NewRegexCache(0),
+	}
+	// Missing functions are reported when they are called.
+	for index, slot := range program.functions {
+		c.functions[index] = funcs.FindByName(slot.Name)
+	}
+	switch variantOperations.(type) {
+	case *variants.TypeUnsafeVariantOperations, *variants.TypeSafeVariantOperations:
+		c.fastOperations = true
+	}
+	c.frames = &sync.Pool{
+		New: func() any {
+			return &frame{
+				machine:  c,
+				slots:    make([]*variants.Variant, len(program.variables)),
+				resolved: make([]bool, len(program.variables)),
+				stack:    make([]*variants.Variant, 0, 16),
+			}
+		},
+	}
+	return c
+}
+
+// Program gets the evaluated program.
+func (c *VirtualMachine) Program() *Program {
+	return c.program
+}
+
+// VariantOperations gets the manager for operations on variant values.
+func (c *VirtualMachine) VariantOperations() variants.IVariantOperations {
+	return c.variantOperations
+}
+
+// Limits gets the limits applied during evaluation.
+func (c *VirtualMachine) Limits() calculator.EvaluationLimits {
+	return c.limits
+}
+
+// SetLimits sets the limits applied during evaluation.
+// Steps are counted in executed instructions.
+//	Parameters:
+//		- limits: The limits to be applied during evaluation.
+func (c *VirtualMachine) SetLimits(limits calculator.EvaluationLimits) {
+	c.limits = limits
+}
+
+// Evaluate the program using specified variables.
+//	Parameters:
+//		- vars: The list of variables or nil if expression has no variables.
+//	Returns: An evaluated expression value.
+func (c *VirtualMachine) Evaluate(vars variables.IVariableCollection) (*variants.Variant, error) {
+	return c.EvaluateWithContext(context.Background(), vars)
+}
+
+// EvaluateWithContext evaluates the program using specified variables.
+// The evaluation stops when the context is cancelled or any of the evaluation limits is exceeded.
+// The context is passed to functions that implement IContextFunction interface
+// together with the cache of regular expressions compiled by this machine.
+//	Parameters:
+//		- ctx: The context to control the evaluation.
+//		- vars: The list of variables or nil if expression has no variables.
+//	Returns: An evaluated expression value.
+func (c *VirtualMachine) EvaluateWithContext(ctx context.Context,
+	vars variables.IVariableCollection) (*variants.Variant, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if vars == nil {
+		vars = variables.NewVariableCollection()
+	}
+
+	if len(c.program.functions) > 0 {
+		ctx = functions.ContextWithRegexCache(ctx, c.regexCache)
+		if c.limits.MaxArrayLength > 0 || c.limits.MaxStringLength > 0 {
+			ctx = functions.ContextWithSizeLimits(ctx, c.limits.SizeLimits())
+		}
+	}
+
+	f := c.frames.Get().(*frame)
+	f.ctx = ctx
+	f.done = ctx.Done()
+	f.vars = vars
+	f.step = 0
+
+	result, err := f.safeRun()
+
+	// Frames referenced by lambdas that may escape the evaluation and frames of failed programs are not reused.
+	if f.retained {
+		return result, err
+	}
+	for index := range f.slots {
+		f.slots[index] = nil
+		f.resolved[index] = false
+	}
+	f.stack = f.stack[:0]
+	f.ctx = nil
+	f.done = nil
+	f.vars = nil
+	c.frames.Put(f)
+	return result, err
+}
+
+// safeRun executes the whole program. Unbalanced stack of corrupted programs is reported as error.
+func (c *frame) safeRun() (result *variants.Variant, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			err = errors.NewExpressionError("", "INTERNAL", "Internal error", 0, 0)
+			c.retained = true
+		}
+	}()
+	return c.run(0, len(c.machine.program.instructions))
+}
+
+func (c *frame) newError(code string, message string, index int) error {
+	position := c.machine.program.positions[index]
+	return errors.NewExpressionError("", code, message, position.Line, position.Column)
+}
+
+func (c *frame) push(value *variants.Variant) {
+	c.stack = append(c.stack, value)
+}
+
+func (c *frame) pop() *variants.Variant {
+	value := c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+	return value
+}
+
+// run executes instructions in the range and returns the single value left on the stack.
+func (c *frame) run(start int, end int) (*variants.Variant, error) {
+	machine := c.machine
+	program := machine.program
+	ops := machine.variantOperations
+	limits := machine.limits
+	checkLimits := limits.MaxStackDepth > 0 || limits.MaxArrayLength > 0 || limits.MaxStringLength > 0
+	base := len(c.stack)
+
+	for index := start; index < end; index++ {
+		if limits.MaxSteps > 0 && c.step >= limits.MaxSteps {
+			return nil, c.newError("STEPS_LIMIT_EXCEEDED",
+				"Evaluation exceeded the limit of "+strconv.Itoa(limits.MaxSteps)+" steps", index)
+		}
+		if c.done != nil && c.step%contextCheckInterval == 0 {
+			select {
+			case <-c.done:
+				return nil, c.newError("EVALUATION_CANCELLED",
+					"Evaluation was cancelled: "+c.ctx.Err().Error(), index)
+			default:
+			}
+		}
+		c.step++
+
+		instruction := program.instructions[index]
+		var result *variants.Variant
+		var err error
+
+		switch instruction.OpCode {
+		case OpConstant:
+			c.push(program.constants[instruction.Operand])
+			continue
+		case OpVariable:
+			value, err := c.variable(int(instruction.Operand), index)
+			if err != nil {
+				return nil, err
+			}
+			c.push(value)
+			continue
+		case OpFunction:
+			result, err = c.callFunction(int(instruction.Operand), index)
+		case OpLambda:
+			definition := &program.lambdas[instruction.Operand]
+			c.retained = true
+			c.push(variants.VariantFromObject(&lambda{frame: c, definition: definition, start: index + 1}))
+			index += definition.Length
+			continue
+		case OpJump:
+			index += int(instruction.Operand)
+			continue
+		case OpJumpIfFalse:
+			condition := c.pop()
+			if condition.Type() != variants.Boolean {
+				condition, err = ops.Convert(condition, variants.Boolean)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if !condition.AsBoolean() {
+				index += int(instruction.Operand)
+			}
+			continue
+		case OpShortCircuitAnd:
+			// The result is defined by false first operand.
+			value := c.stack[len(c.stack)-1]
+			if value.Type() == variants.Boolean && !value.AsBoolean() {
+				index += int(instruction.Operand)
+			}
+			continue
+		case OpShortCircuitOr:
+			// The result is defined by true first operand.
+			value := c.stack[len(c.stack)-1]
+			if value.Type() == variants.Boolean && value.AsBoolean() {
+				index += int(instruction.Operand)
+			}
+			continue
+		case OpShortCircuitCoalesce:
+			// The result is defined by not null first operand.
+			if !c.stack[len(c.stack)-1].IsNull() {
+				index += int(instruction.Operand)
+			}
+			continue
+		case OpJumpTable:
+			skip, err := c.jumpTable(int(instruction.Operand), index)
+			if err != nil {
+				return nil, err
+			}
+			index += skip
+			continue
+		case OpDuplicate:
+			c.push(c.stack[len(c.stack)-1])
+			continue
+		case OpPop:
+			c.pop()
+			continue
+		case OpNegative:
+			result, err = ops.Negative(c.pop())
+		case OpNot:
+			result, err = ops.Not(c.pop())
+		case OpIsNull:
+			result = variants.VariantFromBoolean(c.pop().IsNull())
+		case OpIsNotNull:
+			result = variants.VariantFromBoolean(!c.pop().IsNull())
+		case OpCoalesce:
+			value2 := c.pop()
+			result = c.pop()
+			if result.IsNull() {
+				result = value2
+			}
+		case OpMatches, OpNotMatches:
+			value2 := c.pop()
+			value1 := c.pop()
+			result, err = c.matchPattern(value1, value2, index)
+			if err == nil && instruction.OpCode == OpNotMatches && !result.IsNull() {
+				result = variants.VariantFromBoolean(!result.AsBoolean())
+			}
+		default:
+			value2 := c.pop()
+			value1 := c.pop()
+			result, err = c.evaluateBinary(instruction.OpCode, value1, value2)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		c.push(result)
+
+		if checkLimits {
+			err = c.checkResult(result, index)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(c.stack) != base+1 {
+		return nil, errors.NewExpressionError("", "INTERNAL", "Internal error", 0, 0)
+	}
+	return c.pop(), nil
+}
+
+// checkResult checks the stack depth and the size of value produced by the instruction.
+func (c *frame) checkResult(value *variants.Variant, index int) error {
+	limits := c.machine.limits
+	if limits.MaxStackDepth > 0 && len(c.stack) > limits.MaxStackDepth {
+		return c.newError("STACK_LIMIT_EXCEEDED",
+			"Evaluation exceeded the stack depth of "+strconv.Itoa(limits.MaxStackDepth), index)
+	}
+	if limits.MaxArrayLength > 0 && value.Type() == variants.Array && value.Length() > limits.MaxArrayLength {
+		return c.newError("ARRAY_LIMIT_EXCEEDED",
+			"Array length exceeded the limit of "+strconv.Itoa(limits.MaxArrayLength), index)
+	}
+	if limits.MaxStringLength > 0 && value.Type() == variants.String &&
+		utf8.RuneCountInString(value.AsString()) > limits.MaxStringLength {
+		return c.newError("STRING_LIMIT_EXCEEDED",
+			"String length exceeded the limit of "+strconv.Itoa(limits.MaxStringLength), index)
+	}
+	return nil
+}
+
+func (c *frame) variable(slot int, index int) (*variants.Variant, error) {
+	if c.resolved[slot] {
+		return c.slots[slot], nil
+	}
+
+	name := c.machine.program.variables[slot]
+	variable := c.vars.FindByName(name)
+	if variable == nil {
+		return nil, c.newError("VAR_NOT_FOUND", "Variable "+name+" was not found.", index)
+	}
+	c.slots[slot] = variable.Value()
+	c.resolved[slot] = true
+	return c.slots[slot], nil
+}
+
+func (c *frame) callFunction(slot int, index int) (*variants.Variant, error) {
+	function := c.machine.functions[slot]
+	if function == nil {
+		return nil, c.newError("FUNC_NOT_FOUND",
+			"Function "+c.machine.program.functions[slot].Name+" was not found.", index)
+	}
+
+	count := c.machine.program.functions[slot].ParameterCount
+	parameters := make([]*variants.Variant, count)
+	copy(parameters, c.stack[len(c.stack)-count:])
+	c.stack = c.stack[:len(c.stack)-count]
+
+	var result *variants.Variant
+	var err error
+	if contextFunction, ok := function.(functions.IContextFunction); ok {
+		result, err = contextFunction.CalculateWithContext(c.ctx, parameters, c.machine.variantOperations)
+	} else {
+		result, err = function.Calculate(parameters, c.machine.variantOperations)
+	}
+	if err != nil {
+		position := c.machine.program.positions[index]
+		return nil, errors.ErrorWithPosition(err, position.Line, position.Column)
+	}
+	return result, nil
+}
+
+// matchPattern checks if the value contains a match of the regular expression pattern.
+// The result is null when any of the values is null.
+func (c *frame) matchPattern(value *variants.Variant, pattern *variants.Variant, index int) (*variants.Variant, error) {
+	if value.IsNull() || pattern.IsNull() {
+		return variants.EmptyVariant(), nil
+	}
+	ops := c.machine.variantOperations
+	value, err := ops.Convert(value, variants.String)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err = ops.Convert(pattern, variants.String)
+	if err != nil {
+		return nil, err
+	}
+
+	regex, err := c.machine.regexCache.Compile(pattern.AsString())
+	if err != nil {
+		position := c.machine.program.positions[index]
+		return nil, errors.ErrorWithPosition(err, position.Line, position.Column)
+	}
+	return variants.VariantFromBoolean(regex.MatchString(value.AsString())), nil
+}
+
+func (c *frame) jumpTable(table int, index int) (int, error) {
+	value := c.pop()
+	condition, err := c.machine.variantOperations.Convert(value, variants.Integer)
+	if err != nil {
+		return 0, err
+	}
+
+	branches := c.machine.program.jumpTables[table]
+	branchIndex := condition.AsInteger()
+	if branchIndex < 0 {
+		return 0, c.newError("INDEX_OUT_OF_RANGE",
+			"Choice index "+strconv.Itoa(branchIndex)+" is out of range", index)
+	}
+	if branchIndex > len(branches) {
+		return 0, c.newError("WRONG_PARAM_COUNT",
+			"Expected at least "+strconv.Itoa(branchIndex+1)+" parameters", index)
+	}
+
+	skip := 0
+	if branchIndex == 0 {
+		// Zero index selects the index value itself.
+		c.push(value)
+		branchIndex = len(branches) + 1
+	}
+	for i := 0; i < branchIndex-1; i++ {
+		skip += branches[i]
+	}
+	return skip, nil
+}
+
+func (c *frame) evaluateBinary(opCode OpCode, value1 *variants.Variant, value2 *variants.Variant) (*variants.Variant, error) {
+	if c.machine.fastOperations && value1.Type() == value2.Type() {
+		if result, ok := evaluateFast(opCode, value1, value2); ok {
+			return result, nil
+		}
+	}
+
+	ops := c.machine.variantOperations
+	switch opCode {
+	case OpAdd:
+		return ops.Add(value1, value2)
+	case OpSub:
+		return ops.Sub(value1, value2)
+	case OpMul:
+		return ops.Mul(value1, value2)
+	case OpDiv:
+		return ops.Div(value1, value2)
+	case OpMod:
+		return ops.Mod(value1, value2)
+	case OpPow:
+		return ops.Pow(value1, value2)
+	case OpShiftLeft:
+		return ops.Lsh(value1, value2)
+	case OpShiftRight:
+		return ops.Rsh(value1, value2)
+	case OpAnd:
+		return ops.And(value1, value2)
+	case OpOr:
+		return ops.Or(value1, value2)
+	case OpXor:
+		return ops.Xor(value1, value2)
+	case OpEqual:
+		return ops.Equal(value1, value2)
+	case OpNotEqual:
+		return ops.NotEqual(value1, value2)
+	case OpMore:
+		return ops.More(value1, value2)
+	case OpLess:
+		return ops.Less(value1, value2)
+	case OpMoreEqual:
+		return ops.MoreEqual(value1, value2)
+	case OpLessEqual:
+		return ops.LessEqual(value1, value2)
+	case OpIn:
+		return ops.In(value2, value1)
+	case OpNotIn:
+		result, err := ops.In(value2, value1)
+		if err != nil {
+			return nil, err
+		}
+		return variants.VariantFromBoolean(!result.AsBoolean()), nil
+	case OpLike:
+		return ops.Like(value1, value2)
+	case OpNotLike:
+		result, err := ops.Like(value1, value2)
+		if err != nil {
+			return nil, err
+		}
+		if !result.IsNull() {
+			result = variants.VariantFromBoolean(!result.AsBoolean())
+		}
+		return result, nil
+	case OpElement:
+		return ops.GetElement(value1, value2)
+	case OpMember:
+		return ops.GetMember(value1, value2)
+	}
+	return nil, errors.NewExpressionError("", "INTERNAL", "Internal error", 0, 0)
+}
+
+// evaluateFast performs comparisons and simple arithmetic of values of the same type
+// in the same way as standard variant operations, but without conversions.
+func evaluateFast(opCode OpCode, value1 *variants.Variant, value2 *variants.Variant) (*variants.Variant, bool) {
+	switch value1.Type() {
+	case variants.Integer:
+		a, b := value1.AsInteger(), value2.AsInteger()
+		switch opCode {
+		case OpAdd, OpSub, OpMul:
+			// Overflows are left to variant operations that process them according to the overflow mode.
+			result, ok := arithmeticFast(opCode, a, b)
+			if !ok {
+				return nil, false
+			}
+			return variants.VariantFromInteger(result), true
+		}
+		return compareFast(opCode, a, b)
+	case variants.Long:
+		a, b := value1.AsLong(), value2.AsLong()
+		switch opCode {
+		case OpAdd, OpSub, OpMul:
+			result, ok := arithmeticFast(opCode, a, b)
+			if !ok {
+				return nil, false
+			}
+			return variants.VariantFromLong(result), true
+		}
+		return compareFast(opCode, a, b)
+	case variants.Double:
+		a, b := value1.AsDouble(), value2.AsDouble()
+		switch opCode {
+		case OpAdd:
+			return variants.VariantFromDouble(a + b), true
+		case OpSub:
+			return variants.VariantFromDouble(a - b), true
+		case OpMul:
+			return variants.VariantFromDouble(a * b), true
+		}
+		return compareFast(opCode, a, b)
+	case variants.String:
+		return compareFast(opCode, value1.AsString(), value2.AsString())
+	case variants.Boolean:
+		a, b := value1.AsBoolean(), value2.AsBoolean()
+		switch opCode {
+		case OpAnd:
+			return variants.VariantFromBoolean(a && b), true
+		case OpOr:
+			return variants.VariantFromBoolean(a || b), true
+		case OpEqual:
+			return variants.VariantFromBoolean(a == b), true
+		case OpNotEqual:
+			return variants.VariantFromBoolean(a != b), true
+		}
+	}
+	return nil, false
+}
+
+// arithmeticFast adds, subtracts or multiplies integers.
+// It returns false when the result overflows.
+func arithmeticFast[T int | int64](opCode OpCode, a T, b T) (T, bool) {
+	switch opCode {
+	case OpAdd:
+		result := a + b
+		return result, (result > a) == (b > 0)
+	case OpSub:
+		result := a - b
+		return result, (result < a) == (b > 0)
+	case OpMul:
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		result := a * b
+		return result, result/a == b && !(a == -1 && result == b)
+	}
+	return 0, false
+}
+
+func compareFast[T int | int64 | float64 | string](opCode OpCode, a T, b T) (*variants.Variant, bool) {
+	switch opCode {
+	case OpEqual:
+		return variants.VariantFromBoolean(a == b), true
+	case OpNotEqual:
+		return variants.VariantFromBoolean(a != b), true
+	case OpMore:
+		return variants.VariantFromBoolean(a > b), true
+	case OpLess:
+		return variants.VariantFromBoolean(a < b), true
+	case OpMoreEqual:
+		return variants.VariantFromBoolean(a >= b), true
+	case OpLessEqual:
+		return variants.VariantFromBoolean(a <= b), true
+	}
+	return nil, false
+}
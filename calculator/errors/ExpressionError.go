@@ -7,9 +7,12 @@ import (
 )
 
 // NewExpressionError exception that can be thrown by Expression Calculator.
+// The line and column are also kept in "line" and "column" error details.
 func NewExpressionError(correlationId, code, message string, line, column int) *cerrors.ApplicationError {
+	var details map[string]any
 	if line != 0 || column != 0 {
 		message = message + " at line " + strconv.Itoa(line) + " and column " + strconv.Itoa(column)
+		details = map[string]any{"line": line, "column": column}
 	}
 	return &cerrors.ApplicationError{
 		Category:      cerrors.BadRequest,
@@ -17,5 +20,47 @@ func NewExpressionError(correlationId, code, message string, line, column int) *
 		Code:          code,
 		Message:       message,
 		Status:        400,
+		Details:       details,
 	}
 }
+
+// ErrorPosition gets the position of error created by NewExpressionError or NewSyntaxError.
+//	Parameters:
+//		- err: The error.
+//	Returns: The line, the column and <code>true</code> if the error has a position.
+func ErrorPosition(err error) (int, int, bool) {
+	appErr, ok := err.(*cerrors.ApplicationError)
+	if !ok || appErr.Details == nil {
+		return 0, 0, false
+	}
+	line, ok1 := appErr.Details["line"].(int)
+	column, ok2 := appErr.Details["column"].(int)
+	return line, column, ok1 && ok2
+}
+
+// ErrorWithPosition adds the position to the error that has no position,
+// like errors returned by functions. Errors with positions and errors
+// of other types are returned without changes.
+//	Parameters:
+//		- err: The error.
+//		- line: The line number where the error happened.
+//		- column: The column number where the error happened.
+//	Returns: The error with position.
+func ErrorWithPosition(err error, line int, column int) error {
+	appErr, ok := err.(*cerrors.ApplicationError)
+	if !ok || (line == 0 && column == 0) {
+		return err
+	}
+	if _, _, ok = ErrorPosition(err); ok {
+		return err
+	}
+	result := NewExpressionError(appErr.CorrelationId, appErr.Code, appErr.Message, line, column)
+	result.Category = appErr.Category
+	result.Status = appErr.Status
+	result.Cause = appErr.Cause
+	result.StackTrace = appErr.StackTrace
+	for key, value := range appErr.Details {
+		result.Details[key] = value
+	}
+	return result
+}
@@ -6,9 +6,12 @@ import (
 )
 
 // NewSyntaxError exception that can be thrown by Expression Parser.
+// The line and column are also kept in "line" and "column" error details.
 func NewSyntaxError(correlationId, code, message string, line, column int) *cerrors.ApplicationError {
+	var details map[string]any
 	if line != 0 || column != 0 {
 		message = message + " at line " + strconv.Itoa(line) + " and column " + strconv.Itoa(column)
+		details = map[string]any{"line": line, "column": column}
 	}
 	return &cerrors.ApplicationError{
 		Category:      cerrors.BadRequest,
@@ -16,5 +19,6 @@ func NewSyntaxError(correlationId, code, message string, line, column int) *cerr
 		Code:          code,
 		Message:       message,
 		Status:        400,
+		Details:       details,
 	}
 }
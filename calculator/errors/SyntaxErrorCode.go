@@ -25,4 +25,55 @@ const (
 
 	// ErrMissedCloseSquareBracket the missed close square bracket
 	ErrMissedCloseSquareBracket = "MISSED_CLOSE_SQUARE_BRACKET"
+
+	// ErrMissedMemberName the missed member name after dot
+	ErrMissedMemberName = "MISSED_MEMBER_NAME"
+
+	// ErrMissedColon the missed colon in conditional operator
+	ErrMissedColon = "MISSED_COLON"
+
+	// ErrMissedWhen the missed WHEN in CASE expression
+	ErrMissedWhen = "MISSED_WHEN"
+
+	// ErrMissedThen the missed THEN in CASE expression or IF statement
+	ErrMissedThen = "MISSED_THEN"
+
+	// ErrMissedEnd the missed END in CASE expression or script block
+	ErrMissedEnd = "MISSED_END"
+
+	// ErrMissedDo the missed DO in WHILE or FOR EACH statement
+	ErrMissedDo = "MISSED_DO"
+
+	// ErrMissedIn the missed IN in FOR EACH statement
+	ErrMissedIn = "MISSED_IN"
+
+	// ErrMissedVariableName the missed variable name in FOR EACH statement
+	ErrMissedVariableName = "MISSED_VARIABLE_NAME"
+
+	// ErrMissedExpression the missed expression in script statement
+	ErrMissedExpression = "MISSED_EXPRESSION"
+
+	// ErrMissedFunctionName the missed name in function definition
+	ErrMissedFunctionName = "MISSED_FUNCTION_NAME"
+
+	// ErrMissedEqual the missed '=' in function definition
+	ErrMissedEqual = "MISSED_EQUAL"
+
+	// ErrUnknownParameter the variable in function body that is not a function parameter
+	ErrUnknownParameter = "UNKNOWN_PARAMETER"
+
+	// ErrInvalidPattern the invalid regular expression pattern
+	ErrInvalidPattern = "INVALID_PATTERN"
+
+	// ErrUnknownVariable the variable that is not registered
+	ErrUnknownVariable = "UNKNOWN_VARIABLE"
+
+	// ErrUnknownFunction the function that is not registered
+	ErrUnknownFunction = "UNKNOWN_FUNCTION"
+
+	// ErrWrongParamCount the wrong number of function parameters
+	ErrWrongParamCount = "WRONG_PARAM_COUNT"
+
+	// ErrTypeMismatch the value that cannot be used in the operation or function because of its type
+	ErrTypeMismatch = "TYPE_MISMATCH"
 )
@@ -1,11 +1,17 @@
 package functions
 
 import (
+	"context"
 	"math"
+	"math/big"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	_ "time/tzdata"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
@@ -14,69 +20,180 @@ import (
 // DefaultFunctionCollection implements a list filled with standard functions.
 type DefaultFunctionCollection struct {
 	*FunctionCollection
+	nullHandling NullHandling
 }
 
 // NewDefaultFunctionCollection constructs this list and fills it with the standard functions.
+// All functions except Ticks, Now, Rnd and Random are deterministic.
+// All functions have signatures, so numbers of their parameters are checked when they are called
+// and their calls can be validated before evaluation.
 func NewDefaultFunctionCollection() *DefaultFunctionCollection {
 	c := &DefaultFunctionCollection{
 		FunctionCollection: NewFunctionCollection(),
 	}
 
 	c.Add(NewDelegatedFunction("Ticks", ticksFunctionCalculator))
-	c.Add(NewDelegatedFunction("TimeSpan", timeSpanFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("TimeSpan", timeSpanFunctionCalculator))
 	c.Add(NewDelegatedFunction("Now", nowFunctionCalculator))
-	c.Add(NewDelegatedFunction("Date", dateFunctionCalculator))
-	c.Add(NewDelegatedFunction("DayOfWeek", dayOfWeekFunctionCalculator))
-	c.Add(NewDelegatedFunction("Min", minFunctionCalculator))
-	c.Add(NewDelegatedFunction("Max", maxFunctionCalculator))
-	c.Add(NewDelegatedFunction("Sum", sumFunctionCalculator))
-	c.Add(NewDelegatedFunction("If", ifFunctionCalculator))
-	c.Add(NewDelegatedFunction("Choose", chooseFunctionCalculator))
-	c.Add(NewDelegatedFunction("E", eFunctionCalculator))
-	c.Add(NewDelegatedFunction("Pi", piFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Date", dateFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("DayOfWeek", dayOfWeekFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Year", datePartFunctionCalculator(time.Time.Year)))
+	c.Add(NewDeterministicDelegatedFunction("Month", datePartFunctionCalculator(
+		func(date time.Time) int { return int(date.Month()) })))
+	c.Add(NewDeterministicDelegatedFunction("Day", datePartFunctionCalculator(time.Time.Day)))
+	c.Add(NewDeterministicDelegatedFunction("Hour", datePartFunctionCalculator(time.Time.Hour)))
+	c.Add(NewDeterministicDelegatedFunction("Minute", datePartFunctionCalculator(time.Time.Minute)))
+	c.Add(NewDeterministicDelegatedFunction("Second", datePartFunctionCalculator(time.Time.Second)))
+	c.Add(NewDeterministicDelegatedFunction("AddDays", addDateFunctionCalculator(
+		func(date time.Time, value int) time.Time { return date.AddDate(0, 0, value) })))
+	c.Add(NewDeterministicDelegatedFunction("AddMonths", addDateFunctionCalculator(addMonths)))
+	c.Add(NewDeterministicDelegatedFunction("AddYears", addDateFunctionCalculator(
+		func(date time.Time, value int) time.Time { return addMonths(date, value*12) })))
+	c.Add(NewDeterministicDelegatedFunction("DateDiff", dateDiffFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("StartOfWeek", startOfWeekFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("StartOfMonth", startOfMonthFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("FormatDate", formatDateFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("ParseDate", parseDateFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("ToTimeZone", toTimeZoneFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Min", c.minFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Max", c.maxFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Sum", c.sumFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Product", c.productFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Count", c.countFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("CountIf", c.countIfFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Avg", c.avgFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Median", c.medianFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Mode", c.modeFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Variance", c.varianceFunctionCalculator(false)))
+	c.Add(NewDeterministicDelegatedFunction("StdDev", c.varianceFunctionCalculator(true)))
+	c.Add(NewDeterministicDelegatedFunction("Percentile", c.percentileFunctionCalculator))
+	c.Add(NewLazyDelegatedFunction("If", LazyCondition, ifFunctionCalculator))
+	c.Add(NewLazyDelegatedFunction("Choose", LazyChoice, chooseFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("E", eFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Pi", piFunctionCalculator))
 	c.Add(NewDelegatedFunction("Rnd", rndFunctionCalculator))
 	c.Add(NewDelegatedFunction("Random", rndFunctionCalculator))
-	c.Add(NewDelegatedFunction("Abs", absFunctionCalculator))
-	c.Add(NewDelegatedFunction("Acos", acosFunctionCalculator))
-	c.Add(NewDelegatedFunction("Asin", asinFunctionCalculator))
-	c.Add(NewDelegatedFunction("Atan", atanFunctionCalculator))
-	c.Add(NewDelegatedFunction("Exp", expFunctionCalculator))
-	c.Add(NewDelegatedFunction("Log", logFunctionCalculator))
-	c.Add(NewDelegatedFunction("Ln", logFunctionCalculator))
-	c.Add(NewDelegatedFunction("Log10", log10FunctionCalculator))
-	c.Add(NewDelegatedFunction("Ceil", ceilFunctionCalculator))
-	c.Add(NewDelegatedFunction("Ceiling", ceilFunctionCalculator))
-	c.Add(NewDelegatedFunction("Floor", floorFunctionCalculator))
-	c.Add(NewDelegatedFunction("Round", roundFunctionCalculator))
-	c.Add(NewDelegatedFunction("Trunc", truncFunctionCalculator))
-	c.Add(NewDelegatedFunction("Truncate", truncFunctionCalculator))
-	c.Add(NewDelegatedFunction("Cos", cosFunctionCalculator))
-	c.Add(NewDelegatedFunction("Sin", sinFunctionCalculator))
-	c.Add(NewDelegatedFunction("Tan", tanFunctionCalculator))
-	c.Add(NewDelegatedFunction("Sqr", sqrtFunctionCalculator))
-	c.Add(NewDelegatedFunction("Sqrt", sqrtFunctionCalculator))
-	c.Add(NewDelegatedFunction("Empty", emptyFunctionCalculator))
-	c.Add(NewDelegatedFunction("Null", nullFunctionCalculator))
-	c.Add(NewDelegatedFunction("Contains", containsFunctionCalculator))
-	c.Add(NewDelegatedFunction("Array", arrayFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Abs", absFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Factorial", factorialFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Acos", acosFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Asin", asinFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Atan", atanFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Exp", expFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Log", logFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Ln", logFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Log10", log10FunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Ceil", ceilFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Ceiling", ceilFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Floor", floorFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Round", roundFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Trunc", truncFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Truncate", truncFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Cos", cosFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Sin", sinFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Tan", tanFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Sqr", sqrtFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Sqrt", sqrtFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Empty", emptyFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Null", nullFunctionCalculator))
+	c.Add(NewLazyDelegatedFunction("Coalesce", LazyCoalesce, coalesceFunctionCalculator))
+	c.Add(NewLazyDelegatedFunction("IfNull", LazyCoalesce, ifNullFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("NullIf", nullIfFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Contains", containsFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Upper", upperFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Lower", lowerFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Len", lenFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Substring", substringFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Left", leftFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Right", rightFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Trim", trimFunctionCalculator(strings.TrimFunc)))
+	c.Add(NewDeterministicDelegatedFunction("LTrim", trimFunctionCalculator(strings.TrimLeftFunc)))
+	c.Add(NewDeterministicDelegatedFunction("RTrim", trimFunctionCalculator(strings.TrimRightFunc)))
+	c.Add(NewDeterministicDelegatedContextFunction("Replace", replaceFunctionCalculator))
+	c.Add(NewDeterministicDelegatedContextFunction("Split", splitFunctionCalculator))
+	c.Add(NewDeterministicDelegatedContextFunction("Join", joinFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("IndexOf", indexOfFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("StartsWith", startsWithFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("EndsWith", endsWithFunctionCalculator))
+	c.Add(NewDeterministicDelegatedContextFunction("PadLeft", padFunctionCalculator(true)))
+	c.Add(NewDeterministicDelegatedContextFunction("PadRight", padFunctionCalculator(false)))
+	c.Add(NewDeterministicDelegatedContextFunction("Repeat", repeatFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Reverse", reverseFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Format", formatFunctionCalculator))
+	c.Add(NewDeterministicDelegatedContextFunction("RegexMatch", regexMatchFunctionCalculator))
+	c.Add(NewDeterministicDelegatedContextFunction("RegexReplace", regexReplaceFunctionCalculator))
+	c.Add(NewDeterministicDelegatedContextFunction("RegexExtract", regexExtractFunctionCalculator))
+	c.Add(NewDeterministicDelegatedContextFunction("RegexSplit", regexSplitFunctionCalculator))
+	c.Add(NewDeterministicDelegatedContextFunction("Array", arrayFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Map", mapFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Filter", filterFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Reduce", reduceFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Any", anyFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("All", allFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("Find", findFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("SortBy", sortByFunctionCalculator))
+	c.Add(NewDeterministicDelegatedFunction("GroupBy", groupByFunctionCalculator))
+
+	signatures := newDefaultFunctionSignatures()
+	for _, function := range c.GetAll() {
+		if delegated, ok := function.(*DelegatedFunction); ok {
+			delegated.SetSignature(signatures[function.Name()])
+		}
+	}
 
 	return c
 }
 
+// NullHandling gets the way aggregate functions treat null values. By default nulls are skipped.
+func (c *DefaultFunctionCollection) NullHandling() NullHandling {
+	return c.nullHandling
+}
+
+// SetNullHandling sets the way aggregate functions treat null values.
+//	Parameters:
+//		- value: SkipNulls to ignore null values or RejectNulls to fail on them.
+func (c *DefaultFunctionCollection) SetNullHandling(value NullHandling) {
+	c.nullHandling = value
+}
+
 // checkParamCount checks if parameters contains the correct number of function parameters
 // (must be stored on the top of the parameters).
 //	Parameters:
 //		- parameters: A list with function parameters.
 //		- expectedParamCount: The expected number of function parameters.
 func checkParamCount(parameters []*variants.Variant, expectedParamCount int) error {
-	paramCount := len(parameters)
-	if expectedParamCount != paramCount {
-		err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
-			"Expected "+strconv.Itoa(expectedParamCount)+
-				" parameters but was found "+strconv.Itoa(paramCount), 0, 0)
-		return err
+	return checkParamCountInRange(len(parameters), expectedParamCount, expectedParamCount)
+}
+
+// getLambdaParameter gets function parameter with lambda expression by it's index.
+//	Parameters:
+//		- parameters: A list with function parameters.
+//		- paramIndex: Index for the function parameter (0 for the first parameter).
+//	Returns: Lambda expression or error if the parameter is not a lambda.
+func getLambdaParameter(parameters []*variants.Variant, paramIndex int) (ILambda, error) {
+	value := getParameter(parameters, paramIndex)
+	if value.Type() == variants.Object {
+		if lambda, ok := value.AsObject().(ILambda); ok {
+			return lambda, nil
+		}
+	}
+	err := errors.NewExpressionError("", "WRONG_PARAM_TYPE",
+		"Expected lambda expression in parameter "+strconv.Itoa(paramIndex+1), 0, 0)
+	return nil, err
+}
+
+// getArrayParameter gets function parameter converted to array by it's index.
+//	Parameters:
+//		- parameters: A list with function parameters.
+//		- paramIndex: Index for the function parameter (0 for the first parameter).
+//		- variantOperations: Variants operations manager.
+//	Returns: Array parameter or null variant if the parameter is null.
+func getArrayParameter(parameters []*variants.Variant, paramIndex int,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	value := getParameter(parameters, paramIndex)
+	if value.IsNull() {
+		return value, nil
 	}
-	return nil
+	return variantOperations.Convert(value, variants.Array)
 }
 
 // getParameter gets function parameter by it's index.
@@ -264,76 +381,6 @@ func dayOfWeekFunctionCalculator(parameters []*variants.Variant,
 	return result, nil
 }
 
-func minFunctionCalculator(parameters []*variants.Variant,
-	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
-	paramCount := len(parameters)
-	if paramCount < 2 {
-		err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
-			"Expected at least 2 parameters", 0, 0)
-		return nil, err
-	}
-
-	result := getParameter(parameters, 0)
-	for i := 1; i < paramCount; i = i + 1 {
-		value := getParameter(parameters, i)
-		temp, err := variantOperations.More(result, value)
-		if err != nil {
-			return nil, err
-		}
-		if temp.AsBoolean() {
-			result = value
-		}
-	}
-
-	return result, nil
-}
-
-func maxFunctionCalculator(parameters []*variants.Variant,
-	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
-	paramCount := len(parameters)
-	if paramCount < 2 {
-		err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
-			"Expected at least 2 parameters", 0, 0)
-		return nil, err
-	}
-
-	result := getParameter(parameters, 0)
-	for i := 1; i < paramCount; i++ {
-		value := getParameter(parameters, i)
-		temp, err := variantOperations.Less(result, value)
-		if err != nil {
-			return nil, err
-		}
-		if temp.AsBoolean() {
-			result = value
-		}
-	}
-
-	return result, nil
-}
-
-func sumFunctionCalculator(parameters []*variants.Variant,
-	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
-	paramCount := len(parameters)
-	if paramCount < 2 {
-		err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
-			"Expected at least 2 parameters", 0, 0)
-		return nil, err
-	}
-
-	result := getParameter(parameters, 0)
-	for i := 1; i < paramCount; i++ {
-		value := getParameter(parameters, i)
-		var err error
-		result, err = variantOperations.Add(result, value)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	return result, nil
-}
-
 func ifFunctionCalculator(parameters []*variants.Variant,
 	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
 	err := checkParamCount(parameters, 3)
@@ -443,6 +490,12 @@ func absFunctionCalculator(parameters []*variants.Variant,
 	case variants.Double:
 		result.SetAsDouble(math.Abs(value.AsDouble()))
 		break
+	case variants.Decimal:
+		result.SetAsDecimal(value.AsDecimal().Abs())
+		break
+	case variants.BigInteger:
+		result.SetAsBigInteger(new(big.Int).Abs(value.AsBigInteger()))
+		break
 	default:
 		value, err = variantOperations.Convert(value, variants.Double)
 		if err != nil {
@@ -455,6 +508,33 @@ func absFunctionCalculator(parameters []*variants.Variant,
 	return result, nil
 }
 
+// maxFactorialArgument limits arguments of Factorial function to keep results reasonably small.
+const maxFactorialArgument = 10000
+
+func factorialFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCount(parameters, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := variantOperations.Convert(getParameter(parameters, 0), variants.Long)
+	if err != nil {
+		return nil, err
+	}
+	number := value.AsLong()
+	if number < 0 || number > maxFactorialArgument {
+		return nil, errors.NewExpressionError("", "WRONG_PARAM_VALUE",
+			"Factorial argument must be between 0 and "+strconv.Itoa(maxFactorialArgument), 0, 0)
+	}
+
+	result := new(big.Int).MulRange(1, number)
+	if result.IsInt64() {
+		return variants.VariantFromLong(result.Int64()), nil
+	}
+	return variants.VariantFromBigInteger(result), nil
+}
+
 func acosFunctionCalculator(parameters []*variants.Variant,
 	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
 	err := checkParamCount(parameters, 1)
@@ -558,6 +638,10 @@ func ceilFunctionCalculator(parameters []*variants.Variant,
 		return nil, err
 	}
 
+	if value := getParameter(parameters, 0); value.Type() == variants.Decimal {
+		return variants.VariantFromDecimal(value.AsDecimal().Round(0, variants.RoundCeiling)), nil
+	}
+
 	value, err1 := variantOperations.Convert(getParameter(parameters, 0), variants.Double)
 	if err1 != nil {
 		return nil, err1
@@ -574,6 +658,10 @@ func floorFunctionCalculator(parameters []*variants.Variant,
 		return nil, err
 	}
 
+	if value := getParameter(parameters, 0); value.Type() == variants.Decimal {
+		return variants.VariantFromDecimal(value.AsDecimal().Round(0, variants.RoundFloor)), nil
+	}
+
 	value, err1 := variantOperations.Convert(getParameter(parameters, 0), variants.Double)
 	if err1 != nil {
 		return nil, err1
@@ -583,20 +671,86 @@ func floorFunctionCalculator(parameters []*variants.Variant,
 	return result, nil
 }
 
+// roundFunctionCalculator rounds a number to the optional number of fractional digits
+// with the optional rounding mode, by default variants.DefaultRoundingMode that is also used
+// for decimal division. Decimals are rounded exactly and stay decimals.
 func roundFunctionCalculator(parameters []*variants.Variant,
 	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
-	err := checkParamCount(parameters, 1)
+	err := checkParamCountRange(parameters, 1, 3)
 	if err != nil {
 		return nil, err
 	}
 
-	value, err1 := variantOperations.Convert(getParameter(parameters, 0), variants.Double)
-	if err1 != nil {
-		return nil, err1
+	value := getParameter(parameters, 0)
+	if len(parameters) == 1 && value.Type() != variants.Decimal {
+		value, err = variantOperations.Convert(value, variants.Double)
+		if err != nil {
+			return nil, err
+		}
+		return variants.VariantFromDouble(math.Round(value.AsDouble())), nil
 	}
-	result := variants.VariantFromDouble(math.Round(value.AsDouble()))
 
-	return result, nil
+	digits := 0
+	if len(parameters) > 1 {
+		temp, err := variantOperations.Convert(getParameter(parameters, 1), variants.Integer)
+		if err != nil {
+			return nil, err
+		}
+		digits = temp.AsInteger()
+	}
+	mode := variants.DefaultRoundingMode
+	if len(parameters) > 2 {
+		temp, err := variantOperations.Convert(getParameter(parameters, 2), variants.String)
+		if err != nil {
+			return nil, err
+		}
+		mode, err = parseRoundingMode(temp.AsString())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if value.Type() == variants.Decimal {
+		return variants.VariantFromDecimal(value.AsDecimal().Round(digits, mode)), nil
+	}
+
+	// Numbers are rounded as decimals, so 2.675 is rounded to 2.68 and not to 2.67.
+	bitSize := 64
+	if value.Type() == variants.Float {
+		bitSize = 32
+	}
+	value, err = variantOperations.Convert(value, variants.Double)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(value.AsDouble()) || math.IsInf(value.AsDouble(), 0) {
+		return value, nil
+	}
+	decimal, err := variants.DecimalFromFloat64(value.AsDouble(), bitSize)
+	if err != nil {
+		return nil, err
+	}
+	return variants.VariantFromDouble(decimal.Round(digits, mode).Float64()), nil
+}
+
+// parseRoundingMode parses names of rounding modes like 'HalfUp', 'HALF_EVEN' or 'Floor'.
+func parseRoundingMode(name string) (variants.RoundingMode, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "_", "")) {
+	case "halfup":
+		return variants.RoundHalfUp, nil
+	case "halfeven", "bankers":
+		return variants.RoundHalfEven, nil
+	case "down":
+		return variants.RoundDown, nil
+	case "up":
+		return variants.RoundUp, nil
+	case "ceiling":
+		return variants.RoundCeiling, nil
+	case "floor":
+		return variants.RoundFloor, nil
+	}
+	err := errors.NewExpressionError("", "WRONG_PARAM_VALUE", "Unknown rounding mode "+name, 0, 0)
+	return variants.RoundHalfUp, err
 }
 
 func truncFunctionCalculator(parameters []*variants.Variant,
@@ -606,6 +760,10 @@ func truncFunctionCalculator(parameters []*variants.Variant,
 		return nil, err
 	}
 
+	if value := getParameter(parameters, 0); value.Type() == variants.Decimal {
+		return variants.VariantFromDecimal(value.AsDecimal().Round(0, variants.RoundDown)), nil
+	}
+
 	value, err1 := variantOperations.Convert(getParameter(parameters, 0), variants.Double)
 	if err1 != nil {
 		return nil, err1
@@ -704,6 +862,52 @@ func nullFunctionCalculator(parameters []*variants.Variant,
 	return result, nil
 }
 
+func coalesceFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCountRange(parameters, 1, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, parameter := range parameters {
+		if !parameter.IsNull() {
+			return parameter, nil
+		}
+	}
+	return variants.EmptyVariant(), nil
+}
+
+func ifNullFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCount(parameters, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return coalesceFunctionCalculator(parameters, variantOperations)
+}
+
+func nullIfFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCount(parameters, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	value := getParameter(parameters, 0)
+	if value.IsNull() || getParameter(parameters, 1).IsNull() {
+		return value, nil
+	}
+	equal, err := variantOperations.Equal(value, getParameter(parameters, 1))
+	if err != nil {
+		return nil, err
+	}
+	if equal.AsBoolean() {
+		return variants.EmptyVariant(), nil
+	}
+	return value, nil
+}
+
 func containsFunctionCalculator(parameters []*variants.Variant,
 	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
 	err := checkParamCount(parameters, 2)
@@ -730,8 +934,1378 @@ func containsFunctionCalculator(parameters []*variants.Variant,
 	return result, nil
 }
 
-func arrayFunctionCalculator(parameters []*variants.Variant,
+func arrayFunctionCalculator(ctx context.Context, parameters []*variants.Variant,
 	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := SizeLimitsFromContext(ctx).CheckArrayLength(len(parameters))
+	if err != nil {
+		return nil, err
+	}
 	result := variants.VariantFromArray(parameters)
 	return result, nil
 }
+
+// iterateArrayWithLambda invokes the lambda for each array element with element and its index.
+// The iteration stops when the callback returns false.
+func iterateArrayWithLambda(parameters []*variants.Variant, variantOperations variants.IVariantOperations,
+	callback func(element *variants.Variant, result *variants.Variant) bool) (*variants.Variant, error) {
+	err := checkParamCount(parameters, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	array, err := getArrayParameter(parameters, 0, variantOperations)
+	if err != nil {
+		return nil, err
+	}
+	lambda, err := getLambdaParameter(parameters, 1)
+	if err != nil {
+		return nil, err
+	}
+	if array.IsNull() {
+		return array, nil
+	}
+
+	for index, element := range array.AsArray() {
+		result, err := lambda.Invoke([]*variants.Variant{element, variants.VariantFromInteger(index)})
+		if err != nil {
+			return nil, err
+		}
+		if !callback(element, result) {
+			break
+		}
+	}
+
+	return array, nil
+}
+
+// toBoolean converts a lambda result into boolean value. Null values are false.
+func toBoolean(value *variants.Variant, variantOperations variants.IVariantOperations) (bool, error) {
+	if value.IsNull() {
+		return false, nil
+	}
+	result, err := variantOperations.Convert(value, variants.Boolean)
+	if err != nil {
+		return false, err
+	}
+	return result.AsBoolean(), nil
+}
+
+func mapFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	elements := []*variants.Variant{}
+	array, err := iterateArrayWithLambda(parameters, variantOperations,
+		func(element *variants.Variant, result *variants.Variant) bool {
+			elements = append(elements, result)
+			return true
+		})
+	if err != nil || array.IsNull() {
+		return array, err
+	}
+	return variants.VariantFromArray(elements), nil
+}
+
+func filterFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	elements := []*variants.Variant{}
+	var conversionErr error
+	array, err := iterateArrayWithLambda(parameters, variantOperations,
+		func(element *variants.Variant, result *variants.Variant) bool {
+			matched, err := toBoolean(result, variantOperations)
+			if err != nil {
+				conversionErr = err
+				return false
+			}
+			if matched {
+				elements = append(elements, element)
+			}
+			return true
+		})
+	if err == nil {
+		err = conversionErr
+	}
+	if err != nil || array.IsNull() {
+		return array, err
+	}
+	return variants.VariantFromArray(elements), nil
+}
+
+func reduceFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	paramCount := len(parameters)
+	if paramCount != 2 && paramCount != 3 {
+		err := errors.NewExpressionError("", "WRONG_PARAM_COUNT", "Expected 2 or 3 parameters", 0, 0)
+		return nil, err
+	}
+
+	array, err := getArrayParameter(parameters, 0, variantOperations)
+	if err != nil {
+		return nil, err
+	}
+	lambda, err := getLambdaParameter(parameters, 1)
+	if err != nil {
+		return nil, err
+	}
+	if array.IsNull() {
+		return array, nil
+	}
+
+	// Without initial value the first element is used as the accumulator.
+	elements := array.AsArray()
+	result := variants.EmptyVariant()
+	start := 0
+	if paramCount == 3 {
+		result = getParameter(parameters, 2)
+	} else if len(elements) > 0 {
+		result = elements[0]
+		start = 1
+	}
+
+	for index := start; index < len(elements); index++ {
+		result, err = lambda.Invoke([]*variants.Variant{result, elements[index], variants.VariantFromInteger(index)})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func anyFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	found := false
+	var conversionErr error
+	array, err := iterateArrayWithLambda(parameters, variantOperations,
+		func(element *variants.Variant, result *variants.Variant) bool {
+			found, conversionErr = toBoolean(result, variantOperations)
+			return !found && conversionErr == nil
+		})
+	if err == nil {
+		err = conversionErr
+	}
+	if err != nil || array.IsNull() {
+		return array, err
+	}
+	return variants.VariantFromBoolean(found), nil
+}
+
+func allFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	matched := true
+	var conversionErr error
+	array, err := iterateArrayWithLambda(parameters, variantOperations,
+		func(element *variants.Variant, result *variants.Variant) bool {
+			matched, conversionErr = toBoolean(result, variantOperations)
+			return matched && conversionErr == nil
+		})
+	if err == nil {
+		err = conversionErr
+	}
+	if err != nil || array.IsNull() {
+		return array, err
+	}
+	return variants.VariantFromBoolean(matched), nil
+}
+
+func findFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	found := variants.EmptyVariant()
+	var conversionErr error
+	_, err := iterateArrayWithLambda(parameters, variantOperations,
+		func(element *variants.Variant, result *variants.Variant) bool {
+			matched, err := toBoolean(result, variantOperations)
+			if err != nil {
+				conversionErr = err
+				return false
+			}
+			if matched {
+				found = element
+			}
+			return !matched
+		})
+	if err == nil {
+		err = conversionErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+func sortByFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	elements := []*variants.Variant{}
+	keys := []*variants.Variant{}
+	array, err := iterateArrayWithLambda(parameters, variantOperations,
+		func(element *variants.Variant, result *variants.Variant) bool {
+			elements = append(elements, element)
+			keys = append(keys, result)
+			return true
+		})
+	if err != nil || array.IsNull() {
+		return array, err
+	}
+
+	// Sorts element indexes by keys, null keys go first.
+	indexes := make([]int, len(elements))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	var compareErr error
+	sort.SliceStable(indexes, func(i, j int) bool {
+		key1 := keys[indexes[i]]
+		key2 := keys[indexes[j]]
+		if key1.IsNull() || key2.IsNull() {
+			return key1.IsNull() && !key2.IsNull()
+		}
+		less, err := variantOperations.Less(key1, key2)
+		if err != nil {
+			compareErr = err
+			return false
+		}
+		return less.AsBoolean()
+	})
+	if compareErr != nil {
+		return nil, compareErr
+	}
+
+	result := make([]*variants.Variant, len(elements))
+	for i, index := range indexes {
+		result[i] = elements[index]
+	}
+	return variants.VariantFromArray(result), nil
+}
+
+func groupByFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	keys := []*variants.Variant{}
+	groups := [][]*variants.Variant{}
+	var compareErr error
+	array, err := iterateArrayWithLambda(parameters, variantOperations,
+		func(element *variants.Variant, result *variants.Variant) bool {
+			for i, key := range keys {
+				equal, err := variantOperations.Equal(key, result)
+				if err != nil {
+					compareErr = err
+					return false
+				}
+				if equal.AsBoolean() || (key.IsNull() && result.IsNull()) {
+					groups[i] = append(groups[i], element)
+					return true
+				}
+			}
+			keys = append(keys, result)
+			groups = append(groups, []*variants.Variant{element})
+			return true
+		})
+	if err == nil {
+		err = compareErr
+	}
+	if err != nil || array.IsNull() {
+		return array, err
+	}
+
+	// Each group is an object with Key and Items members in the order of first appearance.
+	result := make([]*variants.Variant, len(groups))
+	for i := range groups {
+		result[i] = variants.VariantFromObject(map[string]*variants.Variant{
+			"Key":   keys[i],
+			"Items": variants.VariantFromArray(groups[i]),
+		})
+	}
+	return variants.VariantFromArray(result), nil
+}
+
+// checkParamCountRange checks if parameters contains the number of function parameters within the range.
+//	Parameters:
+//		- parameters: A list with function parameters.
+//		- minParamCount: The minimum number of function parameters.
+//		- maxParamCount: The maximum number of function parameters or -1 if it is not limited.
+func checkParamCountRange(parameters []*variants.Variant, minParamCount int, maxParamCount int) error {
+	return checkParamCountInRange(len(parameters), minParamCount, maxParamCount)
+}
+
+// checkParamCountInRange checks if the number of function parameters is within the range.
+//	Parameters:
+//		- paramCount: The number of function parameters.
+//		- minParamCount: The minimum number of function parameters.
+//		- maxParamCount: The maximum number of function parameters or -1 if it is not limited.
+func checkParamCountInRange(paramCount int, minParamCount int, maxParamCount int) error {
+	if paramCount >= minParamCount && (maxParamCount < 0 || paramCount <= maxParamCount) {
+		return nil
+	}
+
+	message := "Expected from " + strconv.Itoa(minParamCount) + " to " + strconv.Itoa(maxParamCount) + " parameters"
+	if minParamCount == maxParamCount {
+		message = "Expected " + strconv.Itoa(minParamCount) + " parameters"
+	} else if maxParamCount < 0 {
+		message = "Expected at least " + strconv.Itoa(minParamCount) + " parameters"
+	}
+	err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
+		message+" but was found "+strconv.Itoa(paramCount), 0, 0)
+	return err
+}
+
+// convertParameters converts function parameters into the given types.
+// When there are more parameters than types the last type is used for the rest.
+//	Parameters:
+//		- parameters: A list with function parameters.
+//		- types: The types of function parameters.
+//		- variantOperations: Variants operations manager.
+//	Returns: Converted parameters or nil if any of the parameters is null.
+func convertParameters(parameters []*variants.Variant, types []variants.VariantType,
+	variantOperations variants.IVariantOperations) ([]*variants.Variant, error) {
+	result := make([]*variants.Variant, len(parameters))
+	for index, value := range parameters {
+		if value.IsNull() {
+			return nil, nil
+		}
+		typ := types[len(types)-1]
+		if index < len(types) {
+			typ = types[index]
+		}
+		converted, err := variantOperations.Convert(value, typ)
+		if err != nil {
+			return nil, err
+		}
+		if converted.IsNull() {
+			return nil, nil
+		}
+		result[index] = converted
+	}
+	return result, nil
+}
+
+// calculateNullableFunction checks the number of parameters, converts them
+// and calculates the function. If any of the parameters is null the result is null.
+func calculateNullableFunction(parameters []*variants.Variant, variantOperations variants.IVariantOperations,
+	minParamCount int, maxParamCount int, types []variants.VariantType,
+	calculate func(values []*variants.Variant) (*variants.Variant, error)) (*variants.Variant, error) {
+	err := checkParamCountRange(parameters, minParamCount, maxParamCount)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := convertParameters(parameters, types, variantOperations)
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		return variants.EmptyVariant(), nil
+	}
+
+	return calculate(values)
+}
+
+// clampRange limits the range of characters by the string length.
+func clampRange(start int, length int, stringLength int) (int, int) {
+	if start < 0 {
+		length += start
+		start = 0
+	}
+	if start > stringLength {
+		start = stringLength
+	}
+	if length < 0 {
+		length = 0
+	}
+	if start+length > stringLength {
+		length = stringLength - start
+	}
+	return start, start + length
+}
+
+// padString creates a padding with the given number of characters repeating the pad string.
+func padString(count int, pad string) string {
+	padRunes := []rune(pad)
+	if count <= 0 || len(padRunes) == 0 {
+		return ""
+	}
+	result := make([]rune, count)
+	for i := range result {
+		result[i] = padRunes[i%len(padRunes)]
+	}
+	return string(result)
+}
+
+func upperFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 1, 1,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			return variants.VariantFromString(strings.ToUpper(values[0].AsString())), nil
+		})
+}
+
+func lowerFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 1, 1,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			return variants.VariantFromString(strings.ToLower(values[0].AsString())), nil
+		})
+}
+
+func lenFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 1, 1,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			return variants.VariantFromInteger(utf8.RuneCountInString(values[0].AsString())), nil
+		})
+}
+
+func substringFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 3,
+		[]variants.VariantType{variants.String, variants.Integer},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			runes := []rune(values[0].AsString())
+			length := len(runes)
+			if len(values) > 2 {
+				length = values[2].AsInteger()
+			}
+			start, end := clampRange(values[1].AsInteger(), length, len(runes))
+			return variants.VariantFromString(string(runes[start:end])), nil
+		})
+}
+
+func leftFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 2,
+		[]variants.VariantType{variants.String, variants.Integer},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			runes := []rune(values[0].AsString())
+			start, end := clampRange(0, values[1].AsInteger(), len(runes))
+			return variants.VariantFromString(string(runes[start:end])), nil
+		})
+}
+
+func rightFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 2,
+		[]variants.VariantType{variants.String, variants.Integer},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			runes := []rune(values[0].AsString())
+			length := values[1].AsInteger()
+			if length < 0 {
+				length = 0
+			}
+			start, end := clampRange(len(runes)-length, length, len(runes))
+			return variants.VariantFromString(string(runes[start:end])), nil
+		})
+}
+
+// trimFunctionCalculator creates a calculator that trims whitespaces or the given characters.
+func trimFunctionCalculator(trimFunc func(string, func(rune) bool) string) FunctionCalculator {
+	return func(parameters []*variants.Variant,
+		variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+		return calculateNullableFunction(parameters, variantOperations, 1, 2,
+			[]variants.VariantType{variants.String},
+			func(values []*variants.Variant) (*variants.Variant, error) {
+				isTrimmed := unicode.IsSpace
+				if len(values) > 1 {
+					chars := values[1].AsString()
+					isTrimmed = func(r rune) bool { return strings.ContainsRune(chars, r) }
+				}
+				return variants.VariantFromString(trimFunc(values[0].AsString(), isTrimmed)), nil
+			})
+	}
+}
+
+func replaceFunctionCalculator(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 3, 3,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			oldValue := values[1].AsString()
+			if oldValue == "" {
+				return values[0], nil
+			}
+			count := strings.Count(values[0].AsString(), oldValue)
+			length := utf8.RuneCountInString(values[0].AsString()) +
+				count*(utf8.RuneCountInString(values[2].AsString())-utf8.RuneCountInString(oldValue))
+			err := SizeLimitsFromContext(ctx).CheckStringLength(length)
+			if err != nil {
+				return nil, err
+			}
+			return variants.VariantFromString(strings.ReplaceAll(values[0].AsString(), oldValue, values[2].AsString())), nil
+		})
+}
+
+func splitFunctionCalculator(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 2,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			// An empty separator splits the string into characters.
+			value := values[0].AsString()
+			separator := values[1].AsString()
+			length := utf8.RuneCountInString(value)
+			if separator != "" {
+				length = strings.Count(value, separator) + 1
+			}
+			err := SizeLimitsFromContext(ctx).CheckArrayLength(length)
+			if err != nil {
+				return nil, err
+			}
+			parts := strings.Split(value, separator)
+			elements := make([]*variants.Variant, len(parts))
+			for index, part := range parts {
+				elements[index] = variants.VariantFromString(part)
+			}
+			return variants.VariantFromArray(elements), nil
+		})
+}
+
+func joinFunctionCalculator(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCountRange(parameters, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	array, err := getArrayParameter(parameters, 0, variantOperations)
+	if err != nil || array.IsNull() {
+		return array, err
+	}
+	separator := ""
+	if len(parameters) > 1 {
+		value, err := variantOperations.Convert(getParameter(parameters, 1), variants.String)
+		if err != nil {
+			return nil, err
+		}
+		if value.IsNull() {
+			return value, nil
+		}
+		separator = value.AsString()
+	}
+
+	// Null elements are joined as empty strings.
+	parts := make([]string, array.Length())
+	for index, element := range array.AsArray() {
+		if element.IsNull() {
+			continue
+		}
+		value, err := variantOperations.Convert(element, variants.String)
+		if err != nil {
+			return nil, err
+		}
+		parts[index] = value.AsString()
+	}
+
+	length := utf8.RuneCountInString(separator) * (len(parts) - 1)
+	for _, part := range parts {
+		length += utf8.RuneCountInString(part)
+	}
+	err = SizeLimitsFromContext(ctx).CheckStringLength(length)
+	if err != nil {
+		return nil, err
+	}
+
+	return variants.VariantFromString(strings.Join(parts, separator)), nil
+}
+
+func indexOfFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 3,
+		[]variants.VariantType{variants.String, variants.String, variants.Integer},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			runes := []rune(values[0].AsString())
+			start := 0
+			if len(values) > 2 {
+				start, _ = clampRange(values[2].AsInteger(), 0, len(runes))
+			}
+			index := strings.Index(string(runes[start:]), values[1].AsString())
+			if index < 0 {
+				return variants.VariantFromInteger(-1), nil
+			}
+			// Converts the byte index into the character index.
+			index = start + utf8.RuneCountInString(string(runes[start:])[:index])
+			return variants.VariantFromInteger(index), nil
+		})
+}
+
+func startsWithFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 2,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			return variants.VariantFromBoolean(strings.HasPrefix(values[0].AsString(), values[1].AsString())), nil
+		})
+}
+
+func endsWithFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 2,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			return variants.VariantFromBoolean(strings.HasSuffix(values[0].AsString(), values[1].AsString())), nil
+		})
+}
+
+// padFunctionCalculator creates a calculator that pads strings from the left or from the right side.
+func padFunctionCalculator(left bool) ContextFunctionCalculator {
+	return func(ctx context.Context, parameters []*variants.Variant,
+		variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+		return calculateNullableFunction(parameters, variantOperations, 2, 3,
+			[]variants.VariantType{variants.String, variants.Integer, variants.String},
+			func(values []*variants.Variant) (*variants.Variant, error) {
+				value := values[0].AsString()
+				pad := " "
+				if len(values) > 2 {
+					pad = values[2].AsString()
+				}
+				err := SizeLimitsFromContext(ctx).CheckRepeatedStringLength(1, values[1].AsInteger())
+				if err != nil {
+					return nil, err
+				}
+				padding := padString(values[1].AsInteger()-utf8.RuneCountInString(value), pad)
+				if left {
+					return variants.VariantFromString(padding + value), nil
+				}
+				return variants.VariantFromString(value + padding), nil
+			})
+	}
+}
+
+func repeatFunctionCalculator(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 2,
+		[]variants.VariantType{variants.String, variants.Integer},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			count := values[1].AsInteger()
+			if count < 0 {
+				count = 0
+			}
+			err := SizeLimitsFromContext(ctx).CheckRepeatedStringLength(utf8.RuneCountInString(values[0].AsString()), count)
+			if err != nil {
+				return nil, err
+			}
+			return variants.VariantFromString(strings.Repeat(values[0].AsString(), count)), nil
+		})
+}
+
+func reverseFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 1, 1,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			runes := []rune(values[0].AsString())
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return variants.VariantFromString(string(runes)), nil
+		})
+}
+
+// formatFunctionCalculator replaces {N} placeholders in the format string with parameters.
+// Double braces {{ and }} are used for literal braces. Null values are formatted as empty strings.
+func formatFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCountRange(parameters, 1, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := variantOperations.Convert(getParameter(parameters, 0), variants.String)
+	if err != nil || format.IsNull() {
+		return format, err
+	}
+	values := parameters[1:]
+
+	pattern := format.AsString()
+	builder := strings.Builder{}
+	for index := 0; index < len(pattern); index++ {
+		char := pattern[index]
+		if (char == '{' || char == '}') && index+1 < len(pattern) && pattern[index+1] == char {
+			builder.WriteByte(char)
+			index++
+			continue
+		}
+
+		end := strings.IndexByte(pattern[index:], '}')
+		if char != '{' || end < 2 {
+			builder.WriteByte(char)
+			continue
+		}
+		valueIndex, convErr := strconv.Atoi(pattern[index+1 : index+end])
+		if convErr != nil || valueIndex < 0 {
+			builder.WriteByte(char)
+			continue
+		}
+		if valueIndex >= len(values) {
+			err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
+				"Expected at least "+strconv.Itoa(valueIndex+2)+" parameters but was found "+
+					strconv.Itoa(len(parameters)), 0, 0)
+			return nil, err
+		}
+
+		value := values[valueIndex]
+		if !value.IsNull() {
+			value, err = variantOperations.Convert(value, variants.String)
+			if err != nil {
+				return nil, err
+			}
+			builder.WriteString(value.AsString())
+		}
+		index += end
+	}
+
+	return variants.VariantFromString(builder.String()), nil
+}
+
+// dateLayoutTokens defines conversion of date pattern tokens into Go layout elements.
+// Longer tokens go first, so they are matched before shorter ones.
+var dateLayoutTokens = []struct {
+	token  string
+	layout string
+}{
+	{"yyyy", "2006"}, {"yy", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"dddd", "Monday"}, {"ddd", "Mon"}, {"dd", "02"}, {"d", "2"},
+	{"HH", "15"}, {"hh", "03"}, {"h", "3"},
+	{"mm", "04"}, {"m", "4"}, {"ss", "05"}, {"s", "5"},
+	{"fff", "000"}, {"ff", "00"}, {"f", "0"},
+	{"tt", "PM"}, {"zzz", "Z07:00"}, {"z", "MST"},
+}
+
+// defaultDateLayout is used to format and parse dates when the pattern is not set.
+const defaultDateLayout = time.RFC3339
+
+// convertDateLayout converts date pattern like "yyyy-MM-dd HH:mm:ss" into Go layout.
+// Text in single quotes is copied as it is.
+//	Parameters:
+//		- pattern: The date pattern.
+//	Returns: Go layout for the date pattern.
+func convertDateLayout(pattern string) string {
+	builder := strings.Builder{}
+	for index := 0; index < len(pattern); {
+		if pattern[index] == '\'' {
+			end := strings.IndexByte(pattern[index+1:], '\'')
+			if end < 0 {
+				end = len(pattern) - index - 1
+			}
+			builder.WriteString(pattern[index+1 : index+1+end])
+			index += end + 2
+			continue
+		}
+
+		matched := false
+		for _, token := range dateLayoutTokens {
+			if strings.HasPrefix(pattern[index:], token.token) {
+				builder.WriteString(token.layout)
+				index += len(token.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			builder.WriteByte(pattern[index])
+			index++
+		}
+	}
+	return builder.String()
+}
+
+// loadLocation gets the time zone by its IANA name. Empty name and "Local" mean the local time zone.
+//	Parameters:
+//		- name: The name of the time zone.
+//	Returns: Time zone location or error if the time zone is unknown.
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, "Local") {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.NewExpressionError("", "WRONG_PARAM_VALUE", "Unknown time zone '"+name+"'", 0, 0)
+	}
+	return location, nil
+}
+
+// addMonths adds months to the date. When the day does not exist in the resulting month
+// it is set to the last day of that month.
+func addMonths(date time.Time, months int) time.Time {
+	year, month, day := date.Date()
+	first := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day,
+		date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+}
+
+// timeOfDay gets the time passed since the midnight.
+func timeOfDay(date time.Time) time.Duration {
+	return time.Duration(date.Hour())*time.Hour + time.Duration(date.Minute())*time.Minute +
+		time.Duration(date.Second())*time.Second + time.Duration(date.Nanosecond())
+}
+
+// calendarDifference calculates the number of complete calendar days and months between dates.
+// The end date is converted into the time zone of the start date.
+func calendarDifference(start time.Time, end time.Time) (int, int) {
+	end = end.In(start.Location())
+	year1, month1, day1 := start.Date()
+	year2, month2, day2 := end.Date()
+
+	days := int(time.Date(year2, month2, day2, 0, 0, 0, 0, time.UTC).
+		Sub(time.Date(year1, month1, day1, 0, 0, 0, 0, time.UTC)) / (24 * time.Hour))
+	if days > 0 && timeOfDay(end) < timeOfDay(start) {
+		days--
+	} else if days < 0 && timeOfDay(end) > timeOfDay(start) {
+		days++
+	}
+
+	months := (year2-year1)*12 + int(month2-month1)
+	if months > 0 && addMonths(start, months).After(end) {
+		months--
+	} else if months < 0 && addMonths(start, months).Before(end) {
+		months++
+	}
+
+	return days, months
+}
+
+func datePartFunctionCalculator(part func(date time.Time) int) FunctionCalculator {
+	return func(parameters []*variants.Variant,
+		variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+		return calculateNullableFunction(parameters, variantOperations, 1, 1,
+			[]variants.VariantType{variants.DateTime},
+			func(values []*variants.Variant) (*variants.Variant, error) {
+				return variants.VariantFromInteger(part(values[0].AsDateTime())), nil
+			})
+	}
+}
+
+func addDateFunctionCalculator(add func(date time.Time, value int) time.Time) FunctionCalculator {
+	return func(parameters []*variants.Variant,
+		variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+		return calculateNullableFunction(parameters, variantOperations, 2, 2,
+			[]variants.VariantType{variants.DateTime, variants.Integer},
+			func(values []*variants.Variant) (*variants.Variant, error) {
+				return variants.VariantFromDateTime(add(values[0].AsDateTime(), values[1].AsInteger())), nil
+			})
+	}
+}
+
+// dateDiffFunctionCalculator calculates the number of complete units between two dates.
+// Supported units are year, month, week, day, hour, minute, second and millisecond.
+func dateDiffFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 3, 3,
+		[]variants.VariantType{variants.DateTime, variants.DateTime, variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			start := values[0].AsDateTime()
+			end := values[1].AsDateTime()
+			days, months := calendarDifference(start, end)
+			duration := end.Sub(start)
+
+			result := 0
+			switch strings.TrimSuffix(strings.ToLower(values[2].AsString()), "s") {
+			case "year":
+				result = months / 12
+			case "month":
+				result = months
+			case "week":
+				result = days / 7
+			case "day":
+				result = days
+			case "hour":
+				result = int(duration / time.Hour)
+			case "minute":
+				result = int(duration / time.Minute)
+			case "second":
+				result = int(duration / time.Second)
+			case "millisecond":
+				result = int(duration / time.Millisecond)
+			default:
+				err := errors.NewExpressionError("", "WRONG_PARAM_VALUE",
+					"Unknown date unit '"+values[2].AsString()+"'", 0, 0)
+				return nil, err
+			}
+			return variants.VariantFromInteger(result), nil
+		})
+}
+
+// startOfWeekFunctionCalculator gets the midnight of the first week day.
+// The first day is set in the same way as DayOfWeek returns it, by default it is Monday.
+func startOfWeekFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 1, 2,
+		[]variants.VariantType{variants.DateTime, variants.Integer},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			firstDay := int(time.Monday)
+			if len(values) > 1 {
+				firstDay = values[1].AsInteger()
+			}
+			if firstDay < int(time.Sunday) || firstDay > int(time.Saturday) {
+				err := errors.NewExpressionError("", "WRONG_PARAM_VALUE",
+					"Expected the first week day from 0 to 6 but was found "+strconv.Itoa(firstDay), 0, 0)
+				return nil, err
+			}
+
+			date := values[0].AsDateTime()
+			offset := (int(date.Weekday()) - firstDay + 7) % 7
+			year, month, day := date.Date()
+			return variants.VariantFromDateTime(time.Date(year, month, day-offset, 0, 0, 0, 0, date.Location())), nil
+		})
+}
+
+func startOfMonthFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 1, 1,
+		[]variants.VariantType{variants.DateTime},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			date := values[0].AsDateTime()
+			return variants.VariantFromDateTime(time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())), nil
+		})
+}
+
+func formatDateFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 1, 2,
+		[]variants.VariantType{variants.DateTime, variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			layout := defaultDateLayout
+			if len(values) > 1 {
+				layout = convertDateLayout(values[1].AsString())
+			}
+			return variants.VariantFromString(values[0].AsDateTime().Format(layout)), nil
+		})
+}
+
+// parseDateFunctionCalculator parses a date using the layout in the given time zone.
+// Time zones set in the date string take priority.
+func parseDateFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 1, 3,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			layout := defaultDateLayout
+			if len(values) > 1 {
+				layout = convertDateLayout(values[1].AsString())
+			}
+			zone := ""
+			if len(values) > 2 {
+				zone = values[2].AsString()
+			}
+			location, err := loadLocation(zone)
+			if err != nil {
+				return nil, err
+			}
+
+			date, err := time.ParseInLocation(layout, values[0].AsString(), location)
+			if err != nil {
+				err := errors.NewExpressionError("", "WRONG_PARAM_VALUE",
+					"Date '"+values[0].AsString()+"' does not match the layout", 0, 0)
+				return nil, err
+			}
+			return variants.VariantFromDateTime(date), nil
+		})
+}
+
+func toTimeZoneFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 2,
+		[]variants.VariantType{variants.DateTime, variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			location, err := loadLocation(values[1].AsString())
+			if err != nil {
+				return nil, err
+			}
+			return variants.VariantFromDateTime(values[0].AsDateTime().In(location)), nil
+		})
+}
+
+// regexMatchFunctionCalculator checks if the string contains a match of the regular expression.
+// Patterns are compiled using the cache passed in the evaluation context.
+func regexMatchFunctionCalculator(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 2,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			regex, err := RegexCacheFromContext(ctx).Compile(values[1].AsString())
+			if err != nil {
+				return nil, err
+			}
+			return variants.VariantFromBoolean(regex.MatchString(values[0].AsString())), nil
+		})
+}
+
+// regexReplaceFunctionCalculator replaces all matches of the regular expression.
+// The replacement can reference groups as $1 or ${name}.
+func regexReplaceFunctionCalculator(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 3, 3,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			regex, err := RegexCacheFromContext(ctx).Compile(values[1].AsString())
+			if err != nil {
+				return nil, err
+			}
+			return variants.VariantFromString(regex.ReplaceAllString(values[0].AsString(), values[2].AsString())), nil
+		})
+}
+
+// regexExtractFunctionCalculator gets the first match of the regular expression
+// or its group by index. When there is no match the result is null.
+func regexExtractFunctionCalculator(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 3,
+		[]variants.VariantType{variants.String, variants.String, variants.Integer},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			regex, err := RegexCacheFromContext(ctx).Compile(values[1].AsString())
+			if err != nil {
+				return nil, err
+			}
+			group := 0
+			if len(values) > 2 {
+				group = values[2].AsInteger()
+			}
+			if group < 0 || group > regex.NumSubexp() {
+				err := errors.NewExpressionError("", "WRONG_PARAM_VALUE",
+					"Regular expression has no group "+strconv.Itoa(group), 0, 0)
+				return nil, err
+			}
+
+			match := regex.FindStringSubmatchIndex(values[0].AsString())
+			if match == nil || match[group*2] < 0 {
+				return variants.EmptyVariant(), nil
+			}
+			return variants.VariantFromString(values[0].AsString()[match[group*2]:match[group*2+1]]), nil
+		})
+}
+
+func regexSplitFunctionCalculator(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return calculateNullableFunction(parameters, variantOperations, 2, 2,
+		[]variants.VariantType{variants.String},
+		func(values []*variants.Variant) (*variants.Variant, error) {
+			regex, err := RegexCacheFromContext(ctx).Compile(values[1].AsString())
+			if err != nil {
+				return nil, err
+			}
+			parts := regex.Split(values[0].AsString(), -1)
+			elements := make([]*variants.Variant, len(parts))
+			for index, part := range parts {
+				elements[index] = variants.VariantFromString(part)
+			}
+			return variants.VariantFromArray(elements), nil
+		})
+}
+
+// getAggregateValues gets values of aggregate function. Array parameters are expanded into their elements,
+// so values can be passed both as parameters and as arrays. Null values are skipped or rejected
+// depending on the null handling setting.
+//	Parameters:
+//		- parameters: A list with function parameters that contain aggregated values.
+//		- variantOperations: Variants operations manager.
+//	Returns: Aggregated values or error if null values are rejected.
+func (c *DefaultFunctionCollection) getAggregateValues(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) ([]*variants.Variant, error) {
+	values := make([]*variants.Variant, 0, len(parameters))
+	for _, parameter := range parameters {
+		elements := []*variants.Variant{parameter}
+		if parameter.Type() == variants.Array {
+			elements = parameter.AsArray()
+		}
+		for _, element := range elements {
+			if !element.IsNull() {
+				values = append(values, element)
+			} else if c.nullHandling == RejectNulls {
+				err := errors.NewExpressionError("", "WRONG_PARAM_VALUE",
+					"Aggregated values cannot be null", 0, 0)
+				return nil, err
+			}
+		}
+	}
+	return values, nil
+}
+
+// getAggregateNumbers gets values of aggregate function converted to numbers.
+func (c *DefaultFunctionCollection) getAggregateNumbers(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) ([]float64, error) {
+	values, err := c.getAggregateValues(parameters, variantOperations)
+	if err != nil {
+		return nil, err
+	}
+	numbers := make([]float64, len(values))
+	for index, value := range values {
+		number, err := variantOperations.Convert(value, variants.Double)
+		if err != nil {
+			return nil, err
+		}
+		numbers[index] = number.AsDouble()
+	}
+	return numbers, nil
+}
+
+// aggregate folds values with the operation. The result is null when there are no values.
+func (c *DefaultFunctionCollection) aggregate(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations,
+	operation func(result *variants.Variant, value *variants.Variant) (*variants.Variant, error)) (*variants.Variant, error) {
+	err := checkParamCountRange(parameters, 1, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := c.getAggregateValues(parameters, variantOperations)
+	if err != nil || len(values) == 0 {
+		return variants.EmptyVariant(), err
+	}
+
+	result := values[0]
+	for _, value := range values[1:] {
+		result, err = operation(result, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (c *DefaultFunctionCollection) minFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return c.aggregate(parameters, variantOperations,
+		func(result *variants.Variant, value *variants.Variant) (*variants.Variant, error) {
+			temp, err := variantOperations.More(result, value)
+			if err != nil || !temp.AsBoolean() {
+				return result, err
+			}
+			return value, nil
+		})
+}
+
+func (c *DefaultFunctionCollection) maxFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return c.aggregate(parameters, variantOperations,
+		func(result *variants.Variant, value *variants.Variant) (*variants.Variant, error) {
+			temp, err := variantOperations.Less(result, value)
+			if err != nil || !temp.AsBoolean() {
+				return result, err
+			}
+			return value, nil
+		})
+}
+
+func (c *DefaultFunctionCollection) sumFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return c.aggregate(parameters, variantOperations, variantOperations.Add)
+}
+
+func (c *DefaultFunctionCollection) productFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	return c.aggregate(parameters, variantOperations, variantOperations.Mul)
+}
+
+func (c *DefaultFunctionCollection) countFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	values, err := c.getAggregateValues(parameters, variantOperations)
+	if err != nil {
+		return nil, err
+	}
+	return variants.VariantFromInteger(len(values)), nil
+}
+
+// countIfFunctionCalculator counts values that match the condition set by the lambda in the last parameter.
+func (c *DefaultFunctionCollection) countIfFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCountRange(parameters, 2, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	lambda, err := getLambdaParameter(parameters, len(parameters)-1)
+	if err != nil {
+		return nil, err
+	}
+	values, err := c.getAggregateValues(parameters[:len(parameters)-1], variantOperations)
+	if err != nil {
+		return nil, err
+	}
+
+	count := 0
+	for index, value := range values {
+		result, err := lambda.Invoke([]*variants.Variant{value, variants.VariantFromInteger(index)})
+		if err != nil {
+			return nil, err
+		}
+		matched, err := toBoolean(result, variantOperations)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			count++
+		}
+	}
+	return variants.VariantFromInteger(count), nil
+}
+
+// avgFunctionCalculator gets the average as Double. When any of the values is Decimal
+// the sum and the division are done by variant operations, so the average stays Decimal.
+func (c *DefaultFunctionCollection) avgFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCountRange(parameters, 1, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := c.getAggregateValues(parameters, variantOperations)
+	if err != nil || len(values) == 0 {
+		return variants.EmptyVariant(), err
+	}
+	for _, value := range values {
+		if value.Type() != variants.Decimal {
+			continue
+		}
+		sum := variants.VariantFromDecimal(variants.DecimalFromInt64(0))
+		for _, value := range values {
+			number, err := variantOperations.Convert(value, variants.Decimal)
+			if err != nil {
+				return nil, err
+			}
+			sum, err = variantOperations.Add(sum, number)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return variantOperations.Div(sum, variants.VariantFromInteger(len(values)))
+	}
+
+	numbers, err := c.getAggregateNumbers(parameters, variantOperations)
+	if err != nil || len(numbers) == 0 {
+		return variants.EmptyVariant(), err
+	}
+	sum := 0.0
+	for _, number := range numbers {
+		sum += number
+	}
+	return variants.VariantFromDouble(sum / float64(len(numbers))), nil
+}
+
+func (c *DefaultFunctionCollection) medianFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCountRange(parameters, 1, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	numbers, err := c.getAggregateNumbers(parameters, variantOperations)
+	if err != nil || len(numbers) == 0 {
+		return variants.EmptyVariant(), err
+	}
+	return variants.VariantFromDouble(percentile(numbers, 0.5)), nil
+}
+
+// modeFunctionCalculator gets the most frequent value. When several values
+// are equally frequent the one that appears first is returned.
+func (c *DefaultFunctionCollection) modeFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCountRange(parameters, 1, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := c.getAggregateValues(parameters, variantOperations)
+	if err != nil || len(values) == 0 {
+		return variants.EmptyVariant(), err
+	}
+
+	distinct := []*variants.Variant{}
+	counts := []int{}
+	result := 0
+	for _, value := range values {
+		found := -1
+		for index, other := range distinct {
+			equal, err := variantOperations.Equal(value, other)
+			if err != nil {
+				return nil, err
+			}
+			if equal.AsBoolean() {
+				found = index
+				break
+			}
+		}
+		if found < 0 {
+			distinct = append(distinct, value)
+			counts = append(counts, 0)
+			found = len(distinct) - 1
+		}
+		counts[found]++
+		if counts[found] > counts[result] {
+			result = found
+		}
+	}
+	return distinct[result], nil
+}
+
+// varianceFunctionCalculator creates a calculator of sample variance or standard deviation.
+// The result is null when there are less than two values.
+func (c *DefaultFunctionCollection) varianceFunctionCalculator(deviation bool) FunctionCalculator {
+	return func(parameters []*variants.Variant,
+		variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+		err := checkParamCountRange(parameters, 1, -1)
+		if err != nil {
+			return nil, err
+		}
+
+		numbers, err := c.getAggregateNumbers(parameters, variantOperations)
+		if err != nil || len(numbers) < 2 {
+			return variants.EmptyVariant(), err
+		}
+
+		mean := 0.0
+		for _, number := range numbers {
+			mean += number
+		}
+		mean /= float64(len(numbers))
+		variance := 0.0
+		for _, number := range numbers {
+			variance += (number - mean) * (number - mean)
+		}
+		variance /= float64(len(numbers) - 1)
+
+		if deviation {
+			return variants.VariantFromDouble(math.Sqrt(variance)), nil
+		}
+		return variants.VariantFromDouble(variance), nil
+	}
+}
+
+// percentileFunctionCalculator gets the percentile set in the last parameter from 0 to 1.
+// Values between ranks are linearly interpolated.
+func (c *DefaultFunctionCollection) percentileFunctionCalculator(parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+	err := checkParamCountRange(parameters, 2, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	rank, err := variantOperations.Convert(getParameter(parameters, len(parameters)-1), variants.Double)
+	if err != nil {
+		return nil, err
+	}
+	if rank.IsNull() || rank.AsDouble() < 0 || rank.AsDouble() > 1 {
+		err := errors.NewExpressionError("", "WRONG_PARAM_VALUE",
+			"Expected percentile from 0 to 1", 0, 0)
+		return nil, err
+	}
+
+	numbers, err := c.getAggregateNumbers(parameters[:len(parameters)-1], variantOperations)
+	if err != nil || len(numbers) == 0 {
+		return variants.EmptyVariant(), err
+	}
+	return variants.VariantFromDouble(percentile(numbers, rank.AsDouble())), nil
+}
+
+// percentile calculates the percentile of numbers with linear interpolation between ranks.
+func percentile(numbers []float64, rank float64) float64 {
+	sorted := make([]float64, len(numbers))
+	copy(sorted, numbers)
+	sort.Float64s(sorted)
+
+	position := rank * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
@@ -0,0 +1,183 @@
+package functions
+
+import "github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+
+// newDefaultFunctionSignatures creates signatures of the standard functions by their names.
+// Purity of signatures is set from the functions when they are assigned.
+func newDefaultFunctionSignatures() map[string]*FunctionSignature {
+	param := NewFunctionParameter
+	optional := NewOptionalFunctionParameter
+	variadic := NewVariadicFunctionParameter
+	signature := NewFunctionSignature
+
+	const (
+		anything = variants.Null
+		integer  = variants.Integer
+		long     = variants.Long
+		double   = variants.Double
+		str      = variants.String
+		boolean  = variants.Boolean
+		dateTime = variants.DateTime
+		array    = variants.Array
+		lambda   = variants.Object
+	)
+
+	return map[string]*FunctionSignature{
+		// Dates and times
+		"Ticks": signature(long, "Gets the current Unix time in seconds."),
+		"TimeSpan": signature(variants.TimeSpan,
+			"Creates a time span from milliseconds or from days, hours, minutes, seconds and milliseconds.",
+			param("daysOrMilliseconds", long), optional("hours", long), optional("minutes", long),
+			optional("seconds", long), optional("milliseconds", long)),
+		"Now": signature(dateTime, "Gets the current date and time."),
+		"Date": signature(dateTime,
+			"Creates a date from Unix time in seconds or from year, month, day, hour, minute, second and millisecond.",
+			param("yearOrTicks", long), optional("month", integer), optional("day", integer),
+			optional("hour", integer), optional("minute", integer), optional("second", integer),
+			optional("millisecond", integer)),
+		"DayOfWeek": signature(integer, "Gets the day of week from 0 for Sunday to 6 for Saturday.", param("date", dateTime)),
+		"Year":      signature(integer, "Gets the year of the date.", param("date", dateTime)),
+		"Month":     signature(integer, "Gets the month of the date from 1 to 12.", param("date", dateTime)),
+		"Day":       signature(integer, "Gets the day of month of the date.", param("date", dateTime)),
+		"Hour":      signature(integer, "Gets the hour of the date.", param("date", dateTime)),
+		"Minute":    signature(integer, "Gets the minute of the date.", param("date", dateTime)),
+		"Second":    signature(integer, "Gets the second of the date.", param("date", dateTime)),
+		"AddDays":   signature(dateTime, "Adds days to the date.", param("date", dateTime), param("days", integer)),
+		"AddMonths": signature(dateTime, "Adds months to the date.", param("date", dateTime), param("months", integer)),
+		"AddYears":  signature(dateTime, "Adds years to the date.", param("date", dateTime), param("years", integer)),
+		"DateDiff": signature(integer,
+			"Counts complete units between two dates: year, month, week, day, hour, minute, second or millisecond.",
+			param("start", dateTime), param("end", dateTime), param("unit", str)),
+		"StartOfWeek": signature(dateTime, "Gets the midnight of the first week day, by default Monday.",
+			param("date", dateTime), optional("firstDay", integer)),
+		"StartOfMonth": signature(dateTime, "Gets the midnight of the first month day.", param("date", dateTime)),
+		"FormatDate": signature(str, "Formats the date using the layout.",
+			param("date", dateTime), optional("layout", str)),
+		"ParseDate": signature(dateTime, "Parses the date using the layout in the time zone.",
+			param("value", str), optional("layout", str), optional("timeZone", str)),
+		"ToTimeZone": signature(dateTime, "Converts the date into the time zone.",
+			param("date", dateTime), param("timeZone", str)),
+
+		// Aggregates
+		"Min":     signature(anything, "Gets the minimum of values or array elements.", variadic("values", anything, false)),
+		"Max":     signature(anything, "Gets the maximum of values or array elements.", variadic("values", anything, false)),
+		"Sum":     signature(anything, "Gets the sum of values or array elements.", variadic("values", anything, false)),
+		"Product": signature(anything, "Gets the product of values or array elements.", variadic("values", anything, false)),
+		"Count":   signature(integer, "Counts values or array elements.", variadic("values", anything, true)),
+		"CountIf": signature(integer, "Counts values or array elements that match the predicate in the last parameter.",
+			param("values", anything), variadic("valuesAndPredicate", anything, false)),
+		"Avg":      signature(double, "Gets the average of values or array elements.", variadic("values", anything, false)),
+		"Median":   signature(double, "Gets the median of values or array elements.", variadic("values", anything, false)),
+		"Mode":     signature(anything, "Gets the most frequent of values or array elements.", variadic("values", anything, false)),
+		"Variance": signature(double, "Gets the variance of values or array elements.", variadic("values", anything, false)),
+		"StdDev": signature(double, "Gets the standard deviation of values or array elements.",
+			variadic("values", anything, false)),
+		"Percentile": signature(double, "Gets the percentile of values or array elements with the rank from 0 to 1 in the last parameter.",
+			param("values", anything), variadic("valuesAndRank", anything, false)),
+
+		// Conditions and nulls
+		"If": signature(anything, "Gets the second parameter if the condition is true or the third one otherwise.",
+			param("condition", boolean), param("then", anything), param("else", anything)),
+		"Choose": signature(anything, "Gets the parameter by the index starting from 1.",
+			param("index", integer), param("value", anything), variadic("values", anything, false)),
+		"Empty":    signature(boolean, "Checks if the value is null or empty.", param("value", anything)),
+		"Null":     signature(anything, "Gets the null value."),
+		"Coalesce": signature(anything, "Gets the first value that is not null.", variadic("values", anything, false)),
+		"IfNull": signature(anything, "Gets the value or the default value if it is null.",
+			param("value", anything), param("default", anything)),
+		"NullIf": signature(anything, "Gets null if both values are equal or the first value otherwise.",
+			param("value1", anything), param("value2", anything)),
+
+		// Numbers
+		"E":         signature(variants.Float, "Gets the Euler's number."),
+		"Pi":        signature(variants.Float, "Gets the Pi number."),
+		"Rnd":       signature(variants.Float, "Gets a random number from 0 to 1."),
+		"Random":    signature(variants.Float, "Gets a random number from 0 to 1."),
+		"Abs":       signature(anything, "Gets the absolute value of the number.", param("value", anything)),
+		"Factorial": signature(anything, "Gets the factorial of the number from 0 to 10000.", param("value", long)),
+		"Acos":      signature(double, "Gets the arccosine of the number.", param("value", double)),
+		"Asin":      signature(double, "Gets the arcsine of the number.", param("value", double)),
+		"Atan":      signature(double, "Gets the arctangent of the number.", param("value", double)),
+		"Exp":       signature(double, "Gets the Euler's number raised to the power.", param("value", double)),
+		"Log":       signature(double, "Gets the natural logarithm of the number.", param("value", double)),
+		"Ln":        signature(double, "Gets the natural logarithm of the number.", param("value", double)),
+		"Log10":     signature(double, "Gets the decimal logarithm of the number.", param("value", double)),
+		"Ceil":      signature(anything, "Rounds the number up.", param("value", anything)),
+		"Ceiling":   signature(anything, "Rounds the number up.", param("value", anything)),
+		"Floor":     signature(anything, "Rounds the number down.", param("value", anything)),
+		"Round": signature(anything, "Rounds the number to the digits using the rounding mode, by default HalfUp.",
+			param("value", anything), optional("digits", integer), optional("mode", str)),
+		"Trunc":    signature(anything, "Truncates the fractional part of the number.", param("value", anything)),
+		"Truncate": signature(anything, "Truncates the fractional part of the number.", param("value", anything)),
+		"Cos":      signature(double, "Gets the cosine of the angle in radians.", param("value", double)),
+		"Sin":      signature(double, "Gets the sine of the angle in radians.", param("value", double)),
+		"Tan":      signature(double, "Gets the tangent of the angle in radians.", param("value", double)),
+		"Sqr":      signature(double, "Gets the square root of the number.", param("value", double)),
+		"Sqrt":     signature(double, "Gets the square root of the number.", param("value", double)),
+
+		// Strings
+		"Contains": signature(boolean, "Checks if the string contains the substring.",
+			param("value", str), param("substring", str)),
+		"Upper": signature(str, "Converts the string to upper case.", param("value", str)),
+		"Lower": signature(str, "Converts the string to lower case.", param("value", str)),
+		"Len":   signature(integer, "Gets the number of characters in the string.", param("value", str)),
+		"Substring": signature(str, "Gets the part of the string from the start index.",
+			param("value", str), param("start", integer), optional("length", integer)),
+		"Left": signature(str, "Gets the first characters of the string.",
+			param("value", str), param("length", integer)),
+		"Right": signature(str, "Gets the last characters of the string.",
+			param("value", str), param("length", integer)),
+		"Trim": signature(str, "Removes whitespaces or the characters from both sides of the string.",
+			param("value", str), optional("chars", str)),
+		"LTrim": signature(str, "Removes whitespaces or the characters from the start of the string.",
+			param("value", str), optional("chars", str)),
+		"RTrim": signature(str, "Removes whitespaces or the characters from the end of the string.",
+			param("value", str), optional("chars", str)),
+		"Replace": signature(str, "Replaces all occurrences of the substring.",
+			param("value", str), param("oldValue", str), param("newValue", str)),
+		"Split": signature(array, "Splits the string by the separator.", param("value", str), param("separator", str)),
+		"Join": signature(str, "Joins array elements with the separator.",
+			param("values", array), optional("separator", str)),
+		"IndexOf": signature(integer, "Gets the index of the substring or -1 if it is not found.",
+			param("value", str), param("substring", str), optional("start", integer)),
+		"StartsWith": signature(boolean, "Checks if the string starts with the prefix.",
+			param("value", str), param("prefix", str)),
+		"EndsWith": signature(boolean, "Checks if the string ends with the suffix.",
+			param("value", str), param("suffix", str)),
+		"PadLeft": signature(str, "Pads the string from the left to the length.",
+			param("value", str), param("length", integer), optional("pad", str)),
+		"PadRight": signature(str, "Pads the string from the right to the length.",
+			param("value", str), param("length", integer), optional("pad", str)),
+		"Repeat":  signature(str, "Repeats the string.", param("value", str), param("count", integer)),
+		"Reverse": signature(str, "Reverses characters of the string.", param("value", str)),
+		"Format": signature(str, "Replaces {N} placeholders in the format string with the values.",
+			param("format", str), variadic("values", anything, true)),
+		"RegexMatch": signature(boolean, "Checks if the string matches the regular expression.",
+			param("value", str), param("pattern", str)),
+		"RegexReplace": signature(str, "Replaces all matches of the regular expression.",
+			param("value", str), param("pattern", str), param("replacement", str)),
+		"RegexExtract": signature(str, "Gets the first match of the regular expression or its group.",
+			param("value", str), param("pattern", str), optional("group", integer)),
+		"RegexSplit": signature(array, "Splits the string by the regular expression.",
+			param("value", str), param("pattern", str)),
+
+		// Arrays
+		"Array": signature(array, "Creates an array from the values.", variadic("values", anything, true)),
+		"Map": signature(array, "Transforms array elements with the lambda.",
+			param("values", array), param("lambda", lambda)),
+		"Filter": signature(array, "Gets array elements that match the predicate.",
+			param("values", array), param("predicate", lambda)),
+		"Reduce": signature(anything, "Accumulates array elements with the lambda starting from the initial value.",
+			param("values", array), param("lambda", lambda), optional("initial", anything)),
+		"Any": signature(boolean, "Checks if any of array elements matches the predicate.",
+			param("values", array), param("predicate", lambda)),
+		"All": signature(boolean, "Checks if all array elements match the predicate.",
+			param("values", array), param("predicate", lambda)),
+		"Find": signature(anything, "Gets the first array element that matches the predicate.",
+			param("values", array), param("predicate", lambda)),
+		"SortBy": signature(array, "Sorts array elements by keys calculated with the lambda.",
+			param("values", array), param("lambda", lambda)),
+		"GroupBy": signature(array, "Groups array elements by keys calculated with the lambda.",
+			param("values", array), param("lambda", lambda)),
+	}
+}
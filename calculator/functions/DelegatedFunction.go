@@ -1,6 +1,8 @@
 package functions
 
 import (
+	"context"
+
 	cconv "github.com/pip-services3-gox/pip-services3-commons-gox/convert"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
@@ -15,10 +17,24 @@ import (
 type FunctionCalculator func(parameters []*variants.Variant,
 	variantOperations variants.IVariantOperations) (*variants.Variant, error)
 
+// Defines a delegate to implement a function that receives the evaluation context
+//
+// Parameters:
+//   - ctx: The evaluation context.
+//   - parameters: A list with function parameters
+//   - variantOperations: A manager for variant operations.
+// Returns: A calculated function value.
+type ContextFunctionCalculator func(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (*variants.Variant, error)
+
 // Defines an interface for expression function.
 type DelegatedFunction struct {
-	name       string
-	calculator FunctionCalculator
+	name              string
+	calculator        FunctionCalculator
+	contextCalculator ContextFunctionCalculator
+	deterministic     bool
+	lazyEvaluation    LazyEvaluation
+	signature         *FunctionSignature
 }
 
 // Constructs this function class with specified parameters.
@@ -41,11 +57,103 @@ func NewDelegatedFunction(name string, calculator FunctionCalculator) *Delegated
 	return c
 }
 
+// Constructs a deterministic function class that always returns the same result
+// for the same parameters and has no side effects.
+//
+// Parameters:
+//   - name: The name of this function.
+//   - calculator: The function calculator delegate.
+func NewDeterministicDelegatedFunction(name string, calculator FunctionCalculator) *DelegatedFunction {
+	c := NewDelegatedFunction(name, calculator)
+	c.deterministic = true
+	return c
+}
+
+// Constructs a deterministic function class which parameters are evaluated only when they are needed.
+// The calculator delegate is called only when all parameters are already known.
+//
+// Parameters:
+//   - name: The name of this function.
+//   - lazyEvaluation: The way the function parameters are evaluated.
+//   - calculator: The function calculator delegate.
+func NewLazyDelegatedFunction(name string, lazyEvaluation LazyEvaluation,
+	calculator FunctionCalculator) *DelegatedFunction {
+	c := NewDeterministicDelegatedFunction(name, calculator)
+	c.lazyEvaluation = lazyEvaluation
+	return c
+}
+
+// Constructs this function class with a context aware calculator delegate.
+//
+// Parameters:
+//   - name: The name of this function.
+//   - calculator: The function calculator delegate that receives the evaluation context.
+func NewDelegatedContextFunction(name string, calculator ContextFunctionCalculator) *DelegatedFunction {
+	if name == "" {
+		panic("Name parameter cannot be empty.")
+	}
+	if calculator == nil {
+		panic("Calculator parameter cannot be nil.")
+	}
+
+	c := &DelegatedFunction{
+		name:              name,
+		contextCalculator: calculator,
+	}
+	return c
+}
+
+// Constructs a deterministic function class with a context aware calculator delegate.
+//
+// Parameters:
+//   - name: The name of this function.
+//   - calculator: The function calculator delegate that receives the evaluation context.
+func NewDeterministicDelegatedContextFunction(name string, calculator ContextFunctionCalculator) *DelegatedFunction {
+	c := NewDelegatedContextFunction(name, calculator)
+	c.deterministic = true
+	return c
+}
+
 // The function name.
 func (c *DelegatedFunction) Name() string {
 	return c.name
 }
 
+// Checks if the function always returns the same result for the same parameters.
+func (c *DelegatedFunction) Deterministic() bool {
+	return c.deterministic
+}
+
+// Gets how the function parameters are evaluated.
+func (c *DelegatedFunction) LazyEvaluation() LazyEvaluation {
+	return c.lazyEvaluation
+}
+
+// The function signature or nil if it is not set.
+func (c *DelegatedFunction) Signature() *FunctionSignature {
+	return c.signature
+}
+
+// Sets the function signature. The number of parameters is checked by the signature
+// before calculation. Pure signatures make the function deterministic
+// and signatures of deterministic functions are marked as pure.
+//
+// Parameters:
+//   - signature: The function signature or nil to remove it.
+func (c *DelegatedFunction) SetSignature(signature *FunctionSignature) {
+	if signature == nil {
+		c.signature = nil
+		return
+	}
+
+	value := *signature
+	if value.Pure {
+		c.deterministic = true
+	}
+	value.Pure = c.deterministic
+	c.signature = &value
+}
+
 // The function calculation method.
 //
 // Parameters:
@@ -54,18 +162,39 @@ func (c *DelegatedFunction) Name() string {
 // Returns: A calculated function result.
 func (c *DelegatedFunction) Calculate(parameters []*variants.Variant,
 	variantOperations variants.IVariantOperations) (*variants.Variant, error) {
-	var result *variants.Variant
-	var err error
+	return c.CalculateWithContext(context.Background(), parameters, variantOperations)
+}
+
+// The function calculation method that receives the evaluation context.
+//
+// Parameters:
+//   - ctx: The evaluation context.
+//   - parameters: A list with function parameters.
+//   - variantOperations: Variants operations manager.
+// Returns: A calculated function result.
+func (c *DelegatedFunction) CalculateWithContext(ctx context.Context, parameters []*variants.Variant,
+	variantOperations variants.IVariantOperations) (result *variants.Variant, err error) {
 
 	// Capture calculation error
 	defer func() {
 		if r := recover(); r != nil {
 			message := cconv.StringConverter.ToString(r)
+			result = nil
 			err = errors.NewExpressionError("", "CALC_FAILED", message, 0, 0)
 		}
 	}()
 
-	result, err = c.calculator(parameters, variantOperations)
+	if c.signature != nil {
+		if err = c.signature.CheckParamCount(len(parameters)); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.contextCalculator != nil {
+		result, err = c.contextCalculator(ctx, parameters, variantOperations)
+	} else {
+		result, err = c.calculator(parameters, variantOperations)
+	}
 
 	return result, err
 }
@@ -0,0 +1,235 @@
+package functions
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// FunctionParameter describes a parameter of expression function.
+type FunctionParameter struct {
+	// Name the parameter name.
+	Name string
+	// Type the parameter type or Null if values of any type are accepted.
+	Type variants.VariantType
+	// Optional true if the parameter can be omitted.
+	Optional bool
+	// Variadic true if the parameter can be repeated. It shall be the last parameter.
+	Variadic bool
+}
+
+// NewFunctionParameter creates a description of required parameter.
+//	Parameters:
+//		- name: The parameter name.
+//		- typ: The parameter type or Null if values of any type are accepted.
+//	Returns: The created parameter description.
+func NewFunctionParameter(name string, typ variants.VariantType) FunctionParameter {
+	return FunctionParameter{Name: name, Type: typ}
+}
+
+// NewOptionalFunctionParameter creates a description of parameter that can be omitted.
+//	Parameters:
+//		- name: The parameter name.
+//		- typ: The parameter type or Null if values of any type are accepted.
+//	Returns: The created parameter description.
+func NewOptionalFunctionParameter(name string, typ variants.VariantType) FunctionParameter {
+	return FunctionParameter{Name: name, Type: typ, Optional: true}
+}
+
+// NewVariadicFunctionParameter creates a description of parameter that can be repeated.
+// Variadic parameters are required once unless they are optional.
+//	Parameters:
+//		- name: The parameter name.
+//		- typ: The parameter type or Null if values of any type are accepted.
+//		- optional: True if the parameter can be omitted.
+//	Returns: The created parameter description.
+func NewVariadicFunctionParameter(name string, typ variants.VariantType, optional bool) FunctionParameter {
+	return FunctionParameter{Name: name, Type: typ, Optional: optional, Variadic: true}
+}
+
+// FunctionSignature describes parameters, result and behaviour of expression function.
+// Signatures are used to validate function calls and to document functions.
+// Numbers of parameters are checked when functions are called and also before evaluation
+// by analyzers, while parameter types are checked only by analyzers.
+type FunctionSignature struct {
+	// Parameters the function parameters. Optional parameters follow required ones.
+	Parameters []FunctionParameter
+	// ResultType the type of function result or Null if it depends on parameters.
+	ResultType variants.VariantType
+	// Description the human readable function description.
+	Description string
+	// Pure true if the function always returns the same result for the same parameters and has no side effects.
+	Pure bool
+}
+
+// NewFunctionSignature creates a function signature.
+//	Parameters:
+//		- resultType: The type of function result or Null if it depends on parameters.
+//		- description: The function description.
+//		- parameters: The function parameters.
+//	Returns: The created function signature.
+func NewFunctionSignature(resultType variants.VariantType, description string,
+	parameters ...FunctionParameter) *FunctionSignature {
+
+	return &FunctionSignature{
+		Parameters:  parameters,
+		ResultType:  resultType,
+		Description: description,
+	}
+}
+
+// MinParameters gets the minimum number of parameters.
+func (c *FunctionSignature) MinParameters() int {
+	result := 0
+	for _, parameter := range c.Parameters {
+		if !parameter.Optional {
+			result++
+		}
+	}
+	return result
+}
+
+// MaxParameters gets the maximum number of parameters or -1 if it is unlimited.
+func (c *FunctionSignature) MaxParameters() int {
+	count := len(c.Parameters)
+	if count > 0 && c.Parameters[count-1].Variadic {
+		return -1
+	}
+	return count
+}
+
+// ParameterType gets the type of parameter at the specified index.
+// Parameters after the last one get the type of variadic parameter.
+//	Parameters:
+//		- index: The index of the parameter.
+//	Returns: The parameter type or Null if any type is accepted.
+func (c *FunctionSignature) ParameterType(index int) variants.VariantType {
+	if len(c.Parameters) == 0 {
+		return variants.Null
+	}
+	if index >= len(c.Parameters) {
+		index = len(c.Parameters) - 1
+	}
+	return c.Parameters[index].Type
+}
+
+// CheckParamCount checks if the number of parameters matches this signature.
+//	Parameters:
+//		- paramCount: The number of function parameters.
+//	Returns: WRONG_PARAM_COUNT error or nil if the number is correct.
+func (c *FunctionSignature) CheckParamCount(paramCount int) error {
+	return checkParamCountInRange(paramCount, c.MinParameters(), c.MaxParameters())
+}
+
+// Format gets the function declaration like <code>Round(value: Double, digits?: Integer): Double</code>.
+// Optional parameters are marked with '?' and variadic parameters with '...'.
+//	Parameters:
+//		- name: The function name.
+//	Returns: The function declaration.
+func (c *FunctionSignature) Format(name string) string {
+	builder := strings.Builder{}
+	builder.WriteString(name + "(")
+	for index, parameter := range c.Parameters {
+		if index > 0 {
+			builder.WriteString(", ")
+		}
+		if parameter.Variadic {
+			builder.WriteString("...")
+		}
+		builder.WriteString(parameter.Name)
+		if parameter.Optional {
+			builder.WriteString("?")
+		}
+		builder.WriteString(": " + typeToString(parameter.Type))
+	}
+	builder.WriteString("): " + typeToString(c.ResultType))
+	return builder.String()
+}
+
+// FunctionDoc describes a registered function for documentation tools.
+type FunctionDoc struct {
+	// Name the function name.
+	Name string
+	// Signature the function signature or nil if the function does not describe it.
+	Signature *FunctionSignature
+}
+
+// String gets the function declaration followed by its description.
+func (c *FunctionDoc) String() string {
+	if c.Signature == nil {
+		return c.Name + "(...)"
+	}
+	result := c.Signature.Format(c.Name)
+	if c.Signature.Description != "" {
+		result += " - " + c.Signature.Description
+	}
+	return result
+}
+
+// GetFunctionSignature gets the signature of function.
+//	Parameters:
+//		- function: The function to be described.
+//	Returns: The function signature or nil if the function does not implement ISignatureFunction.
+func GetFunctionSignature(function IFunction) *FunctionSignature {
+	if signatureFunction, ok := function.(ISignatureFunction); ok {
+		return signatureFunction.Signature()
+	}
+	return nil
+}
+
+// GetFunctionDocs gets descriptions of functions ordered by names.
+// When several functions have the same name only the first one is described,
+// the same way as it is found by FindByName.
+//	Parameters:
+//		- funcs: The list of functions.
+//	Returns: The function descriptions.
+func GetFunctionDocs(funcs IFunctionCollection) []*FunctionDoc {
+	result := []*FunctionDoc{}
+	names := map[string]bool{}
+	for _, function := range funcs.GetAll() {
+		name := strings.ToUpper(function.Name())
+		if names[name] {
+			continue
+		}
+		names[name] = true
+		result = append(result, &FunctionDoc{
+			Name:      function.Name(),
+			Signature: GetFunctionSignature(function),
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return strings.ToUpper(result[i].Name) < strings.ToUpper(result[j].Name)
+	})
+	return result
+}
+
+func typeToString(typ variants.VariantType) string {
+	switch typ {
+	case variants.Integer:
+		return "Integer"
+	case variants.Long:
+		return "Long"
+	case variants.Float:
+		return "Float"
+	case variants.Double:
+		return "Double"
+	case variants.Decimal:
+		return "Decimal"
+	case variants.BigInteger:
+		return "BigInteger"
+	case variants.String:
+		return "String"
+	case variants.Boolean:
+		return "Boolean"
+	case variants.DateTime:
+		return "DateTime"
+	case variants.TimeSpan:
+		return "TimeSpan"
+	case variants.Object:
+		return "Object"
+	case variants.Array:
+		return "Array"
+	}
+	return "Any"
+}
@@ -0,0 +1,22 @@
+package functions
+
+import (
+	"context"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// IContextFunction defines an interface for expression function that receives
+// the evaluation context. Long running functions shall check the context
+// and stop when it is cancelled.
+type IContextFunction interface {
+	IFunction
+
+	// CalculateWithContext the function calculation method.
+	//	Parameters:
+	//		- ctx: The evaluation context.
+	//		- parameters: A list with function parameters.
+	//		- variantOperations: Variants operations manager.
+	CalculateWithContext(ctx context.Context, parameters []*variants.Variant,
+		variantOperations variants.IVariantOperations) (*variants.Variant, error)
+}
@@ -0,0 +1,12 @@
+package functions
+
+// IDeterministicFunction defines an interface for expression function that can tell
+// if it always returns the same result for the same parameters and has no side effects.
+// Calls of deterministic functions with constant parameters can be evaluated
+// once during expression optimization.
+type IDeterministicFunction interface {
+	IFunction
+
+	// Deterministic checks if the function always returns the same result for the same parameters.
+	Deterministic() bool
+}
@@ -0,0 +1,17 @@
+package functions
+
+import "github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+
+// ILambda defines an interface for lambda expressions like <code>x => x * 2</code>
+// passed to functions as Object variants.
+type ILambda interface {
+	// Parameters the names of lambda parameters.
+	Parameters() []string
+
+	// Invoke evaluates the lambda body with the specified parameter values.
+	// Missing parameters are set to null and extra parameters are ignored.
+	//	Parameters:
+	//		- parameters: A list with parameter values.
+	//	Returns: The evaluated lambda result.
+	Invoke(parameters []*variants.Variant) (*variants.Variant, error)
+}
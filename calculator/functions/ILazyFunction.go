@@ -0,0 +1,63 @@
+package functions
+
+// LazyEvaluation defines how parameters of expression function are evaluated.
+type LazyEvaluation int
+
+const (
+	// EagerEvaluation evaluates all parameters before the function is called.
+	EagerEvaluation LazyEvaluation = iota
+	// LazyCondition evaluates the first parameter as a condition and then
+	// only the second parameter when it is true or the third one otherwise.
+	LazyCondition
+	// LazyChoice evaluates the first parameter as an index starting from 1
+	// and then only the parameter with that index.
+	LazyChoice
+	// LazyCoalesce evaluates parameters one by one until a value that is not null is found.
+	LazyCoalesce
+)
+
+// ILazyFunction defines an interface for expression function which parameters
+// are evaluated only when they are needed. Calls of lazy functions are compiled
+// into conditional jumps when expressions are parsed, so the function itself is called
+// only when all parameters are already known, like in optimization of constant expressions.
+// Functions with the same name that are not lazy are called as usual, also when they replace
+// lazy functions only in the list of functions passed during evaluation.
+type ILazyFunction interface {
+	IFunction
+
+	// LazyEvaluation gets how the function parameters are evaluated.
+	LazyEvaluation() LazyEvaluation
+}
+
+// GetLazyEvaluation gets how parameters of the function call are evaluated.
+// Calls with wrong number of parameters are evaluated eagerly, so the function reports the error.
+//	Parameters:
+//		- function: The called function or nil if it is not found.
+//		- paramCount: The number of parameters in the call.
+//	Returns: The way the call parameters are evaluated.
+func GetLazyEvaluation(function IFunction, paramCount int) LazyEvaluation {
+	lazyFunction, ok := function.(ILazyFunction)
+	if !ok {
+		return EagerEvaluation
+	}
+	if signature := GetFunctionSignature(function); signature != nil && signature.CheckParamCount(paramCount) != nil {
+		return EagerEvaluation
+	}
+
+	evaluation := lazyFunction.LazyEvaluation()
+	switch evaluation {
+	case LazyCondition:
+		if paramCount == 3 {
+			return evaluation
+		}
+	case LazyChoice:
+		if paramCount >= 2 {
+			return evaluation
+		}
+	case LazyCoalesce:
+		if paramCount >= 1 {
+			return evaluation
+		}
+	}
+	return EagerEvaluation
+}
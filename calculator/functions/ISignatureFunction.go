@@ -0,0 +1,13 @@
+package functions
+
+// ISignatureFunction defines an interface for expression function that describes
+// its parameters, result, purpose and purity. Calls of such functions are validated by
+// TypeAnalyzer and DiagnosticAnalyzer before evaluation and their descriptions can be listed
+// by documentation tools. Analyzers check numbers and types of parameters, while during evaluation
+// only DelegatedFunction checks the number of parameters by its signature.
+type ISignatureFunction interface {
+	IFunction
+
+	// Signature gets the function signature.
+	Signature() *FunctionSignature
+}
@@ -0,0 +1,12 @@
+package functions
+
+// NullHandling defines how aggregate functions treat null values.
+type NullHandling int
+
+const (
+	// SkipNulls ignores null values, so they are not counted and do not change results.
+	SkipNulls NullHandling = iota
+
+	// RejectNulls fails the calculation when any of the values is null.
+	RejectNulls
+)
@@ -0,0 +1,91 @@
+package functions
+
+import (
+	"context"
+	"regexp"
+	"sync"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+)
+
+// DefaultRegexCacheSize is the maximum number of patterns kept in the cache by default.
+const DefaultRegexCacheSize = 100
+
+// RegexCache keeps compiled regular expressions, so patterns used in an expression
+// are not recompiled on every evaluation. When the cache is full it is cleared.
+// The cache is safe for concurrent use.
+type RegexCache struct {
+	lock     sync.RWMutex
+	patterns map[string]*regexp.Regexp
+	maxSize  int
+}
+
+// regexCacheKey is the key of regular expressions cache in the evaluation context.
+type regexCacheKey struct{}
+
+// NewRegexCache creates an empty cache of regular expressions.
+//	Parameters:
+//		- maxSize: The maximum number of cached patterns or 0 to use the default size.
+//	Returns: The created cache.
+func NewRegexCache(maxSize int) *RegexCache {
+	if maxSize <= 0 {
+		maxSize = DefaultRegexCacheSize
+	}
+	return &RegexCache{
+		patterns: map[string]*regexp.Regexp{},
+		maxSize:  maxSize,
+	}
+}
+
+// Compile gets the compiled regular expression from the cache or compiles and caches it.
+// The cache can be nil, then the pattern is compiled every time.
+//	Parameters:
+//		- pattern: The regular expression pattern.
+//	Returns: The compiled regular expression or INVALID_PATTERN error.
+func (c *RegexCache) Compile(pattern string) (*regexp.Regexp, error) {
+	if c != nil {
+		c.lock.RLock()
+		regex, ok := c.patterns[pattern]
+		c.lock.RUnlock()
+		if ok {
+			return regex, nil
+		}
+	}
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.NewExpressionError("", "INVALID_PATTERN",
+			"Invalid regular expression '"+pattern+"': "+err.Error(), 0, 0)
+	}
+
+	if c != nil {
+		c.lock.Lock()
+		if len(c.patterns) >= c.maxSize {
+			c.patterns = map[string]*regexp.Regexp{}
+		}
+		c.patterns[pattern] = regex
+		c.lock.Unlock()
+	}
+	return regex, nil
+}
+
+// ContextWithRegexCache creates a context that passes the cache of regular expressions to functions.
+//	Parameters:
+//		- ctx: The parent context.
+//		- cache: The cache of regular expressions.
+//	Returns: The context with the cache.
+func ContextWithRegexCache(ctx context.Context, cache *RegexCache) context.Context {
+	return context.WithValue(ctx, regexCacheKey{}, cache)
+}
+
+// RegexCacheFromContext gets the cache of regular expressions passed in the context.
+//	Parameters:
+//		- ctx: The evaluation context.
+//	Returns: The cache of regular expressions or nil if the context has no cache.
+func RegexCacheFromContext(ctx context.Context) *RegexCache {
+	if ctx == nil {
+		return nil
+	}
+	cache, _ := ctx.Value(regexCacheKey{}).(*RegexCache)
+	return cache
+}
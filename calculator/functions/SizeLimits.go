@@ -0,0 +1,92 @@
+package functions
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+)
+
+// MaxRepeatedStringLength is the maximum length (in characters) of strings built by
+// Repeat, PadLeft and PadRight functions when MaxStringLength limit is not set.
+const MaxRepeatedStringLength = 10000000
+
+// SizeLimits defines the maximum sizes of values produced by functions.
+// The limits are passed to functions in the evaluation context, so functions
+// can check sizes of their results before allocating them.
+// Zero value of any limit means that the limit is not applied.
+type SizeLimits struct {
+	// MaxArrayLength is the maximum length of produced arrays.
+	MaxArrayLength int
+
+	// MaxStringLength is the maximum length (in characters) of produced strings.
+	MaxStringLength int
+}
+
+// sizeLimitsKey is the key of size limits in the evaluation context.
+type sizeLimitsKey struct{}
+
+// ContextWithSizeLimits creates a context that passes the size limits to functions.
+//	Parameters:
+//		- ctx: The parent context.
+//		- limits: The size limits.
+//	Returns: The context with the limits.
+func ContextWithSizeLimits(ctx context.Context, limits SizeLimits) context.Context {
+	return context.WithValue(ctx, sizeLimitsKey{}, limits)
+}
+
+// SizeLimitsFromContext gets the size limits passed in the context.
+//	Parameters:
+//		- ctx: The evaluation context.
+//	Returns: The size limits or empty limits if the context has no limits.
+func SizeLimitsFromContext(ctx context.Context) SizeLimits {
+	if ctx == nil {
+		return SizeLimits{}
+	}
+	limits, _ := ctx.Value(sizeLimitsKey{}).(SizeLimits)
+	return limits
+}
+
+// CheckArrayLength checks the length of array before it is created.
+//	Parameters:
+//		- length: The length of array.
+//	Returns: ARRAY_LIMIT_EXCEEDED error or nil if the length is within the limit.
+func (c SizeLimits) CheckArrayLength(length int) error {
+	if c.MaxArrayLength > 0 && length > c.MaxArrayLength {
+		return errors.NewExpressionError("", "ARRAY_LIMIT_EXCEEDED",
+			"Array length exceeded the limit of "+strconv.Itoa(c.MaxArrayLength), 0, 0)
+	}
+	return nil
+}
+
+// CheckStringLength checks the length of string before it is created.
+//	Parameters:
+//		- length: The length of string in characters.
+//	Returns: STRING_LIMIT_EXCEEDED error or nil if the length is within the limit.
+func (c SizeLimits) CheckStringLength(length int) error {
+	if c.MaxStringLength > 0 && length > c.MaxStringLength {
+		return errors.NewExpressionError("", "STRING_LIMIT_EXCEEDED",
+			"String length exceeded the limit of "+strconv.Itoa(c.MaxStringLength), 0, 0)
+	}
+	return nil
+}
+
+// CheckRepeatedStringLength checks the length of string built by repeating a part before it is created.
+// When MaxStringLength is not set the length is limited by MaxRepeatedStringLength,
+// so a large count can not exhaust the memory.
+//	Parameters:
+//		- partLength: The length of repeated part in characters.
+//		- count: The number of repetitions.
+//	Returns: STRING_LIMIT_EXCEEDED error or nil if the length is within the limit.
+func (c SizeLimits) CheckRepeatedStringLength(partLength int, count int) error {
+	maxLength := c.MaxStringLength
+	if maxLength <= 0 {
+		maxLength = MaxRepeatedStringLength
+	}
+	// Compares by division to avoid overflow of the length
+	if partLength > 0 && count > maxLength/partLength {
+		return errors.NewExpressionError("", "STRING_LIMIT_EXCEEDED",
+			"String length exceeded the limit of "+strconv.Itoa(maxLength), 0, 0)
+	}
+	return nil
+}
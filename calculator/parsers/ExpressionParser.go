@@ -1,10 +1,15 @@
 package parsers
 
 import (
+	"math/big"
+	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/pip-services3-gox/pip-services3-commons-gox/convert"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
 	ctokenizers "github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/tokenizers"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/tokenizers"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
@@ -19,13 +24,35 @@ type ExpressionParser struct {
 	currentTokenIndex int
 	variableNames     []string
 	resultTokens      []*ExpressionToken
+	lambdaScopes      [][]string
+	nodes             []ast.Node
+	syntaxTree        ast.Node
+	functions         functions.IFunctionCollection
+	lazyCalls         []*LazyCall
+}
+
+// Standard functions used to find lazy functions when the parser has no functions set.
+var standardFunctions functions.IFunctionCollection
+var standardFunctionsOnce sync.Once
+
+// Defines operators of syntax tree nodes for operator token types.
+var operatorNodes map[int]ast.Operator = map[int]ast.Operator{
+	Plus: ast.Add, Minus: ast.Subtract, Star: ast.Multiply, Slash: ast.Divide,
+	Procent: ast.Modulo, Power: ast.Power, Equal: ast.Equal, NotEqual: ast.NotEqual,
+	More: ast.More, Less: ast.Less, EqualMore: ast.MoreEqual, EqualLess: ast.LessEqual,
+	ShiftLeft: ast.ShiftLeft, ShiftRight: ast.ShiftRight, And: ast.And, Or: ast.Or,
+	Xor: ast.Xor, In: ast.In, NotIn: ast.NotIn, Like: ast.Like, NotLike: ast.NotLike,
+	Matches: ast.Matches, NotMatches: ast.NotMatches, Coalesce: ast.Coalesce,
+	Unary: ast.Negate, Not: ast.Not, IsNull: ast.IsNull, IsNotNull: ast.IsNotNull,
 }
 
 // Defines a list of operators.
 var operators []string = []string{
 	"(", ")", "[", "]", "+", "-", "*", "/", "%", "^",
 	"=", "<>", "!=", ">", "<", ">=", "<=", "<<", ">>",
-	"AND", "OR", "XOR", "NOT", "IS", "IN", "NULL", "LIKE", ",",
+	"AND", "OR", "XOR", "NOT", "IS", "IN", "NULL", "LIKE", ",", ".",
+	"?", ":", "CASE", "WHEN", "THEN", "ELSE", "END", "=>",
+	"MATCHES", "~", "!~", "??",
 }
 
 // Defines a list of operator token types.
@@ -33,7 +60,9 @@ var operatorTypes []int = []int{
 	LeftBrace, RightBrace, LeftSquareBrace, RightSquareBrace,
 	Plus, Minus, Star, Slash, Procent, Power, Equal, NotEqual,
 	NotEqual, More, Less, EqualMore, EqualLess, ShiftLeft,
-	ShiftRight, And, Or, Xor, Not, Is, In, Null, Like, Comma,
+	ShiftRight, And, Or, Xor, Not, Is, In, Null, Like, Comma, Dot,
+	Question, Colon, Case, When, Then, Else, End, Arrow,
+	Matches, Matches, NotMatches, Coalesce,
 }
 
 func NewExpressionParser() *ExpressionParser {
@@ -43,6 +72,7 @@ func NewExpressionParser() *ExpressionParser {
 		initialTokens:  []*ExpressionToken{},
 		variableNames:  []string{},
 		resultTokens:   []*ExpressionToken{},
+		lazyCalls:      []*LazyCall{},
 	}
 	return c
 }
@@ -77,11 +107,37 @@ func (c *ExpressionParser) ResultTokens() []*ExpressionToken {
 	return c.resultTokens
 }
 
+// Gets the abstract syntax tree of the parsed expression or nil if the expression is empty.
+func (c *ExpressionParser) SyntaxTree() ast.Node {
+	return c.syntaxTree
+}
+
 // Gets the list of found variable names.
 func (c *ExpressionParser) VariableNames() []string {
 	return c.variableNames
 }
 
+// Gets the list of calls of lazy functions compiled into conditional jumps.
+func (c *ExpressionParser) LazyCalls() []*LazyCall {
+	return c.lazyCalls
+}
+
+// Gets the list of functions used to find lazy functions.
+// Calls of lazy functions like If, Choose and Coalesce are compiled into conditional jumps,
+// so only the needed parameters are evaluated. When no functions are set the standard functions are used.
+func (c *ExpressionParser) Functions() functions.IFunctionCollection {
+	return c.functions
+}
+
+// Sets the list of functions used to find lazy functions.
+// The functions are consulted during parsing, so changes made later do not affect parsed expressions.
+//
+// Parameters:
+//   - value: The list of functions or nil to use the standard functions.
+func (c *ExpressionParser) SetFunctions(value functions.IFunctionCollection) {
+	c.functions = value
+}
+
 // Sets a new expression string and parses it into internal byte code.
 //
 // Parameters:
@@ -108,6 +164,10 @@ func (c *ExpressionParser) Clear() {
 	c.resultTokens = []*ExpressionToken{}
 	c.currentTokenIndex = 0
 	c.variableNames = []string{}
+	c.lambdaScopes = [][]string{}
+	c.nodes = []ast.Node{}
+	c.syntaxTree = nil
+	c.lazyCalls = []*LazyCall{}
 }
 
 // Checks are there more tokens for processing.
@@ -161,6 +221,133 @@ func (c *ExpressionParser) addTokenToResult(typ int, value *variants.Variant, li
 	c.resultTokens = append(c.resultTokens, NewExpressionToken(typ, value, line, column))
 }
 
+// Adds an operator to the result list and combines its operands into a syntax tree node.
+//
+// Parameters:
+//   - typ: The type of the operator token.
+//   - line: The line number where the operator is.
+//   - column: The column number where the operator is.
+func (c *ExpressionParser) addOperatorToResult(typ int, line int, column int) {
+	c.addTokenToResult(typ, variants.Empty, line, column)
+
+	operator := operatorNodes[typ]
+	if typ == Unary || typ == Not || typ == IsNull || typ == IsNotNull {
+		operands := c.popNodes(1)
+		c.pushNode(ast.NewUnaryNode(operator, operands[0], line, column))
+	} else {
+		operands := c.popNodes(2)
+		c.pushNode(ast.NewBinaryNode(operator, operands[0], operands[1], line, column))
+	}
+}
+
+// Pushes a syntax tree node to the stack of parsed nodes.
+//
+// Parameters:
+//   - node: The node to be pushed.
+func (c *ExpressionParser) pushNode(node ast.Node) {
+	c.nodes = append(c.nodes, node)
+}
+
+// Pops syntax tree nodes from the stack of parsed nodes.
+//
+// Parameters:
+//   - count: The number of nodes to pop.
+// Returns: The popped nodes in the order they were pushed.
+func (c *ExpressionParser) popNodes(count int) []ast.Node {
+	result := make([]ast.Node, count)
+	copy(result, c.nodes[len(c.nodes)-count:])
+	c.nodes = c.nodes[:len(c.nodes)-count]
+	return result
+}
+
+// Adds a jump token with unknown offset to the result list.
+//
+// Parameters:
+//   - typ: The type of the jump token.
+//   - line: The line number where the token is.
+//   - column: The column number where the token is.
+// Returns: The index of the added token.
+func (c *ExpressionParser) addJumpToResult(typ int, line int, column int) int {
+	c.addTokenToResult(typ, variants.VariantFromInteger(0), line, column)
+	return len(c.resultTokens) - 1
+}
+
+// Sets the offset of the jump token to skip all tokens added after it.
+//
+// Parameters:
+//   - jumpIndex: The index of the jump token.
+func (c *ExpressionParser) completeJump(jumpIndex int) {
+	c.resultTokens[jumpIndex].value = variants.VariantFromInteger(len(c.resultTokens) - jumpIndex - 1)
+}
+
+// Adds a lazy evaluated condition to the result list.
+// Only one of the branches is evaluated depending on the condition.
+//
+// Parameters:
+//   - condition: The tokens of the condition.
+//   - trueBranch: The tokens evaluated when the condition is true.
+//   - falseBranch: The tokens evaluated when the condition is false.
+//   - line: The line number where the condition is.
+//   - column: The column number where the condition is.
+func (c *ExpressionParser) addConditionToResult(condition []*ExpressionToken,
+	trueBranch []*ExpressionToken, falseBranch []*ExpressionToken, line int, column int) {
+	c.resultTokens = append(c.resultTokens, condition...)
+	falseJump := c.addJumpToResult(JumpIfFalse, line, column)
+	c.resultTokens = append(c.resultTokens, trueBranch...)
+	endJump := c.addJumpToResult(Jump, line, column)
+	c.completeJump(falseJump)
+	c.resultTokens = append(c.resultTokens, falseBranch...)
+	c.completeJump(endJump)
+}
+
+// Adds a lazy evaluated choice to the result list.
+// Only the branch selected by the index is evaluated.
+// Each branch ends with a jump to the end of the choice.
+//
+// Parameters:
+//   - index: The tokens of the branch index (starting from 1).
+//   - branches: The tokens of the branches.
+//   - line: The line number where the choice is.
+//   - column: The column number where the choice is.
+func (c *ExpressionParser) addChoiceToResult(index []*ExpressionToken,
+	branches [][]*ExpressionToken, line int, column int) {
+	c.resultTokens = append(c.resultTokens, index...)
+	lengths := make([]*variants.Variant, len(branches))
+	for i, branch := range branches {
+		lengths[i] = variants.VariantFromInteger(len(branch) + 1)
+	}
+	c.addTokenToResult(JumpTable, variants.VariantFromArray(lengths), line, column)
+
+	for _, branch := range branches {
+		c.resultTokens = append(c.resultTokens, branch...)
+		c.addJumpToResult(Jump, line, column)
+	}
+
+	// Sets jumps at the end of each branch to skip the following branches.
+	position := len(c.resultTokens)
+	for i := len(branches) - 1; i >= 0; i-- {
+		c.resultTokens[position-1].value = variants.VariantFromInteger(len(c.resultTokens) - position)
+		position -= len(branches[i]) + 1
+	}
+}
+
+// Adds a lazy evaluated null-coalescing of values to the result list.
+// Values are evaluated until the first one that is not null.
+//
+// Parameters:
+//   - values: The tokens of the values.
+//   - line: The line number where the coalescing is.
+//   - column: The column number where the coalescing is.
+func (c *ExpressionParser) addCoalesceToResult(values [][]*ExpressionToken, line int, column int) {
+	c.resultTokens = append(c.resultTokens, values[0]...)
+	for _, value := range values[1:] {
+		jumpIndex := c.addJumpToResult(ShortCircuitCoalesce, line, column)
+		c.resultTokens = append(c.resultTokens, value...)
+		c.addTokenToResult(Coalesce, variants.Empty, line, column)
+		c.completeJump(jumpIndex)
+	}
+}
+
 // Matches available tokens types with types from the list.
 // If tokens matchs then shift the list.
 //
@@ -175,6 +362,8 @@ func (c *ExpressionParser) matchTokensWithTypes(types ...int) bool {
 			matches = c.initialTokens[c.currentTokenIndex+i].Type() == typ
 		} else {
 			matches = false
+		}
+		if !matches {
 			break
 		}
 	}
@@ -218,9 +407,17 @@ func (c *ExpressionParser) performParsing() error {
 			return err
 		}
 
+		if len(c.nodes) == 1 {
+			c.syntaxTree = c.nodes[0]
+		}
+
 		if c.hasMoreTokens() {
 			token := c.getCurrentToken()
-			err = errors.NewSyntaxError("", errors.ErrErrorNear, "Syntax error near "+token.Value().AsString(), token.Line(), token.Column())
+			message := "Syntax error"
+			if !token.Value().IsNull() {
+				message += " near " + token.Value().String()
+			}
+			err = errors.NewSyntaxError("", errors.ErrErrorNear, message, token.Line(), token.Column())
 			return err
 		}
 	}
@@ -266,7 +463,7 @@ func (c *ExpressionParser) completeLexicalAnalysis() error {
 		case tokenizers.Integer:
 			{
 				tokenType = Constant
-				tokenValue = variants.VariantFromInteger(convert.IntegerConverter.ToInteger(token.Value()))
+				tokenValue = parseIntegerConstant(token.Value())
 				break
 			}
 		case tokenizers.Float:
@@ -275,6 +472,17 @@ func (c *ExpressionParser) completeLexicalAnalysis() error {
 				tokenValue = variants.VariantFromFloat(convert.FloatConverter.ToFloat(token.Value()))
 				break
 			}
+		case tokenizers.Decimal:
+			{
+				value, err := variants.ParseDecimal(token.Value())
+				if err != nil {
+					return errors.NewSyntaxError("", errors.ErrUnknownSymbol,
+						"Invalid decimal number "+token.Value(), token.Line(), token.Column())
+				}
+				tokenType = Constant
+				tokenValue = variants.VariantFromDecimal(value)
+				break
+			}
 		case tokenizers.Quoted:
 			{
 				tokenType = Constant
@@ -305,13 +513,75 @@ func (c *ExpressionParser) completeLexicalAnalysis() error {
 	return nil
 }
 
-// Performs a syntax analysis at level 0.
+// Performs a syntax analysis of conditional operator "? :".
 func (c *ExpressionParser) performSyntaxAnalysis() error {
 	err := c.checkForMoreTokens()
 	if err != nil {
 		return err
 	}
 
+	resultTokens := c.resultTokens
+	c.resultTokens = []*ExpressionToken{}
+	err = c.performSyntaxAnalysisAtLevel0()
+	condition := c.resultTokens
+	c.resultTokens = resultTokens
+	if err != nil {
+		return err
+	}
+
+	token := c.getCurrentToken()
+	if token == nil || token.Type() != Question {
+		c.resultTokens = append(c.resultTokens, condition...)
+		return nil
+	}
+	c.moveToNextToken()
+
+	trueBranch, err := c.collectSyntaxAnalysis()
+	if err != nil {
+		return err
+	}
+
+	err = c.checkForMoreTokens()
+	if err != nil {
+		return err
+	}
+
+	colonToken := c.getCurrentToken()
+	if colonToken.Type() != Colon {
+		return errors.NewSyntaxError("", errors.ErrMissedColon, "Expected ':' was not found", colonToken.Line(), colonToken.Column())
+	}
+	c.moveToNextToken()
+
+	falseBranch, err := c.collectSyntaxAnalysis()
+	if err != nil {
+		return err
+	}
+
+	c.addConditionToResult(condition, trueBranch, falseBranch, token.Line(), token.Column())
+	nodes := c.popNodes(3)
+	c.pushNode(ast.NewConditionalNode(nodes[0], nodes[1], nodes[2], token.Line(), token.Column()))
+	return nil
+}
+
+// Performs a syntax analysis of a complete expression and collects its tokens separately from the result.
+//
+// Returns: The tokens of the analysed expression.
+func (c *ExpressionParser) collectSyntaxAnalysis() ([]*ExpressionToken, error) {
+	resultTokens := c.resultTokens
+	c.resultTokens = []*ExpressionToken{}
+	err := c.performSyntaxAnalysis()
+	tokens := c.resultTokens
+	c.resultTokens = resultTokens
+	return tokens, err
+}
+
+// Performs a syntax analysis at level 0.
+func (c *ExpressionParser) performSyntaxAnalysisAtLevel0() error {
+	err := c.checkForMoreTokens()
+	if err != nil {
+		return err
+	}
+
 	err = c.performSyntaxAnalysisAtLevel1()
 	if err != nil {
 		return err
@@ -319,15 +589,33 @@ func (c *ExpressionParser) performSyntaxAnalysis() error {
 
 	for c.hasMoreTokens() {
 		token := c.getCurrentToken()
-		if token.Type() == And || token.Type() == Or || token.Type() == Xor {
+		if token.Type() == And || token.Type() == Or {
 			c.moveToNextToken()
 
+			// Skips the second operand when the first one defines the result.
+			jumpType := ShortCircuitAnd
+			if token.Type() == Or {
+				jumpType = ShortCircuitOr
+			}
+			jumpIndex := c.addJumpToResult(jumpType, token.Line(), token.Column())
+
 			err = c.performSyntaxAnalysisAtLevel1()
 			if err != nil {
 				return err
 			}
 
-			c.addTokenToResult(token.Type(), variants.Empty, token.Line(), token.Column())
+			c.addOperatorToResult(token.Type(), token.Line(), token.Column())
+			c.completeJump(jumpIndex)
+			continue
+		} else if token.Type() == Xor {
+			c.moveToNextToken()
+
+			err = c.performSyntaxAnalysisAtLevel1()
+			if err != nil {
+				return err
+			}
+
+			c.addOperatorToResult(token.Type(), token.Line(), token.Column())
 			continue
 		}
 		break
@@ -352,7 +640,7 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel1() error {
 			return err
 		}
 
-		c.addTokenToResult(token.Type(), variants.Empty, token.Line(), token.Column())
+		c.addOperatorToResult(token.Type(), token.Line(), token.Column())
 	} else {
 		err = c.performSyntaxAnalysisAtLevel2()
 		if err != nil {
@@ -370,7 +658,7 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel2() error {
 		return err
 	}
 
-	err = c.performSyntaxAnalysisAtLevel3()
+	err = c.performCoalesceAnalysis()
 	if err != nil {
 		return err
 	}
@@ -381,12 +669,12 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel2() error {
 			token.Type() == Less || token.Type() == EqualMore || token.Type() == EqualLess {
 			c.moveToNextToken()
 
-			err = c.performSyntaxAnalysisAtLevel3()
+			err = c.performCoalesceAnalysis()
 			if err != nil {
 				return err
 			}
 
-			c.addTokenToResult(token.Type(), variants.Empty, token.Line(), token.Column())
+			c.addOperatorToResult(token.Type(), token.Line(), token.Column())
 			continue
 		}
 		break
@@ -395,6 +683,41 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel2() error {
 	return nil
 }
 
+// Performs a syntax analysis of null-coalescing operator "??".
+// The second operand is skipped when the first one is not null.
+// Like other operators it is not resolved through functions, so replacing Coalesce does not change it.
+func (c *ExpressionParser) performCoalesceAnalysis() error {
+	err := c.checkForMoreTokens()
+	if err != nil {
+		return err
+	}
+
+	err = c.performSyntaxAnalysisAtLevel3()
+	if err != nil {
+		return err
+	}
+
+	for c.hasMoreTokens() {
+		token := c.getCurrentToken()
+		if token.Type() != Coalesce {
+			break
+		}
+		c.moveToNextToken()
+
+		jumpIndex := c.addJumpToResult(ShortCircuitCoalesce, token.Line(), token.Column())
+
+		err = c.performSyntaxAnalysisAtLevel3()
+		if err != nil {
+			return err
+		}
+
+		c.addOperatorToResult(token.Type(), token.Line(), token.Column())
+		c.completeJump(jumpIndex)
+	}
+
+	return nil
+}
+
 // Performs a syntax analysis at level 3.
 func (c *ExpressionParser) performSyntaxAnalysisAtLevel3() error {
 	err := c.checkForMoreTokens()
@@ -417,25 +740,45 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel3() error {
 				return err
 			}
 
-			c.addTokenToResult(token.Type(), variants.Empty, token.Line(), token.Column())
+			c.addOperatorToResult(token.Type(), token.Line(), token.Column())
+		} else if token.Type() == Matches || token.Type() == NotMatches {
+			c.moveToNextToken()
+
+			err = c.performPatternAnalysis()
+			if err != nil {
+				return err
+			}
+
+			c.addOperatorToResult(token.Type(), token.Line(), token.Column())
+		} else if c.matchTokensWithTypes(Not, Matches) {
+			err = c.performPatternAnalysis()
+			if err != nil {
+				return err
+			}
+
+			c.addOperatorToResult(NotMatches, token.Line(), token.Column())
 		} else if c.matchTokensWithTypes(Not, Like) {
 			err = c.performSyntaxAnalysisAtLevel4()
 			if err != nil {
 				return err
 			}
 
-			c.addTokenToResult(NotLike, variants.Empty, token.Line(), token.Column())
+			c.addOperatorToResult(NotLike, token.Line(), token.Column())
 		} else if c.matchTokensWithTypes(Is, Null) {
-			c.addTokenToResult(IsNull, variants.Empty, token.Line(), token.Column())
+			c.addOperatorToResult(IsNull, token.Line(), token.Column())
 		} else if c.matchTokensWithTypes(Is, Not, Null) {
-			c.addTokenToResult(IsNotNull, variants.Empty, token.Line(), token.Column())
+			c.addOperatorToResult(IsNotNull, token.Line(), token.Column())
 		} else if c.matchTokensWithTypes(Not, In) {
-			err = c.performSyntaxAnalysisAtLevel4()
+			if c.isInList() {
+				err = c.performInListAnalysis()
+			} else {
+				err = c.performSyntaxAnalysisAtLevel4()
+			}
 			if err != nil {
 				return err
 			}
 
-			c.addTokenToResult(NotIn, variants.Empty, token.Line(), token.Column())
+			c.addOperatorToResult(NotIn, token.Line(), token.Column())
 		} else {
 			break
 		}
@@ -444,6 +787,33 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel3() error {
 	return nil
 }
 
+// Performs a syntax analysis of regular expression pattern.
+// Constant patterns are validated, so invalid patterns are reported before evaluation.
+func (c *ExpressionParser) performPatternAnalysis() error {
+	err := c.checkForMoreTokens()
+	if err != nil {
+		return err
+	}
+
+	token := c.getCurrentToken()
+	start := len(c.resultTokens)
+	err = c.performSyntaxAnalysisAtLevel4()
+	if err != nil {
+		return err
+	}
+
+	if len(c.resultTokens) == start+1 && c.resultTokens[start].Type() == Constant &&
+		c.resultTokens[start].Value().Type() == variants.String {
+		pattern := c.resultTokens[start].Value().AsString()
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errors.NewSyntaxError("", errors.ErrInvalidPattern,
+				"Invalid regular expression '"+pattern+"': "+err.Error(), token.Line(), token.Column())
+		}
+	}
+
+	return nil
+}
+
 // Performs a syntax analysis at level 4.
 func (c *ExpressionParser) performSyntaxAnalysisAtLevel4() error {
 	err := c.checkForMoreTokens()
@@ -466,7 +836,7 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel4() error {
 				return err
 			}
 
-			c.addTokenToResult(token.Type(), variants.Empty, token.Line(), token.Column())
+			c.addOperatorToResult(token.Type(), token.Line(), token.Column())
 			continue
 		}
 		break
@@ -493,12 +863,16 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel5() error {
 			token.Type() == ShiftLeft || token.Type() == ShiftRight {
 			c.moveToNextToken()
 
-			err = c.performSyntaxAnalysisAtLevel6()
+			if token.Type() == In && c.isInList() {
+				err = c.performInListAnalysis()
+			} else {
+				err = c.performSyntaxAnalysisAtLevel6()
+			}
 			if err != nil {
 				return err
 			}
 
-			c.addTokenToResult(token.Type(), variants.Empty, token.Line(), token.Column())
+			c.addOperatorToResult(token.Type(), token.Line(), token.Column())
 			continue
 		}
 		break
@@ -507,6 +881,79 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel5() error {
 	return nil
 }
 
+// Checks if the current tokens are a list of values in parentheses
+// like <code>(1, 2, 3)</code> or <code>()</code>.
+// A single value in parentheses is not a list.
+func (c *ExpressionParser) isInList() bool {
+	token := c.getCurrentToken()
+	if token == nil || token.Type() != LeftBrace {
+		return false
+	}
+
+	depth := 0
+	for index := c.currentTokenIndex; index < len(c.initialTokens); index++ {
+		switch c.initialTokens[index].Type() {
+		case LeftBrace, LeftSquareBrace:
+			depth++
+		case RightBrace, RightSquareBrace:
+			depth--
+			if depth == 0 {
+				return index == c.currentTokenIndex+1
+			}
+		case Comma:
+			if depth == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Performs a syntax analysis of the list of values in parentheses after IN operator
+// like <code>a IN (1, 2, 3)</code>. The list is compiled as a call of Array function.
+func (c *ExpressionParser) performInListAnalysis() error {
+	listToken := c.getCurrentToken()
+	count := 0
+
+	for true {
+		c.moveToNextToken()
+		token := c.getCurrentToken()
+		if token == nil || token.Type() == RightBrace {
+			break
+		}
+
+		err := c.performSyntaxAnalysis()
+		if err != nil {
+			return err
+		}
+		count++
+
+		token = c.getCurrentToken()
+		if token == nil || token.Type() != Comma {
+			break
+		}
+	}
+
+	err := c.checkForMoreTokens()
+	if err != nil {
+		return err
+	}
+
+	token := c.getCurrentToken()
+	if token.Type() != RightBrace {
+		err = errors.NewSyntaxError("", errors.ErrMissedCloseParenthesis, "Expected ')' was not found", token.Line(), token.Column())
+		return err
+	}
+	c.moveToNextToken()
+
+	nodes := c.popNodes(count)
+	c.pushNode(ast.NewFunctionNode("Array", nodes, listToken.Line(), listToken.Column()))
+
+	c.addTokenToResult(Constant, variants.VariantFromInteger(count), listToken.Line(), listToken.Column())
+	c.addTokenToResult(Function, variants.VariantFromString("Array"), listToken.Line(), listToken.Column())
+	return nil
+}
+
 // Performs a syntax analysis at level 6.
 func (c *ExpressionParser) performSyntaxAnalysisAtLevel6() error {
 	err := c.checkForMoreTokens()
@@ -531,6 +978,22 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel6() error {
 		return err
 	}
 
+	// Identify lambda expressions.
+	if parameters, ok := c.matchLambdaParameters(); ok {
+		primitiveToken := c.getCurrentToken()
+		c.moveToNextToken()
+
+		err = c.performLambdaAnalysis(parameters, primitiveToken)
+		if err != nil {
+			return err
+		}
+
+		if unaryToken != nil {
+			c.addOperatorToResult(unaryToken.Type(), unaryToken.Line(), unaryToken.Column())
+		}
+		return nil
+	}
+
 	// Identify function calls.
 	primitiveToken := c.getCurrentToken()
 	nextToken := c.getNextToken()
@@ -538,15 +1001,20 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel6() error {
 		nextToken != nil && nextToken.Type() == LeftBrace {
 		primitiveToken = NewExpressionToken(Function, primitiveToken.Value(), primitiveToken.Line(), primitiveToken.Column())
 	}
+	// NULL outside of IS NULL is a null constant.
+	if primitiveToken.Type() == Null {
+		primitiveToken = NewExpressionToken(Constant, variants.EmptyVariant(), primitiveToken.Line(), primitiveToken.Column())
+	}
 
 	if primitiveToken.Type() == Constant {
 		c.moveToNextToken()
 		c.addTokenToResult(primitiveToken.Type(), primitiveToken.Value(), primitiveToken.Line(), primitiveToken.Column())
+		c.pushNode(ast.NewConstantNode(primitiveToken.Value(), primitiveToken.Line(), primitiveToken.Column()))
 	} else if primitiveToken.Type() == Variable {
 		c.moveToNextToken()
 
 		temp := primitiveToken.Value().AsString()
-		found := false
+		found := c.isLambdaParameter(temp)
 		for _, v := range c.variableNames {
 			if temp == v {
 				found = true
@@ -558,6 +1026,7 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel6() error {
 		}
 
 		c.addTokenToResult(primitiveToken.Type(), primitiveToken.Value(), primitiveToken.Line(), primitiveToken.Column())
+		c.pushNode(ast.NewVariableNode(temp, primitiveToken.Line(), primitiveToken.Column()))
 	} else if primitiveToken.Type() == LeftBrace {
 		c.moveToNextToken()
 
@@ -599,7 +1068,7 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel6() error {
 			return err
 		}
 
-		paramCount := 0
+		parameters := [][]*ExpressionToken{}
 		for true {
 			c.moveToNextToken()
 			token = c.getCurrentToken()
@@ -607,12 +1076,12 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel6() error {
 				break
 			}
 
-			paramCount = paramCount + 1
-
-			err = c.performSyntaxAnalysis()
+			// Collects tokens of each parameter separately.
+			parameter, err := c.collectSyntaxAnalysis()
 			if err != nil {
 				return err
 			}
+			parameters = append(parameters, parameter)
 
 			token = c.getCurrentToken()
 
@@ -633,24 +1102,82 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel6() error {
 
 		c.moveToNextToken()
 
-		c.addTokenToResult(Constant, variants.VariantFromInteger(paramCount), primitiveToken.Line(), primitiveToken.Column())
-		c.addTokenToResult(primitiveToken.Type(), primitiveToken.Value(), primitiveToken.Line(), primitiveToken.Column())
+		nodes := c.popNodes(len(parameters))
+		c.pushNode(ast.NewFunctionNode(primitiveToken.Value().AsString(), nodes, primitiveToken.Line(), primitiveToken.Column()))
+
+		name := primitiveToken.Value().AsString()
+		evaluation := c.getLazyEvaluation(name, len(parameters))
+		if evaluation != functions.EagerEvaluation {
+			c.lazyCalls = append(c.lazyCalls, &LazyCall{Name: name, ParamCount: len(parameters), Evaluation: evaluation})
+		}
+		if evaluation == functions.LazyCondition {
+			c.addConditionToResult(parameters[0], parameters[1], parameters[2], primitiveToken.Line(), primitiveToken.Column())
+		} else if evaluation == functions.LazyChoice {
+			c.addChoiceToResult(parameters[0], parameters[1:], primitiveToken.Line(), primitiveToken.Column())
+		} else if evaluation == functions.LazyCoalesce {
+			c.addCoalesceToResult(parameters, primitiveToken.Line(), primitiveToken.Column())
+		} else {
+			for _, parameter := range parameters {
+				c.resultTokens = append(c.resultTokens, parameter...)
+			}
+			c.addTokenToResult(Constant, variants.VariantFromInteger(len(parameters)), primitiveToken.Line(), primitiveToken.Column())
+			c.addTokenToResult(primitiveToken.Type(), primitiveToken.Value(), primitiveToken.Line(), primitiveToken.Column())
+		}
+	} else if primitiveToken.Type() == Case {
+		c.moveToNextToken()
+
+		err = c.performCaseAnalysis(primitiveToken)
+		if err != nil {
+			return err
+		}
 	} else {
-		err = errors.NewSyntaxError("", errors.ErrErrorAt, "Syntax error at "+primitiveToken.Value().AsString(), primitiveToken.Line(), primitiveToken.Column())
+		message := "Syntax error"
+		if !primitiveToken.Value().IsNull() {
+			message += " at " + primitiveToken.Value().String()
+		}
+		err = errors.NewSyntaxError("", errors.ErrErrorAt, message, primitiveToken.Line(), primitiveToken.Column())
+		return err
+	}
+
+	// Process [] and . operators.
+	err = c.performPostfixAnalysis()
+	if err != nil {
 		return err
 	}
 
 	if unaryToken != nil {
-		c.addTokenToResult(unaryToken.Type(), variants.Empty, unaryToken.Line(), unaryToken.Column())
+		c.addOperatorToResult(unaryToken.Type(), unaryToken.Line(), unaryToken.Column())
 	}
 
-	// Process [] operator.
-	if c.hasMoreTokens() {
-		primitiveToken = c.getCurrentToken()
-		if primitiveToken.Type() == LeftSquareBrace {
+	return nil
+}
+
+// Gets how parameters of the function call are evaluated.
+//
+// Parameters:
+//   - name: The function name.
+//   - paramCount: The number of parameters in the call.
+// Returns: The way the call parameters are evaluated.
+func (c *ExpressionParser) getLazyEvaluation(name string, paramCount int) functions.LazyEvaluation {
+	funcs := c.functions
+	if funcs == nil {
+		standardFunctionsOnce.Do(func() {
+			standardFunctions = functions.NewDefaultFunctionCollection()
+		})
+		funcs = standardFunctions
+	}
+	return functions.GetLazyEvaluation(funcs.FindByName(name), paramCount)
+}
+
+// Performs a syntax analysis of element [] and member . operators.
+func (c *ExpressionParser) performPostfixAnalysis() error {
+	for c.hasMoreTokens() {
+		token := c.getCurrentToken()
+
+		if token.Type() == LeftSquareBrace {
 			c.moveToNextToken()
 
-			err = c.performSyntaxAnalysis()
+			err := c.performSyntaxAnalysis()
 			if err != nil {
 				return err
 			}
@@ -660,15 +1187,257 @@ func (c *ExpressionParser) performSyntaxAnalysisAtLevel6() error {
 				return err
 			}
 
-			primitiveToken := c.getCurrentToken()
-			if primitiveToken.Type() != RightSquareBrace {
-				err = errors.NewSyntaxError("", errors.ErrMissedCloseSquareBracket, "Expected ']' was not found", primitiveToken.Line(), primitiveToken.Column())
+			closeToken := c.getCurrentToken()
+			if closeToken.Type() != RightSquareBrace {
+				return errors.NewSyntaxError("", errors.ErrMissedCloseSquareBracket, "Expected ']' was not found", closeToken.Line(), closeToken.Column())
 			}
 
 			c.moveToNextToken()
-			c.addTokenToResult(Element, variants.Empty, 0, 0)
+			c.addTokenToResult(Element, variants.Empty, token.Line(), token.Column())
+			nodes := c.popNodes(2)
+			c.pushNode(ast.NewElementNode(nodes[0], nodes[1], token.Line(), token.Column()))
+		} else if token.Type() == Dot {
+			c.moveToNextToken()
+
+			err := c.checkForMoreTokens()
+			if err != nil {
+				return err
+			}
+
+			memberToken := c.getCurrentToken()
+			if memberToken.Type() != Variable {
+				return errors.NewSyntaxError("", errors.ErrMissedMemberName, "Expected member name after '.'", memberToken.Line(), memberToken.Column())
+			}
+
+			c.moveToNextToken()
+			c.addTokenToResult(Constant, memberToken.Value(), memberToken.Line(), memberToken.Column())
+			c.addTokenToResult(Member, variants.Empty, token.Line(), token.Column())
+			nodes := c.popNodes(1)
+			c.pushNode(ast.NewMemberNode(nodes[0], memberToken.Value().AsString(), token.Line(), token.Column()))
+		} else {
+			break
 		}
 	}
 
 	return nil
 }
+
+// Performs a syntax analysis of CASE expression in searched form
+// "CASE WHEN cond THEN value ... ELSE value END"
+// or in simple form "CASE expr WHEN value THEN value ... ELSE value END".
+// Conditions are checked in order and only the selected value is evaluated.
+// Without ELSE the expression returns NULL.
+//
+// Parameters:
+//   - caseToken: The CASE token.
+func (c *ExpressionParser) performCaseAnalysis(caseToken *ExpressionToken) error {
+	err := c.checkForMoreTokens()
+	if err != nil {
+		return err
+	}
+
+	// In the simple form the selector stays on the stack and is duplicated for each comparison.
+	simple := c.getCurrentToken().Type() != When
+	if simple {
+		err = c.performSyntaxAnalysis()
+		if err != nil {
+			return err
+		}
+	}
+
+	endJumps := []int{}
+	for c.hasMoreTokens() && c.getCurrentToken().Type() == When {
+		whenToken := c.getCurrentToken()
+		c.moveToNextToken()
+
+		if simple {
+			c.addTokenToResult(Duplicate, variants.Empty, whenToken.Line(), whenToken.Column())
+		}
+		err = c.performSyntaxAnalysis()
+		if err != nil {
+			return err
+		}
+		if simple {
+			c.addTokenToResult(Equal, variants.Empty, whenToken.Line(), whenToken.Column())
+		}
+
+		err = c.checkForMoreTokens()
+		if err != nil {
+			return err
+		}
+		thenToken := c.getCurrentToken()
+		if thenToken.Type() != Then {
+			return errors.NewSyntaxError("", errors.ErrMissedThen, "Expected THEN was not found", thenToken.Line(), thenToken.Column())
+		}
+		c.moveToNextToken()
+
+		falseJump := c.addJumpToResult(JumpIfFalse, whenToken.Line(), whenToken.Column())
+		if simple {
+			c.addTokenToResult(Pop, variants.Empty, whenToken.Line(), whenToken.Column())
+		}
+		err = c.performSyntaxAnalysis()
+		if err != nil {
+			return err
+		}
+		endJumps = append(endJumps, c.addJumpToResult(Jump, whenToken.Line(), whenToken.Column()))
+		c.completeJump(falseJump)
+	}
+
+	if len(endJumps) == 0 {
+		err = c.checkForMoreTokens()
+		if err != nil {
+			return err
+		}
+		token := c.getCurrentToken()
+		return errors.NewSyntaxError("", errors.ErrMissedWhen, "Expected WHEN was not found", token.Line(), token.Column())
+	}
+
+	if simple {
+		c.addTokenToResult(Pop, variants.Empty, caseToken.Line(), caseToken.Column())
+	}
+
+	hasElse := c.hasMoreTokens() && c.getCurrentToken().Type() == Else
+	if hasElse {
+		c.moveToNextToken()
+		err = c.performSyntaxAnalysis()
+		if err != nil {
+			return err
+		}
+	} else {
+		c.addTokenToResult(Constant, variants.Empty, caseToken.Line(), caseToken.Column())
+	}
+
+	err = c.checkForMoreTokens()
+	if err != nil {
+		return err
+	}
+	endToken := c.getCurrentToken()
+	if endToken.Type() != End {
+		return errors.NewSyntaxError("", errors.ErrMissedEnd, "Expected END was not found", endToken.Line(), endToken.Column())
+	}
+	c.moveToNextToken()
+
+	for _, endJump := range endJumps {
+		c.completeJump(endJump)
+	}
+
+	var elseNode ast.Node
+	if hasElse {
+		elseNode = c.popNodes(1)[0]
+	}
+	nodes := c.popNodes(2 * len(endJumps))
+	whens := make([]*ast.WhenClause, len(endJumps))
+	for i := range whens {
+		whens[i] = &ast.WhenClause{When: nodes[2*i], Then: nodes[2*i+1]}
+	}
+	var selectorNode ast.Node
+	if simple {
+		selectorNode = c.popNodes(1)[0]
+	}
+	c.pushNode(ast.NewCaseNode(selectorNode, whens, elseNode, caseToken.Line(), caseToken.Column()))
+
+	return nil
+}
+
+// Checks if the name is a parameter of one of the lambda expressions being parsed.
+//
+// Parameters:
+//   - name: The variable name to check.
+// Returns: <code>true</code> if the name refers to a lambda parameter.
+func (c *ExpressionParser) isLambdaParameter(name string) bool {
+	for _, scope := range c.lambdaScopes {
+		for _, parameter := range scope {
+			if strings.EqualFold(parameter, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Matches lambda parameters in forms "x =>", "(x, y) =>" or "() =>".
+// If tokens match then shift the list to the "=>" token.
+//
+// Returns: The lambda parameter names and <code>true</code> if tokens match.
+func (c *ExpressionParser) matchLambdaParameters() ([]string, bool) {
+	index := c.currentTokenIndex
+	parameters := []string{}
+
+	if index+1 < len(c.initialTokens) && c.initialTokens[index].Type() == Variable &&
+		c.initialTokens[index+1].Type() == Arrow {
+		parameters = append(parameters, c.initialTokens[index].Value().AsString())
+		c.currentTokenIndex = index + 1
+		return parameters, true
+	}
+
+	if index >= len(c.initialTokens) || c.initialTokens[index].Type() != LeftBrace {
+		return nil, false
+	}
+	index++
+
+	for index < len(c.initialTokens) && c.initialTokens[index].Type() == Variable {
+		parameters = append(parameters, c.initialTokens[index].Value().AsString())
+		index++
+		if index < len(c.initialTokens) && c.initialTokens[index].Type() == Comma {
+			index++
+			continue
+		}
+		break
+	}
+
+	if index+1 < len(c.initialTokens) && c.initialTokens[index].Type() == RightBrace &&
+		c.initialTokens[index+1].Type() == Arrow {
+		c.currentTokenIndex = index + 1
+		return parameters, true
+	}
+	return nil, false
+}
+
+// Performs a syntax analysis of lambda body after "=>" token.
+// The lambda is added to the result list as a Lambda token followed by the body tokens.
+// The token value is an array with the parameter names array and the number of body tokens.
+// Parameters of the lambda shadow outer variables inside the body.
+//
+// Parameters:
+//   - parameters: The names of lambda parameters.
+//   - arrowToken: The "=>" token.
+func (c *ExpressionParser) performLambdaAnalysis(parameters []string, arrowToken *ExpressionToken) error {
+	c.lambdaScopes = append(c.lambdaScopes, parameters)
+	body, err := c.collectSyntaxAnalysis()
+	c.lambdaScopes = c.lambdaScopes[:len(c.lambdaScopes)-1]
+	if err != nil {
+		return err
+	}
+
+	names := make([]*variants.Variant, len(parameters))
+	for i, parameter := range parameters {
+		names[i] = variants.VariantFromString(parameter)
+	}
+	value := variants.VariantFromArray([]*variants.Variant{
+		variants.VariantFromArray(names),
+		variants.VariantFromInteger(len(body)),
+	})
+
+	c.addTokenToResult(Lambda, value, arrowToken.Line(), arrowToken.Column())
+	c.resultTokens = append(c.resultTokens, body...)
+
+	nodes := c.popNodes(1)
+	c.pushNode(ast.NewLambdaNode(parameters, nodes[0], arrowToken.Line(), arrowToken.Column()))
+	return nil
+}
+
+// parseIntegerConstant converts integer literals into the smallest type that keeps the value:
+// Integer, Long or BigInteger.
+func parseIntegerConstant(value string) *variants.Variant {
+	number, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return variants.VariantFromInteger(convert.IntegerConverter.ToInteger(value))
+	}
+	if !number.IsInt64() {
+		return variants.VariantFromBigInteger(number)
+	}
+	if int64(int(number.Int64())) != number.Int64() {
+		return variants.VariantFromLong(number.Int64())
+	}
+	return variants.VariantFromInteger(int(number.Int64()))
+}
@@ -39,4 +39,26 @@ const (
 	Function
 	Variable
 	Constant
+	Dot
+	Member
+	Jump
+	JumpIfFalse
+	JumpTable
+	ShortCircuitAnd
+	ShortCircuitOr
+	Question
+	Colon
+	Case
+	When
+	Then
+	Else
+	End
+	Duplicate
+	Pop
+	Arrow
+	Lambda
+	Matches
+	NotMatches
+	Coalesce
+	ShortCircuitCoalesce
 )
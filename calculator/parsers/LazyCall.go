@@ -0,0 +1,23 @@
+package parsers
+
+import "github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+
+// LazyCall describes a call of lazy function that is compiled into conditional jumps.
+// The jumps do not call the function, so the call shall be compiled again
+// when the function is replaced by a function that is evaluated differently.
+type LazyCall struct {
+	// Name the function name.
+	Name string
+	// ParamCount the number of parameters in the call.
+	ParamCount int
+	// Evaluation the way the call parameters are evaluated.
+	Evaluation functions.LazyEvaluation
+}
+
+// Matches checks if the call is compiled the same way for the list of functions.
+//	Parameters:
+//		- funcs: The list of functions to resolve the call.
+//	Returns: <code>true</code> if the called function is evaluated the same way.
+func (c *LazyCall) Matches(funcs functions.IFunctionCollection) bool {
+	return functions.GetLazyEvaluation(funcs.FindByName(c.Name), c.ParamCount) == c.Evaluation
+}
@@ -0,0 +1,252 @@
+package scripts
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// CompiledScript implements an immutable parsed script.
+// Like CompiledExpression it keeps no mutable state between executions,
+// so the same compiled script can be evaluated concurrently from multiple goroutines
+// with different sets of variables.
+type CompiledScript struct {
+	statements        []scriptStatement
+	expressions       []*calculator.CompiledExpression
+	definitions       []*calculator.UserFunction
+	variableNames     []string
+	variantOperations variants.IVariantOperations
+	defaultFunctions  functions.IFunctionCollection
+	limits            ScriptLimits
+}
+
+// NewCompiledScript parses the script string and creates a compiled script.
+//	Parameters:
+//		- script: The script string.
+//		- variantOperations: The manager for operations on variant values or nil to use type unsafe operations.
+//		- defaultFunctions: The list of functions used when no functions are set during evaluation
+//			or nil to use the standard functions.
+//	Returns: A compiled script or error if script has syntax errors.
+func NewCompiledScript(script string, variantOperations variants.IVariantOperations,
+	defaultFunctions functions.IFunctionCollection) (*CompiledScript, error) {
+
+	parser := NewScriptParser()
+	err := parser.ParseString(script)
+	if err != nil {
+		return nil, err
+	}
+	return newCompiledScript(parser, variantOperations, defaultFunctions), nil
+}
+
+// CompileScript parses the script string and creates a compiled script
+// with type unsafe variant operations and standard functions.
+//	Parameters:
+//		- script: The script string.
+//	Returns: A compiled script or error if script has syntax errors.
+func CompileScript(script string) (*CompiledScript, error) {
+	return NewCompiledScript(script, nil, nil)
+}
+
+func newCompiledScript(parser *ScriptParser, variantOperations variants.IVariantOperations,
+	defaultFunctions functions.IFunctionCollection) *CompiledScript {
+
+	if variantOperations == nil {
+		variantOperations = variants.NewTypeUnsafeVariantOperations()
+	}
+	if defaultFunctions == nil {
+		defaultFunctions = functions.NewDefaultFunctionCollection()
+	}
+
+	c := &CompiledScript{
+		statements:        parser.statements,
+		expressions:       make([]*calculator.CompiledExpression, len(parser.expressions)),
+		definitions:       make([]*calculator.UserFunction, len(parser.definitions)),
+		variableNames:     parser.VariableNames(),
+		variantOperations: variantOperations,
+		defaultFunctions:  defaultFunctions,
+		limits:            NewScriptLimits(),
+	}
+	for index, expression := range parser.expressions {
+		c.expressions[index] = calculator.NewCompiledExpressionFromParser(expression, variantOperations, defaultFunctions).
+			WithLimits(c.limits.EvaluationLimits)
+	}
+	for index, definition := range parser.definitions {
+		body := definition.Body().WithDefaults(variantOperations, defaultFunctions).WithLimits(c.limits.EvaluationLimits)
+		c.definitions[index] = calculator.NewUserFunction(definition.Name(), definition.Parameters(), body)
+	}
+	return c
+}
+
+// VariableNames gets the list of variable names used or assigned in the script.
+// Loop variables of FOR EACH statements are not included.
+func (c *CompiledScript) VariableNames() []string {
+	result := make([]string, len(c.variableNames))
+	copy(result, c.variableNames)
+	return result
+}
+
+// VariantOperations gets the manager for operations on variant values.
+func (c *CompiledScript) VariantOperations() variants.IVariantOperations {
+	return c.variantOperations
+}
+
+// DefaultFunctions gets the list of functions used when no functions are set during evaluation.
+func (c *CompiledScript) DefaultFunctions() functions.IFunctionCollection {
+	return c.defaultFunctions
+}
+
+// Limits gets the limits applied during execution.
+func (c *CompiledScript) Limits() ScriptLimits {
+	return c.limits
+}
+
+// WithLimits creates a copy of this compiled script with the specified limits.
+//	Parameters:
+//		- limits: The limits to be applied during execution.
+//	Returns: A new compiled script.
+func (c *CompiledScript) WithLimits(limits ScriptLimits) *CompiledScript {
+	result := *c
+	result.limits = limits
+	result.expressions = make([]*calculator.CompiledExpression, len(c.expressions))
+	for index, expression := range c.expressions {
+		result.expressions[index] = expression.WithLimits(limits.EvaluationLimits)
+	}
+	result.definitions = make([]*calculator.UserFunction, len(c.definitions))
+	for index, definition := range c.definitions {
+		body := definition.Body().WithLimits(limits.EvaluationLimits)
+		result.definitions[index] = calculator.NewUserFunction(definition.Name(), definition.Parameters(), body)
+	}
+	return &result
+}
+
+// Evaluate this script using specified variables and functions.
+// Assignments change values of the specified variables.
+// Functions defined in the script shadow the specified functions with the same names.
+// Calls of lazy functions replaced by the functions are compiled again like in CompiledExpression.
+//	Parameters:
+//		- vars: The list of variables or nil if script has no variables.
+//		- funcs: The list of functions or nil to use default functions.
+//	Returns: The value of RETURN statement or the value of the last executed expression
+//		or assignment when the script has no RETURN statement.
+func (c *CompiledScript) Evaluate(
+	vars variables.IVariableCollection, funcs functions.IFunctionCollection) (*variants.Variant, error) {
+	return c.EvaluateWithContext(context.Background(), vars, funcs)
+}
+
+// EvaluateWithContext evaluates this script using specified variables and functions.
+// The execution stops when the context is cancelled or any of the limits is exceeded.
+// Calls of lazy functions replaced by the functions are compiled again like in CompiledExpression.
+//	Parameters:
+//		- ctx: The context to control the execution.
+//		- vars: The list of variables or nil if script has no variables.
+//		- funcs: The list of functions or nil to use default functions.
+//	Returns: The value of RETURN statement or the value of the last executed expression
+//		or assignment when the script has no RETURN statement.
+func (c *CompiledScript) EvaluateWithContext(ctx context.Context,
+	vars variables.IVariableCollection, funcs functions.IFunctionCollection) (*variants.Variant, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if vars == nil {
+		vars = variables.NewVariableCollection()
+	}
+	if funcs == nil {
+		funcs = c.defaultFunctions
+	}
+	if len(c.definitions) > 0 {
+		collection := calculator.NewUserFunctionCollection(funcs)
+		for _, definition := range c.definitions {
+			collection.Add(definition)
+		}
+		funcs = collection
+	}
+
+	state := &scriptState{
+		ctx:        ctx,
+		script:     c,
+		functions:  funcs,
+		operations: c.variantOperations,
+		result:     variants.EmptyVariant(),
+	}
+	err := state.executeStatements(c.statements, vars)
+	if err != nil {
+		return nil, err
+	}
+	return state.result, nil
+}
+
+// scriptState keeps the state of a single script execution.
+type scriptState struct {
+	ctx        context.Context
+	script     *CompiledScript
+	functions  functions.IFunctionCollection
+	operations variants.IVariantOperations
+	result     *variants.Variant
+	returned   bool
+	statements int
+}
+
+// executeStatements executes the statements until the end of the list or RETURN statement.
+func (c *scriptState) executeStatements(statements []scriptStatement, vars variables.IVariableCollection) error {
+	for _, statement := range statements {
+		line, column := statement.position()
+		if err := c.checkStatement(line, column); err != nil {
+			return err
+		}
+		if err := statement.execute(c, vars); err != nil || c.returned {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStatement checks the context and the statements limit before the next statement or loop iteration.
+func (c *scriptState) checkStatement(line int, column int) error {
+	if c.ctx.Err() != nil {
+		return errors.NewExpressionError("", "EVALUATION_CANCELLED",
+			"Evaluation was cancelled: "+c.ctx.Err().Error(), line, column)
+	}
+	limit := c.script.limits.MaxStatements
+	if limit > 0 && c.statements >= limit {
+		return errors.NewExpressionError("", "STATEMENTS_LIMIT_EXCEEDED",
+			"Execution exceeded the limit of "+strconv.Itoa(limit)+" statements", line, column)
+	}
+	c.statements++
+	return nil
+}
+
+// checkIteration checks the iterations limit of a loop and counts the iteration as a statement.
+func (c *scriptState) checkIteration(iteration int, line int, column int) error {
+	limit := c.script.limits.MaxIterations
+	if limit > 0 && iteration >= limit {
+		return errors.NewExpressionError("", "ITERATION_LIMIT_EXCEEDED",
+			"Loop exceeded the limit of "+strconv.Itoa(limit)+" iterations", line, column)
+	}
+	return c.checkStatement(line, column)
+}
+
+// evaluate evaluates an expression of the script.
+func (c *scriptState) evaluate(expression *scriptExpression,
+	vars variables.IVariableCollection) (*variants.Variant, error) {
+	return c.script.expressions[expression.index].EvaluateWithContext(c.ctx, vars, c.functions)
+}
+
+// evaluateCondition evaluates a condition of IF or WHILE statement. Null values are treated as false.
+func (c *scriptState) evaluateCondition(expression *scriptExpression,
+	vars variables.IVariableCollection) (bool, error) {
+	value, err := c.evaluate(expression, vars)
+	if err != nil || value.IsNull() {
+		return false, err
+	}
+	value, err = c.operations.Convert(value, variants.Boolean)
+	if err != nil {
+		return false, err
+	}
+	return value.AsBoolean(), nil
+}
@@ -0,0 +1,185 @@
+package scripts
+
+import (
+	"context"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// ScriptCalculator implements a calculator of scripts with assignments and control flow statements.
+// Assignments change values of default or specified variables, so results of a script
+// are available through the variables after evaluation.
+type ScriptCalculator struct {
+	defaultVariables  variables.IVariableCollection
+	defaultFunctions  functions.IFunctionCollection
+	variantOperations variants.IVariantOperations
+	parser            *ScriptParser
+	autoVariables     bool
+	limits            ScriptLimits
+	compiled          *CompiledScript
+}
+
+// NewScriptCalculator constructs this class with default parameters.
+func NewScriptCalculator() *ScriptCalculator {
+	c := &ScriptCalculator{
+		defaultVariables:  variables.NewVariableCollection(),
+		defaultFunctions:  functions.NewDefaultFunctionCollection(),
+		variantOperations: variants.NewTypeUnsafeVariantOperations(),
+		parser:            NewScriptParser(),
+		autoVariables:     true,
+		limits:            NewScriptLimits(),
+	}
+	c.parser.SetFunctions(c.defaultFunctions)
+	return c
+}
+
+// ScriptCalculatorFromScript constructs this class and assigns script string.
+//	Parameters:
+//		- script: The script string.
+func ScriptCalculatorFromScript(script string) (*ScriptCalculator, error) {
+	c := NewScriptCalculator()
+	err := c.SetScript(script)
+	return c, err
+}
+
+// Script gets the script string.
+func (c *ScriptCalculator) Script() string {
+	return c.parser.Script()
+}
+
+// SetScript sets the script string.
+func (c *ScriptCalculator) SetScript(value string) error {
+	c.compiled = nil
+	err := c.parser.ParseString(value)
+	if err != nil {
+		return err
+	}
+
+	if c.autoVariables {
+		c.CreateVariables(c.defaultVariables)
+	}
+
+	return nil
+}
+
+// AutoVariables gets the flag to turn on auto creation of variables for specified script.
+func (c *ScriptCalculator) AutoVariables() bool {
+	return c.autoVariables
+}
+
+// SetAutoVariables sets the flag to turn on auto creation of variables for specified script.
+func (c *ScriptCalculator) SetAutoVariables(value bool) {
+	c.autoVariables = value
+}
+
+// VariantOperations gets the manager for operations on variant values.
+func (c *ScriptCalculator) VariantOperations() variants.IVariantOperations {
+	return c.variantOperations
+}
+
+// SetVariantOperations sets the manager for operations on variant values.
+func (c *ScriptCalculator) SetVariantOperations(value variants.IVariantOperations) {
+	c.variantOperations = value
+	c.compiled = nil
+}
+
+// Limits gets the limits applied during execution.
+func (c *ScriptCalculator) Limits() ScriptLimits {
+	return c.limits
+}
+
+// SetLimits sets the limits applied during execution.
+func (c *ScriptCalculator) SetLimits(value ScriptLimits) {
+	c.limits = value
+	c.compiled = nil
+}
+
+// DefaultVariables the list with default variables.
+func (c *ScriptCalculator) DefaultVariables() variables.IVariableCollection {
+	return c.defaultVariables
+}
+
+// DefaultFunctions the list with default functions.
+func (c *ScriptCalculator) DefaultFunctions() functions.IFunctionCollection {
+	return c.defaultFunctions
+}
+
+// CreateVariables populates the specified variables list with variables from parsed script.
+//	Parameters:
+//		- variables: The list of variables to be populated.
+func (c *ScriptCalculator) CreateVariables(vars variables.IVariableCollection) {
+	for _, variableName := range c.parser.VariableNames() {
+		if vars.FindByName(variableName) == nil {
+			vars.Add(variables.EmptyVariable(variableName))
+		}
+	}
+}
+
+// Compile creates an immutable compiled script from the currently parsed script.
+// The compiled script is cached until the script, variant operations or limits are changed.
+//	Returns: A compiled script.
+func (c *ScriptCalculator) Compile() *CompiledScript {
+	if c.compiled == nil {
+		c.compiled = newCompiledScript(c.parser, c.variantOperations, c.defaultFunctions).
+			WithLimits(c.limits)
+	}
+	return c.compiled
+}
+
+// Clear cleans up this calculator from all data.
+func (c *ScriptCalculator) Clear() {
+	c.compiled = nil
+	c.parser.Clear()
+	c.defaultVariables.Clear()
+}
+
+// Evaluate this script using default variables and functions.
+//	Returns: An evaluated script value.
+func (c *ScriptCalculator) Evaluate() (*variants.Variant, error) {
+	return c.EvaluateUsingVariablesAndFunctions(nil, nil)
+}
+
+// EvaluateUsingVariables evaluates this script using specified variables.
+//	Parameters:
+//		- variables: The list of variables
+//	Returns: An evaluated script value.
+func (c *ScriptCalculator) EvaluateUsingVariables(
+	vars variables.IVariableCollection) (*variants.Variant, error) {
+	return c.EvaluateUsingVariablesAndFunctions(vars, nil)
+}
+
+// EvaluateUsingVariablesAndFunctions evaluates this script using specified variables and functions.
+// Calls of lazy functions like If, Choose, Coalesce and IfNull are compiled using default functions.
+// When the functions replace them with functions that are evaluated differently, the expressions
+// are compiled again for this evaluation, so the replacements are called with all parameters evaluated.
+//	Parameters:
+//		- variables: The list of variables
+//		- functions: The list of functions.
+//	Returns: An evaluated script value.
+func (c *ScriptCalculator) EvaluateUsingVariablesAndFunctions(
+	vars variables.IVariableCollection, funcs functions.IFunctionCollection) (*variants.Variant, error) {
+	return c.EvaluateWithContext(context.Background(), vars, funcs)
+}
+
+// EvaluateWithContext evaluates this script using specified variables and functions.
+// The execution stops when the context is cancelled or any of the limits is exceeded.
+// Calls of lazy functions replaced by the functions are processed like in EvaluateUsingVariablesAndFunctions.
+//	Parameters:
+//		- ctx: The context to control the execution.
+//		- variables: The list of variables
+//		- functions: The list of functions.
+//	Returns: An evaluated script value.
+func (c *ScriptCalculator) EvaluateWithContext(ctx context.Context,
+	vars variables.IVariableCollection, funcs functions.IFunctionCollection) (*variants.Variant, error) {
+
+	if vars == nil {
+		vars = c.defaultVariables
+	}
+	if funcs == nil {
+		funcs = c.defaultFunctions
+	}
+
+	return c.Compile().EvaluateWithContext(ctx, vars, funcs)
+}
@@ -0,0 +1,44 @@
+package scripts
+
+import "github.com/pip-services3-gox/pip-services3-expressions-gox/calculator"
+
+// DefaultMaxIterations is the maximum number of loop iterations set by NewScriptLimits.
+const DefaultMaxIterations = 10000
+
+// DefaultMaxStatements is the maximum number of executed statements and loop iterations
+// set by NewScriptLimits. It bounds the total work of nested loops.
+const DefaultMaxStatements = 1000000
+
+// DefaultMaxStringLength is the maximum length (in characters) of strings set by NewScriptLimits.
+// Loops can double strings on every iteration, so the length is bounded by default.
+const DefaultMaxStringLength = 1000000
+
+// DefaultMaxArrayLength is the maximum length of arrays set by NewScriptLimits.
+const DefaultMaxArrayLength = 100000
+
+// ScriptLimits defines limits that protect execution of untrusted scripts.
+// The embedded evaluation limits are applied to every expression in the script.
+// Zero value of any limit means that the limit is not applied.
+type ScriptLimits struct {
+	calculator.EvaluationLimits
+
+	// MaxIterations is the maximum number of iterations of a single WHILE or FOR EACH loop.
+	MaxIterations int
+
+	// MaxStatements is the maximum number of executed statements and loop iterations.
+	// Statements are counted across all loops of the script, including nested ones.
+	MaxStatements int
+}
+
+// NewScriptLimits creates script limits that allow DefaultMaxIterations iterations of each loop,
+// DefaultMaxStatements statements in total, strings up to DefaultMaxStringLength characters
+// and arrays up to DefaultMaxArrayLength elements. All other limits are turned off.
+func NewScriptLimits() ScriptLimits {
+	limits := ScriptLimits{
+		MaxIterations: DefaultMaxIterations,
+		MaxStatements: DefaultMaxStatements,
+	}
+	limits.MaxStringLength = DefaultMaxStringLength
+	limits.MaxArrayLength = DefaultMaxArrayLength
+	return limits
+}
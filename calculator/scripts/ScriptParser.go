@@ -0,0 +1,502 @@
+package scripts
+
+import (
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	ctokenizers "github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/tokenizers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/tokenizers"
+)
+
+// ScriptParser implements a parser of scripts that consist of statements separated by ';'.
+// Supported statements are:
+//	- expression: any expression, its value becomes the script result;
+//	- name := expression: assigns the expression value to a variable;
+//	- IF condition THEN statements [ELSEIF condition THEN statements]... [ELSE statements] END;
+//	- WHILE condition DO statements END;
+//	- FOR EACH name IN expression DO statements END;
+//	- RETURN [expression]: stops the script and returns the expression value;
+//	- DEF name(parameters) = expression: defines a function available in the whole script.
+// Expressions inside statements are parsed by ExpressionParser.
+// Functions can be defined only outside of blocks.
+type ScriptParser struct {
+	tokenizer     tokenizers.ITokenizer
+	script        string
+	tokens        []*tokenizers.Token
+	index         int
+	statements    []scriptStatement
+	expressions   []*parsers.ExpressionParser
+	definitions   []*calculator.UserFunction
+	variableNames []string
+	loopScopes    []string
+	blockDepth    int
+	functions     functions.IFunctionCollection
+}
+
+// NewScriptParser constructs this class with default parameters.
+func NewScriptParser() *ScriptParser {
+	c := &ScriptParser{
+		tokenizer: ctokenizers.NewExpressionTokenizer(),
+	}
+	c.Clear()
+	return c
+}
+
+// Script gets the script string.
+func (c *ScriptParser) Script() string {
+	return c.script
+}
+
+// Definitions gets the list of functions defined in the script.
+func (c *ScriptParser) Definitions() []*calculator.UserFunction {
+	return c.definitions
+}
+
+// Functions gets the list of functions used to find lazy functions like If and Choose.
+func (c *ScriptParser) Functions() functions.IFunctionCollection {
+	return c.functions
+}
+
+// SetFunctions sets the list of functions used to find lazy functions like If and Choose.
+//	Parameters:
+//		- value: The list of functions or nil to use the standard functions.
+func (c *ScriptParser) SetFunctions(value functions.IFunctionCollection) {
+	c.functions = value
+}
+
+// VariableNames gets the list of variable names used or assigned in the script.
+func (c *ScriptParser) VariableNames() []string {
+	return c.variableNames
+}
+
+// ParseString sets a new script string and parses it into statements.
+//	Parameters:
+//		- script: A new script string.
+//	Returns: Error if the script has syntax errors.
+func (c *ScriptParser) ParseString(script string) error {
+	c.Clear()
+	c.script = strings.Trim(script, " \t\r\n")
+	c.tokenizer.SetSkipWhitespaces(true)
+	c.tokenizer.SetSkipComments(true)
+	c.tokenizer.SetSkipEof(true)
+	c.tokenizer.SetDecodeStrings(true)
+	return c.ParseTokens(c.tokenizer.TokenizeBuffer(c.script))
+}
+
+// ParseTokens parses the script tokens into statements.
+//	Parameters:
+//		- tokens: The list of script tokens.
+//	Returns: Error if the script has syntax errors.
+func (c *ScriptParser) ParseTokens(tokens []*tokenizers.Token) error {
+	script := c.script
+	c.Clear()
+	c.script = script
+	for _, token := range tokens {
+		if token.Type() != tokenizers.Whitespace && token.Type() != tokenizers.Comment &&
+			token.Type() != tokenizers.Eof {
+			c.tokens = append(c.tokens, token)
+		}
+	}
+
+	statements, err := c.parseStatements()
+	if err == nil {
+		err = c.checkDefinitions()
+	}
+	if err != nil {
+		c.Clear()
+		return err
+	}
+	c.statements = statements
+	return nil
+}
+
+// checkDefinitions checks that functions defined in the script do not call themselves.
+func (c *ScriptParser) checkDefinitions() error {
+	collection := calculator.NewUserFunctionCollection(functions.NewFunctionCollection())
+	for _, definition := range c.definitions {
+		if _, err := collection.AddUserFunction(definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clear clears parsing results.
+func (c *ScriptParser) Clear() {
+	c.script = ""
+	c.tokens = []*tokenizers.Token{}
+	c.index = 0
+	c.statements = []scriptStatement{}
+	c.expressions = []*parsers.ExpressionParser{}
+	c.definitions = []*calculator.UserFunction{}
+	c.variableNames = []string{}
+	c.loopScopes = []string{}
+	c.blockDepth = 0
+}
+
+// word gets the upper-cased value of word or keyword token at the specified index or empty string.
+func (c *ScriptParser) word(index int) string {
+	if index < len(c.tokens) {
+		token := c.tokens[index]
+		if token.Type() == tokenizers.Word || token.Type() == tokenizers.Keyword {
+			return strings.ToUpper(token.Value())
+		}
+	}
+	return ""
+}
+
+// isSymbol checks if the token at the specified index is the symbol.
+func (c *ScriptParser) isSymbol(index int, symbol string) bool {
+	return index < len(c.tokens) && c.tokens[index].Type() == tokenizers.Symbol &&
+		c.tokens[index].Value() == symbol
+}
+
+// isTerminator checks if the current token ends a list of statements.
+func (c *ScriptParser) isTerminator(terminators []string) bool {
+	word := c.word(c.index)
+	for _, terminator := range terminators {
+		if word == terminator {
+			return true
+		}
+	}
+	return false
+}
+
+// newError creates a syntax error at the current token or at the last token when the script has ended.
+func (c *ScriptParser) newError(code string, message string) error {
+	if c.index < len(c.tokens) {
+		token := c.tokens[c.index]
+		return errors.NewSyntaxError("", code, message, token.Line(), token.Column())
+	}
+	if len(c.tokens) > 0 {
+		token := c.tokens[len(c.tokens)-1]
+		return errors.NewSyntaxError("", code, message, token.Line(), token.Column())
+	}
+	return errors.NewSyntaxError("", code, message, 0, 0)
+}
+
+// expectWord checks that the current token is the keyword and moves to the next token.
+func (c *ScriptParser) expectWord(word string, code string) error {
+	if c.word(c.index) != word {
+		return c.newError(code, "Expected "+word+" was not found")
+	}
+	c.index++
+	return nil
+}
+
+// addVariableName adds a variable name unless it is already known or defined by enclosing FOR EACH loop.
+func (c *ScriptParser) addVariableName(name string) {
+	for _, scope := range c.loopScopes {
+		if strings.EqualFold(scope, name) {
+			return
+		}
+	}
+	for _, v := range c.variableNames {
+		if strings.EqualFold(v, name) {
+			return
+		}
+	}
+	c.variableNames = append(c.variableNames, name)
+}
+
+// parseStatements parses statements until one of the terminators or the end of script.
+// The terminator is not consumed. Without terminators the statements must end the script.
+func (c *ScriptParser) parseStatements(terminators ...string) ([]scriptStatement, error) {
+	if len(terminators) > 0 {
+		c.blockDepth++
+		defer func() { c.blockDepth-- }()
+	}
+
+	statements := []scriptStatement{}
+	for {
+		for c.isSymbol(c.index, ";") {
+			c.index++
+		}
+		if c.index >= len(c.tokens) {
+			if len(terminators) > 0 {
+				return nil, c.newError(errors.ErrMissedEnd, "Expected END was not found")
+			}
+			return statements, nil
+		}
+		if c.isTerminator(terminators) {
+			return statements, nil
+		}
+		if c.isTerminator(blockTerminators) {
+			token := c.tokens[c.index]
+			return nil, c.newError(errors.ErrErrorNear, "Syntax error near "+token.Value())
+		}
+
+		if c.word(c.index) == "DEF" && !c.isSymbol(c.index+1, ":=") {
+			if err := c.parseDefinition(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		statement, err := c.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+}
+
+// blockTerminators are keywords that end simple statements inside blocks.
+var blockTerminators = []string{"END", "ELSE", "ELSEIF"}
+
+// parseStatement parses a single statement starting at the current token.
+func (c *ScriptParser) parseStatement() (scriptStatement, error) {
+	token := c.tokens[c.index]
+	if token.Type() == tokenizers.Word && c.isSymbol(c.index+1, ":=") {
+		return c.parseAssignment()
+	}
+
+	switch c.word(c.index) {
+	case "IF":
+		end := c.findExpressionEnd(c.index+1, "THEN")
+		if c.word(end) == "THEN" {
+			return c.parseIf()
+		}
+	case "WHILE":
+		return c.parseWhile()
+	case "FOR":
+		return c.parseForEach()
+	case "RETURN":
+		return c.parseReturn()
+	}
+
+	expression, err := c.parseExpression(blockTerminators...)
+	if err != nil {
+		return nil, err
+	}
+	return &expressionStatement{expression: expression}, nil
+}
+
+// parseDefinition parses the function definition that ends with ';' or the end of script.
+func (c *ScriptParser) parseDefinition() error {
+	if c.blockDepth > 0 {
+		return c.newError(errors.ErrErrorNear, "Syntax error near DEF")
+	}
+	end := c.findExpressionEnd(c.index+1, blockTerminators...)
+	definition, err := calculator.ParseUserFunctionTokens(c.tokens[c.index:end], nil)
+	if err != nil {
+		return err
+	}
+	c.index = end
+
+	for index, other := range c.definitions {
+		if strings.EqualFold(other.Name(), definition.Name()) {
+			c.definitions = append(c.definitions[:index], c.definitions[index+1:]...)
+			break
+		}
+	}
+	c.definitions = append(c.definitions, definition)
+	return nil
+}
+
+func (c *ScriptParser) parseAssignment() (scriptStatement, error) {
+	token := c.tokens[c.index]
+	c.index += 2
+	expression, err := c.parseExpression(blockTerminators...)
+	if err != nil {
+		return nil, err
+	}
+	c.addVariableName(token.Value())
+	return &assignmentStatement{
+		name:       token.Value(),
+		expression: expression,
+		line:       token.Line(),
+		column:     token.Column(),
+	}, nil
+}
+
+func (c *ScriptParser) parseIf() (scriptStatement, error) {
+	token := c.tokens[c.index]
+	statement := &ifStatement{
+		branches:       []*conditionalBranch{},
+		elseStatements: []scriptStatement{},
+		line:           token.Line(),
+		column:         token.Column(),
+	}
+
+	for {
+		c.index++
+		condition, err := c.parseExpression("THEN")
+		if err != nil {
+			return nil, err
+		}
+		if err = c.expectWord("THEN", errors.ErrMissedThen); err != nil {
+			return nil, err
+		}
+		statements, err := c.parseStatements(blockTerminators...)
+		if err != nil {
+			return nil, err
+		}
+		statement.branches = append(statement.branches,
+			&conditionalBranch{condition: condition, statements: statements})
+		if c.word(c.index) != "ELSEIF" {
+			break
+		}
+	}
+
+	if c.word(c.index) == "ELSE" {
+		c.index++
+		statements, err := c.parseStatements("END")
+		if err != nil {
+			return nil, err
+		}
+		statement.elseStatements = statements
+	}
+	if err := c.expectWord("END", errors.ErrMissedEnd); err != nil {
+		return nil, err
+	}
+	return statement, nil
+}
+
+func (c *ScriptParser) parseWhile() (scriptStatement, error) {
+	token := c.tokens[c.index]
+	c.index++
+	condition, err := c.parseExpression("DO")
+	if err != nil {
+		return nil, err
+	}
+	statements, err := c.parseBody()
+	if err != nil {
+		return nil, err
+	}
+	return &whileStatement{
+		condition:  condition,
+		statements: statements,
+		line:       token.Line(),
+		column:     token.Column(),
+	}, nil
+}
+
+func (c *ScriptParser) parseForEach() (scriptStatement, error) {
+	token := c.tokens[c.index]
+	c.index++
+	if err := c.expectWord("EACH", errors.ErrErrorNear); err != nil {
+		return nil, err
+	}
+	if c.index >= len(c.tokens) || c.tokens[c.index].Type() != tokenizers.Word {
+		return nil, c.newError(errors.ErrMissedVariableName, "Expected variable name after FOR EACH")
+	}
+	name := c.tokens[c.index].Value()
+	c.index++
+	if err := c.expectWord("IN", errors.ErrMissedIn); err != nil {
+		return nil, err
+	}
+	collection, err := c.parseExpression("DO")
+	if err != nil {
+		return nil, err
+	}
+
+	c.loopScopes = append(c.loopScopes, name)
+	statements, err := c.parseBody()
+	c.loopScopes = c.loopScopes[:len(c.loopScopes)-1]
+	if err != nil {
+		return nil, err
+	}
+	return &forEachStatement{
+		name:       name,
+		collection: collection,
+		statements: statements,
+		line:       token.Line(),
+		column:     token.Column(),
+	}, nil
+}
+
+// parseBody parses DO statements END of loops.
+func (c *ScriptParser) parseBody() ([]scriptStatement, error) {
+	if err := c.expectWord("DO", errors.ErrMissedDo); err != nil {
+		return nil, err
+	}
+	statements, err := c.parseStatements("END")
+	if err != nil {
+		return nil, err
+	}
+	if err = c.expectWord("END", errors.ErrMissedEnd); err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
+
+func (c *ScriptParser) parseReturn() (scriptStatement, error) {
+	token := c.tokens[c.index]
+	c.index++
+	statement := &returnStatement{line: token.Line(), column: token.Column()}
+	if c.index < len(c.tokens) && !c.isSymbol(c.index, ";") && !c.isTerminator(blockTerminators) {
+		expression, err := c.parseExpression(blockTerminators...)
+		if err != nil {
+			return nil, err
+		}
+		statement.expression = expression
+	}
+	return statement, nil
+}
+
+// parseExpression parses the expression from the current token until ';', one of the terminators
+// or the end of script. The ending token is not consumed.
+func (c *ScriptParser) parseExpression(terminators ...string) (*scriptExpression, error) {
+	end := c.findExpressionEnd(c.index, terminators...)
+	if end == c.index {
+		return nil, c.newError(errors.ErrMissedExpression, "Expected expression was not found")
+	}
+
+	token := c.tokens[c.index]
+	parser := parsers.NewExpressionParser()
+	parser.SetFunctions(c.functions)
+	err := parser.ParseTokens(c.tokens[c.index:end])
+	if err != nil {
+		return nil, err
+	}
+	c.index = end
+
+	for _, name := range parser.VariableNames() {
+		c.addVariableName(name)
+	}
+	c.expressions = append(c.expressions, parser)
+	return &scriptExpression{
+		index:  len(c.expressions) - 1,
+		line:   token.Line(),
+		column: token.Column(),
+	}, nil
+}
+
+// findExpressionEnd finds the index of ';' or the terminator that ends the expression.
+// Terminators inside parentheses, brackets and CASE expressions are skipped.
+func (c *ScriptParser) findExpressionEnd(start int, terminators ...string) int {
+	depth := 0
+	caseDepth := 0
+	for index := start; index < len(c.tokens); index++ {
+		token := c.tokens[index]
+		if token.Type() == tokenizers.Symbol {
+			switch token.Value() {
+			case "(", "[":
+				depth++
+			case ")", "]":
+				depth--
+			case ";":
+				return index
+			}
+			continue
+		}
+
+		word := c.word(index)
+		if word == "CASE" {
+			caseDepth++
+		} else if word == "END" && caseDepth > 0 {
+			caseDepth--
+		} else if depth <= 0 && caseDepth == 0 {
+			for _, terminator := range terminators {
+				if word == terminator {
+					return index
+				}
+			}
+		}
+	}
+	return len(c.tokens)
+}
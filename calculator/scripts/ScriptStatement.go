@@ -0,0 +1,185 @@
+package scripts
+
+import (
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// scriptExpression references a compiled expression of the script.
+type scriptExpression struct {
+	index  int
+	line   int
+	column int
+}
+
+// scriptStatement defines a single executable statement of the script.
+type scriptStatement interface {
+	// position gets the line and column where the statement starts.
+	position() (int, int)
+
+	// execute runs the statement using the specified variables.
+	execute(state *scriptState, vars variables.IVariableCollection) error
+}
+
+// expressionStatement evaluates an expression and keeps its value as the script result.
+type expressionStatement struct {
+	expression *scriptExpression
+}
+
+func (c *expressionStatement) position() (int, int) {
+	return c.expression.line, c.expression.column
+}
+
+func (c *expressionStatement) execute(state *scriptState, vars variables.IVariableCollection) error {
+	value, err := state.evaluate(c.expression, vars)
+	if err != nil {
+		return err
+	}
+	state.result = value
+	return nil
+}
+
+// assignmentStatement evaluates an expression and assigns its value to a variable.
+type assignmentStatement struct {
+	name       string
+	expression *scriptExpression
+	line       int
+	column     int
+}
+
+func (c *assignmentStatement) position() (int, int) {
+	return c.line, c.column
+}
+
+func (c *assignmentStatement) execute(state *scriptState, vars variables.IVariableCollection) error {
+	value, err := state.evaluate(c.expression, vars)
+	if err != nil {
+		return err
+	}
+	vars.Locate(c.name).SetValue(value)
+	state.result = value
+	return nil
+}
+
+// conditionalBranch is a condition with statements executed when the condition is true.
+type conditionalBranch struct {
+	condition  *scriptExpression
+	statements []scriptStatement
+}
+
+// ifStatement executes the first branch with true condition or the ELSE statements.
+type ifStatement struct {
+	branches       []*conditionalBranch
+	elseStatements []scriptStatement
+	line           int
+	column         int
+}
+
+func (c *ifStatement) position() (int, int) {
+	return c.line, c.column
+}
+
+func (c *ifStatement) execute(state *scriptState, vars variables.IVariableCollection) error {
+	for _, branch := range c.branches {
+		condition, err := state.evaluateCondition(branch.condition, vars)
+		if err != nil {
+			return err
+		}
+		if condition {
+			return state.executeStatements(branch.statements, vars)
+		}
+	}
+	return state.executeStatements(c.elseStatements, vars)
+}
+
+// whileStatement executes statements while the condition is true.
+type whileStatement struct {
+	condition  *scriptExpression
+	statements []scriptStatement
+	line       int
+	column     int
+}
+
+func (c *whileStatement) position() (int, int) {
+	return c.line, c.column
+}
+
+func (c *whileStatement) execute(state *scriptState, vars variables.IVariableCollection) error {
+	for iteration := 0; ; iteration++ {
+		condition, err := state.evaluateCondition(c.condition, vars)
+		if err != nil || !condition {
+			return err
+		}
+		if err = state.checkIteration(iteration, c.line, c.column); err != nil {
+			return err
+		}
+		if err = state.executeStatements(c.statements, vars); err != nil || state.returned {
+			return err
+		}
+	}
+}
+
+// forEachStatement executes statements for every element of an array.
+// The loop variable is defined in a local scope and does not change outer variables.
+type forEachStatement struct {
+	name       string
+	collection *scriptExpression
+	statements []scriptStatement
+	line       int
+	column     int
+}
+
+func (c *forEachStatement) position() (int, int) {
+	return c.line, c.column
+}
+
+func (c *forEachStatement) execute(state *scriptState, vars variables.IVariableCollection) error {
+	collection, err := state.evaluate(c.collection, vars)
+	if err != nil || collection.IsNull() {
+		return err
+	}
+	if collection.Type() != variants.Array {
+		collection, err = state.operations.Convert(collection, variants.Array)
+		if err != nil {
+			return err
+		}
+	}
+
+	item := variables.EmptyVariable(c.name)
+	scope := variables.NewScopedVariableCollection(vars)
+	scope.Add(item)
+	for iteration, element := range collection.AsArray() {
+		if err = state.checkIteration(iteration, c.line, c.column); err != nil {
+			return err
+		}
+		item.SetValue(element)
+		if err = state.executeStatements(c.statements, scope); err != nil || state.returned {
+			return err
+		}
+	}
+	return nil
+}
+
+// returnStatement stops the script and returns the value of the expression or null.
+type returnStatement struct {
+	expression *scriptExpression
+	line       int
+	column     int
+}
+
+func (c *returnStatement) position() (int, int) {
+	return c.line, c.column
+}
+
+func (c *returnStatement) execute(state *scriptState, vars variables.IVariableCollection) error {
+	state.result = variants.EmptyVariant()
+	if c.expression != nil {
+		value, err := state.evaluate(c.expression, vars)
+		if err != nil {
+			return err
+		}
+		state.result = value
+	}
+	state.returned = true
+	return nil
+}
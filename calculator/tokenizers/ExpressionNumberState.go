@@ -2,6 +2,7 @@ package tokenizers
 
 import (
 	"strings"
+	"unicode"
 
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/io"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/tokenizers"
@@ -45,8 +46,15 @@ func (c *ExpressionNumberState) NextToken(scanner io.IScanner,
 		return token
 	}
 
+	token = c.readExponent(scanner, token, line, column)
+	return c.readDecimalSuffix(scanner, token, line, column)
+}
+
+// readExponent reads the exponent of numbers in scientific format like <code>1.5e3</code>.
+func (c *ExpressionNumberState) readExponent(scanner io.IScanner,
+	token *tokenizers.Token, line int, column int) *tokenizers.Token {
 	// Exit if number is not in scientific format.
-	nextChar = scanner.Peek()
+	nextChar := scanner.Peek()
 
 	if nextChar != 'e' && nextChar != 'E' {
 		return token
@@ -80,3 +88,23 @@ func (c *ExpressionNumberState) NextToken(scanner io.IScanner,
 
 	return tokenizers.NewToken(tokenizers.Float, token.Value()+tokenValue.String(), line, column)
 }
+
+// readDecimalSuffix reads the 'm' suffix of decimal numbers like <code>12.30m</code>.
+// The suffix is not included into the token value.
+func (c *ExpressionNumberState) readDecimalSuffix(scanner io.IScanner,
+	token *tokenizers.Token, line int, column int) *tokenizers.Token {
+	nextChar := scanner.Peek()
+	if nextChar != 'm' && nextChar != 'M' {
+		return token
+	}
+
+	// The suffix must not start a word like in <code>12mod</code>.
+	scanner.Read()
+	nextChar = scanner.Peek()
+	if unicode.IsLetter(nextChar) || unicode.IsDigit(nextChar) || nextChar == '_' {
+		scanner.Unread()
+		return token
+	}
+
+	return tokenizers.NewToken(tokenizers.Decimal, token.Value(), line, column)
+}
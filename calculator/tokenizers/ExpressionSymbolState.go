@@ -22,6 +22,10 @@ func NewExpressionSymbolState() *ExpressionSymbolState {
 	c.Add("!=", tokenizers.Symbol)
 	c.Add(">>", tokenizers.Symbol)
 	c.Add("<<", tokenizers.Symbol)
+	c.Add("=>", tokenizers.Symbol)
+	c.Add("!~", tokenizers.Symbol)
+	c.Add("??", tokenizers.Symbol)
+	c.Add(":=", tokenizers.Symbol)
 
 	return c
 }
@@ -16,6 +16,7 @@ type ExpressionWordState struct {
 // Keywords supported expression keywords.
 var Keywords []string = []string{
 	"AND", "OR", "NOT", "XOR", "LIKE", "IS", "IN", "NULL", "TRUE", "FALSE",
+	"CASE", "WHEN", "THEN", "ELSE", "END", "MATCHES",
 }
 
 // NewExpressionWordState constructs an instance of this class.
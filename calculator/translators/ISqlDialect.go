@@ -0,0 +1,44 @@
+package translators
+
+// ISqlDialect defines hooks for database specific SQL syntax used by SqlTranslator.
+type ISqlDialect interface {
+	// Name gets the dialect name.
+	Name() string
+
+	// QuoteIdentifier quotes a name of table or column.
+	//	Parameters:
+	//		- name: The identifier to be quoted.
+	//	Returns: The quoted identifier.
+	QuoteIdentifier(name string) string
+
+	// Parameter gets a placeholder of bind parameter.
+	//	Parameters:
+	//		- index: The parameter index starting from 1.
+	//	Returns: The parameter placeholder.
+	Parameter(index int) string
+
+	// Concat gets a concatenation of strings.
+	//	Parameters:
+	//		- operands: The translated operands.
+	//	Returns: The SQL text of concatenation.
+	Concat(operands []string) string
+
+	// Like gets a LIKE comparison with backslash used as escape character.
+	//	Parameters:
+	//		- value: The translated compared value.
+	//		- pattern: The translated pattern.
+	//		- negate: <code>true</code> for NOT LIKE comparison.
+	//	Returns: The SQL text of comparison.
+	Like(value string, pattern string, negate bool) string
+
+	// Matches gets a regular expression match.
+	//	Parameters:
+	//		- value: The translated compared value.
+	//		- pattern: The translated regular expression.
+	//		- negate: <code>true</code> for negated match.
+	//	Returns: The SQL text of comparison.
+	Matches(value string, pattern string, negate bool) string
+
+	// Functions gets translations of expression functions by their names in upper case.
+	Functions() map[string]SqlFunction
+}
@@ -0,0 +1,591 @@
+package translators
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// mongoOperators defines filter operators for comparisons of a field with a value.
+var mongoOperators = map[ast.Operator]string{
+	ast.Equal: "$eq", ast.NotEqual: "$ne", ast.More: "$gt", ast.Less: "$lt",
+	ast.MoreEqual: "$gte", ast.LessEqual: "$lte", ast.In: "$in", ast.NotIn: "$nin",
+}
+
+// reversedOperators defines comparisons with swapped operands: <code>5 < a</code> is <code>a > 5</code>.
+var reversedOperators = map[ast.Operator]ast.Operator{
+	ast.Equal: ast.Equal, ast.NotEqual: ast.NotEqual, ast.More: ast.Less, ast.Less: ast.More,
+	ast.MoreEqual: ast.LessEqual, ast.LessEqual: ast.MoreEqual,
+}
+
+// MongoFilterTranslator translates expressions into MongoDB-style filter documents and back.
+// Filters are built from maps and slices, so they can be used with any document store driver
+// or serialized into JSON. Comparisons of fields with constant values, AND, OR, NOT,
+// IN, NOT IN, IS NULL, LIKE and MATCHES operators are supported. Field names are taken from variables,
+// members like <code>a.b</code> and constant array elements like <code>a[0]</code>.
+// LIKE patterns are translated into anchored regular expressions.
+// Regular expressions that cannot be written as LIKE patterns are reversed into MATCHES operator.
+//
+//	Example:
+//		translator := NewMongoFilterTranslator()
+//		filter, err := translator.TranslateExpression("a > 5 AND b IN ('x','y')")
+//		// filter: {"$and":[{"a":{"$gt":5}},{"b":{"$in":["x","y"]}}]}
+//		expression, err := translator.ReverseToExpression(filter)
+//		// expression: a > 5 AND b IN ('x', 'y')
+type MongoFilterTranslator struct {
+}
+
+// NewMongoFilterTranslator creates a new instance of the translator.
+func NewMongoFilterTranslator() *MongoFilterTranslator {
+	return &MongoFilterTranslator{}
+}
+
+// TranslateExpression parses the expression and translates it into filter document.
+//	Parameters:
+//		- expression: The expression string.
+//	Returns: The filter document and error if the expression cannot be translated.
+func (c *MongoFilterTranslator) TranslateExpression(expression string) (map[string]any, error) {
+	parser := parsers.NewExpressionParser()
+	err := parser.SetExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	return c.Translate(parser.SyntaxTree())
+}
+
+// Translate translates the syntax tree into filter document.
+//	Parameters:
+//		- node: The root node of the syntax tree.
+//	Returns: The filter document and error if the tree cannot be translated.
+func (c *MongoFilterTranslator) Translate(node ast.Node) (map[string]any, error) {
+	switch n := node.(type) {
+	case *ast.BinaryNode:
+		return c.translateBinary(n)
+	case *ast.UnaryNode:
+		return c.translateUnary(n)
+	case *ast.VariableNode, *ast.MemberNode, *ast.ElementNode:
+		field, err := c.translateField(n)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{field: true}, nil
+	case *ast.ConstantNode:
+		if n.Value.Type() == variants.Boolean {
+			if n.Value.AsBoolean() {
+				return map[string]any{}, nil
+			}
+			return map[string]any{"$nor": []any{map[string]any{}}}, nil
+		}
+	case nil:
+		return map[string]any{}, nil
+	}
+	return nil, newNotFilterableError("Expression", node)
+}
+
+func newNotFilterableError(message string, node ast.Node) error {
+	return errors.NewExpressionError("", ErrNotTranslatable,
+		message+" cannot be translated to filter", node.Pos().Line, node.Pos().Column)
+}
+
+func (c *MongoFilterTranslator) translateUnary(node *ast.UnaryNode) (map[string]any, error) {
+	switch node.Operator {
+	case ast.Not:
+		filter, err := c.Translate(node.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"$nor": []any{filter}}, nil
+	case ast.IsNull, ast.IsNotNull:
+		field, err := c.translateField(node.Operand)
+		if err != nil {
+			return nil, err
+		}
+		if node.Operator == ast.IsNull {
+			return map[string]any{field: nil}, nil
+		}
+		return map[string]any{field: map[string]any{"$ne": nil}}, nil
+	}
+	return nil, newNotFilterableError("Operator '"+string(node.Operator)+"'", node)
+}
+
+func (c *MongoFilterTranslator) translateBinary(node *ast.BinaryNode) (map[string]any, error) {
+	switch node.Operator {
+	case ast.And, ast.Or:
+		conditions := []any{}
+		for _, operand := range flattenBinary(node, node.Operator) {
+			filter, err := c.Translate(operand)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, filter)
+		}
+		if node.Operator == ast.And {
+			return map[string]any{"$and": conditions}, nil
+		}
+		return map[string]any{"$or": conditions}, nil
+	case ast.Like, ast.NotLike, ast.Matches, ast.NotMatches:
+		field, err := c.translateField(node.Left)
+		if err != nil {
+			return nil, err
+		}
+		pattern, err := c.translateValue(node.Right)
+		if err != nil {
+			return nil, err
+		}
+		text, ok := pattern.(string)
+		if !ok {
+			return nil, newNotFilterableError("Non-string "+strings.TrimPrefix(string(node.Operator), "NOT ")+
+				" pattern", node.Right)
+		}
+		if node.Operator == ast.Like || node.Operator == ast.NotLike {
+			text = likeToRegex(text)
+		}
+		regex := map[string]any{"$regex": text}
+		if node.Operator == ast.NotLike || node.Operator == ast.NotMatches {
+			return map[string]any{field: map[string]any{"$not": regex}}, nil
+		}
+		return map[string]any{field: regex}, nil
+	case ast.In, ast.NotIn:
+		field, err := c.translateField(node.Left)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := node.Right.(*ast.FunctionNode)
+		if !ok || strings.ToUpper(list.Name) != "ARRAY" {
+			return nil, newNotFilterableError("Operator '"+string(node.Operator)+"' with non-list operand", node)
+		}
+		values := []any{}
+		for _, parameter := range list.Parameters {
+			value, err := c.translateValue(parameter)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return map[string]any{field: map[string]any{mongoOperators[node.Operator]: values}}, nil
+	}
+
+	operator, ok := mongoOperators[node.Operator]
+	if !ok {
+		return nil, newNotFilterableError("Operator '"+string(node.Operator)+"'", node)
+	}
+
+	left, right := node.Left, node.Right
+	if _, isConstant := constantValue(left); isConstant {
+		left, right = right, left
+		operator = mongoOperators[reversedOperators[node.Operator]]
+	}
+
+	field, err := c.translateField(left)
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.translateValue(right)
+	if err != nil {
+		return nil, err
+	}
+
+	if operator == "$eq" {
+		return map[string]any{field: value}, nil
+	}
+	return map[string]any{field: map[string]any{operator: value}}, nil
+}
+
+// flattenBinary collects operands of nested operations like <code>a AND b AND c</code>.
+func flattenBinary(node ast.Node, operator ast.Operator) []ast.Node {
+	if binary, ok := node.(*ast.BinaryNode); ok && binary.Operator == operator {
+		return append(flattenBinary(binary.Left, operator), flattenBinary(binary.Right, operator)...)
+	}
+	return []ast.Node{node}
+}
+
+// constantValue gets the value of constant node or negated numeric constant.
+func constantValue(node ast.Node) (*variants.Variant, bool) {
+	switch n := node.(type) {
+	case *ast.ConstantNode:
+		return n.Value, true
+	case *ast.UnaryNode:
+		if n.Operator == ast.Negate {
+			if value, ok := constantValue(n.Operand); ok {
+				result, err := variants.NewTypeUnsafeVariantOperations().Negative(value)
+				return result, err == nil
+			}
+		}
+	}
+	return nil, false
+}
+
+func (c *MongoFilterTranslator) translateField(node ast.Node) (string, error) {
+	switch n := node.(type) {
+	case *ast.VariableNode:
+		return n.Name, nil
+	case *ast.MemberNode:
+		object, err := c.translateField(n.Object)
+		if err != nil {
+			return "", err
+		}
+		return object + "." + n.Name, nil
+	case *ast.ElementNode:
+		object, err := c.translateField(n.Object)
+		if err != nil {
+			return "", err
+		}
+		if index, ok := constantValue(n.Index); ok && index.Type() == variants.Integer {
+			return object + "." + strconv.Itoa(index.AsInteger()), nil
+		}
+		return "", newNotFilterableError("Non-constant element index", n.Index)
+	}
+	return "", newNotFilterableError("Non-field operand", node)
+}
+
+func (c *MongoFilterTranslator) translateValue(node ast.Node) (any, error) {
+	value, ok := constantValue(node)
+	if !ok {
+		return nil, newNotFilterableError("Non-constant value", node)
+	}
+	switch value.Type() {
+	case variants.Null, variants.Integer, variants.Long, variants.Float, variants.Double,
+		variants.String, variants.Boolean, variants.DateTime:
+		return value.AsObject(), nil
+	case variants.Decimal:
+		// Filters are plain values without driver types, so decimals are passed as doubles.
+		return value.AsDecimal().Float64(), nil
+	case variants.BigInteger:
+		if value.AsBigInteger().IsInt64() {
+			return value.AsBigInteger().Int64(), nil
+		}
+		return nil, newNotFilterableError("Integer value out of 64-bit range", node)
+	}
+	return nil, newNotFilterableError("Non-scalar value", node)
+}
+
+// likeToRegex converts a LIKE pattern into anchored regular expression.
+func likeToRegex(pattern string) string {
+	builder := strings.Builder{}
+	builder.WriteString("^")
+	escaped := false
+	for _, chr := range pattern {
+		if escaped {
+			builder.WriteString(regexp.QuoteMeta(string(chr)))
+			escaped = false
+		} else if chr == variants.DefaultLikeEscapeChar {
+			escaped = true
+		} else if chr == '%' {
+			builder.WriteString(".*")
+		} else if chr == '_' {
+			builder.WriteString(".")
+		} else {
+			builder.WriteString(regexp.QuoteMeta(string(chr)))
+		}
+	}
+	builder.WriteString("$")
+	return builder.String()
+}
+
+// regexToLike converts a regular expression into LIKE pattern.
+// Only literals, '.', '.*' and anchors are supported.
+func regexToLike(regex string) (string, bool) {
+	builder := strings.Builder{}
+	runes := []rune(regex)
+	if len(runes) > 0 && runes[0] == '^' {
+		runes = runes[1:]
+	} else {
+		builder.WriteString("%")
+	}
+	anchored := len(runes) > 0 && runes[len(runes)-1] == '$' &&
+		(len(runes) < 2 || runes[len(runes)-2] != '\\')
+	if anchored {
+		runes = runes[:len(runes)-1]
+	}
+
+	writeLiteral := func(chr rune) {
+		if chr == '%' || chr == '_' || chr == variants.DefaultLikeEscapeChar {
+			builder.WriteRune(variants.DefaultLikeEscapeChar)
+		}
+		builder.WriteRune(chr)
+	}
+
+	for index := 0; index < len(runes); index++ {
+		chr := runes[index]
+		switch {
+		case chr == '\\':
+			index++
+			if index >= len(runes) || !strings.ContainsRune(`\.+*?()|[]{}^$`, runes[index]) {
+				return "", false
+			}
+			writeLiteral(runes[index])
+		case chr == '.':
+			if index+1 < len(runes) && runes[index+1] == '*' {
+				builder.WriteString("%")
+				index++
+			} else {
+				builder.WriteString("_")
+			}
+		case strings.ContainsRune(`+*?()|[]{}^$`, chr):
+			return "", false
+		default:
+			writeLiteral(chr)
+		}
+	}
+
+	if !anchored {
+		builder.WriteString("%")
+	}
+	return builder.String(), true
+}
+
+// ReverseToExpression translates the filter document into expression string.
+//	Parameters:
+//		- filter: The filter document.
+//	Returns: The expression string and error if the filter cannot be translated.
+func (c *MongoFilterTranslator) ReverseToExpression(filter map[string]any) (string, error) {
+	node, err := c.Reverse(filter)
+	if err != nil {
+		return "", err
+	}
+	return ast.Format(node), nil
+}
+
+// Reverse translates the filter document into syntax tree.
+// Conditions of documents with several fields are combined with AND in order of field names.
+//	Parameters:
+//		- filter: The filter document.
+//	Returns: The root node of the syntax tree and error if the filter cannot be translated.
+func (c *MongoFilterTranslator) Reverse(filter map[string]any) (ast.Node, error) {
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conditions := []ast.Node{}
+	for _, key := range keys {
+		var condition ast.Node
+		var err error
+		if strings.HasPrefix(key, "$") {
+			condition, err = c.reverseLogical(key, filter[key])
+		} else {
+			condition, err = c.reverseField(key, filter[key])
+		}
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if len(conditions) == 0 {
+		return ast.NewConstantNode(variants.VariantFromBoolean(true), 0, 0), nil
+	}
+	return combineNodes(ast.And, conditions), nil
+}
+
+func newNotReversibleError(operator string) error {
+	return errors.NewExpressionError("", ErrNotTranslatable,
+		"Filter operator '"+operator+"' cannot be translated to expression", 0, 0)
+}
+
+// combineNodes joins nodes with associative binary operator into left-nested tree.
+func combineNodes(operator ast.Operator, nodes []ast.Node) ast.Node {
+	operands := []ast.Node{}
+	for _, node := range nodes {
+		operands = append(operands, flattenBinary(node, operator)...)
+	}
+
+	result := operands[0]
+	for _, operand := range operands[1:] {
+		result = ast.NewBinaryNode(operator, result, operand, 0, 0)
+	}
+	return result
+}
+
+func (c *MongoFilterTranslator) reverseLogical(operator string, value any) (ast.Node, error) {
+	items, ok := value.([]any)
+	if !ok {
+		if filters, isFilters := value.([]map[string]any); isFilters {
+			for _, filter := range filters {
+				items = append(items, filter)
+			}
+			ok = true
+		}
+	}
+	if !ok || len(items) == 0 {
+		return nil, newNotReversibleError(operator)
+	}
+
+	conditions := []ast.Node{}
+	for _, item := range items {
+		filter, isFilter := item.(map[string]any)
+		if !isFilter {
+			return nil, newNotReversibleError(operator)
+		}
+		condition, err := c.Reverse(filter)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	switch operator {
+	case "$and":
+		return combineNodes(ast.And, conditions), nil
+	case "$or":
+		return combineNodes(ast.Or, conditions), nil
+	case "$nor":
+		return ast.NewUnaryNode(ast.Not, combineNodes(ast.Or, conditions), 0, 0), nil
+	}
+	return nil, newNotReversibleError(operator)
+}
+
+// reverseFieldName converts a dotted field name into variable, member and element nodes.
+func reverseFieldName(name string) ast.Node {
+	parts := strings.Split(name, ".")
+	var result ast.Node = ast.NewVariableNode(parts[0], 0, 0)
+	for _, part := range parts[1:] {
+		if index, err := strconv.Atoi(part); err == nil {
+			result = ast.NewElementNode(result, ast.NewConstantNode(variants.VariantFromInteger(index), 0, 0), 0, 0)
+		} else {
+			result = ast.NewMemberNode(result, part, 0, 0)
+		}
+	}
+	return result
+}
+
+func (c *MongoFilterTranslator) reverseField(name string, value any) (ast.Node, error) {
+	field := reverseFieldName(name)
+
+	operators, ok := value.(map[string]any)
+	if !ok || len(operators) == 0 {
+		if value == nil {
+			return ast.NewUnaryNode(ast.IsNull, field, 0, 0), nil
+		}
+		constant, err := reverseValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return ast.NewBinaryNode(ast.Equal, field, constant, 0, 0), nil
+	}
+
+	keys := make([]string, 0, len(operators))
+	for key := range operators {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conditions := []ast.Node{}
+	for _, key := range keys {
+		condition, err := c.reverseOperator(field, key, operators[key])
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	return combineNodes(ast.And, conditions), nil
+}
+
+func (c *MongoFilterTranslator) reverseOperator(field ast.Node, operator string, value any) (ast.Node, error) {
+	for astOperator, mongoOperator := range mongoOperators {
+		if mongoOperator != operator {
+			continue
+		}
+
+		if astOperator == ast.In || astOperator == ast.NotIn {
+			list, err := reverseValue(value)
+			if err != nil {
+				return nil, err
+			}
+			if _, isList := list.(*ast.FunctionNode); !isList {
+				return nil, newNotReversibleError(operator)
+			}
+			return ast.NewBinaryNode(astOperator, field, list, 0, 0), nil
+		}
+
+		if value == nil && astOperator == ast.Equal {
+			return ast.NewUnaryNode(ast.IsNull, field, 0, 0), nil
+		}
+		if value == nil && astOperator == ast.NotEqual {
+			return ast.NewUnaryNode(ast.IsNotNull, field, 0, 0), nil
+		}
+		constant, err := reverseValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return ast.NewBinaryNode(astOperator, field, constant, 0, 0), nil
+	}
+
+	switch operator {
+	case "$exists":
+		if exists, ok := value.(bool); ok {
+			if exists {
+				return ast.NewUnaryNode(ast.IsNotNull, field, 0, 0), nil
+			}
+			return ast.NewUnaryNode(ast.IsNull, field, 0, 0), nil
+		}
+	case "$regex":
+		if regex, ok := value.(string); ok {
+			return reverseRegex(field, regex, false), nil
+		}
+	case "$not":
+		if operators, ok := value.(map[string]any); ok && len(operators) == 1 {
+			if regex, ok := operators["$regex"].(string); ok {
+				return reverseRegex(field, regex, true), nil
+			}
+		}
+	}
+	return nil, newNotReversibleError(operator)
+}
+
+// reverseRegex converts a regular expression into LIKE comparison when possible
+// or into MATCHES operator otherwise.
+func reverseRegex(field ast.Node, regex string, negate bool) ast.Node {
+	operator := ast.Matches
+	if pattern, ok := regexToLike(regex); ok {
+		regex = pattern
+		operator = ast.Like
+	}
+	if negate && operator == ast.Like {
+		operator = ast.NotLike
+	} else if negate {
+		operator = ast.NotMatches
+	}
+	constant := ast.NewConstantNode(variants.VariantFromString(regex), 0, 0)
+	return ast.NewBinaryNode(operator, field, constant, 0, 0)
+}
+
+// reverseValue converts a filter value into constant node or list of constants for IN operator.
+func reverseValue(value any) (ast.Node, error) {
+	if items, ok := value.([]any); ok {
+		parameters := []ast.Node{}
+		for _, item := range items {
+			parameter, err := reverseValue(item)
+			if err != nil {
+				return nil, err
+			}
+			if _, isConstant := parameter.(*ast.ConstantNode); !isConstant {
+				return nil, newNotReversibleError("[]")
+			}
+			parameters = append(parameters, parameter)
+		}
+		return ast.NewFunctionNode("Array", parameters, 0, 0), nil
+	}
+
+	// Numbers decoded from JSON are always floating point.
+	if number, ok := value.(float64); ok && number == math.Trunc(number) &&
+		number >= math.MinInt32 && number <= math.MaxInt32 {
+		value = int(number)
+	}
+
+	constant := variants.NewVariant(value)
+	switch constant.Type() {
+	case variants.Null, variants.Integer, variants.Long, variants.Float, variants.Double,
+		variants.String, variants.Boolean, variants.DateTime:
+		return ast.NewConstantNode(constant, 0, 0), nil
+	}
+	return nil, newNotReversibleError("{}")
+}
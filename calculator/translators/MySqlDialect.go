@@ -0,0 +1,64 @@
+package translators
+
+import "strings"
+
+// MySqlDialect implements SQL dialect for MySQL.
+type MySqlDialect struct {
+	functions map[string]SqlFunction
+}
+
+// NewMySqlDialect creates a new instance of the dialect.
+func NewMySqlDialect() *MySqlDialect {
+	c := &MySqlDialect{
+		functions: defaultSqlFunctions(),
+	}
+	c.functions["LENGTH"] = NewSqlFunctionTemplate("CHAR_LENGTH({0})")
+	c.functions["LEN"] = NewSqlFunctionTemplate("CHAR_LENGTH({0})")
+	c.functions["MIN"] = NewSqlFunctionTemplate("LEAST({*})")
+	c.functions["MAX"] = NewSqlFunctionTemplate("GREATEST({*})")
+	c.functions["NOW"] = NewSqlFunctionTemplate("NOW()")
+	c.functions["TRUNC"] = NewSqlFunctionTemplate("TRUNCATE({0}, 0)")
+	c.functions["TRUNCATE"] = NewSqlFunctionTemplate("TRUNCATE({0}, 0)")
+	return c
+}
+
+// Name gets the dialect name.
+func (c *MySqlDialect) Name() string {
+	return "MySQL"
+}
+
+// QuoteIdentifier quotes a name of table or column with backticks.
+func (c *MySqlDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// Parameter gets a placeholder of bind parameter.
+func (c *MySqlDialect) Parameter(index int) string {
+	return "?"
+}
+
+// Concat gets a concatenation of strings with CONCAT function.
+func (c *MySqlDialect) Concat(operands []string) string {
+	return "CONCAT(" + strings.Join(operands, ", ") + ")"
+}
+
+// Like gets a LIKE comparison. MySQL uses backslash as escape character by default.
+func (c *MySqlDialect) Like(value string, pattern string, negate bool) string {
+	if negate {
+		return value + " NOT LIKE " + pattern
+	}
+	return value + " LIKE " + pattern
+}
+
+// Matches gets a regular expression match with REGEXP operator.
+func (c *MySqlDialect) Matches(value string, pattern string, negate bool) string {
+	if negate {
+		return value + " NOT REGEXP " + pattern
+	}
+	return value + " REGEXP " + pattern
+}
+
+// Functions gets translations of expression functions.
+func (c *MySqlDialect) Functions() map[string]SqlFunction {
+	return c.functions
+}
@@ -0,0 +1,65 @@
+package translators
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PostgreSqlDialect implements SQL dialect for PostgreSQL.
+type PostgreSqlDialect struct {
+	functions map[string]SqlFunction
+}
+
+// NewPostgreSqlDialect creates a new instance of the dialect.
+func NewPostgreSqlDialect() *PostgreSqlDialect {
+	c := &PostgreSqlDialect{
+		functions: defaultSqlFunctions(),
+	}
+	c.functions["MIN"] = NewSqlFunctionTemplate("LEAST({*})")
+	c.functions["MAX"] = NewSqlFunctionTemplate("GREATEST({*})")
+	c.functions["NOW"] = NewSqlFunctionTemplate("NOW()")
+	c.functions["TRUNC"] = NewSqlFunctionTemplate("TRUNC({0})")
+	c.functions["TRUNCATE"] = NewSqlFunctionTemplate("TRUNC({0})")
+	return c
+}
+
+// Name gets the dialect name.
+func (c *PostgreSqlDialect) Name() string {
+	return "PostgreSQL"
+}
+
+// QuoteIdentifier quotes a name of table or column.
+func (c *PostgreSqlDialect) QuoteIdentifier(name string) string {
+	return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
+}
+
+// Parameter gets a placeholder of bind parameter like <code>$1</code>.
+func (c *PostgreSqlDialect) Parameter(index int) string {
+	return "$" + strconv.Itoa(index)
+}
+
+// Concat gets a concatenation of strings.
+func (c *PostgreSqlDialect) Concat(operands []string) string {
+	return strings.Join(operands, " || ")
+}
+
+// Like gets a LIKE comparison. PostgreSQL uses backslash as escape character by default.
+func (c *PostgreSqlDialect) Like(value string, pattern string, negate bool) string {
+	if negate {
+		return value + " NOT LIKE " + pattern
+	}
+	return value + " LIKE " + pattern
+}
+
+// Matches gets a POSIX regular expression match.
+func (c *PostgreSqlDialect) Matches(value string, pattern string, negate bool) string {
+	if negate {
+		return value + " !~ " + pattern
+	}
+	return value + " ~ " + pattern
+}
+
+// Functions gets translations of expression functions.
+func (c *PostgreSqlDialect) Functions() map[string]SqlFunction {
+	return c.functions
+}
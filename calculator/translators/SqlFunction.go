@@ -0,0 +1,98 @@
+package translators
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+)
+
+// SqlFunction defines a translation of expression function into SQL.
+//	Parameters:
+//		- parameters: The translated function parameters.
+//	Returns: The SQL text of the function call or error if parameters are not valid.
+type SqlFunction func(parameters []string) (string, error)
+
+// NewSqlFunctionTemplate creates a function translation from a template
+// like <code>UPPER({0})</code> where <code>{N}</code> is replaced with the N-th parameter
+// and <code>{*}</code> with all parameters separated by commas.
+//	Parameters:
+//		- template: The SQL template of the function call.
+//	Returns: The created function translation.
+func NewSqlFunctionTemplate(template string) SqlFunction {
+	return func(parameters []string) (string, error) {
+		builder := strings.Builder{}
+		count := 0
+		for index := 0; index < len(template); index++ {
+			if template[index] == '{' {
+				end := strings.IndexByte(template[index:], '}')
+				if end > 0 {
+					name := template[index+1 : index+end]
+					if name == "*" {
+						builder.WriteString(strings.Join(parameters, ", "))
+						count = len(parameters)
+						index += end
+						continue
+					}
+					if number, err := strconv.Atoi(name); err == nil {
+						if number >= len(parameters) {
+							err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
+								"Expected at least "+strconv.Itoa(number+1)+" parameters", 0, 0)
+							return "", err
+						}
+						builder.WriteString(parameters[number])
+						if number+1 > count {
+							count = number + 1
+						}
+						index += end
+						continue
+					}
+				}
+			}
+			builder.WriteByte(template[index])
+		}
+
+		if count < len(parameters) {
+			err := errors.NewExpressionError("", "WRONG_PARAM_COUNT",
+				"Expected "+strconv.Itoa(count)+" parameters", 0, 0)
+			return "", err
+		}
+		return builder.String(), nil
+	}
+}
+
+// defaultSqlFunctions gets translations of functions that are common for all dialects.
+func defaultSqlFunctions() map[string]SqlFunction {
+	return map[string]SqlFunction{
+		"UPPER":   NewSqlFunctionTemplate("UPPER({0})"),
+		"LOWER":   NewSqlFunctionTemplate("LOWER({0})"),
+		"TRIM":    NewSqlFunctionTemplate("TRIM({0})"),
+		"LTRIM":   NewSqlFunctionTemplate("LTRIM({0})"),
+		"RTRIM":   NewSqlFunctionTemplate("RTRIM({0})"),
+		"REPLACE": NewSqlFunctionTemplate("REPLACE({0}, {1}, {2})"),
+		"LENGTH":  NewSqlFunctionTemplate("LENGTH({0})"),
+		"LEN":     NewSqlFunctionTemplate("LENGTH({0})"),
+		"ABS":     NewSqlFunctionTemplate("ABS({0})"),
+		"CEIL":    NewSqlFunctionTemplate("CEIL({0})"),
+		"CEILING": NewSqlFunctionTemplate("CEIL({0})"),
+		"FLOOR":   NewSqlFunctionTemplate("FLOOR({0})"),
+		"ROUND":   NewSqlFunctionTemplate("ROUND({*})"),
+		"SQRT":    NewSqlFunctionTemplate("SQRT({0})"),
+		"EXP":     NewSqlFunctionTemplate("EXP({0})"),
+		"LN":      NewSqlFunctionTemplate("LN({0})"),
+		"LOG10":   NewSqlFunctionTemplate("LOG10({0})"),
+		"SIN":     NewSqlFunctionTemplate("SIN({0})"),
+		"COS":     NewSqlFunctionTemplate("COS({0})"),
+		"TAN":     NewSqlFunctionTemplate("TAN({0})"),
+		"ASIN":    NewSqlFunctionTemplate("ASIN({0})"),
+		"ACOS":    NewSqlFunctionTemplate("ACOS({0})"),
+		"ATAN":    NewSqlFunctionTemplate("ATAN({0})"),
+		"PI":      NewSqlFunctionTemplate("PI()"),
+		"POWER":   NewSqlFunctionTemplate("POWER({0}, {1})"),
+		"NOW":     NewSqlFunctionTemplate("CURRENT_TIMESTAMP"),
+
+		"COALESCE": NewSqlFunctionTemplate("COALESCE({*})"),
+		"IFNULL":   NewSqlFunctionTemplate("COALESCE({0}, {1})"),
+		"NULLIF":   NewSqlFunctionTemplate("NULLIF({0}, {1})"),
+	}
+}
@@ -0,0 +1,441 @@
+package translators
+
+import (
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// Defines precedence levels of SQL operators.
+const (
+	sqlLowestPrecedence = iota
+	sqlOrPrecedence
+	sqlAndPrecedence
+	sqlNotPrecedence
+	sqlComparePrecedence
+	sqlOtherPrecedence
+	sqlAdditivePrecedence
+	sqlMultiplicativePrecedence
+	sqlUnaryPrecedence
+	sqlPrimaryPrecedence
+)
+
+// SqlTranslator translates expressions into SQL conditions for WHERE clauses.
+// Constant values are passed as bind parameters, variables are translated into column names
+// and members of variables into qualified names like <code>"table"."column"</code>.
+// Functions are translated with a mapping table taken from the SQL dialect,
+// the '^' operator is translated with the "Power" function.
+// Constructs that cannot be translated like lambdas or element access produce errors
+// with their position in the expression.
+//
+//	Example:
+//		translator := NewSqlTranslator(NewPostgreSqlDialect())
+//		sql, params, err := translator.TranslateExpression("a > 5 AND Upper(b) IN ('X', 'Y')")
+//		// sql: "a" > $1 AND UPPER("b") IN ($2, $3)
+//		// params: [5 X Y]
+//
+// The translator can be used from several goroutines at once,
+// as long as the function translations are not changed at the same time.
+type SqlTranslator struct {
+	dialect   ISqlDialect
+	functions map[string]SqlFunction
+}
+
+// sqlTranslation keeps the state of a single translation.
+type sqlTranslation struct {
+	*SqlTranslator
+	parameters []any
+}
+
+// NewSqlTranslator creates a new instance of the translator.
+//	Parameters:
+//		- dialect: The SQL dialect or nil to use PostgreSQL dialect.
+func NewSqlTranslator(dialect ISqlDialect) *SqlTranslator {
+	if dialect == nil {
+		dialect = NewPostgreSqlDialect()
+	}
+
+	c := &SqlTranslator{
+		dialect:   dialect,
+		functions: map[string]SqlFunction{},
+	}
+	for name, function := range dialect.Functions() {
+		c.functions[name] = function
+	}
+	return c
+}
+
+// Dialect gets the SQL dialect.
+func (c *SqlTranslator) Dialect() ISqlDialect {
+	return c.dialect
+}
+
+// Function gets translation of expression function.
+//	Parameters:
+//		- name: The function name.
+//	Returns: The function translation or nil if the function cannot be translated.
+func (c *SqlTranslator) Function(name string) SqlFunction {
+	return c.functions[strings.ToUpper(name)]
+}
+
+// SetFunction sets translation of expression function overriding the dialect mapping.
+//	Parameters:
+//		- name: The function name.
+//		- function: The function translation or nil to disable translation of the function.
+func (c *SqlTranslator) SetFunction(name string, function SqlFunction) {
+	if function == nil {
+		delete(c.functions, strings.ToUpper(name))
+	} else {
+		c.functions[strings.ToUpper(name)] = function
+	}
+}
+
+// TranslateExpression parses the expression and translates it into SQL condition.
+//	Parameters:
+//		- expression: The expression string.
+//	Returns: The SQL condition, values of bind parameters and error if the expression cannot be translated.
+func (c *SqlTranslator) TranslateExpression(expression string) (string, []any, error) {
+	parser := parsers.NewExpressionParser()
+	err := parser.SetExpression(expression)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.Translate(parser.SyntaxTree())
+}
+
+// Translate translates the syntax tree into SQL condition.
+//	Parameters:
+//		- node: The root node of the syntax tree.
+//	Returns: The SQL condition, values of bind parameters and error if the tree cannot be translated.
+func (c *SqlTranslator) Translate(node ast.Node) (string, []any, error) {
+	translation := &sqlTranslation{SqlTranslator: c, parameters: []any{}}
+	result, _, err := translation.translateNode(node)
+	if err != nil {
+		return "", nil, err
+	}
+	return result, translation.parameters, nil
+}
+
+func newNotTranslatableError(message string, node ast.Node) error {
+	return errors.NewExpressionError("", ErrNotTranslatable,
+		message+" cannot be translated to SQL", node.Pos().Line, node.Pos().Column)
+}
+
+func (c *sqlTranslation) addParameter(value any) string {
+	c.parameters = append(c.parameters, value)
+	return c.dialect.Parameter(len(c.parameters))
+}
+
+// translateOperand translates the node and puts it in parentheses
+// when its precedence is lower than the minimum one.
+func (c *sqlTranslation) translateOperand(node ast.Node, minPrecedence int) (string, error) {
+	result, precedence, err := c.translateNode(node)
+	if err != nil {
+		return "", err
+	}
+	if precedence < minPrecedence {
+		result = "(" + result + ")"
+	}
+	return result, nil
+}
+
+func (c *sqlTranslation) translateNodes(nodes []ast.Node) ([]string, error) {
+	result := make([]string, len(nodes))
+	for i, node := range nodes {
+		text, _, err := c.translateNode(node)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = text
+	}
+	return result, nil
+}
+
+func (c *sqlTranslation) translateNode(node ast.Node) (string, int, error) {
+	switch n := node.(type) {
+	case *ast.ConstantNode:
+		return c.translateConstant(n)
+	case *ast.VariableNode:
+		return c.dialect.QuoteIdentifier(n.Name), sqlPrimaryPrecedence, nil
+	case *ast.MemberNode:
+		return c.translateMember(n)
+	case *ast.UnaryNode:
+		return c.translateUnary(n)
+	case *ast.BinaryNode:
+		return c.translateBinary(n)
+	case *ast.FunctionNode:
+		return c.translateFunction(n)
+	case *ast.ConditionalNode:
+		whens := []*ast.WhenClause{{When: n.Condition, Then: n.TrueValue}}
+		return c.translateCase(nil, whens, n.FalseValue)
+	case *ast.CaseNode:
+		return c.translateCase(n.Selector, n.Whens, n.Else)
+	case *ast.ElementNode:
+		return "", 0, newNotTranslatableError("Element access '[]'", node)
+	case *ast.LambdaNode:
+		return "", 0, newNotTranslatableError("Lambda expression '=>'", node)
+	case nil:
+		return "NULL", sqlPrimaryPrecedence, nil
+	}
+	return "", 0, newNotTranslatableError("Expression", node)
+}
+
+func (c *sqlTranslation) translateConstant(node *ast.ConstantNode) (string, int, error) {
+	switch node.Value.Type() {
+	case variants.Null:
+		return "NULL", sqlPrimaryPrecedence, nil
+	case variants.Integer, variants.Long, variants.Float, variants.Double,
+		variants.String, variants.Boolean, variants.DateTime:
+		return c.addParameter(node.Value.AsObject()), sqlPrimaryPrecedence, nil
+	case variants.Decimal:
+		// Decimals are passed as strings to keep all their digits.
+		return c.addParameter(node.Value.AsDecimal().String()), sqlPrimaryPrecedence, nil
+	case variants.BigInteger:
+		// Big integers are passed as strings like decimals.
+		return c.addParameter(node.Value.AsBigInteger().String()), sqlPrimaryPrecedence, nil
+	}
+	return "", 0, newNotTranslatableError("Constant value", node)
+}
+
+func (c *sqlTranslation) translateMember(node *ast.MemberNode) (string, int, error) {
+	switch node.Object.(type) {
+	case *ast.VariableNode, *ast.MemberNode:
+		object, _, err := c.translateNode(node.Object)
+		if err != nil {
+			return "", 0, err
+		}
+		return object + "." + c.dialect.QuoteIdentifier(node.Name), sqlPrimaryPrecedence, nil
+	}
+	return "", 0, newNotTranslatableError("Member access '.'", node)
+}
+
+func (c *sqlTranslation) translateUnary(node *ast.UnaryNode) (string, int, error) {
+	switch node.Operator {
+	case ast.Negate:
+		operand, err := c.translateOperand(node.Operand, sqlUnaryPrecedence)
+		if err != nil {
+			return "", 0, err
+		}
+		return "-" + operand, sqlUnaryPrecedence, nil
+	case ast.Not:
+		operand, err := c.translateOperand(node.Operand, sqlNotPrecedence)
+		if err != nil {
+			return "", 0, err
+		}
+		return "NOT " + operand, sqlNotPrecedence, nil
+	case ast.IsNull, ast.IsNotNull:
+		operand, err := c.translateOperand(node.Operand, sqlOtherPrecedence)
+		if err != nil {
+			return "", 0, err
+		}
+		return operand + " " + string(node.Operator), sqlComparePrecedence, nil
+	}
+	return "", 0, newNotTranslatableError("Operator '"+string(node.Operator)+"'", node)
+}
+
+func isNullConstant(node ast.Node) bool {
+	constant, ok := node.(*ast.ConstantNode)
+	return ok && constant.Value.IsNull()
+}
+
+// isStringNode checks if the node is a string constant or concatenation of strings.
+func isStringNode(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.ConstantNode:
+		return n.Value.Type() == variants.String
+	case *ast.BinaryNode:
+		return n.Operator == ast.Add && (isStringNode(n.Left) || isStringNode(n.Right))
+	}
+	return false
+}
+
+func (c *sqlTranslation) translateBinary(node *ast.BinaryNode) (string, int, error) {
+	switch node.Operator {
+	case ast.Equal, ast.NotEqual:
+		// Comparisons with NULL are true only for NULL values as in the calculator.
+		if isNullConstant(node.Left) || isNullConstant(node.Right) {
+			operand := node.Left
+			if isNullConstant(node.Left) {
+				operand = node.Right
+			}
+			operator := ast.IsNull
+			if node.Operator == ast.NotEqual {
+				operator = ast.IsNotNull
+			}
+			return c.translateUnary(ast.NewUnaryNode(operator, operand, node.Line, node.Column))
+		}
+		return c.translateInfix(node, string(node.Operator), sqlComparePrecedence, true)
+	case ast.More, ast.Less, ast.MoreEqual, ast.LessEqual:
+		return c.translateInfix(node, string(node.Operator), sqlComparePrecedence, true)
+	case ast.And:
+		return c.translateInfix(node, "AND", sqlAndPrecedence, false)
+	case ast.Or:
+		return c.translateInfix(node, "OR", sqlOrPrecedence, false)
+	case ast.Add:
+		if isStringNode(node) {
+			return c.translateConcat(node)
+		}
+		return c.translateInfix(node, "+", sqlAdditivePrecedence, false)
+	case ast.Subtract:
+		return c.translateInfix(node, "-", sqlAdditivePrecedence, false)
+	case ast.Multiply, ast.Divide, ast.Modulo:
+		return c.translateInfix(node, string(node.Operator), sqlMultiplicativePrecedence, false)
+	case ast.ShiftLeft, ast.ShiftRight:
+		return c.translateInfix(node, string(node.Operator), sqlOtherPrecedence, false)
+	case ast.Power:
+		return c.translateFunction(ast.NewFunctionNode("Power", []ast.Node{node.Left, node.Right}, node.Line, node.Column))
+	case ast.Coalesce:
+		return c.translateFunction(ast.NewFunctionNode("Coalesce", []ast.Node{node.Left, node.Right}, node.Line, node.Column))
+	case ast.Like, ast.NotLike, ast.Matches, ast.NotMatches:
+		value, err := c.translateOperand(node.Left, sqlOtherPrecedence)
+		if err != nil {
+			return "", 0, err
+		}
+		pattern, err := c.translateOperand(node.Right, sqlOtherPrecedence)
+		if err != nil {
+			return "", 0, err
+		}
+		if node.Operator == ast.Matches || node.Operator == ast.NotMatches {
+			return c.dialect.Matches(value, pattern, node.Operator == ast.NotMatches), sqlComparePrecedence, nil
+		}
+		return c.dialect.Like(value, pattern, node.Operator == ast.NotLike), sqlComparePrecedence, nil
+	case ast.In, ast.NotIn:
+		return c.translateIn(node)
+	}
+	return "", 0, newNotTranslatableError("Operator '"+string(node.Operator)+"'", node)
+}
+
+func (c *sqlTranslation) translateInfix(node *ast.BinaryNode, operator string,
+	precedence int, nonAssociative bool) (string, int, error) {
+
+	leftPrecedence := precedence
+	if nonAssociative {
+		leftPrecedence++
+	}
+	left, err := c.translateOperand(node.Left, leftPrecedence)
+	if err != nil {
+		return "", 0, err
+	}
+	right, err := c.translateOperand(node.Right, precedence+1)
+	if err != nil {
+		return "", 0, err
+	}
+	return left + " " + operator + " " + right, precedence, nil
+}
+
+func (c *sqlTranslation) translateConcat(node *ast.BinaryNode) (string, int, error) {
+	// Collects operands of nested concatenations.
+	operands := []string{}
+	var collect func(node ast.Node) error
+	collect = func(node ast.Node) error {
+		if binary, ok := node.(*ast.BinaryNode); ok && binary.Operator == ast.Add && isStringNode(binary) {
+			if err := collect(binary.Left); err != nil {
+				return err
+			}
+			return collect(binary.Right)
+		}
+		operand, err := c.translateOperand(node, sqlAdditivePrecedence)
+		if err != nil {
+			return err
+		}
+		operands = append(operands, operand)
+		return nil
+	}
+
+	if err := collect(node); err != nil {
+		return "", 0, err
+	}
+	return c.dialect.Concat(operands), sqlOtherPrecedence, nil
+}
+
+func (c *sqlTranslation) translateIn(node *ast.BinaryNode) (string, int, error) {
+	list, ok := node.Right.(*ast.FunctionNode)
+	if !ok || strings.ToUpper(list.Name) != "ARRAY" {
+		return "", 0, newNotTranslatableError("Operator '"+string(node.Operator)+"' with non-list operand", node)
+	}
+
+	// Empty lists are not allowed in SQL.
+	if len(list.Parameters) == 0 {
+		if node.Operator == ast.NotIn {
+			return "1 = 1", sqlComparePrecedence, nil
+		}
+		return "1 = 0", sqlComparePrecedence, nil
+	}
+
+	value, err := c.translateOperand(node.Left, sqlOtherPrecedence)
+	if err != nil {
+		return "", 0, err
+	}
+	values, err := c.translateNodes(list.Parameters)
+	if err != nil {
+		return "", 0, err
+	}
+	return value + " " + string(node.Operator) + " (" + strings.Join(values, ", ") + ")", sqlComparePrecedence, nil
+}
+
+func (c *sqlTranslation) translateFunction(node *ast.FunctionNode) (string, int, error) {
+	name := strings.ToUpper(node.Name)
+	if name == "IF" && len(node.Parameters) == 3 {
+		whens := []*ast.WhenClause{{When: node.Parameters[0], Then: node.Parameters[1]}}
+		return c.translateCase(nil, whens, node.Parameters[2])
+	}
+	if name == "CHOOSE" && len(node.Parameters) >= 2 {
+		whens := []*ast.WhenClause{}
+		for i, parameter := range node.Parameters[1:] {
+			index := ast.NewConstantNode(variants.VariantFromInteger(i+1), parameter.Pos().Line, parameter.Pos().Column)
+			whens = append(whens, &ast.WhenClause{When: index, Then: parameter})
+		}
+		return c.translateCase(node.Parameters[0], whens, nil)
+	}
+
+	function := c.functions[name]
+	if function == nil {
+		return "", 0, newNotTranslatableError("Function '"+node.Name+"'", node)
+	}
+
+	parameters, err := c.translateNodes(node.Parameters)
+	if err != nil {
+		return "", 0, err
+	}
+	result, err := function(parameters)
+	if err != nil {
+		return "", 0, errors.NewExpressionError("", ErrNotTranslatable,
+			"Function '"+node.Name+"' cannot be translated to SQL: "+err.Error(), node.Line, node.Column)
+	}
+	return result, sqlPrimaryPrecedence, nil
+}
+
+func (c *sqlTranslation) translateCase(selector ast.Node, whens []*ast.WhenClause, elseValue ast.Node) (string, int, error) {
+	builder := strings.Builder{}
+	builder.WriteString("CASE")
+	if selector != nil {
+		text, _, err := c.translateNode(selector)
+		if err != nil {
+			return "", 0, err
+		}
+		builder.WriteString(" " + text)
+	}
+	for _, when := range whens {
+		whenText, _, err := c.translateNode(when.When)
+		if err != nil {
+			return "", 0, err
+		}
+		thenText, _, err := c.translateNode(when.Then)
+		if err != nil {
+			return "", 0, err
+		}
+		builder.WriteString(" WHEN " + whenText + " THEN " + thenText)
+	}
+	if elseValue != nil {
+		text, _, err := c.translateNode(elseValue)
+		if err != nil {
+			return "", 0, err
+		}
+		builder.WriteString(" ELSE " + text)
+	}
+	builder.WriteString(" END")
+	return builder.String(), sqlPrimaryPrecedence, nil
+}
@@ -0,0 +1,63 @@
+package translators
+
+import "strings"
+
+// SqliteDialect implements SQL dialect for SQLite.
+// Mathematical functions require SQLite 3.35 or later.
+type SqliteDialect struct {
+	functions map[string]SqlFunction
+}
+
+// NewSqliteDialect creates a new instance of the dialect.
+func NewSqliteDialect() *SqliteDialect {
+	c := &SqliteDialect{
+		functions: defaultSqlFunctions(),
+	}
+	c.functions["MIN"] = NewSqlFunctionTemplate("MIN({*})")
+	c.functions["MAX"] = NewSqlFunctionTemplate("MAX({*})")
+	c.functions["TRUNC"] = NewSqlFunctionTemplate("TRUNC({0})")
+	c.functions["TRUNCATE"] = NewSqlFunctionTemplate("TRUNC({0})")
+	return c
+}
+
+// Name gets the dialect name.
+func (c *SqliteDialect) Name() string {
+	return "SQLite"
+}
+
+// QuoteIdentifier quotes a name of table or column.
+func (c *SqliteDialect) QuoteIdentifier(name string) string {
+	return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
+}
+
+// Parameter gets a placeholder of bind parameter.
+func (c *SqliteDialect) Parameter(index int) string {
+	return "?"
+}
+
+// Concat gets a concatenation of strings.
+func (c *SqliteDialect) Concat(operands []string) string {
+	return strings.Join(operands, " || ")
+}
+
+// Like gets a LIKE comparison. SQLite has no default escape character, so it is set explicitly.
+func (c *SqliteDialect) Like(value string, pattern string, negate bool) string {
+	if negate {
+		return value + " NOT LIKE " + pattern + " ESCAPE '\\'"
+	}
+	return value + " LIKE " + pattern + " ESCAPE '\\'"
+}
+
+// Matches gets a regular expression match with REGEXP operator.
+// SQLite requires the regexp() function to be registered by the application.
+func (c *SqliteDialect) Matches(value string, pattern string, negate bool) string {
+	if negate {
+		return value + " NOT REGEXP " + pattern
+	}
+	return value + " REGEXP " + pattern
+}
+
+// Functions gets translations of expression functions.
+func (c *SqliteDialect) Functions() map[string]SqlFunction {
+	return c.functions
+}
@@ -0,0 +1,7 @@
+package translators
+
+// Translation errors.
+const (
+	// ErrNotTranslatable the expression construct that cannot be translated
+	ErrNotTranslatable = "NOT_TRANSLATABLE"
+)
@@ -0,0 +1,50 @@
+package variables
+
+import "github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+
+// ObjectVariable implements a variable bound to a field of a Go struct or an entry of a Go map.
+// Reading the variable returns the current value of the field converted to variant.
+// Assigned values are written back to the field when it is possible,
+// otherwise they are kept in the variable itself.
+type ObjectVariable struct {
+	member *variants.ObjectMember
+	value  *variants.Variant
+}
+
+// NewObjectVariable constructs this variable and binds it to the object member.
+//	Parameters:
+//		- member: The object member to bind the variable to.
+func NewObjectVariable(member *variants.ObjectMember) *ObjectVariable {
+	if member == nil {
+		panic("Member parameter cannot be nil")
+	}
+	c := &ObjectVariable{
+		member: member,
+	}
+	return c
+}
+
+// Name the variable name.
+func (c *ObjectVariable) Name() string {
+	return c.member.Name()
+}
+
+// Value gets the variable value.
+func (c *ObjectVariable) Value() *variants.Variant {
+	if c.value != nil {
+		return c.value
+	}
+	return c.member.Value()
+}
+
+// SetValue sets the variable value and writes it back to the bound object member.
+func (c *ObjectVariable) SetValue(value *variants.Variant) {
+	if c.member.CanSet() && c.member.SetValue(value) == nil {
+		c.value = nil
+		return
+	}
+	if value == nil {
+		value = variants.EmptyVariant()
+	}
+	c.value = value
+}
@@ -0,0 +1,186 @@
+package variables
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+)
+
+// ObjectVariableCollection implements a variables list that wraps a Go struct or a map with string keys.
+// Variables are resolved on demand by struct field names, "expr" or "json" tags, or map keys.
+// Values are converted to variants automatically and assigned values are written back
+// to the underlying struct (when it is passed by pointer) or map.
+// Variables that are not members of the object are kept in the collection itself.
+// Lookups are safe for concurrent evaluations; writes to the wrapped object are not synchronized.
+type ObjectVariableCollection struct {
+	obj       any
+	lock      sync.Mutex
+	variables map[string]*ObjectVariable
+	extra     *VariableCollection
+}
+
+// NewObjectVariableCollection constructs this collection and binds it to the object.
+//	Parameters:
+//		- obj: A struct, a pointer to struct or a map with string keys.
+func NewObjectVariableCollection(obj any) *ObjectVariableCollection {
+	c := &ObjectVariableCollection{
+		obj:       obj,
+		variables: map[string]*ObjectVariable{},
+		extra:     NewVariableCollection(),
+	}
+	return c
+}
+
+// Object gets the wrapped object.
+func (c *ObjectVariableCollection) Object() any {
+	return c.obj
+}
+
+// getVariable gets a cached variable for the object member.
+func (c *ObjectVariableCollection) getVariable(member *variants.ObjectMember) *ObjectVariable {
+	key := strings.ToUpper(member.Name())
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	v, ok := c.variables[key]
+	if !ok {
+		v = NewObjectVariable(member)
+		c.variables[key] = v
+	}
+	return v
+}
+
+// removeVariable drops a cached variable for the object member.
+func (c *ObjectVariableCollection) removeVariable(member *variants.ObjectMember) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.variables, strings.ToUpper(member.Name()))
+}
+
+// getAll gets object variables followed by extra variables.
+func (c *ObjectVariableCollection) getAll() []IVariable {
+	result := []IVariable{}
+	for _, member := range variants.ListObjectMembers(c.obj) {
+		if c.extra.FindByName(member.Name()) != nil {
+			continue
+		}
+		result = append(result, c.getVariable(member))
+	}
+	result = append(result, c.extra.GetAll()...)
+	return result
+}
+
+// Add a new variable to the collection.
+// The variable is kept in the collection and shadows object member with the same name.
+//	Parameters:
+//		- variable: a variable to be added.
+func (c *ObjectVariableCollection) Add(variable IVariable) {
+	c.extra.Add(variable)
+}
+
+// Length number of variables stored in the collection.
+func (c *ObjectVariableCollection) Length() int {
+	return len(c.getAll())
+}
+
+// Get a variable by its index.
+//	Parameters:
+//		- index: a variable index.
+//	Returns: a retrieved variable.
+func (c *ObjectVariableCollection) Get(index int) IVariable {
+	return c.getAll()[index]
+}
+
+// GetAll variables stores in the collection
+//	Returns: a list with variables.
+func (c *ObjectVariableCollection) GetAll() []IVariable {
+	return c.getAll()
+}
+
+// FindIndexByName variable index in the list by it's name.
+//	Parameters:
+//		- name: The variable name to be found.
+//	Returns: Variable index in the list or <code>-1</code> if variable was not found.
+func (c *ObjectVariableCollection) FindIndexByName(name string) int {
+	name = strings.ToUpper(name)
+	for i, v := range c.getAll() {
+		if strings.ToUpper(v.Name()) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindByName finds variable in the list by it's name.
+//	Parameters:
+//		- name: The variable name to be found.
+//	Returns: Variable or <code>null</code> if function was not found.
+func (c *ObjectVariableCollection) FindByName(name string) IVariable {
+	if v := c.extra.FindByName(name); v != nil {
+		return v
+	}
+	member := variants.FindObjectMember(c.obj, name)
+	if member != nil {
+		return c.getVariable(member)
+	}
+	return nil
+}
+
+// Locate finds variable in the list or create a new one if variable was not found.
+// New variables are added as map entries when the collection wraps a map.
+//	Parameters:
+//		- name: The variable name to be found.
+//	Returns: Found or created variable.
+func (c *ObjectVariableCollection) Locate(name string) IVariable {
+	v := c.FindByName(name)
+	if v != nil {
+		return v
+	}
+	member := variants.AddObjectMember(c.obj, name)
+	if member != nil {
+		return c.getVariable(member)
+	}
+	return c.extra.Locate(name)
+}
+
+// Remove a variable by its index.
+// Map entries are deleted from the map, struct fields cannot be removed and are left unchanged.
+//	Parameters:
+//		- index: a index of the variable to be removed.
+func (c *ObjectVariableCollection) Remove(index int) {
+	c.RemoveByName(c.Get(index).Name())
+}
+
+// RemoveByName removes variable by it's name.
+// Map entries are deleted from the map, struct fields cannot be removed and are left unchanged.
+//	Parameters:
+//		- name: The variable name to be removed.
+func (c *ObjectVariableCollection) RemoveByName(name string) {
+	if c.extra.FindIndexByName(name) >= 0 {
+		c.extra.RemoveByName(name)
+		return
+	}
+	member := variants.FindObjectMember(c.obj, name)
+	if member != nil && member.Remove() == nil {
+		c.removeVariable(member)
+	}
+}
+
+// Clear the collection.
+// Removes variables kept in the collection and all entries of the wrapped map.
+func (c *ObjectVariableCollection) Clear() {
+	c.extra.Clear()
+	for _, member := range variants.ListObjectMembers(c.obj) {
+		if member.Remove() == nil {
+			c.removeVariable(member)
+		}
+	}
+}
+
+// ClearValues clears all stored variables (assigns null values).
+// Struct fields and map entries are set to their zero values.
+func (c *ObjectVariableCollection) ClearValues() {
+	for _, v := range c.getAll() {
+		v.SetValue(variants.EmptyVariant())
+	}
+}
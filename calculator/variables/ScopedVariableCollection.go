@@ -0,0 +1,124 @@
+package variables
+
+import "strings"
+
+// ScopedVariableCollection implements a variables list with a local scope over a parent list.
+// Variables added to the collection shadow parent variables with the same name.
+// The parent variables are visible through the collection, but Remove, Clear and ClearValues
+// change only the scoped variables.
+type ScopedVariableCollection struct {
+	parent IVariableCollection
+	scope  *VariableCollection
+}
+
+// NewScopedVariableCollection constructs this collection over the parent collection.
+//	Parameters:
+//		- parent: The parent list of variables or nil if there is no parent.
+func NewScopedVariableCollection(parent IVariableCollection) *ScopedVariableCollection {
+	if parent == nil {
+		parent = NewVariableCollection()
+	}
+
+	c := &ScopedVariableCollection{
+		parent: parent,
+		scope:  NewVariableCollection(),
+	}
+	return c
+}
+
+// Parent gets the parent list of variables.
+func (c *ScopedVariableCollection) Parent() IVariableCollection {
+	return c.parent
+}
+
+// Add a new variable to the local scope.
+//	Parameters:
+//		- variable: a variable to be added.
+func (c *ScopedVariableCollection) Add(variable IVariable) {
+	c.scope.Add(variable)
+}
+
+// Length number of variables visible in the collection.
+func (c *ScopedVariableCollection) Length() int {
+	return len(c.GetAll())
+}
+
+// Get a variable by its index.
+//	Parameters:
+//		- index: a variable index.
+//	Returns: a retrieved variable.
+func (c *ScopedVariableCollection) Get(index int) IVariable {
+	return c.GetAll()[index]
+}
+
+// GetAll variables visible in the collection. Scoped variables go first.
+//	Returns: a list with variables.
+func (c *ScopedVariableCollection) GetAll() []IVariable {
+	result := c.scope.GetAll()
+	for _, v := range c.parent.GetAll() {
+		if c.scope.FindByName(v.Name()) == nil {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// FindIndexByName variable index in the list by it's name.
+//	Parameters:
+//		- name: The variable name to be found.
+//	Returns: Variable index in the list or <code>-1</code> if variable was not found.
+func (c *ScopedVariableCollection) FindIndexByName(name string) int {
+	name = strings.ToUpper(name)
+	for i, v := range c.GetAll() {
+		if strings.ToUpper(v.Name()) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindByName finds variable in the local scope first and then in the parent list.
+//	Parameters:
+//		- name: The variable name to be found.
+//	Returns: Variable or <code>null</code> if function was not found.
+func (c *ScopedVariableCollection) FindByName(name string) IVariable {
+	if v := c.scope.FindByName(name); v != nil {
+		return v
+	}
+	return c.parent.FindByName(name)
+}
+
+// Locate variable in the list or create a new one in the parent list if variable was not found.
+//	Parameters:
+//		- name: The variable name to be found.
+//	Returns: Found or created variable.
+func (c *ScopedVariableCollection) Locate(name string) IVariable {
+	if v := c.scope.FindByName(name); v != nil {
+		return v
+	}
+	return c.parent.Locate(name)
+}
+
+// Remove a scoped variable by its index.
+//	Parameters:
+//		- index: a index of the variable to be removed.
+func (c *ScopedVariableCollection) Remove(index int) {
+	c.RemoveByName(c.Get(index).Name())
+}
+
+// RemoveByName removes scoped variable by it's name.
+//	Parameters:
+//		- name: The variable name to be removed.
+func (c *ScopedVariableCollection) RemoveByName(name string) {
+	c.scope.RemoveByName(name)
+}
+
+// Clear removes all scoped variables.
+func (c *ScopedVariableCollection) Clear() {
+	c.scope.Clear()
+}
+
+// ClearValues clears all scoped variables (assigns null values).
+func (c *ScopedVariableCollection) ClearValues() {
+	c.scope.ClearValues()
+}
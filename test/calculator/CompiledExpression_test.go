@@ -0,0 +1,186 @@
+package test_calculator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompiledExpression(t *testing.T) {
+	expression, err := calculator.CompileExpression("price * qty + Max(fee, 1)")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"price", "qty", "fee"}, expression.VariableNames())
+
+	vars := variables.NewVariableCollection()
+	vars.Add(variables.NewVariable("price", variants.VariantFromInteger(10)))
+	vars.Add(variables.NewVariable("qty", variants.VariantFromInteger(3)))
+	vars.Add(variables.NewVariable("fee", variants.VariantFromInteger(5)))
+
+	result, err := expression.Evaluate(vars, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, variants.Integer, result.Type())
+	assert.Equal(t, 35, result.AsInteger())
+
+	_, err = expression.Evaluate(nil, nil)
+	assert.NotNil(t, err)
+
+	_, err = calculator.CompileExpression("2 + ")
+	assert.NotNil(t, err)
+
+	// Errors of functions point to the function call
+	expression, err = calculator.CompileExpression("price + Sin(qty, 1)")
+	assert.Nil(t, err)
+	_, err = expression.Evaluate(vars, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code)
+	assert.Contains(t, err.Error(), "at line 1 and column 9")
+	line, column, ok := errors.ErrorPosition(err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, line)
+	assert.Equal(t, 9, column)
+}
+
+func TestCompiledExpressionConcurrency(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+	err := calc.SetExpression("a * 2 + b")
+	assert.Nil(t, err)
+	expression := calc.Compile()
+
+	var wg sync.WaitGroup
+	results := make([]int, 100)
+	for i := 0; i < len(results); i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			vars := variables.NewVariableCollection()
+			vars.Add(variables.NewVariable("a", variants.VariantFromInteger(index)))
+			vars.Add(variables.NewVariable("b", variants.VariantFromInteger(1)))
+			result, err := expression.Evaluate(vars, nil)
+			if err == nil {
+				results[index] = result.AsInteger()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		assert.Equal(t, i*2+1, result)
+	}
+}
+
+func TestCompiledExpressionLimits(t *testing.T) {
+	expression, err := calculator.CompileExpression("1 + 2 + 3 + 4")
+	assert.Nil(t, err)
+
+	limits := calculator.NewEvaluationLimits()
+	limits.MaxSteps = 3
+	_, err = expression.WithLimits(limits).Evaluate(nil, nil)
+	assert.NotNil(t, err)
+
+	result, err := expression.Evaluate(nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, result.AsInteger())
+
+	expression, err = calculator.CompileExpression("'abc' + 'def' + 'ghi'")
+	assert.Nil(t, err)
+	limits = calculator.NewEvaluationLimits()
+	limits.MaxStringLength = 6
+	_, err = expression.WithLimits(limits).Evaluate(nil, nil)
+	assert.NotNil(t, err)
+
+	expression, err = calculator.CompileExpression("Array(1, 2, 3, 4)")
+	assert.Nil(t, err)
+	limits = calculator.NewEvaluationLimits()
+	limits.MaxArrayLength = 3
+	_, err = expression.WithLimits(limits).Evaluate(nil, nil)
+	assert.NotNil(t, err)
+
+	expression, err = calculator.CompileExpression("1 + (2 + (3 + 4))")
+	assert.Nil(t, err)
+	limits = calculator.NewEvaluationLimits()
+	limits.MaxStackDepth = 3
+	_, err = expression.WithLimits(limits).Evaluate(nil, nil)
+	assert.NotNil(t, err)
+}
+
+func TestCompiledExpressionFunctionSizeLimits(t *testing.T) {
+	limits := calculator.NewEvaluationLimits()
+	limits.MaxArrayLength = 3
+	limits.MaxStringLength = 6
+
+	tests := map[string]string{
+		"Split('a,b,c,d', ',')":          "ARRAY_LIMIT_EXCEEDED",
+		"Split('abcd', '')":              "ARRAY_LIMIT_EXCEEDED",
+		"Join(Array('abc', 'def'), 'x')": "STRING_LIMIT_EXCEEDED",
+		"Replace('aaa', 'a', 'bcd')":     "STRING_LIMIT_EXCEEDED",
+	}
+	for source, code := range tests {
+		expression, err := calculator.CompileExpression(source)
+		assert.Nil(t, err)
+		_, err = expression.WithLimits(limits).Evaluate(nil, nil)
+		assert.NotNil(t, err, source)
+		if err != nil {
+			assert.Equal(t, code, err.(*cerrors.ApplicationError).Code, source)
+		}
+	}
+
+	expression, err := calculator.CompileExpression("Split('a,b,c', ',')")
+	assert.Nil(t, err)
+	result, err := expression.WithLimits(limits).Evaluate(nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.Length())
+
+	// Custom functions get the limits from the context
+	var received functions.SizeLimits
+	funcs := functions.NewDefaultFunctionCollection()
+	funcs.Add(functions.NewDelegatedContextFunction("Limits",
+		func(ctx context.Context, params []*variants.Variant,
+			ops variants.IVariantOperations) (*variants.Variant, error) {
+			received = functions.SizeLimitsFromContext(ctx)
+			return variants.EmptyVariant(), nil
+		}))
+	expression, err = calculator.CompileExpression("Limits()")
+	assert.Nil(t, err)
+	_, err = expression.WithLimits(limits).Evaluate(nil, funcs)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, received.MaxArrayLength)
+	assert.Equal(t, 6, received.MaxStringLength)
+}
+
+func TestCompiledExpressionCancellation(t *testing.T) {
+	funcs := functions.NewDefaultFunctionCollection()
+	funcs.Add(functions.NewDelegatedContextFunction("Wait",
+		func(ctx context.Context, parameters []*variants.Variant,
+			variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(5 * time.Second):
+				return variants.VariantFromBoolean(true), nil
+			}
+		}))
+
+	expression, err := calculator.CompileExpression("Wait()")
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = expression.EvaluateWithContext(ctx, nil, funcs)
+	assert.NotNil(t, err)
+
+	ctx, cancel2 := context.WithCancel(context.Background())
+	cancel2()
+	expression, err = calculator.CompileExpression("1 + 2")
+	assert.Nil(t, err)
+	_, err = expression.EvaluateWithContext(ctx, nil, nil)
+	assert.NotNil(t, err)
+}
@@ -1,9 +1,13 @@
 package test_calculator
 
 import (
+	"context"
 	"testing"
 
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
 	"github.com/stretchr/testify/assert"
 )
@@ -56,4 +60,832 @@ func TestExpressionCalculator(t *testing.T) {
 	assert.Nil(t, err1)
 	assert.Equal(t, variants.Boolean, result.Type())
 	assert.True(t, result.AsBoolean())
+
+	err = calculator.SetExpression("'b' IN ('a', 'b') AND 5 NOT IN (1, (2 + 3) * 2)")
+	assert.Nil(t, err)
+	result, err1 = calculator.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, variants.Boolean, result.Type())
+	assert.True(t, result.AsBoolean())
+}
+
+func TestExpressionCalculatorCompileCache(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+	err := calc.SetExpression("a * 2")
+	assert.Nil(t, err)
+
+	compiled := calc.Compile()
+	assert.Same(t, compiled, calc.Compile())
+
+	calc.SetLimits(calculator.EvaluationLimits{MaxSteps: 100})
+	assert.NotSame(t, compiled, calc.Compile())
+	assert.Equal(t, 100, calc.Compile().Limits().MaxSteps)
+
+	compiled = calc.Compile()
+	calc.SetVariantOperations(variants.NewTypeSafeVariantOperations())
+	assert.NotSame(t, compiled, calc.Compile())
+
+	compiled = calc.Compile()
+	calc.SetAutoOptimize(true)
+	assert.NotSame(t, compiled, calc.Compile())
+
+	err = calc.SetExpression("a * 3")
+	assert.Nil(t, err)
+	calc.DefaultVariables().FindByName("a").SetValue(variants.VariantFromInteger(2))
+	result, err := calc.Evaluate()
+	assert.Nil(t, err)
+	assert.Equal(t, 6, result.AsInteger())
+}
+
+func TestExpressionCalculatorOptimizedLimits(t *testing.T) {
+	limits := calculator.NewEvaluationLimits()
+	limits.MaxStringLength = 10
+	limits.MaxArrayLength = 5
+	limits.MaxSteps = 20
+
+	cases := map[string]string{
+		"Repeat('a', 1000)":                  "STRING_LIMIT_EXCEEDED",
+		"'abcdefghijk' + 'abcdefghij'":       "STRING_LIMIT_EXCEEDED",
+		"Array(1, 2, 3, 4, 5, 6, 7)":         "ARRAY_LIMIT_EXCEEDED",
+		"Upper('abc' + 'defghijklmn') + 'x'": "STRING_LIMIT_EXCEEDED",
+	}
+
+	// Constant sub-expressions that exceed the limits are not folded by the optimizer
+	for _, optimize := range []bool{false, true} {
+		for expression, code := range cases {
+			calc := calculator.NewExpressionCalculator()
+			calc.SetAutoOptimize(optimize)
+			calc.SetLimits(limits)
+			err := calc.SetExpression(expression)
+			assert.Nil(t, err)
+			_, err1 := calc.Evaluate()
+			assert.NotNil(t, err1, expression)
+			if err1 != nil {
+				assert.Equal(t, code, err1.(*cerrors.ApplicationError).Code, expression)
+			}
+		}
+
+		calc := calculator.NewExpressionCalculator()
+		calc.SetAutoOptimize(optimize)
+		calc.SetLimits(limits)
+		err := calc.SetExpression("Repeat('a', 5) + 'b'")
+		assert.Nil(t, err)
+		result, err1 := calc.Evaluate()
+		assert.Nil(t, err1)
+		assert.Equal(t, "aaaaab", result.AsString())
+	}
+
+	// Cancelled optimization leaves the expression unfolded
+	optimizer := calculator.NewExpressionOptimizer(nil, nil)
+	calc := calculator.NewExpressionCalculator()
+	err := calc.SetExpression("1 + 2")
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Len(t, optimizer.OptimizeWithContext(ctx, calc.ResultTokens()), 3)
+	assert.Len(t, optimizer.Optimize(calc.ResultTokens()), 1)
+}
+
+func TestExpressionCalculatorDecimals(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	testCases := []struct {
+		expression string
+		expected   string
+	}{
+		{"0.1m + 0.2m", "0.3"},
+		{"3 * 12.30m", "36.90"},
+		{"12.30m * 3", "36.90"},
+		{"10.00m / 4", "2.50"},
+		{"1m / 3", "0.3333333333333333"},
+		{"-2.50m", "-2.50"},
+		{"Round(2.345m, 2)", "2.35"},
+		{"Round(2.345m, 2, 'HalfEven')", "2.34"},
+		{"Round(0.125m, 2)", "0.13"},
+		{"Round(2.5m)", "3"},
+		{"Round(-2.5m)", "-3"},
+		{"Floor(-2.5m)", "-3"},
+		{"Trunc(-2.5m)", "-2"},
+		{"Abs(-2.50m)", "2.50"},
+		{"Sum(1.10m, 2.20m, 3.30m)", "6.60"},
+		{"Avg(1.10m, 2.20m, 3.30m)", "2.20"},
+		{"Avg(0.1m, 0.2m)", "0.15"},
+		{"Avg(Array(1, 0.2m))", "0.6"},
+		{"Avg(1m, 1, 2m)", "1.3333333333333333"},
+	}
+	for _, test := range testCases {
+		err := calc.SetExpression(test.expression)
+		assert.Nil(t, err, test.expression)
+		result, err := calc.Evaluate()
+		assert.Nil(t, err, test.expression)
+		assert.Equal(t, variants.Decimal, result.Type(), test.expression)
+		assert.Equal(t, test.expected, result.AsDecimal().String(), test.expression)
+	}
+
+	err := calc.SetExpression("0.1m + 0.2m = 0.3")
+	assert.Nil(t, err)
+	result, err := calc.Evaluate()
+	assert.Nil(t, err)
+	assert.True(t, result.AsBoolean())
+
+	// Doubles are rounded by decimal digits too.
+	err = calc.SetExpression("Round(2.675, 2)")
+	assert.Nil(t, err)
+	result, err = calc.Evaluate()
+	assert.Nil(t, err)
+	assert.Equal(t, 2.68, result.AsDouble())
+
+	err = calc.SetExpression("Round(2.5m, 0, 'Sideways')")
+	assert.Nil(t, err)
+	_, err = calc.Evaluate()
+	assert.Equal(t, "WRONG_PARAM_VALUE", err.(*cerrors.ApplicationError).Code)
+}
+
+func TestExpressionCalculatorUnicodeStrings(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	cases := map[string]string{
+		"Upper('straße')":           "STRAßE",
+		"Lower('ÉCOLE')":            "école",
+		"Reverse('héllo')":          "olléh",
+		"Left('日本語テキスト', 3)":        "日本語",
+		"Substring('привет', 1, 3)": "рив",
+		"PadLeft('é', 3, 'ü')":      "üüé",
+		"'ça' + ' va'":              "ça va",
+		"'l''été'":                  "l'été",
+	}
+	for expression, expected := range cases {
+		err := calc.SetExpression(expression)
+		assert.Nil(t, err, expression)
+		result, err1 := calc.Evaluate()
+		assert.Nil(t, err1, expression)
+		assert.Equal(t, expected, result.AsString(), expression)
+	}
+
+	err := calc.SetExpression("Len('héllo')")
+	assert.Nil(t, err)
+	result, err1 := calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 5, result.AsInteger())
+}
+
+func TestExpressionCalculatorIntegerOverflow(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	err := calc.SetExpression("9223372036854775807 + 1")
+	assert.Nil(t, err)
+	_, err = calc.Evaluate()
+	assert.NotNil(t, err)
+	assert.Equal(t, "INTEGER_OVERFLOW", err.(*cerrors.ApplicationError).Code)
+
+	calc.VariantOperations().SetOverflowMode(variants.OverflowPromote)
+	result, err := calc.Evaluate()
+	assert.Nil(t, err)
+	assert.Equal(t, variants.BigInteger, result.Type())
+	assert.Equal(t, "9223372036854775808", result.String())
+
+	err = calc.SetExpression("Factorial(25) / Factorial(23)")
+	assert.Nil(t, err)
+	result, err = calc.Evaluate()
+	assert.Nil(t, err)
+	assert.Equal(t, "600", result.String())
+
+	err = calc.SetExpression("123456789012345678901234567890 % 97")
+	assert.Nil(t, err)
+	result, err = calc.Evaluate()
+	assert.Nil(t, err)
+	assert.Equal(t, "52", result.String())
+}
+
+func TestExpressionCalculatorLike(t *testing.T) {
+	calculator := calculator.NewExpressionCalculator()
+
+	err := calculator.SetExpression("'abcdef' LIKE 'abc%'")
+	assert.Nil(t, err)
+	result, err1 := calculator.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, variants.Boolean, result.Type())
+	assert.True(t, result.AsBoolean())
+
+	err = calculator.SetExpression("'abcdef' NOT LIKE 'a_c%'")
+	assert.Nil(t, err)
+	result, err1 = calculator.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, variants.Boolean, result.Type())
+	assert.False(t, result.AsBoolean())
+
+	err = calculator.SetExpression("'ABC' LIKE 'abc'")
+	assert.Nil(t, err)
+	result, err1 = calculator.Evaluate()
+	assert.Nil(t, err1)
+	assert.False(t, result.AsBoolean())
+
+	operations := variants.NewTypeUnsafeVariantOperations()
+	operations.SetLikeCaseSensitive(false)
+	calculator.SetVariantOperations(operations)
+	result, err1 = calculator.Evaluate()
+	assert.Nil(t, err1)
+	assert.True(t, result.AsBoolean())
+
+	err = calculator.SetExpression("123 LIKE '1%'")
+	assert.Nil(t, err)
+	result, err1 = calculator.Evaluate()
+	assert.Nil(t, err1)
+	assert.True(t, result.AsBoolean())
+
+	calculator.SetVariantOperations(variants.NewTypeSafeVariantOperations())
+	_, err1 = calculator.Evaluate()
+	assert.NotNil(t, err1)
+}
+
+func TestExpressionCalculatorRegex(t *testing.T) {
+	vars := variables.NewVariableCollection()
+	vars.Add(variables.NewVariable("sku", variants.VariantFromString("ABC-123")))
+	vars.Add(variables.NewVariable("zip", variants.VariantFromString("1234")))
+	vars.Add(variables.NewVariable("pattern", variants.VariantFromString("[")))
+	vars.Add(variables.EmptyVariable("missing"))
+
+	tests := []struct {
+		expression string
+		expected   any
+	}{
+		{"sku MATCHES '^[A-Z]{3}-\\d+$'", true},
+		{"zip ~ '^\\d{5}$'", false},
+		{"zip !~ '^\\d{5}$' AND zip NOT MATCHES 'x'", true},
+		{"missing MATCHES 'a'", nil},
+		{"12345 ~ '^\\d+$'", true},
+		{"RegexMatch(sku, '^ABC')", true},
+		{"RegexReplace(sku, '(\\w+)-(\\d+)', '$2/$1')", "123/ABC"},
+		{"RegexExtract(sku, '\\d+')", "123"},
+		{"RegexExtract(sku, '([A-Z])([A-Z])', 2)", "B"},
+		{"RegexExtract(zip, '[a-z]')", nil},
+		{"RegexSplit('a1b22c', '\\d+')", []any{"a", "b", "c"}},
+		{"RegexMatch(missing, 'a')", nil},
+	}
+
+	for _, test := range tests {
+		compiled, err := calculator.CompileExpression(test.expression)
+		assert.Nil(t, err, test.expression)
+		result, err := compiled.Evaluate(vars, nil)
+		assert.Nil(t, err, test.expression)
+		assert.Equal(t, test.expected, variants.VariantToValue(result), test.expression)
+	}
+
+	// Constant patterns are validated by the parser.
+	_, err := calculator.CompileExpression("sku MATCHES '[a-'")
+	assert.NotNil(t, err)
+	assert.Equal(t, "INVALID_PATTERN", err.(*cerrors.ApplicationError).Code)
+	assert.Contains(t, err.Error(), "at line 1 and column 13")
+
+	compiled, _ := calculator.CompileExpression("sku ~ pattern")
+	_, err = compiled.Evaluate(vars, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "INVALID_PATTERN", err.(*cerrors.ApplicationError).Code)
+	assert.Contains(t, err.Error(), "at line 1 and column 5")
+
+	compiled, _ = calculator.CompileExpression("sku + RegexReplace(sku, pattern, '')")
+	_, err = compiled.Evaluate(vars, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "INVALID_PATTERN", err.(*cerrors.ApplicationError).Code)
+	assert.Contains(t, err.Error(), "at line 1 and column 7")
+}
+
+type testItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+type testOrder struct {
+	Customer map[string]any
+	Items    []testItem
+}
+
+func TestExpressionCalculatorMembers(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+	order := testOrder{
+		Customer: map[string]any{"country": "US", "address": map[string]any{"city": "Boston"}},
+		Items:    []testItem{{Price: 2.5}, {Price: 7}},
+	}
+	calc.DefaultVariables().Add(variables.NewVariable("order", variants.VariantFromValue(order)))
+
+	err := calc.SetExpression("order.customer.country = 'US'")
+	assert.Nil(t, err)
+	result, err1 := calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, variants.Boolean, result.Type())
+	assert.True(t, result.AsBoolean())
+
+	err = calc.SetExpression("order.customer.address.city")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, "Boston", result.AsString())
+
+	err = calc.SetExpression("order.items[1].price * 2")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, variants.Double, result.Type())
+	assert.Equal(t, 14.0, result.AsDouble())
+
+	err = calc.SetExpression("order.customer.zip IS NULL")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.True(t, result.AsBoolean())
+
+	operations := variants.NewTypeUnsafeVariantOperations()
+	operations.SetStrictMembers(true)
+	calc.SetVariantOperations(operations)
+	_, err1 = calc.Evaluate()
+	assert.NotNil(t, err1)
+
+	err = calc.SetExpression("order.")
+	assert.NotNil(t, err)
+}
+
+func TestExpressionCalculatorShortCircuit(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+	calls := 0
+	calc.DefaultFunctions().Add(functions.NewDelegatedFunction("Touch",
+		func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			calls++
+			return variants.VariantFromBoolean(true), nil
+		}))
+
+	err := calc.SetExpression("If(x <> 0, 10 / x, 0)")
+	assert.Nil(t, err)
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(0))
+	result, err1 := calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 0, result.AsInteger())
+
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(5))
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 2, result.AsInteger())
+
+	err = calc.SetExpression("1 > 2 AND Touch()")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.False(t, result.AsBoolean())
+	assert.Equal(t, 0, calls)
+
+	err = calc.SetExpression("1 < 2 OR Touch()")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.True(t, result.AsBoolean())
+	assert.Equal(t, 0, calls)
+
+	err = calc.SetExpression("1 < 2 AND Touch() AND 2 > 1")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.True(t, result.AsBoolean())
+	assert.Equal(t, 1, calls)
+
+	err = calc.SetExpression("6 AND 3")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 2, result.AsInteger())
+
+	err = calc.SetExpression("Choose(2, Touch(), 'b', Touch())")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, "b", result.AsString())
+	assert.Equal(t, 1, calls)
+
+	err = calc.SetExpression("Choose(0, 'a', 'b')")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 0, result.AsInteger())
+
+	err = calc.SetExpression("Choose(3, 'a', 'b')")
+	assert.Nil(t, err)
+	_, err1 = calc.Evaluate()
+	assert.NotNil(t, err1)
+}
+
+func TestExpressionCalculatorDefaultRounding(t *testing.T) {
+	// Decimal division and Round function use the same default rounding mode
+	assert.Equal(t, variants.RoundHalfUp, variants.DefaultRoundingMode)
+	operations := variants.NewTypeUnsafeVariantOperations()
+	assert.Equal(t, variants.DefaultRoundingMode, operations.DecimalRoundingMode())
+	operations.SetDecimalDivisionScale(2)
+
+	calc := calculator.NewExpressionCalculator()
+	calc.SetVariantOperations(operations)
+	for expression, expected := range map[string]string{
+		"1m / 8":            "0.13",
+		"Round(0.125m, 2)":  "0.13",
+		"-1m / 8":           "-0.13",
+		"Round(-0.125m, 2)": "-0.13",
+		"Round(2.5)":        "3",
+		"Round(0.125, 2)":   "0.13",
+	} {
+		err := calc.SetExpression(expression)
+		assert.Nil(t, err, expression)
+		result, err1 := calc.Evaluate()
+		assert.Nil(t, err1, expression)
+		assert.Equal(t, expected, result.String(), expression)
+	}
+}
+
+func TestExpressionCalculatorLazyFunctions(t *testing.T) {
+	// Choose requires the index and at least two values
+	calc := calculator.NewExpressionCalculator()
+	err := calc.SetExpression("Choose(1, 'a')")
+	assert.Nil(t, err)
+	_, err1 := calc.Evaluate()
+	assert.NotNil(t, err1)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err1.(*cerrors.ApplicationError).Code)
+
+	err = calc.SetExpression("If(TRUE, 1)")
+	assert.Nil(t, err)
+	_, err1 = calc.Evaluate()
+	assert.NotNil(t, err1)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err1.(*cerrors.ApplicationError).Code)
+
+	// Functions that are not lazy replace standard lazy functions
+	calls := 0
+	calc.DefaultFunctions().RemoveByName("If")
+	calc.DefaultFunctions().Add(functions.NewDelegatedFunction("If",
+		func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			calls++
+			return variants.VariantFromString("custom"), nil
+		}))
+	err = calc.SetExpression("If(TRUE, 1, 2)")
+	assert.Nil(t, err)
+	result, err1 := calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, "custom", result.AsString())
+	assert.Equal(t, 1, calls)
+
+	// Custom functions can be lazy
+	calc.DefaultFunctions().Add(functions.NewLazyDelegatedFunction("Pick", functions.LazyChoice,
+		func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			return nil, nil
+		}))
+	calc.DefaultFunctions().Add(functions.NewDelegatedFunction("Fail",
+		func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			return nil, cerrors.NewError("Fail is called")
+		}))
+	err = calc.SetExpression("Pick(2, Fail(), 'b')")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, "b", result.AsString())
+}
+
+func TestExpressionCalculatorLazyFunctionOverrides(t *testing.T) {
+	calls := 0
+	funcs := functions.NewDefaultFunctionCollection()
+	funcs.RemoveByName("If")
+	funcs.Add(functions.NewDelegatedFunction("If",
+		func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			calls++
+			return variants.VariantFromString("custom if"), nil
+		}))
+	funcs.RemoveByName("Choose")
+	funcs.Add(functions.NewDelegatedFunction("Choose",
+		func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			return variants.VariantFromString("custom choose"), nil
+		}))
+	funcs.Add(functions.NewDelegatedFunction("Touch",
+		func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			calls++
+			return variants.VariantFromString("touched"), nil
+		}))
+
+	// Functions passed during evaluation replace lazy functions and get all parameters evaluated
+	for _, optimize := range []bool{false, true} {
+		calc := calculator.NewExpressionCalculator()
+		calc.SetAutoOptimize(optimize)
+		err := calc.SetExpression("If(TRUE, 'x', Touch()) + ' ' + Choose(1, 'a', 'b')")
+		assert.Nil(t, err)
+
+		result, err1 := calc.Evaluate()
+		assert.Nil(t, err1)
+		assert.Equal(t, "x a", result.AsString())
+
+		calls = 0
+		result, err1 = calc.EvaluateUsingVariablesAndFunctions(nil, funcs)
+		assert.Nil(t, err1)
+		assert.Equal(t, "custom if custom choose", result.AsString())
+		assert.Equal(t, 2, calls)
+
+		result, err1 = calc.EvaluateWithContext(context.Background(), nil, funcs)
+		assert.Nil(t, err1)
+		assert.Equal(t, "custom if custom choose", result.AsString())
+
+		// The compiled expression is not changed by the replacements
+		result, err1 = calc.Evaluate()
+		assert.Nil(t, err1)
+		assert.Equal(t, "x a", result.AsString())
+	}
+
+	expression, err := calculator.CompileExpression("If(1 > 0, 'yes', 'no')")
+	assert.Nil(t, err)
+	result, err1 := expression.Evaluate(nil, funcs)
+	assert.Nil(t, err1)
+	assert.Equal(t, "custom if", result.AsString())
+	result, err1 = expression.Optimize().Evaluate(nil, funcs)
+	assert.Nil(t, err1)
+	assert.Equal(t, "custom if", result.AsString())
+}
+
+func TestExpressionCalculatorFunctionSignatures(t *testing.T) {
+	// Numbers of parameters are checked by signatures when functions are called
+	calc := calculator.NewExpressionCalculator()
+	err := calc.SetExpression("Sin(1, 2)")
+	assert.Nil(t, err)
+	_, err1 := calc.Evaluate()
+	assert.NotNil(t, err1)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err1.(*cerrors.ApplicationError).Code)
+
+	_, err1 = calc.Compile().Evaluate(nil, nil)
+	assert.NotNil(t, err1)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err1.(*cerrors.ApplicationError).Code)
+
+	err = calc.SetExpression("Sin(0)")
+	assert.Nil(t, err)
+	result, err1 := calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 0.0, result.AsDouble())
+}
+
+func TestExpressionCalculatorThreeValuedLogic(t *testing.T) {
+	// Null stands for an unknown value, it defines the result only when the other operand does not.
+	testCases := map[string]any{
+		"FALSE AND NULL": false,
+		"NULL AND FALSE": false,
+		"TRUE AND NULL":  nil,
+		"NULL AND TRUE":  nil,
+		"NULL AND NULL":  nil,
+		"TRUE OR NULL":   true,
+		"NULL OR TRUE":   true,
+		"FALSE OR NULL":  nil,
+		"NULL OR FALSE":  nil,
+		"NULL OR NULL":   nil,
+		"x AND 1 > 2":    false,
+		"1 > 2 AND x":    false,
+		"x OR 1 < 2":     true,
+		"1 < 2 OR x":     true,
+		"x AND 1 < 2":    nil,
+		"1 > 2 OR x":     nil,
+	}
+
+	for _, optimize := range []bool{false, true} {
+		calc := calculator.NewExpressionCalculator()
+		calc.SetAutoOptimize(optimize)
+		for expression, expected := range testCases {
+			err := calc.SetExpression(expression)
+			assert.Nil(t, err, expression)
+			result, err1 := calc.Evaluate()
+			assert.Nil(t, err1, expression)
+			if expected == nil {
+				assert.True(t, result.IsNull(), expression)
+			} else {
+				assert.Equal(t, variants.Boolean, result.Type(), expression)
+				assert.Equal(t, expected, result.AsBoolean(), expression)
+			}
+		}
+	}
+}
+
+func TestExpressionCalculatorNullCoalescing(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+	calls := 0
+	calc.DefaultFunctions().Add(functions.NewDelegatedFunction("Touch",
+		func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			calls++
+			return variants.VariantFromInteger(-1), nil
+		}))
+
+	// Variables without values are null.
+	err := calc.SetExpression("x ?? 0 > 5")
+	assert.Nil(t, err)
+	result, err1 := calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.False(t, result.AsBoolean())
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(7))
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.True(t, result.AsBoolean())
+
+	testCases := []struct {
+		expression string
+		expected   *variants.Variant
+	}{
+		{"a ?? 'a'", variants.VariantFromString("a")},
+		{"a ?? b ?? 3", variants.VariantFromInteger(3)},
+		{"NULL ?? 'n'", variants.VariantFromString("n")},
+		{"5 ?? NULL", variants.VariantFromInteger(5)},
+		{"a ?? 1 + 2", variants.VariantFromInteger(3)},
+		{"FALSE ?? TRUE", variants.VariantFromBoolean(false)},
+		{"Coalesce(a, b, 2.5m)", variants.VariantFromDecimal(variants.NewDecimal(25, 1))},
+		{"Coalesce(a)", variants.EmptyVariant()},
+		{"IfNull(a, 'b')", variants.VariantFromString("b")},
+		{"NullIf(5, 5)", variants.EmptyVariant()},
+		{"NullIf(5, 6)", variants.VariantFromInteger(5)},
+		{"NullIf('', '') ?? 'empty'", variants.VariantFromString("empty")},
+		{"5 ?? Touch()", variants.VariantFromInteger(5)},
+		{"Coalesce(1, Touch(), Touch())", variants.VariantFromInteger(1)},
+		{"IfNull('a', Touch())", variants.VariantFromString("a")},
+	}
+	for _, test := range testCases {
+		err = calc.SetExpression(test.expression)
+		assert.Nil(t, err, test.expression)
+		result, err1 = calc.Evaluate()
+		assert.Nil(t, err1, test.expression)
+		assert.Equal(t, test.expected.Type(), result.Type(), test.expression)
+		assert.True(t, test.expected.Equals(result), test.expression)
+	}
+	assert.Equal(t, 0, calls)
+
+	err = calc.SetExpression("a ?? Touch()")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, -1, result.AsInteger())
+	assert.Equal(t, 1, calls)
+
+	err = calc.SetExpression("IfNull(1)")
+	assert.Nil(t, err)
+	_, err1 = calc.Evaluate()
+	assert.Equal(t, "WRONG_PARAM_COUNT", err1.(*cerrors.ApplicationError).Code)
+
+	err = calc.SetExpression("IfNull(1, Touch(), 2)")
+	assert.Nil(t, err)
+	_, err1 = calc.Evaluate()
+	assert.Equal(t, "WRONG_PARAM_COUNT", err1.(*cerrors.ApplicationError).Code)
+
+	// Functions that are not lazy replace standard lazy functions
+	calls = 0
+	calc.DefaultFunctions().RemoveByName("Coalesce")
+	calc.DefaultFunctions().Add(functions.NewDelegatedFunction("Coalesce",
+		func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			return variants.VariantFromInteger(len(parameters)), nil
+		}))
+	err = calc.SetExpression("Coalesce(1, Touch())")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 2, result.AsInteger())
+	assert.Equal(t, 1, calls)
+}
+
+func TestExpressionCalculatorCoalesceOverrides(t *testing.T) {
+	funcs := functions.NewDefaultFunctionCollection()
+	for _, name := range []string{"Coalesce", "IfNull"} {
+		name := name
+		funcs.RemoveByName(name)
+		funcs.Add(functions.NewDelegatedFunction(name,
+			func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+				return variants.VariantFromString("custom " + name), nil
+			}))
+	}
+
+	calc := calculator.NewExpressionCalculator()
+	err := calc.SetExpression("Coalesce(NULL, 'a') + ' ' + IfNull(NULL, 'b') + ' ' + (NULL ?? 'c')")
+	assert.Nil(t, err)
+	result, err1 := calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, "a b c", result.AsString())
+
+	// Functions passed during evaluation replace lazy functions, while '??' operator is not changed
+	result, err1 = calc.EvaluateUsingVariablesAndFunctions(nil, funcs)
+	assert.Nil(t, err1)
+	assert.Equal(t, "custom Coalesce custom IfNull c", result.AsString())
+}
+
+func TestExpressionCalculatorConditions(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	err := calc.SetExpression("x > 0 ? 10 / x : x < 0 ? -1 : 0")
+	assert.Nil(t, err)
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(0))
+	result, err1 := calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 0, result.AsInteger())
+
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(-5))
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, -1, result.AsInteger())
+
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(5))
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 2, result.AsInteger())
+
+	err = calc.SetExpression("CASE WHEN x = 0 THEN 'zero' WHEN 10 / x > 1 THEN 'small' ELSE 'big' END")
+	assert.Nil(t, err)
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(0))
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, "zero", result.AsString())
+
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(20))
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, "big", result.AsString())
+
+	err = calc.SetExpression("CASE x + 1 WHEN 1 THEN 'one' WHEN 2 THEN 'two' END")
+	assert.Nil(t, err)
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(1))
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, "two", result.AsString())
+
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(5))
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.True(t, result.IsNull())
+
+	err = calc.SetExpression("Max(1, TRUE ? 5 : 2) * 2")
+	assert.Nil(t, err)
+	result, err1 = calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 10, result.AsInteger())
+
+	err = calc.SetExpression("x > 0 ? 1 2")
+	assert.NotNil(t, err)
+	err = calc.SetExpression("CASE WHEN x THEN 1")
+	assert.NotNil(t, err)
+	err = calc.SetExpression("CASE x 1 END")
+	assert.NotNil(t, err)
+}
+
+func TestExpressionCalculatorLambdas(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	err := calc.SetExpression("Map(items, x => x.price * k)")
+	assert.Nil(t, err)
+	assert.Nil(t, calc.DefaultVariables().FindByName("x"))
+	calc.DefaultVariables().FindByName("items").SetValue(variants.VariantFromValue([]testItem{
+		{Name: "a", Price: 3},
+		{Name: "b", Price: 1},
+		{Name: "c", Price: 2},
+	}))
+	calc.DefaultVariables().FindByName("k").SetValue(variants.VariantFromInteger(2))
+	result, err1 := calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, []any{6.0, 2.0, 4.0}, variants.VariantToValue(result))
+
+	items := calc.DefaultVariables().FindByName("items").Value()
+	vars := variables.NewVariableCollection()
+	vars.Add(variables.NewVariable("items", items))
+	vars.Add(variables.NewVariable("x", variants.VariantFromInteger(100)))
+	vars.Add(variables.EmptyVariable("missing"))
+
+	tests := []struct {
+		expression string
+		expected   any
+	}{
+		{"Map(Filter(items, x => x.price > 1), x => x.name)", []any{"a", "c"}},
+		{"Reduce(items, (acc, x) => acc + x.price, 0)", 6},
+		{"Reduce(Array(1, 2, 3), (a, b) => a * b)", 6},
+		{"Any(items, x => x.price > 2)", true},
+		{"All(items, x => x.price > 2)", false},
+		{"Find(items, x => x.price = 1).name", "b"},
+		{"Map(SortBy(items, x => x.price), x => x.name)", []any{"b", "c", "a"}},
+		{"Map(Array(1, 2), (v, i) => v + i + x)", []any{101, 103}},
+		{"Map(Array(1, 2), x => Map(Array(10, 20), y => x + y))", []any{[]any{11, 21}, []any{12, 22}}},
+		{"Map(GroupBy(Array(1, 2, 3, 4), v => v % 2), g => Sum(g.key, Sum(g.items[0], 0)))", []any{2, 2}},
+		{"Map(missing, x => x)", nil},
+		{"(x) => x + 1", nil},
+	}
+
+	for _, test := range tests {
+		compiled, err := calculator.CompileExpression(test.expression)
+		assert.Nil(t, err, test.expression)
+		result, err := compiled.Evaluate(vars, nil)
+		assert.Nil(t, err, test.expression)
+		if test.expression == "(x) => x + 1" {
+			lambda, ok := result.AsObject().(functions.ILambda)
+			assert.True(t, ok)
+			assert.Equal(t, []string{"x"}, lambda.Parameters())
+			result, err = lambda.Invoke([]*variants.Variant{variants.VariantFromInteger(1)})
+			assert.Nil(t, err)
+			assert.Equal(t, 2, result.AsInteger())
+			continue
+		}
+		assert.Equal(t, test.expected, variants.VariantToValue(result), test.expression)
+	}
+
+	compiled, _ := calculator.CompileExpression("Map(Array(1, 2), x => x + y)")
+	assert.Equal(t, []string{"y"}, compiled.VariableNames())
+
+	_, err1 = calculator.CompileExpression("Map(items, x => )")
+	assert.NotNil(t, err1)
+
+	compiled, _ = calculator.CompileExpression("Map(items, 123)")
+	_, err1 = compiled.Evaluate(vars, nil)
+	assert.NotNil(t, err1)
 }
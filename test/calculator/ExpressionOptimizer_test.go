@@ -0,0 +1,184 @@
+package test_calculator
+
+import (
+	"testing"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpressionOptimizerFolding(t *testing.T) {
+	optimizer := calculator.NewExpressionOptimizer(nil, nil)
+	parser := parsers.NewExpressionParser()
+
+	err := parser.SetExpression("2 * Pi() * r")
+	assert.Nil(t, err)
+	tokens := optimizer.Optimize(parser.ResultTokens())
+	assert.Equal(t, 3, len(tokens))
+	assert.Equal(t, parsers.Constant, tokens[0].Type())
+	assert.Equal(t, parsers.Variable, tokens[1].Type())
+
+	// Identities are not simplified by default
+	err = parser.SetExpression("x * 1 + 0")
+	assert.Nil(t, err)
+	tokens = optimizer.Optimize(parser.ResultTokens())
+	assert.Equal(t, 5, len(tokens))
+
+	err = parser.SetExpression("If(1 > 2, x / 0, y)")
+	assert.Nil(t, err)
+	tokens = optimizer.Optimize(parser.ResultTokens())
+	assert.Equal(t, 1, len(tokens))
+	assert.Equal(t, "y", tokens[0].Value().AsString())
+
+	err = parser.SetExpression("NULL ?? 2 ?? x / 0")
+	assert.Nil(t, err)
+	tokens = optimizer.Optimize(parser.ResultTokens())
+	assert.Equal(t, 1, len(tokens))
+	assert.Equal(t, 2, tokens[0].Value().AsInteger())
+
+	err = parser.SetExpression("Rnd() + 1 + 2")
+	assert.Nil(t, err)
+	tokens = optimizer.Optimize(parser.ResultTokens())
+	assert.Equal(t, parsers.Function, tokens[1].Type())
+
+	// Identities are simplified only for operands of known types
+	optimizer.SetSimplifyIdentities(true)
+	err = parser.SetExpression("Len(s) * 1 + 0")
+	assert.Nil(t, err)
+	tokens = optimizer.Optimize(parser.ResultTokens())
+	assert.Equal(t, 3, len(tokens))
+	assert.Equal(t, parsers.Function, tokens[2].Type())
+
+	err = parser.SetExpression("x + 0")
+	assert.Nil(t, err)
+	tokens = optimizer.Optimize(parser.ResultTokens())
+	assert.Equal(t, 3, len(tokens))
+
+	err = parser.SetExpression("Sin(x) * 1")
+	assert.Nil(t, err)
+	tokens = optimizer.Optimize(parser.ResultTokens())
+	assert.Equal(t, 5, len(tokens))
+}
+
+func TestExpressionOptimizerIdentities(t *testing.T) {
+	optimizer := calculator.NewExpressionOptimizer(nil, nil)
+	assert.False(t, optimizer.SimplifyIdentities())
+	optimizer.SetSimplifyIdentities(true)
+
+	values := []*variants.Variant{
+		variants.VariantFromInteger(3),
+		variants.VariantFromLong(3),
+		variants.VariantFromDouble(2.5),
+		variants.VariantFromString("abc"),
+		variants.VariantFromBoolean(true),
+		variants.EmptyVariant(),
+	}
+	expressions := []string{
+		"1.0 * x / 2",
+		"x + 0",
+		"0 + x",
+		"x - 0",
+		"x * 1",
+		"1 * x",
+		"x / 1",
+		"0.0 + x",
+		"Len(x) + 0",
+		"0 + Len(x)",
+		"Sin(x) * 1",
+		"Sin(x) * 1.0",
+		"Year(x) * 1",
+	}
+
+	for _, value := range values {
+		vars := variables.NewVariableCollection()
+		vars.Add(variables.NewVariable("x", value))
+
+		for _, expression := range expressions {
+			parser := parsers.NewExpressionParser()
+			err := parser.SetExpression(expression)
+			assert.Nil(t, err, expression)
+
+			compiled := calculator.NewCompiledExpression(parser.ResultTokens(), nil, nil)
+			expected, expectedErr := compiled.Evaluate(vars, nil)
+			optimized := calculator.NewCompiledExpression(optimizer.Optimize(parser.ResultTokens()), nil, nil)
+			result, resultErr := optimized.Evaluate(vars, nil)
+
+			if expectedErr != nil {
+				assert.NotNil(t, resultErr, expression)
+				continue
+			}
+			assert.Nil(t, resultErr, expression)
+			assert.Equal(t, expected.Type(), result.Type(), expression)
+			assert.Equal(t, variants.VariantToValue(expected), variants.VariantToValue(result), expression)
+		}
+	}
+}
+
+func TestExpressionOptimizerResults(t *testing.T) {
+	vars := variables.NewVariableCollection()
+	vars.Add(variables.NewVariable("x", variants.VariantFromInteger(5)))
+	vars.Add(variables.NewVariable("y", variants.VariantFromInteger(0)))
+	vars.Add(variables.NewVariable("s", variants.VariantFromString("abc")))
+
+	expressions := []string{
+		"2 * Pi() * x",
+		"x * 1 + 0 - 0",
+		"1 * x / 1",
+		"If(2 > 1, x, 1 / y)",
+		"If(x > 1, 'a', 'b')",
+		"2 > 1 OR 1 / y",
+		"1 > 2 AND 1 / y",
+		"TRUE AND x > 1",
+		"x > 1 AND 1 < 2",
+		"Choose(2, 1 / y, x + 1, 3)",
+		"Choose(0, 'a', 'b')",
+		"Choose(x - 3, 'a', 'b' + 'c', s)",
+		"TRUE ? x : 1 / y",
+		"CASE WHEN 1 > 2 THEN 1 / y WHEN x > 1 THEN 'big' ELSE 'small' END",
+		"CASE 1 + 1 WHEN 1 THEN 'one' WHEN 2 THEN 'two' END",
+		"CASE x WHEN 2 + 3 THEN 'five' ELSE 'other' END",
+		"Map(Array(1, 2 + 1), v => v * 1 + x * (2 + 3))",
+		"Max(1, 2, 3) + Min(x, 2 * 3)",
+		"'abc' LIKE 'a%' AND s NOT LIKE 'x%'",
+		"Array(1, 2, 3)[1] + x",
+		"-(2 + 3) + x",
+		"NOT (1 > 2) AND x IS NOT NULL",
+		"NULL ?? x + 1",
+		"x ?? 1 / y",
+		"Coalesce(NULL, NULL, s) + IfNull(NULL, 'a')",
+		"1 / y",
+	}
+
+	for _, expression := range expressions {
+		compiled, err := calculator.CompileExpression(expression)
+		assert.Nil(t, err, expression)
+		expected, expectedErr := compiled.Evaluate(vars, nil)
+
+		optimized := compiled.Optimize()
+		assert.True(t, len(optimized.Tokens()) <= len(compiled.Tokens()), expression)
+		result, resultErr := optimized.Evaluate(vars, nil)
+
+		if expectedErr != nil {
+			assert.NotNil(t, resultErr, expression)
+			continue
+		}
+		assert.Nil(t, resultErr, expression)
+		assert.Equal(t, variants.VariantToValue(expected), variants.VariantToValue(result), expression)
+	}
+}
+
+func TestExpressionCalculatorAutoOptimize(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+	calc.SetAutoOptimize(true)
+
+	err := calc.SetExpression("2 * 3 + x")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(calc.OptimizedTokens()))
+	calc.DefaultVariables().FindByName("x").SetValue(variants.VariantFromInteger(1))
+	result, err1 := calc.Evaluate()
+	assert.Nil(t, err1)
+	assert.Equal(t, 7, result.AsInteger())
+}
@@ -0,0 +1,141 @@
+package test_calculator
+
+import (
+	"testing"
+
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserFunctionCollectionDefine(t *testing.T) {
+	funcs := calculator.NewUserFunctionCollection(nil)
+
+	margin, err := funcs.Define("DEF Margin(price, cost) = (price - cost) * 100 / price")
+	assert.Nil(t, err)
+	assert.Equal(t, "Margin", margin.Name())
+	assert.Equal(t, []string{"price", "cost"}, margin.Parameters())
+	assert.Equal(t, "Margin(price: Any, cost: Any): Any", margin.Signature().Format(margin.Name()))
+
+	_, err = funcs.Define("MarginText(price, cost) = '' + Margin(price, cost) + '%'")
+	assert.Nil(t, err)
+	assert.NotNil(t, funcs.FindByName("margintext"))
+	assert.NotNil(t, funcs.FindByName("Max"))
+	assert.Equal(t, 0, funcs.FindIndexByName("Margin"))
+
+	calc := calculator.NewExpressionCalculator()
+	err = calc.SetExpression("MarginText(a, 60) + ' of ' + Max(a, 1)")
+	assert.Nil(t, err)
+	vars := variables.NewVariableCollection()
+	vars.Add(variables.NewVariable("a", variants.VariantFromInteger(80)))
+	vars.Add(variables.NewVariable("price", variants.VariantFromInteger(1)))
+	result, err := calc.EvaluateUsingVariablesAndFunctions(vars, funcs)
+	assert.Nil(t, err)
+	assert.Equal(t, "25% of 80", result.String())
+
+	// Functions are replaced by name and shadow parent functions
+	_, err = funcs.Define("DEF Max(a, b) = 0")
+	assert.Nil(t, err)
+	_, err = funcs.Define("DEF Margin(price, cost) = price - cost")
+	assert.Nil(t, err)
+	result, err = calc.EvaluateUsingVariablesAndFunctions(vars, funcs)
+	assert.Nil(t, err)
+	assert.Equal(t, "20% of 0", result.String())
+
+	// Parameters are bound by position
+	err = calc.SetExpression("Margin(1)")
+	assert.Nil(t, err)
+	_, err = calc.EvaluateUsingVariablesAndFunctions(vars, funcs)
+	assert.NotNil(t, err)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code)
+
+	funcs.RemoveByName("Max")
+	assert.NotNil(t, funcs.FindByName("Max"))
+	funcs.Clear()
+	assert.Nil(t, funcs.FindByName("Margin"))
+}
+
+func TestUserFunctionSyntaxErrors(t *testing.T) {
+	cases := map[string]string{
+		"DEF (a) = a":         errors.ErrMissedFunctionName,
+		"DEF F(a b) = a":      errors.ErrMissedCloseParenthesis,
+		"DEF F(a) a":          errors.ErrMissedEqual,
+		"DEF F(a) =":          errors.ErrUnexpectedEnd,
+		"DEF F(a) = a + b":    errors.ErrUnknownParameter,
+		"DEF F(a) = (a + 1":   errors.ErrUnexpectedEnd,
+		"DEF F(a) = a + 1 a ": errors.ErrErrorNear,
+	}
+	for definition, code := range cases {
+		_, err := calculator.ParseUserFunction(definition, nil)
+		assert.NotNil(t, err, definition)
+		if err != nil {
+			assert.Equal(t, code, err.(*cerrors.ApplicationError).Code, definition)
+		}
+	}
+
+	// Lambda parameters are not function parameters
+	_, err := calculator.ParseUserFunction("DEF Total(items) = Sum(Map(items, x => x * 2))", nil)
+	assert.Nil(t, err)
+}
+
+func TestUserFunctionCollectionRecursion(t *testing.T) {
+	funcs := calculator.NewUserFunctionCollection(nil)
+
+	_, err := funcs.Define("DEF Fact(n) = If(n <= 1, 1, n * Fact(n - 1))")
+	assert.NotNil(t, err)
+	assert.Equal(t, "FUNCTION_CYCLE", err.(*cerrors.ApplicationError).Code)
+
+	_, err = funcs.Define("DEF A(x) = B(x) + 1")
+	assert.Nil(t, err)
+	_, err = funcs.Define("DEF B(x) = C(x) * 2")
+	assert.Nil(t, err)
+	_, err = funcs.Define("DEF C(x) = A(x)")
+	assert.NotNil(t, err)
+	assert.Equal(t, "FUNCTION_CYCLE", err.(*cerrors.ApplicationError).Code)
+	assert.Contains(t, err.Error(), "C -> A -> B -> C")
+
+	// Functions added directly are checked for cycles as well
+	function, err := calculator.ParseUserFunction("DEF C(x) = A(x)", nil)
+	assert.Nil(t, err)
+	assert.Panics(t, func() { funcs.Add(function) })
+	assert.Nil(t, funcs.FindByName("C"))
+
+	funcs.SetAllowRecursion(true)
+	fact, err := funcs.Define("DEF Fact(n) = If(n <= 1, 1, n * Fact(n - 1))")
+	assert.Nil(t, err)
+	result, err := fact.Calculate([]*variants.Variant{variants.VariantFromInteger(10)}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3628800, result.AsInteger())
+
+	funcs.SetMaxRecursionDepth(5)
+	_, err = fact.Calculate([]*variants.Variant{variants.VariantFromInteger(10)}, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "RECURSION_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+
+	// Bodies of functions share the steps and the limits of the calling expression
+	funcs.SetMaxRecursionDepth(calculator.DefaultMaxRecursionDepth)
+	_, err = funcs.Define("DEF Twice(n) = If(n <= 0, 1, Twice(n - 1) + Twice(n - 1))")
+	assert.Nil(t, err)
+	expression, err := calculator.CompileExpression("Twice(30)")
+	assert.Nil(t, err)
+	limits := calculator.NewEvaluationLimits()
+	limits.MaxSteps = 10000
+	_, err = expression.WithLimits(limits).Evaluate(nil, funcs)
+	assert.NotNil(t, err)
+	assert.Equal(t, "STEPS_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+
+	expression, err = calculator.CompileExpression("Twice(3)")
+	assert.Nil(t, err)
+	result, err = expression.WithLimits(limits).Evaluate(nil, funcs)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, result.AsInteger())
+
+	loop, err := funcs.Define("DEF Loop(x) = Loop(x)")
+	assert.Nil(t, err)
+	_, err = loop.Calculate([]*variants.Variant{variants.VariantFromInteger(1)}, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "RECURSION_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+}
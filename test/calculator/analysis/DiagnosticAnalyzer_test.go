@@ -0,0 +1,90 @@
+package test_calculator_analysis
+
+import (
+	"testing"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/analysis"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDiagnosticVariables() variables.IVariableCollection {
+	vars := variables.NewVariableCollection()
+	vars.Add(variables.NewVariable("price", variants.VariantFromInteger(10)))
+	vars.Add(variables.NewVariable("cost", variants.EmptyVariant()))
+	vars.Add(variables.NewVariable("name", variants.VariantFromString("abc")))
+	return vars
+}
+
+func TestDiagnosticAnalyzerSyntaxErrors(t *testing.T) {
+	analyzer := analysis.NewDiagnosticAnalyzer(nil, nil)
+
+	diagnostics := analyzer.Analyze("price 1 2", newDiagnosticVariables())
+	assert.Len(t, diagnostics, 2)
+	assert.Equal(t, errors.ErrErrorNear, diagnostics[0].Code)
+	assert.Equal(t, ast.Position{Line: 1, Column: 7}, diagnostics[0].Start)
+	assert.Equal(t, ast.Position{Line: 1, Column: 8}, diagnostics[0].End)
+	assert.Equal(t, errors.ErrErrorNear, diagnostics[1].Code)
+	assert.Equal(t, ast.Position{Line: 1, Column: 9}, diagnostics[1].Start)
+
+	diagnostics = analyzer.Analyze("(price + 1", nil)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, errors.ErrUnexpectedEnd, diagnostics[0].Code)
+	assert.Equal(t, ast.Position{Line: 1, Column: 11}, diagnostics[0].Start)
+
+	diagnostics = analyzer.Analyze("price + cost", newDiagnosticVariables())
+	assert.Len(t, diagnostics, 0)
+}
+
+func TestDiagnosticAnalyzerSemanticErrors(t *testing.T) {
+	analyzer := analysis.NewDiagnosticAnalyzer(nil, nil)
+
+	diagnostics := analyzer.Analyze("prize + Sinn(cost) + nme", newDiagnosticVariables())
+	assert.Len(t, diagnostics, 3)
+	assert.Equal(t, errors.ErrUnknownVariable, diagnostics[0].Code)
+	assert.Equal(t, []string{"price"}, diagnostics[0].Suggestions)
+	assert.Equal(t, ast.Position{Line: 1, Column: 6}, diagnostics[0].End)
+	assert.Equal(t, "Variable prize was not found at line 1 and column 1. Did you mean price?",
+		diagnostics[0].Error())
+	assert.Equal(t, errors.ErrUnknownFunction, diagnostics[1].Code)
+	assert.Equal(t, []string{"Sin"}, diagnostics[1].Suggestions)
+	assert.Equal(t, errors.ErrUnknownVariable, diagnostics[2].Code)
+	assert.Equal(t, []string{"name"}, diagnostics[2].Suggestions)
+
+	// Unknown variables are not checked without variables
+	diagnostics = analyzer.Analyze("prize + Foo(1)", nil)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, errors.ErrUnknownFunction, diagnostics[0].Code)
+	assert.Len(t, diagnostics[0].Suggestions, 0)
+
+	diagnostics = analyzer.Analyze("name * 2", newDiagnosticVariables())
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, errors.ErrTypeMismatch, diagnostics[0].Code)
+
+	diagnostics = analyzer.Analyze("Sin(1, 2) + Sin(cost)", newDiagnosticVariables())
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, errors.ErrWrongParamCount, diagnostics[0].Code)
+	assert.Equal(t, ast.Position{Line: 1, Column: 4}, diagnostics[0].End)
+}
+
+func TestDiagnosticRender(t *testing.T) {
+	analyzer := analysis.NewDiagnosticAnalyzer(nil, nil)
+
+	source := "price +\n\tFooBar(cost)"
+	diagnostics := analyzer.Analyze(source, newDiagnosticVariables())
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, ast.Position{Line: 2, Column: 2}, diagnostics[0].Start)
+	assert.Equal(t, "Function FooBar was not found at line 2 and column 2\n"+
+		"\tFooBar(cost)\n"+
+		"\t^^^^^^", diagnostics[0].Render(source))
+
+	source = "(price + 1"
+	diagnostics = analyzer.Analyze(source, nil)
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, "Unexpected end of expression at line 1 and column 11\n"+
+		"(price + 1\n"+
+		"          ^", diagnostics[0].Render(source))
+}
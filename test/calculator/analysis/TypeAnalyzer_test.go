@@ -0,0 +1,103 @@
+package test_calculator_analysis
+
+import (
+	"testing"
+
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/analysis"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeAnalyzerResultTypes(t *testing.T) {
+	analyzer := analysis.NewTypeAnalyzer(nil, nil)
+	analyzer.SetVariableType("a", variants.Integer)
+	analyzer.SetVariableType("b", variants.Double)
+	analyzer.SetVariableType("s", variants.String)
+	analyzer.SetVariableType("d", variants.DateTime)
+	analyzer.SetVariableType("arr", variants.Array)
+	analyzer.SetVariableType("x", variants.Null)
+
+	testCases := []struct {
+		expression string
+		expected   variants.VariantType
+	}{
+		{"a + 1", variants.Integer},
+		{"b * 2", variants.Double},
+		{"s + 'abc'", variants.String},
+		{"a > 2 AND s LIKE 'a%'", variants.Boolean},
+		{"d - d", variants.TimeSpan},
+		{"a IN arr", variants.Boolean},
+		{"Abs(b)", variants.Double},
+		{"s[0]", variants.String},
+		{"Now()", variants.DateTime},
+		{"a > 0 ? 'yes' : 'no'", variants.String},
+		{"IF(a > 0, 1, 'no')", variants.Null},
+		{"CASE a WHEN 1 THEN b WHEN 2 THEN b + 1 END", variants.Double},
+		{"Map(arr, e => e * 2)", variants.Array},
+		{"x + 1", variants.Null},
+		{"x = 1", variants.Boolean},
+		{"arr[0]", variants.Null},
+		{"s MATCHES '^a' OR x !~ s", variants.Boolean},
+		{"RegexExtract(s, '(a)', 1) + Upper(s)", variants.String},
+		{"DateDiff(d, Now(), 'day') + Len(s)", variants.Integer},
+		{"a ?? 0", variants.Integer},
+		{"Coalesce(s, 'none', Upper(s))", variants.String},
+		{"x ?? 1", variants.Null},
+	}
+
+	for _, testCase := range testCases {
+		typ, errs := analyzer.AnalyzeExpression(testCase.expression)
+		assert.Len(t, errs, 0, testCase.expression)
+		assert.Equal(t, testCase.expected, typ, testCase.expression)
+	}
+}
+
+func TestTypeAnalyzerErrors(t *testing.T) {
+	analyzer := analysis.NewTypeAnalyzer(variants.NewTypeSafeVariantOperations(), nil)
+
+	_, errs := analyzer.AnalyzeExpression("'abc' * 3")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "CONV_NOT_SUPPORTED", errs[0].(*cerrors.ApplicationError).Code)
+	assert.Contains(t, errs[0].Error(), "at line 1 and column 7")
+
+	analyzer = analysis.NewTypeAnalyzer(nil, nil)
+	analyzer.SetVariableType("d", variants.DateTime)
+	analyzer.SetVariableType("f", variants.Boolean)
+
+	typ, errs := analyzer.AnalyzeExpression("d = f")
+	assert.Equal(t, variants.Boolean, typ)
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "at line 1 and column 3")
+
+	_, errs = analyzer.AnalyzeExpression("y + Foo(1)")
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "VAR_NOT_FOUND", errs[0].(*cerrors.ApplicationError).Code)
+	assert.Equal(t, "FUNC_NOT_FOUND", errs[1].(*cerrors.ApplicationError).Code)
+
+	analyzer.SetFunctionSignature("Now", functions.NewFunctionSignature(variants.DateTime, ""))
+	_, errs = analyzer.AnalyzeExpression("Now(1)")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "WRONG_PARAM_COUNT", errs[0].(*cerrors.ApplicationError).Code)
+
+	_, errs = analyzer.AnalyzeExpression("Map(d, e => e)")
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "at line 1 and column 5")
+
+	// Calls are checked by function signatures even when parameter types are unknown
+	analyzer.SetVariableType("x", variants.Null)
+	typ, errs = analyzer.AnalyzeExpression("Sin(x, x)")
+	assert.Equal(t, variants.Double, typ)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "WRONG_PARAM_COUNT", errs[0].(*cerrors.ApplicationError).Code)
+	assert.Contains(t, errs[0].Error(), "at line 1 and column 1")
+
+	typ, errs = analyzer.AnalyzeExpression("Sin(x)")
+	assert.Equal(t, variants.Double, typ)
+	assert.Len(t, errs, 0)
+
+	_, errs = analyzer.AnalyzeExpression("AddDays(f, 1)")
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "at line 1 and column 9")
+}
@@ -0,0 +1,56 @@
+package test_calculator_ast
+
+import (
+	"testing"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"2+2*3", "2 + 2 * 3"},
+		{"(2+2)*3", "(2 + 2) * 3"},
+		{"a - (b - c)", "a - (b - c)"},
+		{"(a - b) - c", "a - b - c"},
+		{"-(a + b) ^ 2", "-(a + b) ^ 2"},
+		{"NOT (a AND b) OR c", "NOT (a AND b) OR c"},
+		{"not a = b", "NOT a = b"},
+		{"a is not null and b like 'x''%'", "a IS NOT NULL AND b LIKE 'x''%'"},
+		{"x NOT IN Array(1, 2.5, TRUE)", "x NOT IN (1, 2.5, TRUE)"},
+		{"x IN (a, (b))", "x IN (a, b)"},
+		{"x IN (a)", "x IN a"},
+		{"items[0].price * 2", "items[0].price * 2"},
+		{"(a + b).c", "(a + b).c"},
+		{"max( 1 , -x )", "max(1, -x)"},
+		{"a ? b : c ? d : e", "a ? b : c ? d : e"},
+		{"(a ? b : c) ? d : e", "(a ? b : c) ? d : e"},
+		{"case when a then 1 else 2 end", "CASE WHEN a THEN 1 ELSE 2 END"},
+		{"CASE x WHEN 1 THEN 'a' WHEN 2 THEN 'b' END", "CASE x WHEN 1 THEN 'a' WHEN 2 THEN 'b' END"},
+		{"Map(items, x => x.price)", "Map(items, x => x.price)"},
+		{"Reduce(items, (a,b) => a + b, 0)", "Reduce(items, (a, b) => a + b, 0)"},
+		{"\"my var\" + 1", "\"my var\" + 1"},
+		{"price * 1.50m", "price * 1.50m"},
+		{"(a ?? b) + 1 > c ?? NULL", "(a ?? b) + 1 > c ?? NULL"},
+		{"id + 123456789012345678901234567890", "id + 123456789012345678901234567890"},
+	}
+
+	parser := parsers.NewExpressionParser()
+	for _, test := range tests {
+		err := parser.SetExpression(test.expression)
+		assert.Nil(t, err, test.expression)
+		text := ast.Format(parser.SyntaxTree())
+		assert.Equal(t, test.expected, text, test.expression)
+
+		// Formatted text is parsed into the same tokens.
+		expectedTokens := parser.ResultTokens()
+		err = parser.SetExpression(text)
+		assert.Nil(t, err, text)
+		assert.Equal(t, len(expectedTokens), len(parser.ResultTokens()), text)
+		assert.Equal(t, text, ast.Format(parser.SyntaxTree()))
+	}
+}
@@ -0,0 +1,70 @@
+package test_calculator_ast
+
+import (
+	"testing"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/ast"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingVisitor struct {
+	counts map[string]int
+}
+
+func (c *countingVisitor) Visit(node ast.Node) ast.IVisitor {
+	switch node.(type) {
+	case *ast.VariableNode:
+		c.counts["variable"]++
+	case *ast.ConstantNode:
+		c.counts["constant"]++
+	case *ast.FunctionNode:
+		c.counts["function"]++
+		// Skips function parameters.
+		return nil
+	}
+	return c
+}
+
+func TestWalk(t *testing.T) {
+	parser := parsers.NewExpressionParser()
+	err := parser.SetExpression("a + 2 * b - Max(c, 3)")
+	assert.Nil(t, err)
+
+	visitor := &countingVisitor{counts: map[string]int{}}
+	ast.Walk(visitor, parser.SyntaxTree())
+	assert.Equal(t, 2, visitor.counts["variable"])
+	assert.Equal(t, 1, visitor.counts["constant"])
+	assert.Equal(t, 1, visitor.counts["function"])
+
+	root := parser.SyntaxTree().(*ast.BinaryNode)
+	assert.Equal(t, ast.Subtract, root.Operator)
+	assert.Equal(t, ast.Position{Line: 1, Column: 11}, root.Pos())
+	function := root.Right.(*ast.FunctionNode)
+	assert.Equal(t, "Max", function.Name)
+	assert.Equal(t, ast.Position{Line: 1, Column: 13}, function.Pos())
+}
+
+func TestInspectAndRewrite(t *testing.T) {
+	parser := parsers.NewExpressionParser()
+	err := parser.SetExpression("If(x > 0, x.y, CASE WHEN z THEN x END)")
+	assert.Nil(t, err)
+
+	names := []string{}
+	ast.Inspect(parser.SyntaxTree(), func(node ast.Node) bool {
+		if v, ok := node.(*ast.VariableNode); ok {
+			names = append(names, v.Name)
+		}
+		return true
+	})
+	assert.Equal(t, []string{"x", "x", "z", "x"}, names)
+
+	tree := ast.Rewrite(parser.SyntaxTree(), func(node ast.Node) ast.Node {
+		if v, ok := node.(*ast.VariableNode); ok && v.Name == "x" {
+			return ast.NewConstantNode(variants.VariantFromInteger(1), v.Line, v.Column)
+		}
+		return node
+	})
+	assert.Equal(t, "If(1 > 0, 1.y, CASE WHEN z THEN 1 END)", ast.Format(tree))
+}
@@ -0,0 +1,211 @@
+package test_calculator_bytecode
+
+import (
+	"testing"
+
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/bytecode"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+const benchmarkExpression = "(a + b) * 2 > c AND Max(a, b, c) < 100 OR name = 'test'"
+
+func newTestVariables() variables.IVariableCollection {
+	vars := variables.NewVariableCollection()
+	vars.Add(variables.NewVariable("a", variants.VariantFromInteger(3)))
+	vars.Add(variables.NewVariable("b", variants.VariantFromInteger(5)))
+	vars.Add(variables.NewVariable("c", variants.VariantFromInteger(10)))
+	vars.Add(variables.NewVariable("d", variants.VariantFromDouble(2.5)))
+	vars.Add(variables.NewVariable("name", variants.VariantFromString("test")))
+	vars.Add(variables.NewVariable("items", variants.VariantFromArray([]*variants.Variant{
+		variants.VariantFromInteger(1), variants.VariantFromInteger(2), variants.VariantFromInteger(3),
+	})))
+	return vars
+}
+
+func TestVirtualMachineEvaluate(t *testing.T) {
+	expressions := []string{
+		benchmarkExpression,
+		"2 + 2 * 3 - 10 / 2 % 3",
+		"-d * 2 + a",
+		"name + '!' LIKE 't%' AND name NOT LIKE 'x%'",
+		"2 IN items AND 5 NOT IN (1, 2, 3)",
+		"items[1] + items[2]",
+		"IF(a > b, 'a', 'b') + Choose(2, 'x', 'y', 'z')",
+		"Choose(0, 'x', 'y')",
+		"a > 1 ? (b > 10 ? 1 : 2) : 3",
+		"CASE a WHEN 1 THEN 'one' WHEN 3 THEN 'three' ELSE 'other' END",
+		"CASE WHEN a > 5 THEN 1 END",
+		"Map(items, x => x * a)[2]",
+		"Reduce(items, (acc, x) => acc + x * Reduce(Map(items, y => y + x), (s, v) => s + v, 0), 0)",
+		"Filter(items, a => a > 1)[0] + a",
+		"x IS NULL OR a IS NOT NULL",
+		"x AND a > b",
+		"a > b AND x",
+		"x OR a < b",
+		"a < b OR x",
+		"NOT (a < b XOR c >= 10)",
+		"name MATCHES '^t' AND name !~ 'x' AND RegexMatch(name, 'e') AND x ~ 'a' IS NULL",
+		"Round(a * 2.345m, 2, 'HalfEven') + 0.10m",
+		"x ?? NullIf(a, 1) ?? 'none'",
+		"Coalesce(x, NULL, b) + IfNull(x, a)",
+		"Factorial(21) - 99999999999999999999 * a + 2 ^ 62",
+	}
+
+	vars := newTestVariables()
+	vars.Add(variables.NewVariable("x", variants.EmptyVariant()))
+
+	for _, expression := range expressions {
+		calc := calculator.NewExpressionCalculator()
+		err := calc.SetExpression(expression)
+		assert.Nil(t, err, expression)
+		expected, err := calc.EvaluateUsingVariables(vars)
+		assert.Nil(t, err, expression)
+
+		program, err := bytecode.CompileExpression(expression)
+		assert.Nil(t, err, expression)
+		machine := bytecode.NewVirtualMachine(program, nil, nil)
+		result, err := machine.Evaluate(vars)
+		assert.Nil(t, err, expression)
+		assert.Equal(t, expected.Type(), result.Type(), expression)
+		assert.Equal(t, expected.AsObject(), result.AsObject(), expression)
+
+		// Evaluation of the same machine gives the same result.
+		result, err = machine.Evaluate(vars)
+		assert.Nil(t, err, expression)
+		assert.Equal(t, expected.AsObject(), result.AsObject(), expression)
+	}
+}
+
+func TestVirtualMachineResultsAreNotShared(t *testing.T) {
+	vars := newTestVariables()
+	program, err := bytecode.CompileExpression("a < b")
+	assert.Nil(t, err)
+	machine := bytecode.NewVirtualMachine(program, nil, nil)
+
+	// Changes of a result do not affect results of other evaluations
+	result, err := machine.Evaluate(vars)
+	assert.Nil(t, err)
+	assert.True(t, result.AsBoolean())
+	result.SetAsBoolean(false)
+
+	result, err = machine.Evaluate(vars)
+	assert.Nil(t, err)
+	assert.True(t, result.AsBoolean())
+
+	other := bytecode.NewVirtualMachine(program, nil, nil)
+	result, err = other.Evaluate(vars)
+	assert.Nil(t, err)
+	assert.True(t, result.AsBoolean())
+	result.SetAsString("changed")
+
+	result, err = machine.Evaluate(vars)
+	assert.Nil(t, err)
+	assert.Equal(t, variants.Boolean, result.Type())
+	assert.True(t, result.AsBoolean())
+}
+
+func TestVirtualMachineErrors(t *testing.T) {
+	program, err := bytecode.CompileExpression("a + Foo(1)")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a"}, program.VariableNames())
+
+	machine := bytecode.NewVirtualMachine(program, nil, nil)
+	_, err = machine.Evaluate(nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "VAR_NOT_FOUND", err.(*cerrors.ApplicationError).Code)
+
+	_, err = machine.Evaluate(newTestVariables())
+	assert.NotNil(t, err)
+	assert.Equal(t, "FUNC_NOT_FOUND", err.(*cerrors.ApplicationError).Code)
+	assert.Contains(t, err.Error(), "at line 1 and column 5")
+
+	program, err = bytecode.CompileExpression("a +\n Sin(a, 1)")
+	assert.Nil(t, err)
+	machine = bytecode.NewVirtualMachine(program, nil, nil)
+	_, err = machine.Evaluate(newTestVariables())
+	assert.NotNil(t, err)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code)
+	line, column, ok := errors.ErrorPosition(err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, line)
+	assert.Equal(t, 2, column)
+
+	program, err = bytecode.CompileExpression("Reduce(Map(items, x => x + 1), (s, v) => s + v)")
+	assert.Nil(t, err)
+	machine = bytecode.NewVirtualMachine(program, nil, nil)
+	machine.SetLimits(calculator.EvaluationLimits{MaxSteps: 10})
+	_, err = machine.Evaluate(newTestVariables())
+	assert.NotNil(t, err)
+	assert.Equal(t, "STEPS_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+
+	program, err = bytecode.CompileExpression("a > 0 AND name ~ pattern")
+	assert.Nil(t, err)
+	machine = bytecode.NewVirtualMachine(program, nil, nil)
+	vars := newTestVariables()
+	vars.Add(variables.NewVariable("pattern", variants.VariantFromString("(")))
+	_, err = machine.Evaluate(vars)
+	assert.NotNil(t, err)
+	assert.Equal(t, "INVALID_PATTERN", err.(*cerrors.ApplicationError).Code)
+	assert.Contains(t, err.Error(), "at line 1 and column 16")
+}
+
+func TestProgramSerialization(t *testing.T) {
+	program, err := bytecode.CompileExpression(
+		"Choose(2, 'x', 'y') + (a > 1 ? 'a' : 'b') + Reduce(Map(items, x => x * 2), (s, v) => s + v, 0)")
+	assert.Nil(t, err)
+
+	data, err := program.MarshalBinary()
+	assert.Nil(t, err)
+
+	restored := &bytecode.Program{}
+	err = restored.UnmarshalBinary(data)
+	assert.Nil(t, err)
+	assert.Equal(t, program.Instructions(), restored.Instructions())
+	assert.Equal(t, program.VariableNames(), restored.VariableNames())
+
+	result, err := bytecode.NewVirtualMachine(restored, nil, nil).Evaluate(newTestVariables())
+	assert.Nil(t, err)
+	assert.Equal(t, "ya12", result.AsString())
+
+	err = restored.UnmarshalBinary(data[:len(data)-3])
+	assert.NotNil(t, err)
+	err = restored.UnmarshalBinary([]byte("abc"))
+	assert.NotNil(t, err)
+}
+
+func BenchmarkExpressionCalculator(b *testing.B) {
+	calc := calculator.NewExpressionCalculator()
+	_ = calc.SetExpression(benchmarkExpression)
+	vars := newTestVariables()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = calc.EvaluateUsingVariables(vars)
+	}
+}
+
+func BenchmarkCompiledExpression(b *testing.B) {
+	compiled, _ := calculator.CompileExpression(benchmarkExpression)
+	vars := newTestVariables()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = compiled.Evaluate(vars, nil)
+	}
+}
+
+func BenchmarkVirtualMachine(b *testing.B) {
+	program, _ := bytecode.CompileExpression(benchmarkExpression)
+	machine := bytecode.NewVirtualMachine(program, nil, nil)
+	vars := newTestVariables()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = machine.Evaluate(vars)
+	}
+}
@@ -1,9 +1,11 @@
 package test_calculator_functions
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
 	"github.com/stretchr/testify/assert"
@@ -54,3 +56,284 @@ func TestDefaultFunctionsCollectionDateFunctions(t *testing.T) {
 	date := time.Date(1975, time.Month(4), 8, 0, 0, 0, 0, time.Local)
 	assert.Equal(t, date, result.AsDateTime())
 }
+
+func TestDefaultFunctionsCollectionStringFunctions(t *testing.T) {
+	collection := functions.NewDefaultFunctionCollection()
+	operations := variants.NewTypeUnsafeVariantOperations()
+
+	calculate := func(name string, values ...any) (*variants.Variant, error) {
+		parameters := []*variants.Variant{}
+		for _, value := range values {
+			if variant, ok := value.(*variants.Variant); ok {
+				parameters = append(parameters, variant)
+			} else {
+				parameters = append(parameters, variants.NewVariant(value))
+			}
+		}
+		f := collection.FindByName(name)
+		assert.NotNil(t, f, name)
+		return f.Calculate(parameters, operations)
+	}
+
+	testCases := []struct {
+		name     string
+		values   []any
+		expected any
+	}{
+		{"Upper", []any{"čaj"}, "ČAJ"},
+		{"Lower", []any{"ÄBC"}, "äbc"},
+		{"Len", []any{"héllo"}, 5},
+		{"Substring", []any{"привет", 2}, "ивет"},
+		{"Substring", []any{"привет", 1, 3}, "рив"},
+		{"Substring", []any{"abc", 5, 1}, ""},
+		{"Left", []any{"日本語", 2}, "日本"},
+		{"Right", []any{"日本語", 2}, "本語"},
+		{"Right", []any{"abc", 10}, "abc"},
+		{"Trim", []any{"  abc\t"}, "abc"},
+		{"LTrim", []any{"xxabcxx", "x"}, "abcxx"},
+		{"RTrim", []any{"xxabcxx", "x"}, "xxabc"},
+		{"Replace", []any{"a-b-c", "-", "+"}, "a+b+c"},
+		{"IndexOf", []any{"żółw żółw", "w"}, 3},
+		{"IndexOf", []any{"żółw żółw", "w", 4}, 8},
+		{"IndexOf", []any{"abc", "x"}, -1},
+		{"StartsWith", []any{"abc", "ab"}, true},
+		{"EndsWith", []any{"abc", "ab"}, false},
+		{"PadLeft", []any{"7", 3, "0"}, "007"},
+		{"PadRight", []any{"äb", 5, "xy"}, "äbxyx"},
+		{"PadLeft", []any{"abc", 2}, "abc"},
+		{"Repeat", []any{"ab", 3}, "ababab"},
+		{"Reverse", []any{"añb"}, "bña"},
+		{"Join", []any{variants.VariantFromArray([]*variants.Variant{variants.VariantFromString("a"),
+			variants.VariantFromInteger(1), variants.EmptyVariant(), variants.VariantFromBoolean(true)}), ","}, "a,1,,true"},
+		{"Format", []any{"{1} {0}! {{{0}}}", "world", "Hello"}, "Hello world! {world}"},
+		{"Format", []any{"{0}-{1}", nil, 2}, "-2"},
+	}
+
+	for _, testCase := range testCases {
+		result, err := calculate(testCase.name, testCase.values...)
+		assert.Nil(t, err, testCase.name)
+		assert.Equal(t, testCase.expected, result.AsObject(), testCase.name)
+	}
+
+	result, err := calculate("Split", "a,b,,c", ",")
+	assert.Nil(t, err)
+	assert.Equal(t, variants.Array, result.Type())
+	assert.Equal(t, 4, result.Length())
+	assert.Equal(t, "", result.GetByIndex(2).AsString())
+
+	// Null parameters give null results.
+	for _, name := range []string{"Upper", "Len", "Reverse", "Trim"} {
+		result, err = calculate(name, nil)
+		assert.Nil(t, err, name)
+		assert.True(t, result.IsNull(), name)
+	}
+	result, err = calculate("Replace", "abc", nil, "x")
+	assert.Nil(t, err)
+	assert.True(t, result.IsNull())
+	result, err = calculate("Join", nil)
+	assert.Nil(t, err)
+	assert.True(t, result.IsNull())
+
+	for _, name := range []string{"Upper", "Substring", "Replace", "PadLeft", "Format"} {
+		_, err = calculate(name)
+		assert.NotNil(t, err, name)
+		assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code, name)
+	}
+	_, err = calculate("Left", "a", 1, 2)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code)
+	_, err = calculate("Format", "{0} {2}", 1, 2)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code)
+
+	// Huge strings are rejected before they are allocated
+	for _, name := range []string{"Repeat", "PadLeft", "PadRight"} {
+		_, err = calculate(name, "ab", 100000000000)
+		assert.NotNil(t, err, name)
+		assert.Equal(t, "STRING_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code, name)
+	}
+	ctx := functions.ContextWithSizeLimits(context.Background(), functions.SizeLimits{MaxStringLength: 5})
+	_, err = collection.FindByName("Repeat").(functions.IContextFunction).CalculateWithContext(ctx,
+		[]*variants.Variant{variants.VariantFromString("ab"), variants.VariantFromInteger(3)}, operations)
+	assert.NotNil(t, err)
+	assert.Equal(t, "STRING_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+	result, err = collection.FindByName("PadLeft").(functions.IContextFunction).CalculateWithContext(ctx,
+		[]*variants.Variant{variants.VariantFromString("ab"), variants.VariantFromInteger(5)}, operations)
+	assert.Nil(t, err)
+	assert.Equal(t, "   ab", result.AsString())
+}
+
+func TestDefaultFunctionsCollectionCalendarFunctions(t *testing.T) {
+	collection := functions.NewDefaultFunctionCollection()
+	operations := variants.NewTypeUnsafeVariantOperations()
+
+	calculate := func(name string, values ...*variants.Variant) (*variants.Variant, error) {
+		f := collection.FindByName(name)
+		assert.NotNil(t, f, name)
+		return f.Calculate(values, operations)
+	}
+	date := func(year int, month time.Month, day int, hour int, minute int) *variants.Variant {
+		return variants.VariantFromDateTime(time.Date(year, month, day, hour, minute, 0, 0, time.UTC))
+	}
+	text := variants.VariantFromString
+	number := variants.VariantFromInteger
+
+	value := date(2024, time.January, 31, 13, 45)
+	for name, expected := range map[string]int{"Year": 2024, "Month": 1, "Day": 31, "Hour": 13, "Minute": 45} {
+		result, err := calculate(name, value)
+		assert.Nil(t, err, name)
+		assert.Equal(t, expected, result.AsInteger(), name)
+	}
+
+	dateTests := []struct {
+		name     string
+		values   []*variants.Variant
+		expected *variants.Variant
+	}{
+		{"AddDays", []*variants.Variant{value, number(30)}, date(2024, time.March, 1, 13, 45)},
+		{"AddMonths", []*variants.Variant{value, number(1)}, date(2024, time.February, 29, 13, 45)},
+		{"AddMonths", []*variants.Variant{value, number(-2)}, date(2023, time.November, 30, 13, 45)},
+		{"AddYears", []*variants.Variant{date(2024, time.February, 29, 0, 0), number(1)}, date(2025, time.February, 28, 0, 0)},
+		{"StartOfWeek", []*variants.Variant{value}, date(2024, time.January, 29, 0, 0)},
+		{"StartOfWeek", []*variants.Variant{value, number(0)}, date(2024, time.January, 28, 0, 0)},
+		{"StartOfMonth", []*variants.Variant{value}, date(2024, time.January, 1, 0, 0)},
+		{"ParseDate", []*variants.Variant{text("2024-01-31T13:45:00Z")}, value},
+		{"ParseDate", []*variants.Variant{text("31.01.2024 13:45"), text("dd.MM.yyyy HH:mm"), text("UTC")}, value},
+	}
+	for _, test := range dateTests {
+		result, err := calculate(test.name, test.values...)
+		assert.Nil(t, err, test.name)
+		assert.True(t, test.expected.AsDateTime().Equal(result.AsDateTime()), test.name)
+	}
+
+	diffTests := []struct {
+		start    *variants.Variant
+		end      *variants.Variant
+		unit     string
+		expected int
+	}{
+		// Months are counted in the same way as AddMonths clamps them.
+		{value, date(2024, time.February, 29, 13, 45), "month", 1},
+		{value, date(2024, time.February, 29, 13, 44), "months", 0},
+		{date(2024, time.January, 29, 0, 0), date(2024, time.February, 29, 0, 0), "month", 1},
+		{date(2024, time.February, 29, 0, 0), date(2025, time.February, 28, 0, 0), "year", 1},
+		{value, date(2024, time.February, 2, 13, 44), "day", 1},
+		{date(2024, time.February, 2, 0, 0), value, "days", -1},
+		{value, date(2024, time.February, 14, 13, 45), "Week", 2},
+		{value, date(2024, time.January, 31, 15, 0), "minute", 75},
+	}
+	for _, test := range diffTests {
+		result, err := calculate("DateDiff", test.start, test.end, text(test.unit))
+		assert.Nil(t, err, test.unit)
+		assert.Equal(t, test.expected, result.AsInteger(), test.unit)
+	}
+
+	result, err := calculate("FormatDate", value, text("yyyy-MM-dd'T'HH:mm, ddd d MMM"))
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-01-31T13:45, Wed 31 Jan", result.AsString())
+
+	// The embedded time zone database is used for conversions.
+	result, err = calculate("ToTimeZone", value, text("America/New_York"))
+	assert.Nil(t, err)
+	assert.Equal(t, 8, result.AsDateTime().Hour())
+	result, err = calculate("FormatDate", result, text("HH:mm zzz"))
+	assert.Nil(t, err)
+	assert.Equal(t, "08:45 -05:00", result.AsString())
+
+	result, err = calculate("AddDays", variants.EmptyVariant(), number(1))
+	assert.Nil(t, err)
+	assert.True(t, result.IsNull())
+
+	_, err = calculate("ToTimeZone", value, text("Mars/Olympus"))
+	assert.Equal(t, "WRONG_PARAM_VALUE", err.(*cerrors.ApplicationError).Code)
+	_, err = calculate("DateDiff", value, value, text("decade"))
+	assert.Equal(t, "WRONG_PARAM_VALUE", err.(*cerrors.ApplicationError).Code)
+	_, err = calculate("ParseDate", text("2024/01/31"), text("yyyy-MM-dd"))
+	assert.Equal(t, "WRONG_PARAM_VALUE", err.(*cerrors.ApplicationError).Code)
+	_, err = calculate("StartOfMonth", value, value)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code)
+}
+
+type testLambda struct {
+	invoke func(parameters []*variants.Variant) (*variants.Variant, error)
+}
+
+func (c *testLambda) Parameters() []string {
+	return []string{"x"}
+}
+
+func (c *testLambda) Invoke(parameters []*variants.Variant) (*variants.Variant, error) {
+	return c.invoke(parameters)
+}
+
+func TestDefaultFunctionsCollectionAggregateFunctions(t *testing.T) {
+	collection := functions.NewDefaultFunctionCollection()
+	operations := variants.NewTypeUnsafeVariantOperations()
+
+	calculate := func(name string, parameters ...*variants.Variant) (*variants.Variant, error) {
+		f := collection.FindByName(name)
+		assert.NotNil(t, f, name)
+		return f.Calculate(parameters, operations)
+	}
+	number := variants.VariantFromInteger
+	array := func(values ...*variants.Variant) *variants.Variant {
+		return variants.VariantFromArray(values)
+	}
+	values := array(number(4), number(1), variants.EmptyVariant(), number(3), number(1), number(6))
+
+	testCases := []struct {
+		name       string
+		parameters []*variants.Variant
+		expected   any
+	}{
+		{"Min", []*variants.Variant{values}, 1},
+		{"Max", []*variants.Variant{values, number(7)}, 7},
+		{"Sum", []*variants.Variant{values}, 15},
+		{"Sum", []*variants.Variant{number(1), number(2), number(3)}, 6},
+		{"Product", []*variants.Variant{values}, 72},
+		{"Count", []*variants.Variant{values}, 5},
+		{"Count", []*variants.Variant{array()}, 0},
+		{"Avg", []*variants.Variant{values}, 3.0},
+		{"Median", []*variants.Variant{values}, 3.0},
+		{"Median", []*variants.Variant{number(4), number(1), number(3), number(2)}, 2.5},
+		{"Mode", []*variants.Variant{values}, 1},
+		{"Variance", []*variants.Variant{values}, 4.5},
+		{"StdDev", []*variants.Variant{number(2), number(4), number(4), number(4),
+			number(5), number(5), number(7), number(9)}, 2.138089935299395},
+		{"Percentile", []*variants.Variant{values, variants.VariantFromDouble(0.9)}, 5.2},
+		{"Percentile", []*variants.Variant{values, number(0)}, 1.0},
+		{"Avg", []*variants.Variant{array()}, nil},
+		{"Sum", []*variants.Variant{variants.EmptyVariant()}, nil},
+		{"Variance", []*variants.Variant{number(1)}, nil},
+	}
+	for _, test := range testCases {
+		result, err := calculate(test.name, test.parameters...)
+		assert.Nil(t, err, test.name)
+		if test.expected == nil {
+			assert.True(t, result.IsNull(), test.name)
+		} else if expected, ok := test.expected.(float64); ok {
+			assert.InDelta(t, expected, result.AsDouble(), 1e-9, test.name)
+		} else {
+			assert.Equal(t, test.expected, result.AsObject(), test.name)
+		}
+	}
+
+	lambda := variants.VariantFromObject(&testLambda{
+		invoke: func(parameters []*variants.Variant) (*variants.Variant, error) {
+			return operations.More(parameters[0], number(2))
+		},
+	})
+	result, err := calculate("CountIf", values, number(5), lambda)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, result.AsInteger())
+
+	_, err = calculate("Percentile", values, number(2))
+	assert.Equal(t, "WRONG_PARAM_VALUE", err.(*cerrors.ApplicationError).Code)
+	_, err = calculate("Min")
+	assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code)
+
+	collection.SetNullHandling(functions.RejectNulls)
+	_, err = calculate("Sum", values)
+	assert.Equal(t, "WRONG_PARAM_VALUE", err.(*cerrors.ApplicationError).Code)
+	result, err = calculate("Count", number(1), number(2))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.AsInteger())
+}
@@ -0,0 +1,74 @@
+package test_calculator_functions
+
+import (
+	"testing"
+
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctionSignature(t *testing.T) {
+	signature := functions.NewFunctionSignature(variants.String, "Pads the string.",
+		functions.NewFunctionParameter("value", variants.String),
+		functions.NewOptionalFunctionParameter("length", variants.Integer),
+		functions.NewVariadicFunctionParameter("pads", variants.Null, true))
+
+	assert.Equal(t, 1, signature.MinParameters())
+	assert.Equal(t, -1, signature.MaxParameters())
+	assert.Equal(t, variants.Integer, signature.ParameterType(1))
+	assert.Equal(t, variants.Null, signature.ParameterType(5))
+	assert.Equal(t, "PadAll(value: String, length?: Integer, ...pads?: Any): String",
+		signature.Format("PadAll"))
+
+	assert.Nil(t, signature.CheckParamCount(4))
+	err := signature.CheckParamCount(0)
+	assert.NotNil(t, err)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code)
+}
+
+func TestDelegatedFunctionSignature(t *testing.T) {
+	function := functions.NewDelegatedFunction("Test", testFunc)
+	assert.Nil(t, function.Signature())
+	_, err := function.Calculate([]*variants.Variant{variants.EmptyVariant()}, nil)
+	assert.Nil(t, err)
+
+	signature := functions.NewFunctionSignature(variants.Null, "Does nothing.")
+	signature.Pure = true
+	function.SetSignature(signature)
+	assert.True(t, function.Deterministic())
+	assert.True(t, function.Signature().Pure)
+
+	_, err = function.Calculate([]*variants.Variant{variants.EmptyVariant()}, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code)
+}
+
+func TestDefaultFunctionSignatures(t *testing.T) {
+	collection := functions.NewDefaultFunctionCollection()
+
+	// All standard functions are described
+	docs := functions.GetFunctionDocs(collection)
+	assert.Equal(t, collection.Length(), len(docs))
+	for _, doc := range docs {
+		assert.NotNil(t, doc.Signature, doc.Name)
+	}
+	assert.Equal(t, "Abs", docs[0].Name)
+
+	sin := functions.GetFunctionSignature(collection.FindByName("Sin"))
+	assert.Equal(t, "Sin(value: Double): Double - Gets the sine of the angle in radians.",
+		(&functions.FunctionDoc{Name: "Sin", Signature: sin}).String())
+	assert.True(t, sin.Pure)
+	assert.False(t, functions.GetFunctionSignature(collection.FindByName("Now")).Pure)
+
+	round := functions.GetFunctionSignature(collection.FindByName("Round"))
+	assert.Equal(t, 1, round.MinParameters())
+	assert.Equal(t, 3, round.MaxParameters())
+
+	_, err := collection.FindByName("Sin").Calculate([]*variants.Variant{
+		variants.VariantFromInteger(1), variants.VariantFromInteger(2),
+	}, variants.NewTypeUnsafeVariantOperations())
+	assert.NotNil(t, err)
+	assert.Equal(t, "WRONG_PARAM_COUNT", err.(*cerrors.ApplicationError).Code)
+}
@@ -3,6 +3,8 @@ package test_calculator_parsers
 import (
 	"testing"
 
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/parsers"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
 	"github.com/stretchr/testify/assert"
@@ -33,3 +35,43 @@ func TestExpressionParserParseString(t *testing.T) {
 		assert.Equal(t, expectedTokens[i].Value().AsObject(), tokens[i].Value().AsObject())
 	}
 }
+
+func TestExpressionParserMembers(t *testing.T) {
+	parser := parsers.NewExpressionParser()
+	err := parser.SetExpression("a.b[0].c")
+	assert.Nil(t, err)
+
+	expectedTypes := []int{
+		parsers.Variable, parsers.Constant, parsers.Member,
+		parsers.Constant, parsers.Element,
+		parsers.Constant, parsers.Member,
+	}
+
+	tokens := parser.ResultTokens()
+	assert.Equal(t, len(expectedTypes), len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		assert.Equal(t, expectedTypes[i], tokens[i].Type())
+	}
+	assert.Equal(t, []string{"a"}, parser.VariableNames())
+}
+
+func TestExpressionParserConditionErrors(t *testing.T) {
+	parser := parsers.NewExpressionParser()
+
+	err := parser.SetExpression("a ? 1 2")
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ErrMissedColon, err.(*cerrors.ApplicationError).Code)
+	assert.Contains(t, err.Error(), "at line 1 and column 7")
+
+	err = parser.SetExpression("CASE WHEN a 1 END")
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ErrMissedThen, err.(*cerrors.ApplicationError).Code)
+
+	err = parser.SetExpression("CASE WHEN a THEN 1 ELSE 2")
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ErrUnexpectedEnd, err.(*cerrors.ApplicationError).Code)
+
+	err = parser.SetExpression("CASE a ELSE 1 END")
+	assert.NotNil(t, err)
+	assert.Equal(t, errors.ErrMissedWhen, err.(*cerrors.ApplicationError).Code)
+}
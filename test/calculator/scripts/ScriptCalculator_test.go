@@ -0,0 +1,276 @@
+package test_calculator_scripts
+
+import (
+	"context"
+	"testing"
+
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/functions"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/scripts"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptCalculatorAssignments(t *testing.T) {
+	calculator := scripts.NewScriptCalculator()
+
+	err := calculator.SetScript("total := price * qty; discount := total / 10; total - discount")
+	assert.Nil(t, err)
+	assert.NotNil(t, calculator.DefaultVariables().FindByName("price"))
+	assert.NotNil(t, calculator.DefaultVariables().FindByName("total"))
+	calculator.DefaultVariables().FindByName("price").SetValue(variants.VariantFromInteger(10))
+	calculator.DefaultVariables().FindByName("qty").SetValue(variants.VariantFromInteger(5))
+
+	result, err := calculator.Evaluate()
+	assert.Nil(t, err)
+	assert.Equal(t, 45, result.AsInteger())
+	assert.Equal(t, 50, calculator.DefaultVariables().FindByName("total").Value().AsInteger())
+
+	err = calculator.SetScript("")
+	assert.Nil(t, err)
+	result, err = calculator.Evaluate()
+	assert.Nil(t, err)
+	assert.True(t, result.IsNull())
+}
+
+func TestScriptCalculatorIf(t *testing.T) {
+	script := `
+		IF x > 10 THEN
+			size := 'large'
+		ELSEIF x > 5 THEN
+			size := 'medium'
+		ELSE
+			size := 'small'
+		END;
+		If(size = 'large', 1, 0) + Len(size)`
+
+	compiled, err := scripts.CompileScript(script)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x", "size"}, compiled.VariableNames())
+
+	cases := map[int]string{20: "large", 7: "medium", 1: "small"}
+	for x, size := range cases {
+		vars := variables.NewVariableCollection()
+		vars.Add(variables.NewVariable("x", variants.VariantFromInteger(x)))
+		result, err := compiled.Evaluate(vars, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, size, vars.FindByName("size").Value().AsString())
+		expected := len(size)
+		if size == "large" {
+			expected++
+		}
+		assert.Equal(t, expected, result.AsInteger())
+	}
+
+	// Null conditions are false
+	vars := variables.NewVariableCollection()
+	vars.Add(variables.EmptyVariable("x"))
+	result, err := compiled.Evaluate(vars, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, result.AsInteger())
+}
+
+func TestScriptCalculatorLazyFunctionOverrides(t *testing.T) {
+	funcs := functions.NewDefaultFunctionCollection()
+	funcs.RemoveByName("If")
+	funcs.Add(functions.NewDelegatedFunction("If",
+		func(parameters []*variants.Variant, variantOperations variants.IVariantOperations) (*variants.Variant, error) {
+			return variants.VariantFromString("custom"), nil
+		}))
+
+	calculator := scripts.NewScriptCalculator()
+	err := calculator.SetScript("DEF Size(x) = If(x > 10, 'large', 'small'); s := If(TRUE, 'a', 'b'); s + ' ' + Size(20)")
+	assert.Nil(t, err)
+
+	result, err := calculator.Evaluate()
+	assert.Nil(t, err)
+	assert.Equal(t, "a large", result.AsString())
+
+	// Functions passed during evaluation replace lazy functions in expressions and function bodies
+	result, err = calculator.EvaluateUsingVariablesAndFunctions(nil, funcs)
+	assert.Nil(t, err)
+	assert.Equal(t, "custom custom", result.AsString())
+}
+
+func TestScriptCalculatorLoops(t *testing.T) {
+	compiled, err := scripts.CompileScript(`
+		sum := 0;
+		FOR EACH item IN items DO
+			IF item > 0 THEN sum := sum + item END
+		END;
+		n := 1;
+		WHILE n < sum DO n := n * 2 END;
+		n`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"sum", "items", "n"}, compiled.VariableNames())
+
+	vars := variables.NewVariableCollection()
+	vars.Add(variables.NewVariable("item", variants.VariantFromString("outer")))
+	vars.Add(variables.NewVariable("items", variants.VariantFromArray([]*variants.Variant{
+		variants.VariantFromInteger(3), variants.VariantFromInteger(-1), variants.VariantFromInteger(7),
+	})))
+	result, err := compiled.Evaluate(vars, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 16, result.AsInteger())
+	assert.Equal(t, 10, vars.FindByName("sum").Value().AsInteger())
+	// Loop variable does not change outer variables
+	assert.Equal(t, "outer", vars.FindByName("item").Value().AsString())
+
+	// Null collections are not iterated
+	vars = variables.NewVariableCollection()
+	vars.Add(variables.EmptyVariable("items"))
+	result, err = compiled.Evaluate(vars, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.AsInteger())
+}
+
+func TestScriptCalculatorReturn(t *testing.T) {
+	compiled, err := scripts.CompileScript(`
+		FOR EACH x IN Array(1, 2, 3, 4) DO
+			IF x * x > 5 THEN RETURN x END
+		END;
+		RETURN 0`)
+	assert.Nil(t, err)
+	result, err := compiled.Evaluate(nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.AsInteger())
+
+	compiled, err = scripts.CompileScript("a := 1; RETURN; a := 2")
+	assert.Nil(t, err)
+	vars := variables.NewVariableCollection()
+	result, err = compiled.Evaluate(vars, nil)
+	assert.Nil(t, err)
+	assert.True(t, result.IsNull())
+	assert.Equal(t, 1, vars.FindByName("a").Value().AsInteger())
+}
+
+func TestScriptCalculatorLimits(t *testing.T) {
+	compiled, err := scripts.CompileScript("WHILE TRUE DO x := 1 END")
+	assert.Nil(t, err)
+	assert.Equal(t, scripts.DefaultMaxIterations, compiled.Limits().MaxIterations)
+	assert.Equal(t, scripts.DefaultMaxStatements, compiled.Limits().MaxStatements)
+	assert.Equal(t, scripts.DefaultMaxStringLength, compiled.Limits().MaxStringLength)
+	assert.Equal(t, scripts.DefaultMaxArrayLength, compiled.Limits().MaxArrayLength)
+
+	_, err = compiled.WithLimits(scripts.ScriptLimits{MaxIterations: 100}).Evaluate(nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "ITERATION_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+
+	_, err = compiled.WithLimits(scripts.ScriptLimits{MaxStatements: 100}).Evaluate(nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "STATEMENTS_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+
+	limits := scripts.NewScriptLimits()
+	limits.MaxStringLength = 10
+	calculator := scripts.NewScriptCalculator()
+	calculator.SetLimits(limits)
+	err = calculator.SetScript("s := 'x'; FOR EACH i IN Array(1, 2, 3, 4) DO s := s + s END")
+	assert.Nil(t, err)
+	_, err = calculator.Evaluate()
+	assert.NotNil(t, err)
+	assert.Equal(t, "STRING_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+
+	// Sizes of strings and arrays are bounded by default
+	compiled, err = scripts.CompileScript("s := 'ab'; i := 0; WHILE i < 40 DO s := s + s; i := i + 1 END")
+	assert.Nil(t, err)
+	_, err = compiled.Evaluate(nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "STRING_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+
+	compiled, err = scripts.CompileScript("s := Split(Repeat('a,', 200000), ',')")
+	assert.Nil(t, err)
+	_, err = compiled.Evaluate(nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "ARRAY_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+
+	// Statements of nested loops are counted together
+	compiled, err = scripts.CompileScript(
+		"i := 0; WHILE i < 5000 DO j := 0; WHILE j < 5000 DO j := j + 1 END; i := i + 1 END")
+	assert.Nil(t, err)
+	_, err = compiled.Evaluate(nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "STATEMENTS_LIMIT_EXCEEDED", err.(*cerrors.ApplicationError).Code)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = compiled.EvaluateWithContext(ctx, nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, "EVALUATION_CANCELLED", err.(*cerrors.ApplicationError).Code)
+}
+
+func TestScriptCalculatorCompileCache(t *testing.T) {
+	calculator, err := scripts.ScriptCalculatorFromScript("x := 1; x + 1")
+	assert.Nil(t, err)
+	compiled := calculator.Compile()
+	assert.Same(t, compiled, calculator.Compile())
+
+	calculator.SetLimits(scripts.NewScriptLimits())
+	assert.NotSame(t, compiled, calculator.Compile())
+	compiled = calculator.Compile()
+
+	calculator.SetVariantOperations(variants.NewTypeSafeVariantOperations())
+	assert.NotSame(t, compiled, calculator.Compile())
+	compiled = calculator.Compile()
+
+	err = calculator.SetScript("x := 2; x + 1")
+	assert.Nil(t, err)
+	assert.NotSame(t, compiled, calculator.Compile())
+	result, err := calculator.Evaluate()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.AsInteger())
+}
+
+func TestScriptCalculatorSyntaxErrors(t *testing.T) {
+	cases := map[string]string{
+		"IF a THEN b := 1":             errors.ErrMissedEnd,
+		"IF a THEN b := 1 ELSE c := 2": errors.ErrMissedEnd,
+		"WHILE a > 0":                  errors.ErrMissedDo,
+		"FOR EACH 1 IN a DO END":       errors.ErrMissedVariableName,
+		"FOR EACH x a DO END":          errors.ErrMissedIn,
+		"a := ; b":                     errors.ErrMissedExpression,
+		"a := 1 END":                   errors.ErrErrorNear,
+		"a := 1; b := Max(a, 2))":      errors.ErrErrorNear,
+	}
+	for script, code := range cases {
+		_, err := scripts.CompileScript(script)
+		assert.NotNil(t, err, script)
+		if err != nil {
+			assert.Equal(t, code, err.(*cerrors.ApplicationError).Code, script)
+		}
+	}
+}
+
+func TestScriptCalculatorDefinitions(t *testing.T) {
+	compiled, err := scripts.CompileScript(`
+		total := Margin(price, cost) * qty;
+		DEF Margin(price, cost) = (price - cost) * Rate();
+		DEF Rate() = 2;
+		total`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"price", "cost", "qty", "total"}, compiled.VariableNames())
+
+	vars := variables.NewVariableCollection()
+	vars.Add(variables.NewVariable("price", variants.VariantFromInteger(10)))
+	vars.Add(variables.NewVariable("cost", variants.VariantFromInteger(6)))
+	vars.Add(variables.NewVariable("qty", variants.VariantFromInteger(3)))
+	result, err := compiled.Evaluate(vars, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 24, result.AsInteger())
+
+	cases := map[string]string{
+		"DEF A(x) = B(x); DEF B(x) = A(x)":  "FUNCTION_CYCLE",
+		"IF TRUE THEN DEF A(x) = x END":     errors.ErrErrorNear,
+		"DEF A(x) = y":                      errors.ErrUnknownParameter,
+		"DEF A(x) = CASE WHEN x THEN 1 END": "",
+	}
+	for script, code := range cases {
+		_, err := scripts.CompileScript(script)
+		if code == "" {
+			assert.Nil(t, err, script)
+		} else if assert.NotNil(t, err, script) {
+			assert.Equal(t, code, err.(*cerrors.ApplicationError).Code, script)
+		}
+	}
+}
@@ -83,6 +83,27 @@ func TestExpressionTokenizerNumberToken(t *testing.T) {
 	test_tokenizers.AssertAreEqualsTokenLists(t, expectedTokens, tokenList)
 }
 
+func TestExpressionTokenizerDecimalToken(t *testing.T) {
+	tokenString := "12.30m 2M 1.5e3m 3mod"
+	expectedTokens := []*tokenizers.Token{
+		tokenizers.NewToken(tokenizers.Decimal, "12.30", 0, 0),
+		tokenizers.NewToken(tokenizers.Whitespace, " ", 0, 0),
+		tokenizers.NewToken(tokenizers.Decimal, "2", 0, 0),
+		tokenizers.NewToken(tokenizers.Whitespace, " ", 0, 0),
+		tokenizers.NewToken(tokenizers.Decimal, "1.5e3", 0, 0),
+		tokenizers.NewToken(tokenizers.Whitespace, " ", 0, 0),
+		tokenizers.NewToken(tokenizers.Integer, "3", 0, 0),
+		tokenizers.NewToken(tokenizers.Word, "mod", 0, 0),
+	}
+
+	tokenizer := ctokenizers.NewExpressionTokenizer()
+	tokenizer.SetSkipEof(true)
+	tokenizer.SetDecodeStrings(true)
+	tokenList := tokenizer.TokenizeBuffer(tokenString)
+
+	test_tokenizers.AssertAreEqualsTokenLists(t, expectedTokens, tokenList)
+}
+
 func TestExpressionTokenizerExpressionToken(t *testing.T) {
 	tokenString := "A + b / (3 - Max(-123, 1)*2)"
 
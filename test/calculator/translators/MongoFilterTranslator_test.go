@@ -0,0 +1,86 @@
+package test_calculator_translators
+
+import (
+	"encoding/json"
+	"testing"
+
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/translators"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMongoFilterTranslatorTranslate(t *testing.T) {
+	translator := translators.NewMongoFilterTranslator()
+
+	testCases := []struct {
+		expression string
+		filter     string
+	}{
+		{"a > 5 AND b IN ('x','y')", `{"$and":[{"a":{"$gt":5}},{"b":{"$in":["x","y"]}}]}`},
+		{"a = 1 OR b <> 'x' OR c <= -2", `{"$or":[{"a":1},{"b":{"$ne":"x"}},{"c":{"$lte":-2}}]}`},
+		{"5 < a", `{"a":{"$gt":5}}`},
+		{"NOT (a NOT IN (1, 2))", `{"$nor":[{"a":{"$nin":[1,2]}}]}`},
+		{"a.b IS NULL AND c[0] IS NOT NULL", `{"$and":[{"a.b":null},{"c.0":{"$ne":null}}]}`},
+		{"name LIKE 'a.b%' AND code NOT LIKE '\\_x_'", `{"$and":[{"name":{"$regex":"^a\\.b.*$"}},{"code":{"$not":{"$regex":"^_x.$"}}}]}`},
+		{"active", `{"active":true}`},
+		{"sku MATCHES '^[A-Z]{3}-\\d+$' AND zip NOT MATCHES '^\\d{5}$'",
+			`{"$and":[{"sku":{"$regex":"^[A-Z]{3}-\\d+$"}},{"zip":{"$not":{"$regex":"^\\d{5}$"}}}]}`},
+	}
+
+	for _, testCase := range testCases {
+		filter, err := translator.TranslateExpression(testCase.expression)
+		assert.Nil(t, err, testCase.expression)
+		json, _ := json.Marshal(filter)
+		assert.Equal(t, testCase.filter, string(json), testCase.expression)
+	}
+
+	_, err := translator.TranslateExpression("a > 1 AND a + 1 < 5")
+	assert.NotNil(t, err)
+	assert.Equal(t, translators.ErrNotTranslatable, err.(*cerrors.ApplicationError).Code)
+	assert.Contains(t, err.Error(), "at line 1 and column 13")
+
+	_, err = translator.TranslateExpression("a = b")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "at line 1 and column 5")
+}
+
+func TestMongoFilterTranslatorReverse(t *testing.T) {
+	translator := translators.NewMongoFilterTranslator()
+
+	testCases := []struct {
+		filter     string
+		expression string
+	}{
+		{`{"$and":[{"a":{"$gt":5}},{"b":{"$in":["x","y"]}}]}`, "a > 5 AND b IN ('x', 'y')"},
+		{`{"a":1,"b":{"$gte":2,"$lt":3}}`, "a = 1 AND b >= 2 AND b < 3"},
+		{`{"$or":[{"a.b":null},{"c.0":{"$exists":true}}]}`, "a.b IS NULL OR c[0] IS NOT NULL"},
+		{`{"$nor":[{"a":"x"},{"b":{"$nin":[1]}}]}`, "NOT (a = 'x' OR b NOT IN Array(1))"},
+		{`{"name":{"$regex":"^a\\.b.*$"},"code":{"$not":{"$regex":"x_"}}}`, "code NOT LIKE '%x\\_%' AND name LIKE 'a.b%'"},
+		{`{"a":{"$regex":"a+"},"b":{"$not":{"$regex":"^\\d"}}}`, "a MATCHES 'a+' AND b NOT MATCHES '^\\d'"},
+	}
+
+	for _, testCase := range testCases {
+		var filter map[string]any
+		err := json.Unmarshal([]byte(testCase.filter), &filter)
+		assert.Nil(t, err)
+
+		expression, err := translator.ReverseToExpression(filter)
+		assert.Nil(t, err, testCase.filter)
+		assert.Equal(t, testCase.expression, expression, testCase.filter)
+	}
+
+	_, err := translator.ReverseToExpression(map[string]any{"$where": "this.a > 1"})
+	assert.NotNil(t, err)
+}
+
+func TestMongoFilterTranslatorRoundTrip(t *testing.T) {
+	translator := translators.NewMongoFilterTranslator()
+	expression := "(a > 5 OR b LIKE 'x%') AND c IN ('x', 'y') AND NOT d"
+
+	filter, err := translator.TranslateExpression(expression)
+	assert.Nil(t, err)
+	result, err := translator.ReverseToExpression(filter)
+	assert.Nil(t, err)
+	// AND and OR have the same precedence in expressions.
+	assert.Equal(t, "a > 5 OR b LIKE 'x%' AND c IN ('x', 'y') AND NOT d = TRUE", result)
+}
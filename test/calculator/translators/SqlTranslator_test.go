@@ -0,0 +1,113 @@
+package test_calculator_translators
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/translators"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqlTranslatorPostgreSql(t *testing.T) {
+	translator := translators.NewSqlTranslator(translators.NewPostgreSqlDialect())
+
+	testCases := []struct {
+		expression string
+		sql        string
+		parameters []any
+	}{
+		{"a > 5 AND b IN ('x', 'y')", "\"a\" > $1 AND \"b\" IN ($2, $3)", []any{5, "x", "y"}},
+		{"(a = 1 OR b <> 2) AND NOT c", "(\"a\" = $1 OR \"b\" <> $2) AND NOT \"c\"", []any{1, 2}},
+		{"a IS NULL OR b IS NOT NULL", "\"a\" IS NULL OR \"b\" IS NOT NULL", []any{}},
+		{"a = NULL", "\"a\" IS NULL", []any{}},
+		{"a NOT IN (1, 2)", "\"a\" NOT IN ($1, $2)", []any{1, 2}},
+		{"name LIKE 'ab%'", "\"name\" LIKE $1", []any{"ab%"}},
+		{"(a + b) * 2 - c % 3 > 10", "(\"a\" + \"b\") * $1 - \"c\" % $2 > $3", []any{2, 3, 10}},
+		{"a - (b - c)", "\"a\" - (\"b\" - \"c\")", []any{}},
+		{"Upper(name) = 'ABC' AND created < Now()", "UPPER(\"name\") = $1 AND \"created\" < NOW()", []any{"ABC"}},
+		{"a ^ 2 > 4", "POWER(\"a\", $1) > $2", []any{2, 4}},
+		{"'Mr. ' + name = 'Mr. X'", "$1 || \"name\" = $2", []any{"Mr. ", "Mr. X"}},
+		{"t.a >= -1.5", "\"t\".\"a\" >= -$1", []any{float32(1.5)}},
+		{"IF(a > 0, a, 0) < 5", "CASE WHEN \"a\" > $1 THEN \"a\" ELSE $2 END < $3", []any{0, 0, 5}},
+		{"code ~ '^[0-9]+$' OR code !~ 'x'", "\"code\" ~ $1 OR \"code\" !~ $2", []any{"^[0-9]+$", "x"}},
+		{"price * 2 > 12.30m", "\"price\" * $1 > $2", []any{2, "12.30"}},
+		{"a ?? 0 > NullIf(b, '')", "COALESCE(\"a\", $1) > NULLIF(\"b\", $2)", []any{0, ""}},
+	}
+
+	for _, testCase := range testCases {
+		sql, parameters, err := translator.TranslateExpression(testCase.expression)
+		assert.Nil(t, err, testCase.expression)
+		assert.Equal(t, testCase.sql, sql, testCase.expression)
+		assert.Equal(t, testCase.parameters, parameters, testCase.expression)
+	}
+}
+
+func TestSqlTranslatorDialects(t *testing.T) {
+	translator := translators.NewSqlTranslator(translators.NewMySqlDialect())
+	sql, parameters, err := translator.TranslateExpression("name + '!' LIKE 'a%' AND Max(a, b) > 1")
+	assert.Nil(t, err)
+	assert.Equal(t, "CONCAT(`name`, ?) LIKE ? AND GREATEST(`a`, `b`) > ?", sql)
+	assert.Equal(t, []any{"!", "a%", 1}, parameters)
+
+	sql, _, err = translator.TranslateExpression("Len(RTrim(name)) > 3")
+	assert.Nil(t, err)
+	assert.Equal(t, "CHAR_LENGTH(RTRIM(`name`)) > ?", sql)
+
+	sql, _, err = translator.TranslateExpression("sku NOT MATCHES '^[A-Z]+$'")
+	assert.Nil(t, err)
+	assert.Equal(t, "`sku` NOT REGEXP ?", sql)
+
+	translator = translators.NewSqlTranslator(translators.NewSqliteDialect())
+	sql, _, err = translator.TranslateExpression("name NOT LIKE 'a\\_%' AND created < Now()")
+	assert.Nil(t, err)
+	assert.Equal(t, "\"name\" NOT LIKE ? ESCAPE '\\' AND \"created\" < CURRENT_TIMESTAMP", sql)
+
+	translator.SetFunction("Now", translators.NewSqlFunctionTemplate("datetime('now')"))
+	sql, _, err = translator.TranslateExpression("created < Now()")
+	assert.Nil(t, err)
+	assert.Equal(t, "\"created\" < datetime('now')", sql)
+}
+
+func TestSqlTranslatorErrors(t *testing.T) {
+	translator := translators.NewSqlTranslator(nil)
+
+	_, _, err := translator.TranslateExpression("a > 1 AND Foo(b)")
+	assert.NotNil(t, err)
+	assert.Equal(t, translators.ErrNotTranslatable, err.(*cerrors.ApplicationError).Code)
+	assert.Contains(t, err.Error(), "'Foo'")
+	assert.Contains(t, err.Error(), "at line 1 and column 11")
+
+	_, _, err = translator.TranslateExpression("a XOR b")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "'XOR'")
+	assert.Contains(t, err.Error(), "at line 1 and column 3")
+
+	_, _, err = translator.TranslateExpression("a[1] = 2")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "'[]'")
+
+	_, _, err = translator.TranslateExpression("Upper(a, b) = 'X'")
+	assert.NotNil(t, err)
+	assert.Equal(t, translators.ErrNotTranslatable, err.(*cerrors.ApplicationError).Code)
+}
+
+func TestSqlTranslatorConcurrentTranslations(t *testing.T) {
+	translator := translators.NewSqlTranslator(translators.NewPostgreSqlDialect())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sql, parameters, err := translator.TranslateExpression("a = " + strconv.Itoa(i) + " AND b = 'x'")
+				assert.Nil(t, err)
+				assert.Equal(t, "\"a\" = $1 AND \"b\" = $2", sql)
+				assert.Equal(t, []any{i, "x"}, parameters)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,138 @@
+package test_calculator_variables
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCustomer struct {
+	Name    string
+	Country string `expr:"country_code"`
+}
+
+type testOrder struct {
+	Id       string `json:"id"`
+	Total    float64
+	Quantity int16
+	Created  time.Time
+	Timeout  time.Duration
+	Tags     []string
+	Customer testCustomer
+	Secret   string `expr:"-"`
+	internal int
+}
+
+func TestObjectVariableCollectionStruct(t *testing.T) {
+	order := &testOrder{
+		Id:       "123",
+		Total:    10.5,
+		Quantity: 3,
+		Created:  time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		Timeout:  time.Minute,
+		Tags:     []string{"a", "b"},
+		Customer: testCustomer{Name: "John", Country: "US"},
+	}
+	collection := variables.NewObjectVariableCollection(order)
+
+	assert.Equal(t, 7, collection.Length())
+	assert.Nil(t, collection.FindByName("Secret"))
+	assert.Nil(t, collection.FindByName("internal"))
+
+	v := collection.FindByName("id")
+	assert.NotNil(t, v)
+	assert.Equal(t, variants.String, v.Value().Type())
+	assert.Equal(t, "123", v.Value().AsString())
+
+	v = collection.FindByName("quantity")
+	assert.Equal(t, variants.Integer, v.Value().Type())
+	assert.Equal(t, 3, v.Value().AsInteger())
+
+	v = collection.FindByName("Created")
+	assert.Equal(t, variants.DateTime, v.Value().Type())
+
+	v = collection.FindByName("Timeout")
+	assert.Equal(t, variants.TimeSpan, v.Value().Type())
+	assert.Equal(t, time.Minute, v.Value().AsTimeSpan())
+
+	v = collection.FindByName("Tags")
+	assert.Equal(t, variants.Array, v.Value().Type())
+	assert.Equal(t, 2, v.Value().Length())
+	assert.Equal(t, "b", v.Value().GetByIndex(1).AsString())
+
+	v = collection.FindByName("Customer")
+	assert.Equal(t, variants.Object, v.Value().Type())
+
+	collection.FindByName("Total").SetValue(variants.VariantFromInteger(20))
+	assert.Equal(t, 20.0, order.Total)
+
+	collection.FindByName("Tags").SetValue(variants.VariantFromArray([]*variants.Variant{
+		variants.VariantFromString("x"),
+	}))
+	assert.Equal(t, []string{"x"}, order.Tags)
+
+	collection.FindByName("Quantity").SetValue(variants.VariantFromInteger(100000))
+	assert.Equal(t, int16(3), order.Quantity)
+	assert.Equal(t, 100000, collection.FindByName("Quantity").Value().AsInteger())
+
+	collection.Add(variables.NewVariable("extra", variants.VariantFromInteger(1)))
+	assert.Equal(t, 8, collection.Length())
+	assert.Equal(t, 1, collection.FindByName("EXTRA").Value().AsInteger())
+}
+
+func TestObjectVariableCollectionMap(t *testing.T) {
+	data := map[string]any{
+		"a": 1,
+		"b": "xyz",
+		"c": map[string]any{"d": true},
+	}
+	collection := variables.NewObjectVariableCollection(data)
+
+	assert.Equal(t, 3, collection.Length())
+	assert.Equal(t, "a", collection.Get(0).Name())
+	assert.Equal(t, variants.Object, collection.FindByName("c").Value().Type())
+
+	collection.FindByName("a").SetValue(variants.VariantFromInteger(5))
+	assert.Equal(t, 5, data["a"])
+
+	collection.Locate("e").SetValue(variants.VariantFromString("new"))
+	assert.Equal(t, "new", data["e"])
+
+	collection.RemoveByName("b")
+	_, ok := data["b"]
+	assert.False(t, ok)
+}
+
+func TestObjectVariableCollectionEvaluate(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+	err := calc.SetExpression("Total * Quantity")
+	assert.Nil(t, err)
+
+	order := testOrder{Total: 2.5, Quantity: 4}
+	result, err := calc.EvaluateUsingVariables(variables.NewObjectVariableCollection(order))
+	assert.Nil(t, err)
+	assert.Equal(t, variants.Double, result.Type())
+	assert.Equal(t, 10.0, result.AsDouble())
+}
+
+func TestObjectVariableCollectionConcurrentLookup(t *testing.T) {
+	collection := variables.NewObjectVariableCollection(map[string]any{"a": 1, "b": 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.NotNil(t, collection.FindByName("a"))
+				assert.NotNil(t, collection.FindByName("b"))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,34 @@
+package test_calculator_variables
+
+import (
+	"testing"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/calculator/variables"
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopedVariableCollection(t *testing.T) {
+	parent := variables.NewVariableCollection()
+	parent.Add(variables.NewVariable("x", variants.VariantFromInteger(1)))
+	parent.Add(variables.NewVariable("y", variants.VariantFromInteger(2)))
+
+	collection := variables.NewScopedVariableCollection(parent)
+	collection.Add(variables.NewVariable("X", variants.VariantFromInteger(10)))
+
+	assert.Equal(t, 2, collection.Length())
+	assert.Equal(t, 10, collection.FindByName("x").Value().AsInteger())
+	assert.Equal(t, 2, collection.FindByName("y").Value().AsInteger())
+	assert.Equal(t, 0, collection.FindIndexByName("x"))
+
+	v := collection.Locate("z")
+	assert.NotNil(t, v)
+	assert.NotNil(t, parent.FindByName("z"))
+
+	collection.RemoveByName("y")
+	assert.NotNil(t, collection.FindByName("y"))
+
+	collection.Clear()
+	assert.Equal(t, 1, collection.FindByName("x").Value().AsInteger())
+	assert.Equal(t, 3, parent.Length())
+}
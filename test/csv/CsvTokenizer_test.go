@@ -6,6 +6,7 @@ import (
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/csv"
 	test_tokenizers "github.com/pip-services3-gox/pip-services3-expressions-gox/test/tokenizers"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/tokenizers"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestCsvTokenizerWithDefaultParameters(t *testing.T) {
@@ -109,3 +110,9 @@ func TestCsvTokenizerWithOverridenParameters(t *testing.T) {
 
 	test_tokenizers.AssertAreEqualsTokenLists(t, expectedTokens, tokenList)
 }
+
+func TestCsvQuoteStateDecodeUnicode(t *testing.T) {
+	state := csv.NewCsvQuoteState()
+	assert.Equal(t, "héllo \"wörld\"", state.DecodeString("\"héllo \"\"wörld\"\"\"", '"'))
+	assert.Equal(t, "日本", state.DecodeString("\"日本\"", '"'))
+}
@@ -0,0 +1,74 @@
+package test_variants
+
+import (
+	"testing"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimalParse(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected string
+		scale    int
+	}{
+		{"12.30", "12.30", 2},
+		{"-0.05", "-0.05", 2},
+		{"+7", "7", 0},
+		{"1.5e3", "1500", 0},
+		{"1.5e-3", "0.0015", 4},
+		{".5", "0.5", 1},
+	}
+	for _, test := range testCases {
+		value, err := variants.ParseDecimal(test.text)
+		assert.Nil(t, err, test.text)
+		assert.Equal(t, test.expected, value.String(), test.text)
+		assert.Equal(t, test.scale, value.Scale(), test.text)
+	}
+
+	for _, text := range []string{"", "abc", "1.2.3", "--1", "1e", "NaN"} {
+		_, err := variants.ParseDecimal(text)
+		assert.NotNil(t, err, text)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	parse := func(text string) variants.DecimalNumber {
+		value, err := variants.ParseDecimal(text)
+		assert.Nil(t, err)
+		return value
+	}
+
+	assert.Equal(t, "0.3", parse("0.1").Add(parse("0.2")).String())
+	assert.Equal(t, "9.80", parse("12.30").Sub(parse("2.5")).String())
+	assert.Equal(t, "24.60", parse("12.30").Mul(parse("2")).String())
+	assert.Equal(t, "0.3333", parse("1").Div(parse("3"), 4, variants.RoundHalfEven).String())
+	assert.Equal(t, "2.50", parse("10.00").Div(parse("4"), 16, variants.RoundHalfEven).String())
+	assert.Equal(t, "-0.7", parse("-10.3").Mod(parse("3.2")).String())
+	assert.Equal(t, 0, parse("1.50").Cmp(parse("1.5")))
+	assert.Equal(t, -1, parse("-2").Cmp(parse("1.5")))
+	assert.Equal(t, int64(12), parse("12.99").Int64())
+	assert.Equal(t, 12.3, parse("12.30").Float64())
+
+	roundCases := []struct {
+		value    string
+		mode     variants.RoundingMode
+		expected string
+	}{
+		{"2.345", variants.RoundHalfUp, "2.35"},
+		{"2.345", variants.RoundHalfEven, "2.34"},
+		{"2.355", variants.RoundHalfEven, "2.36"},
+		{"-2.345", variants.RoundHalfUp, "-2.35"},
+		{"-2.345", variants.RoundHalfEven, "-2.34"},
+		{"2.341", variants.RoundUp, "2.35"},
+		{"2.349", variants.RoundDown, "2.34"},
+		{"-2.341", variants.RoundCeiling, "-2.34"},
+		{"-2.341", variants.RoundFloor, "-2.35"},
+		{"2.3", variants.RoundHalfUp, "2.3"},
+	}
+	for _, test := range roundCases {
+		assert.Equal(t, test.expected, parse(test.value).Round(2, test.mode).String(), test.value)
+	}
+	assert.Equal(t, "1300", parse("1250").Round(-2, variants.RoundHalfUp).String())
+}
@@ -0,0 +1,44 @@
+package test_variants
+
+import (
+	"testing"
+
+	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchLike(t *testing.T) {
+	matches, err := variants.MatchLike("abcdef", "abc%", '\\', true)
+	assert.Nil(t, err)
+	assert.True(t, matches)
+
+	matches, _ = variants.MatchLike("abcdef", "%c%f", '\\', true)
+	assert.True(t, matches)
+
+	matches, _ = variants.MatchLike("abcdef", "a_c_e_", '\\', true)
+	assert.True(t, matches)
+
+	matches, _ = variants.MatchLike("abcdef", "a_c", '\\', true)
+	assert.False(t, matches)
+
+	matches, _ = variants.MatchLike("", "%", '\\', true)
+	assert.True(t, matches)
+
+	matches, _ = variants.MatchLike("100%", "100\\%", '\\', true)
+	assert.True(t, matches)
+
+	matches, _ = variants.MatchLike("1000", "100\\%", '\\', true)
+	assert.False(t, matches)
+
+	matches, _ = variants.MatchLike("a_b", "a!_b", '!', true)
+	assert.True(t, matches)
+
+	matches, _ = variants.MatchLike("ПРИВЕТ", "прив%", '\\', false)
+	assert.True(t, matches)
+
+	matches, _ = variants.MatchLike("ПРИВЕТ", "прив%", '\\', true)
+	assert.False(t, matches)
+
+	_, err = variants.MatchLike("abc", "abc\\", '\\', true)
+	assert.NotNil(t, err)
+}
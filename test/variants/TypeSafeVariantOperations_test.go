@@ -1,6 +1,7 @@
 package test_variants
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
@@ -37,3 +38,38 @@ func TestSafeOperations(t *testing.T) {
 	v, _ = manager.GetElement(d, variants.NewVariant(1))
 	assert.Equal(t, "bbb", v.AsString())
 }
+
+func TestSafeDecimalOperations(t *testing.T) {
+	manager := variants.NewTypeSafeVariantOperations()
+	price := variants.VariantFromDecimal(variants.NewDecimal(1230, 2))
+
+	v, err := manager.Add(price, variants.NewVariant(2))
+	assert.Nil(t, err)
+	assert.Equal(t, "14.30", v.AsDecimal().String())
+	v, err = manager.Convert(price, variants.Double)
+	assert.Nil(t, err)
+	assert.Equal(t, 12.3, v.AsDouble())
+
+	// Decimals are not silently converted into lossy types.
+	_, err = manager.Convert(price, variants.Integer)
+	assert.NotNil(t, err)
+	_, err = manager.Add(price, variants.NewVariant(0.1))
+	assert.NotNil(t, err)
+}
+
+func TestSafeBigIntegerOperations(t *testing.T) {
+	manager := variants.NewTypeSafeVariantOperations()
+	value := variants.VariantFromBigInteger(big.NewInt(1000))
+
+	v, err := manager.Mul(value, variants.NewVariant(3))
+	assert.Nil(t, err)
+	assert.Equal(t, variants.BigInteger, v.Type())
+	assert.Equal(t, "3000", v.String())
+	v, err = manager.Convert(value, variants.Double)
+	assert.Nil(t, err)
+	assert.Equal(t, 1000.0, v.AsDouble())
+
+	// Big integers are not silently truncated.
+	_, err = manager.Convert(value, variants.Integer)
+	assert.NotNil(t, err)
+}
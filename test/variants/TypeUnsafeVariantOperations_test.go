@@ -1,8 +1,10 @@
 package test_variants
 
 import (
+	"math"
 	"testing"
 
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
 	"github.com/stretchr/testify/assert"
 )
@@ -23,3 +25,132 @@ func TestUnsafeOperations(t *testing.T) {
 	v, _ = manager.Equal(a, b)
 	assert.True(t, v.AsBoolean())
 }
+
+func TestUnsafeDecimalOperations(t *testing.T) {
+	manager := variants.NewTypeUnsafeVariantOperations()
+	price, _ := manager.Convert(variants.NewVariant("12.30"), variants.Decimal)
+	assert.Equal(t, variants.Decimal, price.Type())
+
+	// Integers are promoted to decimals.
+	v, err := manager.Mul(variants.NewVariant(3), price)
+	assert.Nil(t, err)
+	assert.Equal(t, variants.Decimal, v.Type())
+	assert.Equal(t, "36.90", v.String())
+
+	v, _ = manager.Add(price, variants.NewVariant(0.1))
+	assert.Equal(t, "12.40", v.AsDecimal().String())
+	v, _ = manager.Div(price, variants.NewVariant(4))
+	assert.Equal(t, "3.075", v.AsDecimal().String())
+	v, _ = manager.Pow(price, variants.NewVariant(2))
+	assert.Equal(t, "151.2900", v.AsDecimal().String())
+	v, _ = manager.More(price, variants.NewVariant(12.3))
+	assert.False(t, v.AsBoolean())
+	v, _ = manager.Negative(price)
+	assert.Equal(t, "-12.30", v.AsDecimal().String())
+
+	_, err = manager.Div(price, variants.NewVariant(0))
+	assert.NotNil(t, err)
+
+	manager.SetDecimalDivisionScale(2)
+	manager.SetDecimalRoundingMode(variants.RoundHalfUp)
+	v, _ = manager.Div(price, variants.NewVariant(8))
+	assert.Equal(t, "1.54", v.AsDecimal().String())
+
+	v, _ = manager.Convert(price, variants.Integer)
+	assert.Equal(t, 12, v.AsInteger())
+	v, _ = manager.Convert(price, variants.String)
+	assert.Equal(t, "12.30", v.AsString())
+}
+
+func TestUnsafeIntegerOverflow(t *testing.T) {
+	manager := variants.NewTypeUnsafeVariantOperations()
+	max := variants.NewVariant(int64(math.MaxInt64))
+	assert.Equal(t, variants.OverflowError, manager.OverflowMode())
+
+	_, err := manager.Add(max, variants.NewVariant(1))
+	assert.NotNil(t, err)
+	assert.Equal(t, "INTEGER_OVERFLOW", err.(*cerrors.ApplicationError).Code)
+	_, err = manager.Mul(variants.NewVariant(math.MaxInt), variants.NewVariant(2))
+	assert.NotNil(t, err)
+	_, err = manager.Pow(variants.NewVariant(10), variants.NewVariant(19))
+	assert.NotNil(t, err)
+
+	v, _ := manager.Pow(variants.NewVariant(2), variants.NewVariant(10))
+	assert.Equal(t, variants.Integer, v.Type())
+	assert.Equal(t, 1024, v.AsInteger())
+	v, _ = manager.Pow(variants.NewVariant(2), variants.NewVariant(-1))
+	assert.Equal(t, 0.5, v.AsDouble())
+
+	manager.SetOverflowMode(variants.OverflowPromote)
+	v, err = manager.Add(max, variants.NewVariant(1))
+	assert.Nil(t, err)
+	assert.Equal(t, variants.BigInteger, v.Type())
+	assert.Equal(t, "9223372036854775808", v.String())
+	v, _ = manager.Pow(variants.NewVariant(2), variants.NewVariant(100))
+	assert.Equal(t, "1267650600228229401496703205376", v.String())
+	v, _ = manager.Sub(v, variants.NewVariant(1))
+	assert.Equal(t, "1267650600228229401496703205375", v.String())
+	v, _ = manager.Mod(v, variants.NewVariant(1000))
+	assert.Equal(t, variants.BigInteger, v.Type())
+	assert.Equal(t, "375", v.String())
+
+	manager.SetOverflowMode(variants.OverflowWrap)
+	v, _ = manager.Add(max, variants.NewVariant(1))
+	assert.Equal(t, int64(math.MinInt64), v.AsLong())
+
+	big, _ := manager.Convert(variants.NewVariant("123456789012345678901234567890"), variants.BigInteger)
+	assert.Equal(t, variants.BigInteger, big.Type())
+	v, _ = manager.Convert(big, variants.Decimal)
+	assert.Equal(t, "123456789012345678901234567890", v.AsDecimal().String())
+	v, _ = manager.More(big, max)
+	assert.True(t, v.AsBoolean())
+	v, _ = manager.Convert(variants.NewVariant(12.7), variants.BigInteger)
+	assert.Equal(t, "12", v.String())
+	_, err = manager.Convert(variants.NewVariant("12x"), variants.BigInteger)
+	assert.NotNil(t, err)
+}
+
+func TestUnsafeShiftOverflow(t *testing.T) {
+	manager := variants.NewTypeUnsafeVariantOperations()
+
+	v, err := manager.Lsh(variants.NewVariant(1), variants.NewVariant(10))
+	assert.Nil(t, err)
+	assert.Equal(t, variants.Integer, v.Type())
+	assert.Equal(t, 1024, v.AsInteger())
+	v, err = manager.Rsh(variants.NewVariant(int64(-1024)), variants.NewVariant(70))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1), v.AsLong())
+
+	_, err = manager.Lsh(variants.NewVariant(1), variants.NewVariant(70))
+	assert.NotNil(t, err)
+	assert.Equal(t, "INTEGER_OVERFLOW", err.(*cerrors.ApplicationError).Code)
+	_, err = manager.Lsh(variants.NewVariant(int64(math.MaxInt64)), variants.NewVariant(1))
+	assert.NotNil(t, err)
+	assert.Equal(t, "INTEGER_OVERFLOW", err.(*cerrors.ApplicationError).Code)
+
+	for _, value := range []interface{}{1, int64(1)} {
+		_, err = manager.Lsh(variants.NewVariant(value), variants.NewVariant(-1))
+		assert.NotNil(t, err)
+		assert.Equal(t, "NEGATIVE_SHIFT_COUNT", err.(*cerrors.ApplicationError).Code)
+		_, err = manager.Rsh(variants.NewVariant(value), variants.NewVariant(-1))
+		assert.NotNil(t, err)
+		assert.Equal(t, "NEGATIVE_SHIFT_COUNT", err.(*cerrors.ApplicationError).Code)
+	}
+
+	manager.SetOverflowMode(variants.OverflowPromote)
+	v, err = manager.Lsh(variants.NewVariant(1), variants.NewVariant(40))
+	assert.Nil(t, err)
+	assert.Equal(t, "1099511627776", v.String())
+	v, err = manager.Lsh(variants.NewVariant(1), variants.NewVariant(70))
+	assert.Nil(t, err)
+	assert.Equal(t, variants.BigInteger, v.Type())
+	assert.Equal(t, "1180591620717411303424", v.String())
+
+	manager.SetOverflowMode(variants.OverflowWrap)
+	v, err = manager.Lsh(variants.NewVariant(int64(1)), variants.NewVariant(70))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), v.AsLong())
+	v, err = manager.Lsh(variants.NewVariant(int64(3)), variants.NewVariant(63))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(math.MinInt64), v.AsLong())
+}
@@ -1,8 +1,11 @@
 package test_variants
 
 import (
+	"reflect"
 	"testing"
 
+	cerrors "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+
 	"github.com/pip-services3-gox/pip-services3-expressions-gox/variants"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,3 +21,39 @@ func TestVariants(t *testing.T) {
 	assert.Equal(t, "xyz", b.AsString())
 	assert.Equal(t, "xyz", b.AsObject())
 }
+
+func TestAssignVariantToValueRange(t *testing.T) {
+	var i8 int8
+	err := variants.AssignVariantToValue(reflect.ValueOf(&i8).Elem(), variants.VariantFromInteger(127))
+	assert.Nil(t, err)
+	assert.Equal(t, int8(127), i8)
+
+	err = variants.AssignVariantToValue(reflect.ValueOf(&i8).Elem(), variants.VariantFromInteger(128))
+	assert.NotNil(t, err)
+	assert.Equal(t, "VALUE_OUT_OF_RANGE", err.(*cerrors.ApplicationError).Code)
+	assert.Equal(t, int8(127), i8)
+
+	var i32 int32
+	err = variants.AssignVariantToValue(reflect.ValueOf(&i32).Elem(), variants.VariantFromLong(1<<40))
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(0), i32)
+
+	var u uint
+	err = variants.AssignVariantToValue(reflect.ValueOf(&u).Elem(), variants.VariantFromInteger(-1))
+	assert.NotNil(t, err)
+	assert.Equal(t, "VALUE_OUT_OF_RANGE", err.(*cerrors.ApplicationError).Code)
+
+	var u16 uint16
+	err = variants.AssignVariantToValue(reflect.ValueOf(&u16).Elem(), variants.VariantFromInteger(65536))
+	assert.NotNil(t, err)
+	err = variants.AssignVariantToValue(reflect.ValueOf(&u16).Elem(), variants.VariantFromInteger(65535))
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(65535), u16)
+
+	var f32 float32
+	err = variants.AssignVariantToValue(reflect.ValueOf(&f32).Elem(), variants.VariantFromDouble(1e40))
+	assert.NotNil(t, err)
+	err = variants.AssignVariantToValue(reflect.ValueOf(&f32).Elem(), variants.VariantFromDouble(1.5))
+	assert.Nil(t, err)
+	assert.Equal(t, float32(1.5), f32)
+}
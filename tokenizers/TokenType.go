@@ -16,4 +16,5 @@ const (
 	Whitespace
 	Comment
 	Special
+	Decimal
 )
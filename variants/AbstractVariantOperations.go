@@ -1,6 +1,11 @@
 package variants
 
-import "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+import (
+	"math"
+	"math/big"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
 
 type IVariantOperationsOverrides interface {
 	Convert(value *Variant, newType VariantType) (*Variant, error)
@@ -8,16 +13,127 @@ type IVariantOperationsOverrides interface {
 
 // AbstractVariantOperations implements an abstract variant operations manager object.
 type AbstractVariantOperations struct {
-	Overrides IVariantOperationsOverrides
+	Overrides         IVariantOperationsOverrides
+	likeEscapeChar    rune
+	likeCaseSensitive bool
+	strictMembers     bool
+	decimalScale      int
+	decimalRounding   RoundingMode
+	overflowMode      OverflowMode
 }
 
 func InheritAbstractVariantOperations(overrides IVariantOperationsOverrides) *AbstractVariantOperations {
 	c := AbstractVariantOperations{
-		Overrides: overrides,
+		Overrides:         overrides,
+		likeEscapeChar:    DefaultLikeEscapeChar,
+		likeCaseSensitive: true,
+		decimalScale:      DefaultDecimalDivisionScale,
+		decimalRounding:   DefaultRoundingMode,
+		overflowMode:      OverflowError,
 	}
 	return &c
 }
 
+// LikeEscapeChar gets the escape character used in LIKE patterns.
+func (c *AbstractVariantOperations) LikeEscapeChar() rune {
+	return c.likeEscapeChar
+}
+
+// SetLikeEscapeChar sets the escape character used in LIKE patterns.
+// Set it to 0 to disable escaping.
+//	Parameters:
+//		- value: a new escape character.
+func (c *AbstractVariantOperations) SetLikeEscapeChar(value rune) {
+	c.likeEscapeChar = value
+}
+
+// LikeCaseSensitive gets the flag to perform case sensitive LIKE comparisons.
+func (c *AbstractVariantOperations) LikeCaseSensitive() bool {
+	return c.likeCaseSensitive
+}
+
+// SetLikeCaseSensitive sets the flag to perform case sensitive LIKE comparisons.
+//	Parameters:
+//		- value: <code>true</code> for case sensitive comparisons.
+func (c *AbstractVariantOperations) SetLikeCaseSensitive(value bool) {
+	c.likeCaseSensitive = value
+}
+
+// StrictMembers gets the flag to return errors when accessed object members are not found.
+func (c *AbstractVariantOperations) StrictMembers() bool {
+	return c.strictMembers
+}
+
+// SetStrictMembers sets the flag to return errors when accessed object members are not found.
+// When the flag is not set, missing members are returned as Null values.
+//	Parameters:
+//		- value: <code>true</code> to return errors for missing members.
+func (c *AbstractVariantOperations) SetStrictMembers(value bool) {
+	c.strictMembers = value
+}
+
+// DecimalDivisionScale gets the maximum number of fractional digits in results of decimal division.
+func (c *AbstractVariantOperations) DecimalDivisionScale() int {
+	return c.decimalScale
+}
+
+// SetDecimalDivisionScale sets the maximum number of fractional digits in results of decimal division.
+//	Parameters:
+//		- value: a new number of fractional digits.
+func (c *AbstractVariantOperations) SetDecimalDivisionScale(value int) {
+	if value < 0 {
+		value = 0
+	}
+	c.decimalScale = value
+}
+
+// DecimalRoundingMode gets the rounding mode for results of decimal division.
+// By default DefaultRoundingMode (RoundHalfUp) is used, the same as in the Round function.
+func (c *AbstractVariantOperations) DecimalRoundingMode() RoundingMode {
+	return c.decimalRounding
+}
+
+// SetDecimalRoundingMode sets the rounding mode for results of decimal division.
+//	Parameters:
+//		- value: a new rounding mode.
+func (c *AbstractVariantOperations) SetDecimalRoundingMode(value RoundingMode) {
+	c.decimalRounding = value
+}
+
+// OverflowMode gets the way overflows of Integer and Long arithmetic are processed.
+// Left shifts overflow when they lose bits of the shifted value. By default overflows are returned as errors.
+func (c *AbstractVariantOperations) OverflowMode() OverflowMode {
+	return c.overflowMode
+}
+
+// SetOverflowMode sets the way overflows of Integer and Long arithmetic are processed.
+//	Parameters:
+//		- value: a new overflow mode.
+func (c *AbstractVariantOperations) SetOverflowMode(value OverflowMode) {
+	c.overflowMode = value
+}
+
+// promoteInteger converts the first operand of Integer or Long type into BigInteger or Decimal
+// when the second operand has that type, so values are not truncated in expressions like <code>2 * 12.30m</code>.
+// BigInteger first operand is also converted into Decimal.
+//	Parameters:
+//		- value1: The first operand.
+//		- value2: The second operand.
+//	Returns: The promoted first operand.
+func (c *AbstractVariantOperations) promoteInteger(value1 *Variant, value2 *Variant) (*Variant, error) {
+	switch value1.Type() {
+	case Integer, Long:
+		if value2.Type() == BigInteger || value2.Type() == Decimal {
+			return c.Overrides.Convert(value1, value2.Type())
+		}
+	case BigInteger:
+		if value2.Type() == Decimal {
+			return c.Overrides.Convert(value1, Decimal)
+		}
+	}
+	return value1, nil
+}
+
 // typeToString convert variant type to string representation
 //	Parameters:
 //		- value: a variant type to be converted.
@@ -46,6 +162,10 @@ func typeToString(value VariantType) string {
 		return "Object"
 	case Array:
 		return "Array"
+	case Decimal:
+		return "Decimal"
+	case BigInteger:
+		return "BigInteger"
 	default:
 		return "Unknown"
 	}
@@ -68,6 +188,10 @@ func (c *AbstractVariantOperations) Add(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -76,10 +200,11 @@ func (c *AbstractVariantOperations) Add(
 	// Performs operation.
 	switch value1.Type() {
 	case Integer:
-		result.SetAsInteger(value1.AsInteger() + value2.AsInteger())
-		return result, nil
+		return c.integerOperation(Integer, '+', int64(value1.AsInteger()), int64(value2.AsInteger()))
 	case Long:
-		result.SetAsLong(value1.AsLong() + value2.AsLong())
+		return c.integerOperation(Long, '+', value1.AsLong(), value2.AsLong())
+	case BigInteger:
+		result.SetAsBigInteger(new(big.Int).Add(value1.AsBigInteger(), value2.AsBigInteger()))
 		return result, nil
 	case Float:
 		result.SetAsFloat(value1.AsFloat() + value2.AsFloat())
@@ -93,6 +218,9 @@ func (c *AbstractVariantOperations) Add(
 	case String:
 		result.SetAsString(value1.AsString() + value2.AsString())
 		return result, nil
+	case Decimal:
+		result.SetAsDecimal(value1.AsDecimal().Add(value2.AsDecimal()))
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -117,6 +245,10 @@ func (c *AbstractVariantOperations) Sub(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -125,10 +257,11 @@ func (c *AbstractVariantOperations) Sub(
 	// Performs operation.
 	switch value1.Type() {
 	case Integer:
-		result.SetAsInteger(value1.AsInteger() - value2.AsInteger())
-		return result, nil
+		return c.integerOperation(Integer, '-', int64(value1.AsInteger()), int64(value2.AsInteger()))
 	case Long:
-		result.SetAsLong(value1.AsLong() - value2.AsLong())
+		return c.integerOperation(Long, '-', value1.AsLong(), value2.AsLong())
+	case BigInteger:
+		result.SetAsBigInteger(new(big.Int).Sub(value1.AsBigInteger(), value2.AsBigInteger()))
 		return result, nil
 	case Float:
 		result.SetAsFloat(value1.AsFloat() - value2.AsFloat())
@@ -142,6 +275,9 @@ func (c *AbstractVariantOperations) Sub(
 	case DateTime:
 		result.SetAsTimeSpan(value1.AsDateTime().Sub(value2.AsDateTime()))
 		return result, nil
+	case Decimal:
+		result.SetAsDecimal(value1.AsDecimal().Sub(value2.AsDecimal()))
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -166,6 +302,10 @@ func (c *AbstractVariantOperations) Mul(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -174,10 +314,11 @@ func (c *AbstractVariantOperations) Mul(
 	// Performs operation.
 	switch value1.Type() {
 	case Integer:
-		result.SetAsInteger(value1.AsInteger() * value2.AsInteger())
-		return result, nil
+		return c.integerOperation(Integer, '*', int64(value1.AsInteger()), int64(value2.AsInteger()))
 	case Long:
-		result.SetAsLong(value1.AsLong() * value2.AsLong())
+		return c.integerOperation(Long, '*', value1.AsLong(), value2.AsLong())
+	case BigInteger:
+		result.SetAsBigInteger(new(big.Int).Mul(value1.AsBigInteger(), value2.AsBigInteger()))
 		return result, nil
 	case Float:
 		result.SetAsFloat(value1.AsFloat() * value2.AsFloat())
@@ -185,6 +326,9 @@ func (c *AbstractVariantOperations) Mul(
 	case Double:
 		result.SetAsDouble(value1.AsDouble() * value2.AsDouble())
 		return result, nil
+	case Decimal:
+		result.SetAsDecimal(value1.AsDecimal().Mul(value2.AsDecimal()))
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -192,6 +336,11 @@ func (c *AbstractVariantOperations) Mul(
 	return nil, err
 }
 
+// newDivisionByZeroError creates an error for integer or decimal division by zero.
+func newDivisionByZeroError() error {
+	return errors.NewBadRequestError("", "DIVISION_BY_ZERO", "Division by zero")
+}
+
 // Div performs '/' operation for two variants.
 //	Parameters:
 //		- value1: The first operand for this operation.
@@ -209,6 +358,10 @@ func (c *AbstractVariantOperations) Div(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -217,10 +370,20 @@ func (c *AbstractVariantOperations) Div(
 	// Performs operation.
 	switch value1.Type() {
 	case Integer:
-		result.SetAsInteger(value1.AsInteger() / value2.AsInteger())
-		return result, nil
+		if value2.AsInteger() == 0 {
+			return nil, newDivisionByZeroError()
+		}
+		return c.integerOperation(Integer, '/', int64(value1.AsInteger()), int64(value2.AsInteger()))
 	case Long:
-		result.SetAsLong(value1.AsLong() / value2.AsLong())
+		if value2.AsLong() == 0 {
+			return nil, newDivisionByZeroError()
+		}
+		return c.integerOperation(Long, '/', value1.AsLong(), value2.AsLong())
+	case BigInteger:
+		if value2.AsBigInteger().Sign() == 0 {
+			return nil, newDivisionByZeroError()
+		}
+		result.SetAsBigInteger(new(big.Int).Quo(value1.AsBigInteger(), value2.AsBigInteger()))
 		return result, nil
 	case Float:
 		result.SetAsFloat(value1.AsFloat() / value2.AsFloat())
@@ -228,6 +391,12 @@ func (c *AbstractVariantOperations) Div(
 	case Double:
 		result.SetAsDouble(value1.AsDouble() / value2.AsDouble())
 		return result, nil
+	case Decimal:
+		if value2.AsDecimal().IsZero() {
+			return nil, newDivisionByZeroError()
+		}
+		result.SetAsDecimal(value1.AsDecimal().Div(value2.AsDecimal(), c.decimalScale, c.decimalRounding))
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -252,6 +421,10 @@ func (c *AbstractVariantOperations) Mod(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -260,11 +433,29 @@ func (c *AbstractVariantOperations) Mod(
 	// Performs operation.
 	switch value1.Type() {
 	case Integer:
+		if value2.AsInteger() == 0 {
+			return nil, newDivisionByZeroError()
+		}
 		result.SetAsInteger(value1.AsInteger() % value2.AsInteger())
 		return result, nil
 	case Long:
+		if value2.AsLong() == 0 {
+			return nil, newDivisionByZeroError()
+		}
 		result.SetAsLong(value1.AsLong() % value2.AsLong())
 		return result, nil
+	case BigInteger:
+		if value2.AsBigInteger().Sign() == 0 {
+			return nil, newDivisionByZeroError()
+		}
+		result.SetAsBigInteger(new(big.Int).Rem(value1.AsBigInteger(), value2.AsBigInteger()))
+		return result, nil
+	case Decimal:
+		if value2.AsDecimal().IsZero() {
+			return nil, newDivisionByZeroError()
+		}
+		result.SetAsDecimal(value1.AsDecimal().Mod(value2.AsDecimal()))
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -286,13 +477,42 @@ func (c *AbstractVariantOperations) Pow(
 		return result, nil
 	}
 
-	// Performs operation.
+	if value1.Type() == Decimal {
+		return c.powDecimal(value1, value2)
+	}
+
+	// Integer numbers in non-negative integer powers stay integer.
+	exponent := int64(-1)
+	switch value2.Type() {
+	case Integer:
+		exponent = int64(value2.AsInteger())
+	case Long:
+		exponent = value2.AsLong()
+	}
 	switch value1.Type() {
 	case Integer:
+		if exponent >= 0 {
+			return c.integerOperation(Integer, '^', int64(value1.AsInteger()), exponent)
+		}
 	case Long:
-	case Float:
-	case Double:
-		// Converts second operant to the type of the first operand.
+		if exponent >= 0 {
+			return c.integerOperation(Long, '^', value1.AsLong(), exponent)
+		}
+	case BigInteger:
+		if exponent >= 0 {
+			power, err := bigOperation('^', value1.AsBigInteger(), big.NewInt(exponent))
+			if err != nil {
+				return nil, err
+			}
+			result.SetAsBigInteger(power)
+			return result, nil
+		}
+	}
+
+	// Performs operation.
+	switch value1.Type() {
+	case Integer, Long, BigInteger, Float, Double:
+		// Converts both operands to double.
 		var err error
 		value1, err = c.Overrides.Convert(value1, Double)
 		if err != nil {
@@ -304,7 +524,7 @@ func (c *AbstractVariantOperations) Pow(
 			return nil, err
 		}
 
-		result.SetAsDouble(value1.AsDouble() * value2.AsDouble())
+		result.SetAsDouble(math.Pow(value1.AsDouble(), value2.AsDouble()))
 		return result, nil
 	}
 
@@ -313,7 +533,63 @@ func (c *AbstractVariantOperations) Pow(
 	return nil, err
 }
 
+// maxDecimalExponent is the maximum integer exponent that is calculated exactly for decimals.
+const maxDecimalExponent = 1000
+
+// powDecimal performs '^' operation for decimal base. Integer exponents are calculated exactly,
+// other exponents are calculated with floating point numbers.
+//	Parameters:
+//		- value1: The decimal base.
+//		- value2: The exponent.
+//	Returns: A result variant object.
+func (c *AbstractVariantOperations) powDecimal(value1 *Variant, value2 *Variant) (*Variant, error) {
+	exponent, err := c.Overrides.Convert(value2, Decimal)
+	if err != nil {
+		return nil, err
+	}
+
+	base := value1.AsDecimal()
+	power := exponent.AsDecimal()
+	if !power.IsInteger() || power.Abs().Cmp(DecimalFromInt64(maxDecimalExponent)) > 0 {
+		value, err := DecimalFromFloat64(math.Pow(base.Float64(), power.Float64()), 64)
+		if err != nil {
+			return nil, errors.NewBadRequestError("", "OP_NOT_SUPPORTED",
+				"Operation '^' result is not a valid decimal number")
+		}
+		return VariantFromDecimal(value), nil
+	}
+
+	n := power.Int64()
+	if n < 0 && base.IsZero() {
+		return nil, newDivisionByZeroError()
+	}
+	i := n
+	if i < 0 {
+		i = -i
+	}
+	// Calculates the power by squaring.
+	result := DecimalFromInt64(1)
+	for ; i > 0; i /= 2 {
+		if i%2 == 1 {
+			result = result.Mul(base)
+		}
+		if i > 1 {
+			base = base.Mul(base)
+		}
+	}
+	if n < 0 {
+		result = DecimalFromInt64(1).Div(result, c.decimalScale, c.decimalRounding)
+	}
+	return VariantFromDecimal(result), nil
+}
+
+// isBooleanValue checks if the variant is a boolean with the specified value.
+func isBooleanValue(value *Variant, expected bool) bool {
+	return value.Type() == Boolean && value.AsBoolean() == expected
+}
+
 // And performs AND operation for two variants.
+// Logical AND follows three-valued logic: false AND Null is false, true AND Null is Null.
 //	Parameters:
 //		- value1: The first operand for this operation.
 //		- value2: The second operand for this operation.
@@ -323,12 +599,20 @@ func (c *AbstractVariantOperations) And(
 	result := EmptyVariant()
 
 	// Processes VariantType.Null values.
+	// False operand defines the result regardless of the other one.
 	if value1.Type() == Null || value2.Type() == Null {
+		if isBooleanValue(value1, false) || isBooleanValue(value2, false) {
+			result.SetAsBoolean(false)
+		}
 		return result, nil
 	}
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -342,6 +626,9 @@ func (c *AbstractVariantOperations) And(
 	case Long:
 		result.SetAsLong(value1.AsLong() & value2.AsLong())
 		return result, nil
+	case BigInteger:
+		result.SetAsBigInteger(new(big.Int).And(value1.AsBigInteger(), value2.AsBigInteger()))
+		return result, nil
 	case Boolean:
 		result.SetAsBoolean(value1.AsBoolean() && value2.AsBoolean())
 		return result, nil
@@ -353,6 +640,7 @@ func (c *AbstractVariantOperations) And(
 }
 
 // Or performs OR operation for two variants.
+// Logical OR follows three-valued logic: true OR Null is true, false OR Null is Null.
 //	Parameters:
 //		- value1: The first operand for this operation.
 //		- value2: The second operand for this operation.
@@ -363,12 +651,20 @@ func (c *AbstractVariantOperations) Or(
 	result := EmptyVariant()
 
 	// Processes VariantType.Null values.
+	// True operand defines the result regardless of the other one.
 	if value1.Type() == Null || value2.Type() == Null {
+		if isBooleanValue(value1, true) || isBooleanValue(value2, true) {
+			result.SetAsBoolean(true)
+		}
 		return result, nil
 	}
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -382,6 +678,9 @@ func (c *AbstractVariantOperations) Or(
 	case Long:
 		result.SetAsLong(value1.AsLong() | value2.AsLong())
 		return result, nil
+	case BigInteger:
+		result.SetAsBigInteger(new(big.Int).Or(value1.AsBigInteger(), value2.AsBigInteger()))
+		return result, nil
 	case Boolean:
 		result.SetAsBoolean(value1.AsBoolean() || value2.AsBoolean())
 		return result, nil
@@ -408,6 +707,10 @@ func (c *AbstractVariantOperations) Xor(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -421,6 +724,9 @@ func (c *AbstractVariantOperations) Xor(
 	case Long:
 		result.SetAsLong(value1.AsLong() ^ value2.AsLong())
 		return result, nil
+	case BigInteger:
+		result.SetAsBigInteger(new(big.Int).Xor(value1.AsBigInteger(), value2.AsBigInteger()))
+		return result, nil
 	case Boolean:
 		result.SetAsBoolean((value1.AsBoolean() && !value2.AsBoolean()) ||
 			(!value1.AsBoolean() && value2.AsBoolean()))
@@ -433,6 +739,7 @@ func (c *AbstractVariantOperations) Xor(
 }
 
 // Lsh performs '<<' operation for two variants.
+// Overflows are processed according to the overflow mode and negative counts are returned as errors.
 //	Parameters:
 //		- value1: The first operand for this operation.
 //		- value2: The second operand for this operation.
@@ -453,14 +760,19 @@ func (c *AbstractVariantOperations) Lsh(
 		return nil, err
 	}
 
+	count := value2.AsInteger()
+	if count < 0 {
+		return nil, newNegativeShiftError("<<")
+	}
+
 	// Performs operation.
 	switch value1.Type() {
 	case Integer:
-		result.SetAsInteger(value1.AsInteger() << value2.AsInteger())
-		return result, nil
+		return c.integerShift(Integer, "<<", int64(value1.AsInteger()), count)
 	case Long:
-		result.SetAsLong(value1.AsLong() << value2.AsInteger())
-		return result, nil
+		return c.integerShift(Long, "<<", value1.AsLong(), count)
+	case BigInteger:
+		return shiftBigInteger(value1.AsBigInteger(), count)
 	}
 
 	err = errors.NewUnsupportedError("",
@@ -469,6 +781,7 @@ func (c *AbstractVariantOperations) Lsh(
 }
 
 // Rsh performs '>>' operation for two variants.
+// Negative counts are returned as errors.
 //	Parameters:
 //		- value1: The first operand for this operation.
 //		- value2: The second operand for this operation.
@@ -490,14 +803,19 @@ func (c *AbstractVariantOperations) Rsh(
 		return nil, err
 	}
 
+	count := value2.AsInteger()
+	if count < 0 {
+		return nil, newNegativeShiftError(">>")
+	}
+
 	// Performs operation.
 	switch value1.Type() {
 	case Integer:
-		result.SetAsInteger(value1.AsInteger() >> value2.AsInteger())
-		return result, nil
+		return c.integerShift(Integer, ">>", int64(value1.AsInteger()), count)
 	case Long:
-		result.SetAsLong(value1.AsLong() >> value2.AsInteger())
-		return result, nil
+		return c.integerShift(Long, ">>", value1.AsLong(), count)
+	case BigInteger:
+		return shiftBigInteger(value1.AsBigInteger(), -count)
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -527,6 +845,9 @@ func (c *AbstractVariantOperations) Not(value *Variant) (*Variant, error) {
 	case Long:
 		result.SetAsLong(^value.AsLong())
 		return result, nil
+	case BigInteger:
+		result.SetAsBigInteger(new(big.Int).Not(value.AsBigInteger()))
+		return result, nil
 	case Boolean:
 		result.SetAsBoolean(!value.AsBoolean())
 		return result, nil
@@ -552,10 +873,11 @@ func (c *AbstractVariantOperations) Negative(value *Variant) (*Variant, error) {
 	// Performs operation.
 	switch value.Type() {
 	case Integer:
-		result.SetAsInteger(-value.AsInteger())
-		return result, nil
+		return c.integerOperation(Integer, '-', 0, int64(value.AsInteger()))
 	case Long:
-		result.SetAsLong(-value.AsLong())
+		return c.integerOperation(Long, '-', 0, value.AsLong())
+	case BigInteger:
+		result.SetAsBigInteger(new(big.Int).Neg(value.AsBigInteger()))
 		return result, nil
 	case Float:
 		result.SetAsFloat(-value.AsFloat())
@@ -563,6 +885,9 @@ func (c *AbstractVariantOperations) Negative(value *Variant) (*Variant, error) {
 	case Double:
 		result.SetAsDouble(-value.AsDouble())
 		return result, nil
+	case Decimal:
+		result.SetAsDecimal(value.AsDecimal().Neg())
+		return result, nil
 	}
 
 	err := errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -592,6 +917,10 @@ func (c *AbstractVariantOperations) Equal(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -628,6 +957,12 @@ func (c *AbstractVariantOperations) Equal(
 	case Object:
 		result.SetAsBoolean(value1.AsObject() == value2.AsObject())
 		return result, nil
+	case BigInteger:
+		result.SetAsBoolean(value1.AsBigInteger().Cmp(value2.AsBigInteger()) == 0)
+		return result, nil
+	case Decimal:
+		result.SetAsBoolean(value1.AsDecimal().Cmp(value2.AsDecimal()) == 0)
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -657,6 +992,10 @@ func (c *AbstractVariantOperations) NotEqual(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -693,6 +1032,12 @@ func (c *AbstractVariantOperations) NotEqual(
 	case Object:
 		result.SetAsBoolean(value1.AsObject() != value2.AsObject())
 		return result, nil
+	case BigInteger:
+		result.SetAsBoolean(value1.AsBigInteger().Cmp(value2.AsBigInteger()) != 0)
+		return result, nil
+	case Decimal:
+		result.SetAsBoolean(value1.AsDecimal().Cmp(value2.AsDecimal()) != 0)
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -717,6 +1062,10 @@ func (c *AbstractVariantOperations) More(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -745,6 +1094,12 @@ func (c *AbstractVariantOperations) More(
 	case DateTime:
 		result.SetAsBoolean(value1.AsDateTime().After(value2.AsDateTime()))
 		return result, nil
+	case BigInteger:
+		result.SetAsBoolean(value1.AsBigInteger().Cmp(value2.AsBigInteger()) > 0)
+		return result, nil
+	case Decimal:
+		result.SetAsBoolean(value1.AsDecimal().Cmp(value2.AsDecimal()) > 0)
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -769,6 +1124,10 @@ func (c *AbstractVariantOperations) Less(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -797,6 +1156,12 @@ func (c *AbstractVariantOperations) Less(
 	case DateTime:
 		result.SetAsBoolean(value1.AsDateTime().Before(value2.AsDateTime()))
 		return result, nil
+	case BigInteger:
+		result.SetAsBoolean(value1.AsBigInteger().Cmp(value2.AsBigInteger()) < 0)
+		return result, nil
+	case Decimal:
+		result.SetAsBoolean(value1.AsDecimal().Cmp(value2.AsDecimal()) < 0)
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -821,6 +1186,10 @@ func (c *AbstractVariantOperations) MoreEqual(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -851,6 +1220,12 @@ func (c *AbstractVariantOperations) MoreEqual(
 		date2 := value2.AsDateTime()
 		result.SetAsBoolean(date1.After(date2) || date1.Equal(date2))
 		return result, nil
+	case BigInteger:
+		result.SetAsBoolean(value1.AsBigInteger().Cmp(value2.AsBigInteger()) >= 0)
+		return result, nil
+	case Decimal:
+		result.SetAsBoolean(value1.AsDecimal().Cmp(value2.AsDecimal()) >= 0)
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -874,6 +1249,10 @@ func (c *AbstractVariantOperations) LessEqual(
 
 	// Converts second operant to the type of the first operand.
 	var err error
+	value1, err = c.promoteInteger(value1, value2)
+	if err != nil {
+		return nil, err
+	}
 	value2, err = c.Overrides.Convert(value2, value1.Type())
 	if err != nil {
 		return nil, err
@@ -904,6 +1283,12 @@ func (c *AbstractVariantOperations) LessEqual(
 		date2 := value2.AsDateTime()
 		result.SetAsBoolean(date1.Before(date2) || date1.Equal(date2))
 		return result, nil
+	case BigInteger:
+		result.SetAsBoolean(value1.AsBigInteger().Cmp(value2.AsBigInteger()) <= 0)
+		return result, nil
+	case Decimal:
+		result.SetAsBoolean(value1.AsDecimal().Cmp(value2.AsDecimal()) <= 0)
+		return result, nil
 	}
 
 	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
@@ -951,6 +1336,42 @@ func (c *AbstractVariantOperations) In(
 	return c.Equal(value1, value2)
 }
 
+// Like performs LIKE operation for two variants.
+//	Parameters:
+//		- value1: The value to be matched.
+//		- value2: The LIKE pattern.
+//	Returns: A result variant object.
+func (c *AbstractVariantOperations) Like(
+	value1 *Variant, value2 *Variant) (*Variant, error) {
+
+	result := EmptyVariant()
+
+	// Processes VariantType.Null values.
+	if value1.Type() == Null || value2.Type() == Null {
+		return result, nil
+	}
+
+	// Converts both operands to strings.
+	var err error
+	value1, err = c.Overrides.Convert(value1, String)
+	if err != nil {
+		return nil, err
+	}
+	value2, err = c.Overrides.Convert(value2, String)
+	if err != nil {
+		return nil, err
+	}
+
+	// Performs operation.
+	matches, err := MatchLike(value1.AsString(), value2.AsString(), c.likeEscapeChar, c.likeCaseSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	result.SetAsBoolean(matches)
+	return result, nil
+}
+
 // GetElement performs [] operation for two variants.
 //	Parameters:
 //		- value1: The first operand for this operation.
@@ -985,3 +1406,47 @@ func (c *AbstractVariantOperations) GetElement(
 		"Operation '[]' is not supported for type "+typeToString(value1.Type()))
 	return nil, err
 }
+
+// GetMember performs '.' operation for two variants.
+//	Parameters:
+//		- value1: The object which member is accessed.
+//		- value2: The name of the member.
+//	Returns: A result variant object.
+func (c *AbstractVariantOperations) GetMember(
+	value1 *Variant, value2 *Variant) (*Variant, error) {
+	result := EmptyVariant()
+
+	// Processes VariantType.Null values.
+	if value1.Type() == Null || value2.Type() == Null {
+		return result, nil
+	}
+
+	var err error
+	value2, err = c.Overrides.Convert(value2, String)
+	if err != nil {
+		return nil, err
+	}
+
+	name := value2.AsString()
+
+	if value1.Type() == Object {
+		member := FindObjectMember(value1.AsObject(), name)
+		if member != nil {
+			return member.Value(), nil
+		}
+		if !c.strictMembers {
+			return result, nil
+		}
+		err = errors.NewBadRequestError("", "MEMBER_NOT_FOUND",
+			"Member "+name+" was not found")
+		return nil, err
+	}
+
+	if !c.strictMembers {
+		return result, nil
+	}
+
+	err = errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
+		"Operation '.' is not supported for type "+typeToString(value1.Type()))
+	return nil, err
+}
@@ -0,0 +1,339 @@
+package variants
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+// RoundingMode defines how decimal values are rounded when digits are discarded.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest neighbor, ties are rounded away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest neighbor, ties are rounded to the even neighbor (banker's rounding).
+	RoundHalfEven
+	// RoundDown rounds towards zero (truncates).
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundCeiling rounds towards positive infinity.
+	RoundCeiling
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor
+)
+
+// DefaultRoundingMode is the rounding mode used by default for results of decimal division
+// and by the Round function: ties are rounded away from zero, so 2.5 is rounded to 3.
+const DefaultRoundingMode = RoundHalfUp
+
+// DefaultDecimalDivisionScale is the default number of fractional digits kept in results of decimal division.
+const DefaultDecimalDivisionScale = 16
+
+var bigTen = big.NewInt(10)
+
+// DecimalNumber implements an immutable fixed-point decimal number with arbitrary precision.
+// The number is kept as an unscaled integer and a scale that is the number of fractional digits,
+// so 12.30 is kept as 1230 with scale 2. The scale is preserved by operations,
+// so decimals are printed with the same number of digits they were written with.
+// A zero value of DecimalNumber is 0.
+type DecimalNumber struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// NewDecimal creates a decimal from an unscaled value and a scale.
+//	Parameters:
+//		- unscaled: The unscaled integer value.
+//		- scale: The number of fractional digits.
+//	Returns: A created decimal equal to unscaled * 10^-scale.
+func NewDecimal(unscaled int64, scale int) DecimalNumber {
+	if scale < 0 {
+		return DecimalNumber{unscaled: new(big.Int).Mul(big.NewInt(unscaled), pow10(-scale))}
+	}
+	return DecimalNumber{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// DecimalFromInt64 creates a decimal from an integer value.
+//	Parameters:
+//		- value: The integer value.
+//	Returns: A created decimal with zero scale.
+func DecimalFromInt64(value int64) DecimalNumber {
+	return DecimalNumber{unscaled: big.NewInt(value)}
+}
+
+// DecimalFromBigInt creates a decimal from an arbitrary-precision integer value.
+//	Parameters:
+//		- value: The integer value.
+//	Returns: A created decimal with zero scale.
+func DecimalFromBigInt(value *big.Int) DecimalNumber {
+	return DecimalNumber{unscaled: new(big.Int).Set(value)}
+}
+
+// DecimalFromFloat64 creates a decimal from a floating point value.
+// The shortest decimal representation of the value is used, so 0.1 is converted into 0.1 exactly.
+//	Parameters:
+//		- value: The floating point value.
+//		- bitSize: 32 for float32 values or 64 for float64 values.
+//	Returns: A created decimal or error if the value is not a finite number.
+func DecimalFromFloat64(value float64, bitSize int) (DecimalNumber, error) {
+	return ParseDecimal(strconv.FormatFloat(value, 'f', -1, bitSize))
+}
+
+// ParseDecimal parses a decimal from a string like <code>-12.30</code> or <code>1.5e3</code>.
+//	Parameters:
+//		- value: The string to be parsed.
+//	Returns: A parsed decimal or error if the string is not a valid decimal number.
+func ParseDecimal(value string) (DecimalNumber, error) {
+	text := strings.TrimSpace(value)
+	exponent := 0
+	if index := strings.IndexAny(text, "eE"); index >= 0 {
+		var err error
+		exponent, err = strconv.Atoi(text[index+1:])
+		if err != nil {
+			return DecimalNumber{}, newDecimalFormatError(value)
+		}
+		text = text[:index]
+	}
+
+	scale := 0
+	if index := strings.IndexByte(text, '.'); index >= 0 {
+		scale = len(text) - index - 1
+		text = text[:index] + text[index+1:]
+	}
+
+	digits := strings.TrimLeft(text, "+-")
+	if digits == "" || len(text)-len(digits) > 1 || strings.Trim(digits, "0123456789") != "" {
+		return DecimalNumber{}, newDecimalFormatError(value)
+	}
+
+	unscaled, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return DecimalNumber{}, newDecimalFormatError(value)
+	}
+	scale -= exponent
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+	return DecimalNumber{unscaled: unscaled, scale: scale}, nil
+}
+
+func newDecimalFormatError(value string) error {
+	return errors.NewBadRequestError("", "WRONG_FORMAT", "Value '"+value+"' is not a valid decimal number")
+}
+
+// pow10 calculates 10^n for non-negative n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// value gets the unscaled value that is never nil.
+func (d DecimalNumber) value() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// rescale increases the scale of the decimal without changing its value.
+func (d DecimalNumber) rescale(scale int) DecimalNumber {
+	if scale <= d.scale {
+		return d
+	}
+	unscaled := new(big.Int).Mul(d.value(), pow10(scale-d.scale))
+	return DecimalNumber{unscaled: unscaled, scale: scale}
+}
+
+// align converts both decimals to the same scale.
+func align(d1 DecimalNumber, d2 DecimalNumber) (*big.Int, *big.Int, int) {
+	scale := d1.scale
+	if d2.scale > scale {
+		scale = d2.scale
+	}
+	return d1.rescale(scale).value(), d2.rescale(scale).value(), scale
+}
+
+// Scale gets the number of fractional digits.
+func (d DecimalNumber) Scale() int {
+	return d.scale
+}
+
+// Sign gets -1 if the decimal is negative, 0 if it is zero and 1 if it is positive.
+func (d DecimalNumber) Sign() int {
+	return d.value().Sign()
+}
+
+// IsZero checks if the decimal is equal to zero.
+func (d DecimalNumber) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// IsInteger checks if the decimal has no fractional part.
+func (d DecimalNumber) IsInteger() bool {
+	return d.scale == 0 || new(big.Int).Rem(d.value(), pow10(d.scale)).Sign() == 0
+}
+
+// Add calculates the sum of two decimals.
+func (d DecimalNumber) Add(other DecimalNumber) DecimalNumber {
+	value1, value2, scale := align(d, other)
+	return DecimalNumber{unscaled: new(big.Int).Add(value1, value2), scale: scale}
+}
+
+// Sub calculates the difference of two decimals.
+func (d DecimalNumber) Sub(other DecimalNumber) DecimalNumber {
+	value1, value2, scale := align(d, other)
+	return DecimalNumber{unscaled: new(big.Int).Sub(value1, value2), scale: scale}
+}
+
+// Mul calculates the product of two decimals. The scale of the result is the sum of scales.
+func (d DecimalNumber) Mul(other DecimalNumber) DecimalNumber {
+	return DecimalNumber{unscaled: new(big.Int).Mul(d.value(), other.value()), scale: d.scale + other.scale}
+}
+
+// Div calculates the quotient of two decimals. The divisor must not be zero.
+// Trailing zeros of the result are removed but the scale is kept not less than the scale of the dividend.
+//	Parameters:
+//		- other: The divisor.
+//		- scale: The maximum number of fractional digits in the result.
+//		- mode: The rounding mode for discarded digits.
+//	Returns: The calculated quotient.
+func (d DecimalNumber) Div(other DecimalNumber, scale int, mode RoundingMode) DecimalNumber {
+	// Calculates d / other * 10^scale as an integer and rounds the remainder.
+	numerator := new(big.Int).Mul(d.value(), pow10(scale+other.scale))
+	denominator := new(big.Int).Mul(other.value(), pow10(d.scale))
+	result := DecimalNumber{unscaled: roundQuotient(numerator, denominator, mode), scale: scale}
+	return result.trimZeros(d.scale)
+}
+
+// Mod calculates the remainder of truncated division. The divisor must not be zero.
+// The result has the same sign as the dividend.
+func (d DecimalNumber) Mod(other DecimalNumber) DecimalNumber {
+	value1, value2, scale := align(d, other)
+	return DecimalNumber{unscaled: new(big.Int).Rem(value1, value2), scale: scale}
+}
+
+// Neg calculates the negated decimal.
+func (d DecimalNumber) Neg() DecimalNumber {
+	return DecimalNumber{unscaled: new(big.Int).Neg(d.value()), scale: d.scale}
+}
+
+// Abs calculates the absolute value of the decimal.
+func (d DecimalNumber) Abs() DecimalNumber {
+	return DecimalNumber{unscaled: new(big.Int).Abs(d.value()), scale: d.scale}
+}
+
+// Cmp compares two decimals.
+//	Returns: -1 if the decimal is less than other, 0 if they are equal and 1 if it is greater.
+func (d DecimalNumber) Cmp(other DecimalNumber) int {
+	value1, value2, _ := align(d, other)
+	return value1.Cmp(value2)
+}
+
+// Equal checks if two decimals have the same value regardless of their scales.
+func (d DecimalNumber) Equal(other DecimalNumber) bool {
+	return d.Cmp(other) == 0
+}
+
+// Round rounds the decimal to the specified number of fractional digits.
+// Decimals that already have less digits are not changed.
+//	Parameters:
+//		- places: The number of fractional digits to keep. Negative values round to tens, hundreds and so on.
+//		- mode: The rounding mode for discarded digits.
+//	Returns: The rounded decimal.
+func (d DecimalNumber) Round(places int, mode RoundingMode) DecimalNumber {
+	if places >= d.scale {
+		return d
+	}
+	unscaled := roundQuotient(d.value(), pow10(d.scale-places), mode)
+	if places < 0 {
+		return DecimalNumber{unscaled: unscaled.Mul(unscaled, pow10(-places))}
+	}
+	return DecimalNumber{unscaled: unscaled, scale: places}
+}
+
+// trimZeros removes trailing fractional zeros keeping at least minScale digits.
+func (d DecimalNumber) trimZeros(minScale int) DecimalNumber {
+	unscaled := new(big.Int).Set(d.value())
+	scale := d.scale
+	remainder := new(big.Int)
+	for scale > minScale {
+		quotient, rem := new(big.Int).QuoRem(unscaled, bigTen, remainder)
+		if rem.Sign() != 0 {
+			break
+		}
+		unscaled = quotient
+		scale--
+	}
+	return DecimalNumber{unscaled: unscaled, scale: scale}
+}
+
+// roundQuotient divides two integers rounding the result with the specified mode.
+func roundQuotient(numerator *big.Int, denominator *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	// The sign of the exact result defines the direction to round away from zero.
+	sign := numerator.Sign() * denominator.Sign()
+	half := new(big.Int).Abs(remainder)
+	half.Mul(half, big.NewInt(2))
+	compare := half.Cmp(new(big.Int).Abs(denominator))
+
+	away := false
+	switch mode {
+	case RoundHalfUp:
+		away = compare >= 0
+	case RoundHalfEven:
+		away = compare > 0 || (compare == 0 && quotient.Bit(0) == 1)
+	case RoundDown:
+		away = false
+	case RoundUp:
+		away = true
+	case RoundCeiling:
+		away = sign > 0
+	case RoundFloor:
+		away = sign < 0
+	}
+	if away {
+		quotient.Add(quotient, big.NewInt(int64(sign)))
+	}
+	return quotient
+}
+
+// Int64 converts the decimal into an integer discarding the fractional part.
+func (d DecimalNumber) Int64() int64 {
+	return d.Round(0, RoundDown).value().Int64()
+}
+
+// BigInt converts the decimal into an arbitrary-precision integer discarding the fractional part.
+func (d DecimalNumber) BigInt() *big.Int {
+	return new(big.Int).Set(d.Round(0, RoundDown).value())
+}
+
+// Float64 converts the decimal into the nearest floating point value.
+func (d DecimalNumber) Float64() float64 {
+	value, _ := strconv.ParseFloat(d.String(), 64)
+	return value
+}
+
+// String gets a plain string representation of the decimal with all its fractional digits.
+func (d DecimalNumber) String() string {
+	digits := new(big.Int).Abs(d.value()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
@@ -2,6 +2,14 @@ package variants
 
 // IVariantOperations defines an interface for variant operations manager.
 type IVariantOperations interface {
+	// OverflowMode gets the way overflows of Integer and Long arithmetic are processed.
+	OverflowMode() OverflowMode
+
+	// SetOverflowMode sets the way overflows of Integer and Long arithmetic are processed.
+	//	Parameters:
+	//		- value: a new overflow mode.
+	SetOverflowMode(value OverflowMode)
+
 	// Convert variant to specified type
 	//	Parameters:
 	//		- value: A variant value to be converted.
@@ -147,10 +155,24 @@ type IVariantOperations interface {
 	//	Returns: A result variant object.
 	In(value1 *Variant, value2 *Variant) (*Variant, error)
 
+	// Like performs LIKE operation for two variants.
+	//	Parameters:
+	//		- value1: The value to be matched.
+	//		- value2: The LIKE pattern.
+	//	Returns: A result variant object.
+	Like(value1 *Variant, value2 *Variant) (*Variant, error)
+
 	// GetElement performs [] operation for two variants.
 	//	Parameters:
 	//		- value1: The first operand for this operation.
 	//		- value2: The second operand for this operation.
 	//	Returns: A result variant object.
 	GetElement(value1 *Variant, value2 *Variant) (*Variant, error)
+
+	// GetMember performs '.' operation for two variants.
+	//	Parameters:
+	//		- value1: The object which member is accessed.
+	//		- value2: The name of the member.
+	//	Returns: A result variant object.
+	GetMember(value1 *Variant, value2 *Variant) (*Variant, error)
 }
@@ -0,0 +1,120 @@
+package variants
+
+import (
+	"unicode"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+// DefaultLikeEscapeChar is the escape character used in LIKE patterns by default.
+const DefaultLikeEscapeChar = '\\'
+
+// likePatternElement represents a single element of a compiled LIKE pattern.
+type likePatternElement struct {
+	// wildcard is '%' for any sequence, '_' for any single character and 0 for a literal.
+	wildcard rune
+	literal  rune
+}
+
+// compileLikePattern breaks a LIKE pattern into literals and wildcards.
+//	Parameters:
+//		- pattern: a LIKE pattern to be compiled.
+//		- escapeChar: an escape character or 0 if escaping is not supported.
+//	Returns: a list of pattern elements.
+func compileLikePattern(pattern string, escapeChar rune) ([]likePatternElement, error) {
+	runes := []rune(pattern)
+	result := make([]likePatternElement, 0, len(runes))
+
+	for i := 0; i < len(runes); i++ {
+		chr := runes[i]
+		if escapeChar != 0 && chr == escapeChar {
+			if i+1 >= len(runes) {
+				err := errors.NewBadRequestError("", "INVALID_PATTERN",
+					"LIKE pattern '"+pattern+"' ends with an escape character")
+				return nil, err
+			}
+			i++
+			result = append(result, likePatternElement{literal: runes[i]})
+		} else if chr == '%' {
+			// Collapses sequences of '%' into one element.
+			if len(result) > 0 && result[len(result)-1].wildcard == '%' {
+				continue
+			}
+			result = append(result, likePatternElement{wildcard: '%'})
+		} else if chr == '_' {
+			result = append(result, likePatternElement{wildcard: '_'})
+		} else {
+			result = append(result, likePatternElement{literal: chr})
+		}
+	}
+
+	return result, nil
+}
+
+// equalRunes compares two characters with or without case sensitivity.
+func equalRunes(chr1 rune, chr2 rune, caseSensitive bool) bool {
+	if chr1 == chr2 {
+		return true
+	}
+	if caseSensitive {
+		return false
+	}
+	return unicode.ToLower(chr1) == unicode.ToLower(chr2) ||
+		unicode.ToUpper(chr1) == unicode.ToUpper(chr2)
+}
+
+// MatchLike checks if a string matches SQL-style LIKE pattern.
+// The pattern may contain '%' that matches any sequence of characters
+// and '_' that matches exactly one character. Wildcards preceded by the escape character
+// are treated as literals.
+//	Parameters:
+//		- value: a string value to be checked.
+//		- pattern: a LIKE pattern.
+//		- escapeChar: an escape character or 0 to disable escaping.
+//		- caseSensitive: <code>true</code> to perform case sensitive comparison.
+//	Returns: <code>true</code> if the value matches the pattern.
+func MatchLike(value string, pattern string, escapeChar rune, caseSensitive bool) (bool, error) {
+	elements, err := compileLikePattern(pattern, escapeChar)
+	if err != nil {
+		return false, err
+	}
+
+	runes := []rune(value)
+	valueIndex := 0
+	patternIndex := 0
+	// Positions to backtrack after the last '%' wildcard.
+	starPatternIndex := -1
+	starValueIndex := 0
+
+	for valueIndex < len(runes) {
+		if patternIndex < len(elements) {
+			element := elements[patternIndex]
+			if element.wildcard == '%' {
+				starPatternIndex = patternIndex
+				starValueIndex = valueIndex
+				patternIndex++
+				continue
+			}
+			if element.wildcard == '_' || equalRunes(element.literal, runes[valueIndex], caseSensitive) {
+				valueIndex++
+				patternIndex++
+				continue
+			}
+		}
+
+		// Backtracks to the last '%' and lets it absorb one more character.
+		if starPatternIndex < 0 {
+			return false, nil
+		}
+		starValueIndex++
+		valueIndex = starValueIndex
+		patternIndex = starPatternIndex + 1
+	}
+
+	// Skips trailing '%' wildcards.
+	for patternIndex < len(elements) && elements[patternIndex].wildcard == '%' {
+		patternIndex++
+	}
+
+	return patternIndex == len(elements), nil
+}
@@ -0,0 +1,210 @@
+package variants
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+// ObjectMember refers to a field of a Go struct or an entry of a Go map
+// that can be read and, if possible, written as a variant value.
+// Struct fields are named by "expr" tag, "json" tag or field name in that order.
+// Fields with tag <code>expr:"-"</code> and unexported fields are skipped.
+type ObjectMember struct {
+	name  string
+	owner reflect.Value
+	field reflect.Value
+	key   reflect.Value
+}
+
+// Name gets the member name.
+func (c *ObjectMember) Name() string {
+	return c.name
+}
+
+// Value gets the current member value converted to variant.
+func (c *ObjectMember) Value() *Variant {
+	if c.owner.Kind() == reflect.Map {
+		return variantFromReflectValue(c.owner.MapIndex(c.key))
+	}
+	return variantFromReflectValue(c.field)
+}
+
+// CanSet checks if the member value can be changed.
+func (c *ObjectMember) CanSet() bool {
+	if c.owner.Kind() == reflect.Map {
+		return true
+	}
+	return c.field.CanSet()
+}
+
+// SetValue writes a new value into the underlying struct field or map entry.
+//	Parameters:
+//		- value: a new member value.
+//	Returns: error if the value cannot be written or converted.
+func (c *ObjectMember) SetValue(value *Variant) error {
+	if c.owner.Kind() == reflect.Map {
+		element := reflect.New(c.owner.Type().Elem()).Elem()
+		err := AssignVariantToValue(element, value)
+		if err != nil {
+			return err
+		}
+		c.owner.SetMapIndex(c.key, element)
+		return nil
+	}
+	return AssignVariantToValue(c.field, value)
+}
+
+// Remove deletes the member from the underlying map.
+// Struct fields cannot be removed.
+//	Returns: error if the member cannot be removed.
+func (c *ObjectMember) Remove() error {
+	if c.owner.Kind() == reflect.Map {
+		c.owner.SetMapIndex(c.key, reflect.Value{})
+		return nil
+	}
+	return errors.NewUnsupportedError("", "OP_NOT_SUPPORTED",
+		"Struct field "+c.name+" cannot be removed")
+}
+
+// resolveObject dereferences pointers and interfaces until a struct or map is found.
+func resolveObject(obj any) reflect.Value {
+	value, ok := obj.(reflect.Value)
+	if !ok {
+		value = reflect.ValueOf(obj)
+	}
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		if value.Type() == reflect.PointerTo(variantType) {
+			value = reflect.ValueOf(value.Interface().(*Variant).AsObject())
+			continue
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Map && value.Type().Key().Kind() != reflect.String {
+		return reflect.Value{}
+	}
+	if value.Kind() != reflect.Map && value.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return value
+}
+
+// structFieldName gets the member name for a struct field or empty string if the field shall be skipped.
+func structFieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	for _, tagName := range []string{"expr", "json"} {
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// ListObjectMembers gets all members of a Go struct or a map with string keys.
+// Pointers and Object variants are dereferenced. Fields of embedded structs are promoted.
+//	Parameters:
+//		- obj: a struct, a map or a pointer to them.
+//	Returns: a list of object members or empty list if the object has no members.
+func ListObjectMembers(obj any) []*ObjectMember {
+	value := resolveObject(obj)
+	result := []*ObjectMember{}
+
+	switch value.Kind() {
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			result = append(result, &ObjectMember{
+				name:  key.String(),
+				owner: value,
+				key:   key,
+			})
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				result = append(result, ListObjectMembers(value.Field(i))...)
+				continue
+			}
+			name := structFieldName(field)
+			if name == "" {
+				continue
+			}
+			result = append(result, &ObjectMember{
+				name:  name,
+				owner: value,
+				field: value.Field(i),
+			})
+		}
+	}
+
+	return result
+}
+
+// FindObjectMember finds a member of a Go struct or a map with string keys by its name.
+// Exact names are matched first, then names are compared case insensitive.
+//	Parameters:
+//		- obj: a struct, a map or a pointer to them.
+//		- name: the name of the member.
+//	Returns: the found member or nil if the member was not found.
+func FindObjectMember(obj any, name string) *ObjectMember {
+	value := resolveObject(obj)
+
+	if value.Kind() == reflect.Map {
+		key := reflect.ValueOf(name).Convert(value.Type().Key())
+		if value.MapIndex(key).IsValid() {
+			return &ObjectMember{name: name, owner: value, key: key}
+		}
+	}
+
+	members := ListObjectMembers(value)
+	for _, member := range members {
+		if member.name == name {
+			return member
+		}
+	}
+	for _, member := range members {
+		if strings.EqualFold(member.name, name) {
+			return member
+		}
+	}
+	return nil
+}
+
+// AddObjectMember adds a new entry with a zero value into a map with string keys.
+// If the entry already exists it is returned without changes.
+//	Parameters:
+//		- obj: a map or a pointer to it.
+//		- name: the name of the entry.
+//	Returns: the added member or nil if the object is not a map.
+func AddObjectMember(obj any, name string) *ObjectMember {
+	value := resolveObject(obj)
+	if value.Kind() != reflect.Map {
+		return nil
+	}
+	if value.IsNil() {
+		return nil
+	}
+
+	key := reflect.ValueOf(name).Convert(value.Type().Key())
+	if !value.MapIndex(key).IsValid() {
+		value.SetMapIndex(key, reflect.Zero(value.Type().Elem()))
+	}
+	return &ObjectMember{name: name, owner: value, key: key}
+}
@@ -0,0 +1,188 @@
+package variants
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+// OverflowMode defines how overflows of Integer and Long arithmetic are processed.
+type OverflowMode int
+
+const (
+	// OverflowError returns INTEGER_OVERFLOW error when the result does not fit into the operand type.
+	OverflowError OverflowMode = iota
+	// OverflowPromote promotes the result to Long or BigInteger type that keeps the exact value.
+	OverflowPromote
+	// OverflowWrap wraps the result around like native Go arithmetic.
+	OverflowWrap
+)
+
+// maxBigIntegerBits is the maximum size of BigInteger values created by powers and shifts,
+// so expressions like <code>2 ^ 1000000000000</code> cannot exhaust the memory.
+const maxBigIntegerBits = 1 << 20
+
+// newOverflowError creates an error for integer overflow.
+func newOverflowError(operation string) error {
+	return errors.NewBadRequestError("", "INTEGER_OVERFLOW",
+		"Integer overflow in operation '"+operation+"'")
+}
+
+// newNegativeShiftError creates an error for shift by a negative number of bits.
+func newNegativeShiftError(operation string) error {
+	return errors.NewBadRequestError("", "NEGATIVE_SHIFT_COUNT",
+		"Negative shift count in operation '"+operation+"'")
+}
+
+// checkedOperation performs arithmetic operation on 64-bit integers.
+//	Parameters:
+//		- operation: The operation: '+', '-', '*', '/' or '^'. The exponent of '^' must not be negative.
+//		- value1: The first operand.
+//		- value2: The second operand. The divisor of '/' must not be zero.
+//	Returns: The result and <code>false</code> if the operation overflowed.
+func checkedOperation(operation rune, value1 int64, value2 int64) (int64, bool) {
+	switch operation {
+	case '+':
+		result := value1 + value2
+		return result, (result > value1) == (value2 > 0)
+	case '-':
+		result := value1 - value2
+		return result, (result < value1) == (value2 > 0)
+	case '*':
+		result := value1 * value2
+		if value1 == 0 || value2 == 0 {
+			return result, true
+		}
+		return result, result/value1 == value2 && !(value1 == -1 && result == value2)
+	case '/':
+		return value1 / value2, !(value1 == math.MinInt64 && value2 == -1)
+	case '^':
+		// Calculates the power by squaring.
+		result, base, valid := int64(1), value1, true
+		for ; value2 > 0; value2 /= 2 {
+			var ok bool
+			if value2%2 == 1 {
+				result, ok = checkedOperation('*', result, base)
+				valid = valid && ok
+			}
+			if value2 > 1 {
+				base, ok = checkedOperation('*', base, base)
+				valid = valid && ok
+			}
+		}
+		return result, valid
+	}
+	return 0, false
+}
+
+// bigOperation performs arithmetic operation on arbitrary-precision integers.
+// Operations are the same as in checkedOperation.
+func bigOperation(operation rune, value1 *big.Int, value2 *big.Int) (*big.Int, error) {
+	switch operation {
+	case '+':
+		return new(big.Int).Add(value1, value2), nil
+	case '-':
+		return new(big.Int).Sub(value1, value2), nil
+	case '*':
+		return new(big.Int).Mul(value1, value2), nil
+	case '/':
+		return new(big.Int).Quo(value1, value2), nil
+	case '^':
+		if value1.CmpAbs(big.NewInt(1)) > 0 &&
+			(!value2.IsInt64() || value2.Int64() > maxBigIntegerBits/int64(value1.BitLen()-1)) {
+			return nil, newOverflowError(string(operation))
+		}
+		return new(big.Int).Exp(value1, value2, nil), nil
+	}
+	return nil, newOverflowError(string(operation))
+}
+
+// integerOperation performs arithmetic operation on Integer or Long values.
+// Overflows are processed according to the overflow mode.
+//	Parameters:
+//		- typ: The type of operands: Integer or Long.
+//		- operation: The operation: '+', '-', '*', '/' or '^'.
+//		- value1: The first operand.
+//		- value2: The second operand.
+//	Returns: A result variant object.
+func (c *AbstractVariantOperations) integerOperation(typ VariantType,
+	operation rune, value1 int64, value2 int64) (*Variant, error) {
+	result, ok := checkedOperation(operation, value1, value2)
+	if ok && typ == Integer && int64(int(result)) != result {
+		ok = false
+	}
+
+	if ok || c.overflowMode == OverflowWrap {
+		if typ == Integer {
+			return VariantFromInteger(int(result)), nil
+		}
+		return VariantFromLong(result), nil
+	}
+	if c.overflowMode == OverflowError {
+		return nil, newOverflowError(string(operation))
+	}
+
+	exact, err := bigOperation(operation, big.NewInt(value1), big.NewInt(value2))
+	if err != nil {
+		return nil, err
+	}
+	if exact.IsInt64() {
+		return VariantFromLong(exact.Int64()), nil
+	}
+	return VariantFromBigInteger(exact), nil
+}
+
+// integerShift performs shift operation on Integer or Long values.
+// Left shifts that lose bits are processed according to the overflow mode.
+//	Parameters:
+//		- typ: The type of the shifted value: Integer or Long.
+//		- operation: The operation: '<<' or '>>'.
+//		- value: The shifted value.
+//		- count: The number of bits to shift. It must not be negative.
+//	Returns: A result variant object.
+func (c *AbstractVariantOperations) integerShift(typ VariantType,
+	operation string, value int64, count int) (*Variant, error) {
+	var result int64
+	ok := true
+	if operation == ">>" {
+		result = value >> uint(count)
+	} else {
+		result = value << uint(count)
+		ok = count < 64 && result>>uint(count) == value
+	}
+	if ok && typ == Integer && int64(int(result)) != result {
+		ok = false
+	}
+
+	if ok || c.overflowMode == OverflowWrap {
+		if typ == Integer {
+			return VariantFromInteger(int(result)), nil
+		}
+		return VariantFromLong(result), nil
+	}
+	if c.overflowMode == OverflowError {
+		return nil, newOverflowError(operation)
+	}
+
+	exact, err := shiftBigInteger(big.NewInt(value), count)
+	if err != nil {
+		return nil, err
+	}
+	if exact.AsBigInteger().IsInt64() {
+		return VariantFromLong(exact.AsBigInteger().Int64()), nil
+	}
+	return exact, nil
+}
+
+// shiftBigInteger shifts arbitrary-precision integer to the left for positive counts
+// and to the right for negative counts.
+func shiftBigInteger(value *big.Int, count int) (*Variant, error) {
+	if count < 0 {
+		return VariantFromBigInteger(new(big.Int).Rsh(value, uint(-count))), nil
+	}
+	if value.BitLen()+count > maxBigIntegerBits {
+		return nil, newOverflowError("<<")
+	}
+	return VariantFromBigInteger(new(big.Int).Lsh(value, uint(count))), nil
+}
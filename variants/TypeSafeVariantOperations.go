@@ -1,6 +1,10 @@
 package variants
 
-import "github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+import (
+	"math/big"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
 
 // TypeSafeVariantOperations implements a strongly typed (type safe) variant operations manager object.
 type TypeSafeVariantOperations struct {
@@ -36,6 +40,10 @@ func (c *TypeSafeVariantOperations) Convert(
 		return c.convertFromLong(value, newType)
 	case Float:
 		return c.convertFromFloat(value, newType)
+	case Decimal:
+		return c.convertFromDecimal(value, newType)
+	case BigInteger:
+		return c.convertFromBigInteger(value, newType)
 	case Double:
 		break
 	case String:
@@ -68,6 +76,12 @@ func (c *TypeSafeVariantOperations) convertFromInteger(
 	case Double:
 		result.SetAsDouble(float64(value.AsInteger()))
 		return result, nil
+	case Decimal:
+		result.SetAsDecimal(DecimalFromInt64(int64(value.AsInteger())))
+		return result, nil
+	case BigInteger:
+		result.SetAsBigInteger(big.NewInt(int64(value.AsInteger())))
+		return result, nil
 	}
 
 	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
@@ -87,6 +101,12 @@ func (c *TypeSafeVariantOperations) convertFromLong(
 	case Double:
 		result.SetAsDouble(float64(value.AsLong()))
 		return result, nil
+	case Decimal:
+		result.SetAsDecimal(DecimalFromInt64(value.AsLong()))
+		return result, nil
+	case BigInteger:
+		result.SetAsBigInteger(big.NewInt(value.AsLong()))
+		return result, nil
 	}
 
 	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
@@ -110,3 +130,39 @@ func (c *TypeSafeVariantOperations) convertFromFloat(
 			" to "+typeToString(newType)+" is not supported.")
 	return nil, err
 }
+
+func (c *TypeSafeVariantOperations) convertFromDecimal(
+	value *Variant, newType VariantType) (*Variant, error) {
+
+	result := EmptyVariant()
+	switch newType {
+	case Double:
+		result.SetAsDouble(value.AsDecimal().Float64())
+		return result, nil
+	}
+
+	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
+		"Variant convertion from "+typeToString(value.Type())+
+			" to "+typeToString(newType)+" is not supported.")
+	return nil, err
+}
+
+func (c *TypeSafeVariantOperations) convertFromBigInteger(
+	value *Variant, newType VariantType) (*Variant, error) {
+
+	result := EmptyVariant()
+	switch newType {
+	case Double:
+		number, _ := new(big.Float).SetInt(value.AsBigInteger()).Float64()
+		result.SetAsDouble(number)
+		return result, nil
+	case Decimal:
+		result.SetAsDecimal(DecimalFromBigInt(value.AsBigInteger()))
+		return result, nil
+	}
+
+	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
+		"Variant convertion from "+typeToString(value.Type())+
+			" to "+typeToString(newType)+" is not supported.")
+	return nil, err
+}
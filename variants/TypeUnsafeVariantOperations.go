@@ -2,6 +2,8 @@ package variants
 
 import (
 	"math"
+	"math/big"
+	"strings"
 	"time"
 
 	cconv "github.com/pip-services3-gox/pip-services3-commons-gox/convert"
@@ -50,6 +52,10 @@ func (c *TypeUnsafeVariantOperations) Convert(
 		return c.convertFromFloat(value, newType)
 	case Double:
 		return c.convertFromDouble(value, newType)
+	case Decimal:
+		return c.convertFromDecimal(value, newType)
+	case BigInteger:
+		return c.convertFromBigInteger(value, newType)
 	case DateTime:
 		return c.convertFromDateTime(value, newType)
 	case TimeSpan:
@@ -101,6 +107,12 @@ func (c *TypeUnsafeVariantOperations) convertFromNull(
 	case Array:
 		result.SetAsArray([]*Variant{})
 		return result, nil
+	case Decimal:
+		result.SetAsDecimal(DecimalFromInt64(0))
+		return result, nil
+	case BigInteger:
+		result.SetAsBigInteger(new(big.Int))
+		return result, nil
 	}
 
 	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
@@ -132,6 +144,12 @@ func (c *TypeUnsafeVariantOperations) convertFromInteger(
 	case Boolean:
 		result.SetAsBoolean(value.AsInteger() != 0)
 		return result, nil
+	case Decimal:
+		result.SetAsDecimal(DecimalFromInt64(int64(value.AsInteger())))
+		return result, nil
+	case BigInteger:
+		result.SetAsBigInteger(big.NewInt(int64(value.AsInteger())))
+		return result, nil
 	}
 
 	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
@@ -163,6 +181,12 @@ func (c *TypeUnsafeVariantOperations) convertFromLong(
 	case Boolean:
 		result.SetAsBoolean(value.AsLong() != 0)
 		return result, nil
+	case Decimal:
+		result.SetAsDecimal(DecimalFromInt64(value.AsLong()))
+		return result, nil
+	case BigInteger:
+		result.SetAsBigInteger(big.NewInt(value.AsLong()))
+		return result, nil
 	}
 
 	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
@@ -188,6 +212,15 @@ func (c *TypeUnsafeVariantOperations) convertFromFloat(
 	case Boolean:
 		result.SetAsBoolean(value.AsFloat() != 0)
 		return result, nil
+	case Decimal:
+		decimal, err := DecimalFromFloat64(float64(value.AsFloat()), 32)
+		if err != nil {
+			return nil, err
+		}
+		result.SetAsDecimal(decimal)
+		return result, nil
+	case BigInteger:
+		return c.convertFromDouble(VariantFromDouble(float64(value.AsFloat())), newType)
 	}
 
 	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
@@ -213,6 +246,21 @@ func (c *TypeUnsafeVariantOperations) convertFromDouble(
 	case Boolean:
 		result.SetAsBoolean(value.AsDouble() != 0)
 		return result, nil
+	case Decimal:
+		decimal, err := DecimalFromFloat64(value.AsDouble(), 64)
+		if err != nil {
+			return nil, err
+		}
+		result.SetAsDecimal(decimal)
+		return result, nil
+	case BigInteger:
+		number := value.AsDouble()
+		if math.IsNaN(number) || math.IsInf(number, 0) {
+			break
+		}
+		integer, _ := big.NewFloat(math.Trunc(number)).Int(nil)
+		result.SetAsBigInteger(integer)
+		return result, nil
 	}
 
 	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
@@ -247,6 +295,22 @@ func (c *TypeUnsafeVariantOperations) convertFromString(
 	case Boolean:
 		result.SetAsBoolean(cconv.BooleanConverter.ToBoolean(value.AsString()))
 		return result, nil
+	case Decimal:
+		decimal, err := ParseDecimal(value.AsString())
+		if err != nil {
+			return nil, err
+		}
+		result.SetAsDecimal(decimal)
+		return result, nil
+	case BigInteger:
+		integer, ok := new(big.Int).SetString(strings.TrimSpace(value.AsString()), 10)
+		if !ok {
+			err := errors.NewBadRequestError("", "WRONG_FORMAT",
+				"Value '"+value.AsString()+"' is not a valid integer number")
+			return nil, err
+		}
+		result.SetAsBigInteger(integer)
+		return result, nil
 	}
 
 	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
@@ -295,6 +359,87 @@ func (c *TypeUnsafeVariantOperations) convertFromBoolean(
 			result.SetAsString("false")
 		}
 		return result, nil
+	case Decimal:
+		if value.AsBoolean() {
+			result.SetAsDecimal(DecimalFromInt64(1))
+		} else {
+			result.SetAsDecimal(DecimalFromInt64(0))
+		}
+		return result, nil
+	case BigInteger:
+		if value.AsBoolean() {
+			result.SetAsBigInteger(big.NewInt(1))
+		} else {
+			result.SetAsBigInteger(new(big.Int))
+		}
+		return result, nil
+	}
+
+	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
+		"Variant convertion from "+typeToString(value.Type())+
+			" to "+typeToString(newType)+" is not supported.")
+	return nil, err
+}
+
+func (c *TypeUnsafeVariantOperations) convertFromDecimal(
+	value *Variant, newType VariantType) (*Variant, error) {
+
+	result := EmptyVariant()
+	switch newType {
+	case Integer:
+		result.SetAsInteger(int(value.AsDecimal().Int64()))
+		return result, nil
+	case Long:
+		result.SetAsLong(value.AsDecimal().Int64())
+		return result, nil
+	case Float:
+		result.SetAsFloat(float32(value.AsDecimal().Float64()))
+		return result, nil
+	case Double:
+		result.SetAsDouble(value.AsDecimal().Float64())
+		return result, nil
+	case Boolean:
+		result.SetAsBoolean(!value.AsDecimal().IsZero())
+		return result, nil
+	case BigInteger:
+		result.SetAsBigInteger(value.AsDecimal().BigInt())
+		return result, nil
+	}
+
+	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
+		"Variant convertion from "+typeToString(value.Type())+
+			" to "+typeToString(newType)+" is not supported.")
+	return nil, err
+}
+
+func (c *TypeUnsafeVariantOperations) convertFromBigInteger(
+	value *Variant, newType VariantType) (*Variant, error) {
+
+	result := EmptyVariant()
+	switch newType {
+	case Integer:
+		result.SetAsInteger(int(value.AsBigInteger().Int64()))
+		return result, nil
+	case Long:
+		result.SetAsLong(value.AsBigInteger().Int64())
+		return result, nil
+	case Float:
+		number, _ := new(big.Float).SetInt(value.AsBigInteger()).Float32()
+		result.SetAsFloat(number)
+		return result, nil
+	case Double:
+		number, _ := new(big.Float).SetInt(value.AsBigInteger()).Float64()
+		result.SetAsDouble(number)
+		return result, nil
+	case Decimal:
+		result.SetAsDecimal(DecimalFromBigInt(value.AsBigInteger()))
+		return result, nil
+	case String:
+		result.SetAsString(value.AsBigInteger().String())
+		return result, nil
+	case Boolean:
+		result.SetAsBoolean(value.AsBigInteger().Sign() != 0)
+		return result, nil
 	}
 
 	err := errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
@@ -1,6 +1,7 @@
 package variants
 
 import (
+	"math/big"
 	"time"
 
 	cconv "github.com/pip-services3-gox/pip-services3-commons-gox/convert"
@@ -82,6 +83,26 @@ func VariantFromDouble(value float64) *Variant {
 	return c
 }
 
+// VariantFromDecimal creates a new variant from Decimal value.
+//	Parameters:
+//		- value: a variant value.
+//	Returns: A created variant object
+func VariantFromDecimal(value DecimalNumber) *Variant {
+	c := &Variant{}
+	c.SetAsDecimal(value)
+	return c
+}
+
+// VariantFromBigInteger creates a new variant from BigInteger value.
+//	Parameters:
+//		- value: a variant value.
+//	Returns: A created variant object
+func VariantFromBigInteger(value *big.Int) *Variant {
+	c := &Variant{}
+	c.SetAsBigInteger(value)
+	return c
+}
+
 // VariantFromString creates a new variant from String value.
 //	Parameters:
 //		- value: a variant value.
@@ -202,6 +223,34 @@ func (c *Variant) SetAsDouble(value float64) {
 	c.value = value
 }
 
+// AsDecimal gets variant value as decimal
+func (c *Variant) AsDecimal() DecimalNumber {
+	return c.value.(DecimalNumber)
+}
+
+// SetAsDecimal sets variant value as decimal
+//	Parameters:
+//		- value a value to be set
+func (c *Variant) SetAsDecimal(value DecimalNumber) {
+	c.typ = Decimal
+	c.value = value
+}
+
+// AsBigInteger gets variant value as arbitrary-precision integer.
+// The returned value must not be modified.
+func (c *Variant) AsBigInteger() *big.Int {
+	return c.value.(*big.Int)
+}
+
+// SetAsBigInteger sets variant value as arbitrary-precision integer.
+// The value is not copied, so it must not be modified after it is set.
+//	Parameters:
+//		- value a value to be set
+func (c *Variant) SetAsBigInteger(value *big.Int) {
+	c.typ = BigInteger
+	c.value = value
+}
+
 // AsString gets variant value as string
 func (c *Variant) AsString() string {
 	return c.value.(string)
@@ -278,6 +327,10 @@ func (c *Variant) SetAsObject(value any) {
 		c.typ = Float
 	case float64:
 		c.typ = Double
+	case DecimalNumber:
+		c.typ = Decimal
+	case *big.Int:
+		c.typ = BigInteger
 	case bool:
 		c.typ = Boolean
 	case time.Time:
@@ -430,6 +483,12 @@ func (c *Variant) Equals(obj *Variant) bool {
 	if value1 == nil || value2 == nil {
 		return value1 == value2
 	}
+	if c.typ == Decimal && obj.typ == Decimal {
+		return c.AsDecimal().Equal(obj.AsDecimal())
+	}
+	if c.typ == BigInteger && obj.typ == BigInteger {
+		return c.AsBigInteger().Cmp(obj.AsBigInteger()) == 0
+	}
 	return c.typ == obj.typ && value1 == value2
 }
 
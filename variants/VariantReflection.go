@@ -0,0 +1,299 @@
+package variants
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/pip-services3-gox/pip-services3-commons-gox/errors"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+var durationType = reflect.TypeOf(time.Duration(0))
+var variantType = reflect.TypeOf(Variant{})
+var decimalType = reflect.TypeOf(DecimalNumber{})
+var bigIntegerType = reflect.TypeOf(&big.Int{})
+
+// VariantFromValue creates a new variant from an arbitrary Go value.
+// Unlike NewVariant it converts all numeric kinds to numeric variants,
+// dereferences pointers, converts slices and arrays to Array variants with converted elements,
+// and keeps structs and maps as Object variants that can be accessed by members.
+//	Parameters:
+//		- value: a Go value to be converted.
+//	Returns: a created variant object.
+func VariantFromValue(value any) *Variant {
+	if value == nil {
+		return EmptyVariant()
+	}
+	switch v := value.(type) {
+	case *Variant:
+		if v == nil {
+			return EmptyVariant()
+		}
+		return v
+	case []*Variant:
+		return VariantFromArray(v)
+	}
+	return variantFromReflectValue(reflect.ValueOf(value))
+}
+
+func variantFromReflectValue(value reflect.Value) *Variant {
+	if !value.IsValid() {
+		return EmptyVariant()
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return EmptyVariant()
+		}
+		if value.Kind() == reflect.Pointer && value.Type().Elem() == variantType {
+			return value.Interface().(*Variant)
+		}
+		if value.Type() == bigIntegerType {
+			return VariantFromBigInteger(value.Interface().(*big.Int))
+		}
+		return variantFromReflectValue(value.Elem())
+	case reflect.Bool:
+		return VariantFromBoolean(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return VariantFromInteger(int(value.Int()))
+	case reflect.Int64:
+		if value.Type() == durationType {
+			return VariantFromTimeSpan(time.Duration(value.Int()))
+		}
+		return VariantFromLong(value.Int())
+	case reflect.Uint8, reflect.Uint16:
+		return VariantFromInteger(int(value.Uint()))
+	case reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > math.MaxInt64 {
+			return VariantFromBigInteger(new(big.Int).SetUint64(value.Uint()))
+		}
+		return VariantFromLong(int64(value.Uint()))
+	case reflect.Float32:
+		return VariantFromFloat(float32(value.Float()))
+	case reflect.Float64:
+		return VariantFromDouble(value.Float())
+	case reflect.String:
+		return VariantFromString(value.String())
+	case reflect.Slice:
+		if value.IsNil() {
+			return EmptyVariant()
+		}
+		return variantFromReflectArray(value)
+	case reflect.Array:
+		return variantFromReflectArray(value)
+	case reflect.Struct:
+		if value.Type() == timeType {
+			return VariantFromDateTime(value.Interface().(time.Time))
+		}
+		if value.Type() == decimalType {
+			return VariantFromDecimal(value.Interface().(DecimalNumber))
+		}
+		if value.Type() == variantType {
+			result := value.Interface().(Variant)
+			return &result
+		}
+	case reflect.Map:
+		if value.IsNil() {
+			return EmptyVariant()
+		}
+	}
+
+	if !value.CanInterface() {
+		return EmptyVariant()
+	}
+	return VariantFromObject(value.Interface())
+}
+
+func variantFromReflectArray(value reflect.Value) *Variant {
+	elements := make([]*Variant, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		elements[i] = variantFromReflectValue(value.Index(i))
+	}
+	return VariantFromArray(elements)
+}
+
+// VariantToValue converts a variant into a plain Go value.
+// Array variants are converted into []any with converted elements.
+//	Parameters:
+//		- value: a variant to be converted.
+//	Returns: a Go value.
+func VariantToValue(value *Variant) any {
+	if value == nil || value.IsNull() {
+		return nil
+	}
+	if value.Type() == Array {
+		array := value.AsArray()
+		result := make([]any, len(array))
+		for i, element := range array {
+			result[i] = VariantToValue(element)
+		}
+		return result
+	}
+	return value.AsObject()
+}
+
+func newValueOutOfRangeError(value *Variant, targetType reflect.Type) error {
+	return errors.NewBadRequestError("", "VALUE_OUT_OF_RANGE",
+		"Value "+value.String()+" is out of range of "+targetType.String())
+}
+
+// AssignVariantToValue assigns a variant to a settable Go value converting it to the target type.
+//	Parameters:
+//		- target: a settable reflected value.
+//		- value: a variant to be assigned.
+//	Returns: error if the value cannot be set or converted
+//		or does not fit into the target numeric type.
+func AssignVariantToValue(target reflect.Value, value *Variant) error {
+	if !target.CanSet() {
+		return errors.NewUnsupportedError("", "VALUE_NOT_SETTABLE",
+			"Value of type "+target.Type().String()+" cannot be set")
+	}
+
+	if value == nil || value.IsNull() {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	converter := NewTypeUnsafeVariantOperations()
+	targetType := target.Type()
+
+	switch {
+	case targetType == variantType:
+		target.Set(reflect.ValueOf(*value))
+		return nil
+	case targetType == reflect.PointerTo(variantType):
+		target.Set(reflect.ValueOf(value))
+		return nil
+	case targetType == timeType:
+		converted, err := converter.Convert(value, DateTime)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(converted.AsDateTime()))
+		return nil
+	case targetType == durationType:
+		converted, err := converter.Convert(value, TimeSpan)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(converted.AsTimeSpan()))
+		return nil
+	case targetType == decimalType:
+		converted, err := converter.Convert(value, Decimal)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(converted.AsDecimal()))
+		return nil
+	case targetType == bigIntegerType:
+		converted, err := converter.Convert(value, BigInteger)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(converted.AsBigInteger()))
+		return nil
+	}
+
+	switch targetType.Kind() {
+	case reflect.Interface:
+		result := reflect.ValueOf(VariantToValue(value))
+		if !result.Type().AssignableTo(targetType) {
+			break
+		}
+		target.Set(result)
+		return nil
+	case reflect.Pointer:
+		element := reflect.New(targetType.Elem())
+		err := AssignVariantToValue(element.Elem(), value)
+		if err != nil {
+			return err
+		}
+		target.Set(element)
+		return nil
+	case reflect.Bool:
+		converted, err := converter.Convert(value, Boolean)
+		if err != nil {
+			return err
+		}
+		target.SetBool(converted.AsBoolean())
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		converted, err := converter.Convert(value, Long)
+		if err != nil {
+			return err
+		}
+		if target.OverflowInt(converted.AsLong()) {
+			return newValueOutOfRangeError(value, targetType)
+		}
+		target.SetInt(converted.AsLong())
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.Type() == BigInteger && value.AsBigInteger().IsUint64() {
+			if target.OverflowUint(value.AsBigInteger().Uint64()) {
+				return newValueOutOfRangeError(value, targetType)
+			}
+			target.SetUint(value.AsBigInteger().Uint64())
+			return nil
+		}
+		converted, err := converter.Convert(value, Long)
+		if err != nil {
+			return err
+		}
+		if converted.AsLong() < 0 || target.OverflowUint(uint64(converted.AsLong())) {
+			return newValueOutOfRangeError(value, targetType)
+		}
+		target.SetUint(uint64(converted.AsLong()))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		converted, err := converter.Convert(value, Double)
+		if err != nil {
+			return err
+		}
+		if target.OverflowFloat(converted.AsDouble()) {
+			return newValueOutOfRangeError(value, targetType)
+		}
+		target.SetFloat(converted.AsDouble())
+		return nil
+	case reflect.String:
+		converted, err := converter.Convert(value, String)
+		if err != nil {
+			return err
+		}
+		target.SetString(converted.AsString())
+		return nil
+	case reflect.Slice:
+		if value.Type() != Array {
+			break
+		}
+		array := value.AsArray()
+		result := reflect.MakeSlice(targetType, len(array), len(array))
+		for i, element := range array {
+			err := AssignVariantToValue(result.Index(i), element)
+			if err != nil {
+				return err
+			}
+		}
+		target.Set(result)
+		return nil
+	default:
+		if value.AsObject() == nil {
+			break
+		}
+		result := reflect.ValueOf(value.AsObject())
+		if result.Type().AssignableTo(targetType) {
+			target.Set(result)
+			return nil
+		}
+		if result.Kind() == reflect.Pointer && result.Type().Elem().AssignableTo(targetType) && !result.IsNil() {
+			target.Set(result.Elem())
+			return nil
+		}
+	}
+
+	return errors.NewUnsupportedError("", "CONV_NOT_SUPPORTED",
+		"Variant convertion from "+typeToString(value.Type())+
+			" to "+targetType.String()+" is not supported.")
+}